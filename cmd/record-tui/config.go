@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/choonkeat/record-tui/playback"
+)
+
+// configFileName is the config file read from the recordings directory
+// (~/.record-tui/config.json) unless -config names another
+const configFileName = "config.json"
+
+// flagEnvs maps flags to the environment variables that set their defaults,
+// which take precedence over the config file
+var flagEnvs = map[string]string{
+	"open-cmd": openerEnv,
+	"no-open":  noOpenEnv,
+}
+
+// configOptions are the flags a config file may set: defaults for how
+// recordings are made, rendered and opened. Flags saying what to do or
+// which files to do it to (-convert, -log, -append, -dry-run, -format, ...)
+// are only taken from the command line.
+var configOptions = map[string]bool{
+	"keep-alt-screen": true, "stash-alt-screen": true, "open-cmd": true, "no-open": true, "timeout": true, "separate-stderr": true,
+	"banner": true, "view": true, "sanitize-binary": true, "colors": true,
+	"overflow": true, "pages": true, "show-env": true, "env-allow": true,
+	"redact": true, "output-only": true, "trim-idle": true, "trim-prompt": true,
+	"error-nav": true, "toc-exclude": true, "toc-exclude-pattern": true,
+	"collapse-repeats": true, "show-typed": true, "line-times": true,
+	"final-screen": true, "screen-cols": true, "screen-rows": true,
+	"prompt-pattern": true, "title": true, "footer-link": true,
+	"open-graph": true, "og-image": true,
+}
+
+// defaultConfigPath returns ~/.record-tui/config.json
+func defaultConfigPath() (string, error) {
+	baseDir, err := getBaseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(baseDir, configFileName), nil
+}
+
+// loadConfig reads a config file of flag defaults: a JSON object of flag
+// names (without the dash) and their values, e.g.
+//
+//	{"redact": true, "title": "{command}", "toc-exclude": "ls,pwd"}
+//
+// Returns the values as they'd be given on the command line. A missing file
+// is an empty config unless required (named with -config).
+func loadConfig(path string, required bool) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var s string
+		switch value = bytes.TrimSpace(value); {
+		case len(value) > 0 && value[0] == '"':
+			if err := json.Unmarshal(value, &s); err != nil {
+				return nil, fmt.Errorf("%s: %q: %w", path, name, err)
+			}
+		case len(value) > 0 && (value[0] == '[' || value[0] == '{'):
+			return nil, fmt.Errorf("%s: %q must be a string, number or boolean", path, name)
+		default:
+			s = string(value)
+		}
+		cfg[name] = s
+	}
+	return cfg, nil
+}
+
+// applyConfig sets the flags in cfg that weren't given on the command line
+// (nor by their environment variable, see flagEnvs), so flags override the
+// config file, which overrides the built-in defaults. Only configOptions
+// may be set.
+func applyConfig(fs *flag.FlagSet, cfg map[string]string) error {
+	given := map[string]bool{"config": true}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for name, env := range flagEnvs {
+		if os.Getenv(env) != "" {
+			given[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(cfg))
+	for name := range cfg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown option %q", name)
+		}
+		if !configOptions[name] {
+			return fmt.Errorf("%q can only be given on the command line", name)
+		}
+		if given[name] {
+			continue
+		}
+		if err := fs.Set(name, cfg[name]); err != nil {
+			return fmt.Errorf("invalid %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// conflictingOptions are the pairs of configOptions that can't be used
+// together (see dropConfigConflicts)
+var conflictingOptions = [][2]string{
+	{"pages", "output-only"}, {"pages", "stash-alt-screen"}, {"pages", "final-screen"},
+	{"keep-alt-screen", "stash-alt-screen"}, {"pages", "since"}, {"pages", "until"},
+}
+
+// dropConfigConflicts resets to its default each flag the config file set
+// that conflicts (see conflictingOptions) with one given on the command
+// line, so the command line overrides the config file rather than making
+// every invocation fail. given are the flags set before applyConfig.
+func dropConfigConflicts(fs *flag.FlagSet, given map[string]bool) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, pair := range conflictingOptions {
+		if !set[pair[0]] || !set[pair[1]] {
+			continue
+		}
+		for i, name := range pair {
+			if given[pair[1-i]] && !given[name] {
+				resetFlag(fs, name)
+			}
+		}
+	}
+}
+
+// resetFlag sets a flag back to its default value
+func resetFlag(fs *flag.FlagSet, name string) {
+	f := fs.Lookup(name)
+	f.Value.Set(f.DefValue)
+}
+
+// parseFooterLink parses a -footer-link value, "text=url"; empty is no link
+func parseFooterLink(value string) (playback.FooterLink, error) {
+	if value == "" {
+		return playback.FooterLink{}, nil
+	}
+	text, url, ok := strings.Cut(value, "=")
+	if !ok || text == "" || url == "" {
+		return playback.FooterLink{}, fmt.Errorf("want text=url, got %q", value)
+	}
+	return playback.FooterLink{Text: text, URL: url}, nil
+}
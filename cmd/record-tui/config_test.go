@@ -0,0 +1,196 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/choonkeat/record-tui/internal/record"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), configFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := loadConfig(writeConfig(t, `{"redact": true, "title": "CI: {command}", "pages": 20, "timeout": "30m"}`), true)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	want := map[string]string{"redact": "true", "title": "CI: {command}", "pages": "20", "timeout": "30m"}
+	if len(cfg) != len(want) {
+		t.Errorf("got %v, want %v", cfg, want)
+	}
+	for name, value := range want {
+		if cfg[name] != value {
+			t.Errorf("%s = %q, want %q", name, cfg[name], value)
+		}
+	}
+
+	missing := filepath.Join(t.TempDir(), configFileName)
+	if cfg, err := loadConfig(missing, false); err != nil || cfg != nil {
+		t.Errorf("missing optional config: got %v, %v", cfg, err)
+	}
+	if _, err := loadConfig(missing, true); err == nil {
+		t.Error("missing -config file should fail")
+	}
+	for _, bad := range []string{`{"redact": `, `["redact"]`, `{"toc-exclude": ["ls"]}`} {
+		if _, err := loadConfig(writeConfig(t, bad), true); err == nil {
+			t.Errorf("loadConfig(%s) should fail", bad)
+		}
+	}
+}
+
+func TestApplyConfig_Precedence(t *testing.T) {
+	fs := flag.NewFlagSet("record-tui", flag.ContinueOnError)
+	title := fs.String("title", "", "")
+	redact := fs.Bool("redact", false, "")
+	pages := fs.Int("pages", 0, "")
+	colors := fs.String("colors", "", "")
+	if err := fs.Parse([]string{"-title", "from flag", "-redact=false"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := applyConfig(fs, map[string]string{"title": "from config", "redact": "true", "pages": "20"})
+	if err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	// flag > config > built-in default
+	if *title != "from flag" || *redact {
+		t.Errorf("flags should win: title = %q, redact = %t", *title, *redact)
+	}
+	if *pages != 20 {
+		t.Errorf("config should set pages, got %d", *pages)
+	}
+	if *colors != "" {
+		t.Errorf("colors should keep its default, got %q", *colors)
+	}
+}
+
+func TestApplyConfig_CommandLineOnly(t *testing.T) {
+	// Flags saying what to do can't be defaulted from the config file
+	for _, name := range []string{"convert", "log", "append", "timing", "input", "since", "until", "format"} {
+		fs := flag.NewFlagSet("record-tui", flag.ContinueOnError)
+		value := fs.String(name, "", "")
+		fs.Parse(nil)
+		err := applyConfig(fs, map[string]string{name: "session.log"})
+		if err == nil || !strings.Contains(err.Error(), "command line") {
+			t.Errorf("%s: applyConfig = %v, want a command line only error", name, err)
+		}
+		if *value != "" {
+			t.Errorf("%s = %q, want it unset", name, *value)
+		}
+	}
+	for _, name := range []string{"dry-run", "version", "streaming"} {
+		fs := flag.NewFlagSet("record-tui", flag.ContinueOnError)
+		value := fs.Bool(name, false, "")
+		fs.Parse(nil)
+		if err := applyConfig(fs, map[string]string{name: "true"}); err == nil || *value {
+			t.Errorf("%s: applyConfig = %v, %s = %t, want an error and it unset", name, err, name, *value)
+		}
+	}
+}
+
+func TestApplyConfig_EnvBeatsConfig(t *testing.T) {
+	t.Setenv(openerEnv, "code {dir}")
+	fs := flag.NewFlagSet("record-tui", flag.ContinueOnError)
+	openCmd := fs.String("open-cmd", os.Getenv(openerEnv), "")
+	fs.Parse(nil)
+
+	if err := applyConfig(fs, map[string]string{"open-cmd": "firefox {html}"}); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+	if *openCmd != "code {dir}" {
+		t.Errorf("open-cmd = %q, want the environment's", *openCmd)
+	}
+}
+
+func TestApplyConfig_Invalid(t *testing.T) {
+	fs := flag.NewFlagSet("record-tui", flag.ContinueOnError)
+	fs.Int("pages", 0, "")
+	fs.String("config", "", "")
+	fs.Parse(nil)
+
+	for _, cfg := range []map[string]string{{"nope": "1"}, {"pages": "many"}, {"config": "other.json"}} {
+		if err := applyConfig(fs, cfg); err == nil {
+			t.Errorf("applyConfig(%v) should fail", cfg)
+		}
+	}
+}
+
+func TestDropConfigConflicts(t *testing.T) {
+	fs := flag.NewFlagSet("record-tui", flag.ContinueOnError)
+	pages := fs.Int("pages", 0, "")
+	finalScreen := fs.Bool("final-screen", false, "")
+	keepAltScreen := fs.Bool("keep-alt-screen", false, "")
+	stashAltScreen := fs.Bool("stash-alt-screen", false, "")
+	outputOnly := fs.Bool("output-only", false, "")
+	if err := fs.Parse([]string{"-final-screen", "-stash-alt-screen", "-keep-alt-screen"}); err != nil {
+		t.Fatal(err)
+	}
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	if err := applyConfig(fs, map[string]string{"pages": "20", "output-only": "true"}); err != nil {
+		t.Fatalf("applyConfig: %v", err)
+	}
+
+	dropConfigConflicts(fs, given)
+	// The config's -pages gives way to -final-screen and -stash-alt-screen
+	if *pages != 0 || !*finalScreen || !*stashAltScreen {
+		t.Errorf("pages = %d, final-screen = %t, stash-alt-screen = %t, want 0, true, true", *pages, *finalScreen, *stashAltScreen)
+	}
+	// -output-only only conflicted with the dropped -pages, and conflicts on
+	// the command line are left to fail
+	if !*keepAltScreen || !*outputOnly {
+		t.Errorf("keep-alt-screen = %t, output-only = %t, want both kept", *keepAltScreen, *outputOnly)
+	}
+}
+
+func TestEnvBool(t *testing.T) {
+	for value, want := range map[string]bool{
+		"": false, "0": false, "false": false, "no": false, "Off": false,
+		"1": true, "true": true, "yes": true, "on": true,
+	} {
+		t.Setenv(noOpenEnv, value)
+		if got := envBool(noOpenEnv); got != want {
+			t.Errorf("envBool with %q = %t, want %t", value, got, want)
+		}
+	}
+}
+
+func TestUnsupportedFlags(t *testing.T) {
+	given := map[string]bool{"title": true, "keep-alt-screen": true, "overflow": true}
+	want := "keep-alt-screen,title"
+	for _, format := range []string{record.FormatStreaming, record.FormatCast} {
+		if got := strings.Join(unsupportedFlags(given, format), ","); got != want {
+			t.Errorf("%s: got %q, want %q", format, got, want)
+		}
+	}
+	for _, format := range []string{record.FormatHTML, record.FormatText, record.FormatSVG} {
+		if got := unsupportedFlags(given, format); len(got) != 0 {
+			t.Errorf("%s: got %q, want none", format, got)
+		}
+	}
+}
+
+func TestParseFooterLink(t *testing.T) {
+	link, err := parseFooterLink("my-team=https://example.com/?a=b")
+	if err != nil || link.Text != "my-team" || link.URL != "https://example.com/?a=b" {
+		t.Errorf("got %+v, %v", link, err)
+	}
+	if link, err := parseFooterLink(""); err != nil || link.Text != "" {
+		t.Errorf("empty: got %+v, %v", link, err)
+	}
+	for _, bad := range []string{"my-team", "=https://example.com", "my-team="} {
+		if _, err := parseFooterLink(bad); err == nil {
+			t.Errorf("parseFooterLink(%q) should fail", bad)
+		}
+	}
+}
@@ -1,16 +1,40 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/choonkeat/record-tui/internal/record"
+	"github.com/choonkeat/record-tui/playback"
 )
 
+// version is the record-tui version, set at build time with
+// -ldflags "-X main.version=v1.2.3" (see the Makefile). Empty falls back to
+// the module version from the build info, e.g. for go install ...@v1.2.3.
+var version string
+
+// appVersion returns version, the module version, or "(devel)".
+func appVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, `Usage: record-tui [command ...]
 
@@ -20,23 +44,46 @@ Arguments:
   [command ...]  Command to execute in the recorded session (optional)
                  If omitted, starts an interactive shell
 
+Managing recordings:
+  record-tui list             # List past recordings, newest first
+  record-tui open <index>     # Open a recording's HTML
+  record-tui rm <index>       # Delete a recording
+  record-tui reprocess [flags] <dir>
+                              # Regenerate a recording's HTML with this version,
+                              # taking the same conversion flags as -convert
+
 Examples:
   record-tui                  # Start interactive shell recording
   record-tui echo hello       # Record specific command
   record-tui /bin/bash        # Record bash session
+  record-tui -view claude     # Open the HTML instead of the directory when done
+  record-tui -timeout 30m ./deploy.sh
+                              # Stop recording after 30 minutes
+  record-tui -append ~/.record-tui/20260112-064143
+                              # Continue a previous recording
+  record-tui -open-cmd 'firefox {html}' claude
+                              # Open the HTML in Firefox when done
 `)
 }
 
+// getBaseDir returns the directory holding all recordings (~/.record-tui)
+func getBaseDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".record-tui"), nil
+}
+
 // getRecordingDir creates and returns the recording directory path
 // Format: ~/.record-tui/YYYYMMDD-HHMMSS/
 func getRecordingDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	baseDir, err := getBaseDir()
 	if err != nil {
-		return "", fmt.Errorf("cannot determine home directory: %w", err)
+		return "", err
 	}
 
-	baseDir := filepath.Join(homeDir, ".record-tui")
-	timestamp := time.Now().Format("20060102-150405")
+	timestamp := time.Now().Format(record.RecordingDirFormat)
 	recordingDir := filepath.Join(baseDir, timestamp)
 
 	// Create directory with permissions 0755
@@ -48,6 +95,15 @@ func getRecordingDir() (string, error) {
 	return recordingDir, nil
 }
 
+// getAppendDir validates a previous recording directory to continue, which
+// must hold an uncompressed session.log
+func getAppendDir(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, "session.log")); err != nil {
+		return "", fmt.Errorf("no session.log to append to in %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
 // isInteractiveTerminal checks if stdout is connected to a TTY
 func isInteractiveTerminal() bool {
 	stat, err := os.Stdout.Stat()
@@ -63,9 +119,36 @@ func isSSHSession() bool {
 	return os.Getenv("SSH_CLIENT") != ""
 }
 
-// openRecordingDir opens the recording directory in the file explorer
+// openerEnv names the environment variable holding a custom opener command
+// (the -open-cmd flag takes precedence)
+const openerEnv = "RECORD_TUI_OPENER"
+
+// noOpenEnv names the environment variable that, set to a true value (1,
+// true, ...), stops finished recordings being opened, as -no-open does
+const noOpenEnv = "RECORD_TUI_NO_OPEN"
+
+// envBool reports whether the environment variable name is set to a true
+// value: one strconv.ParseBool reads as false, "no" or "off" (in any case)
+// is false, and any other non-empty value counts as true, so
+// RECORD_TUI_NO_OPEN=yes works
+func envBool(name string) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return false
+	}
+	switch strings.ToLower(v) {
+	case "no", "off":
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	return b || err != nil
+}
+
+// openRecordingDir opens a finished recording: the directory in the file
+// explorer (or the HTML in the browser if view is set and there is HTML),
+// or whatever the custom opener command (see openerCommand) does.
 // Only opens if running in an interactive terminal and not over SSH
-func openRecordingDir(dir string) {
+func openRecordingDir(dir, htmlPath, custom string, view bool) {
 	// Skip if not interactive
 	if !isInteractiveTerminal() {
 		return
@@ -74,29 +157,527 @@ func openRecordingDir(dir string) {
 	if isSSHSession() {
 		return
 	}
-	// Open directory (silently ignore errors)
-	exec.Command("open", dir).Run()
+	target := dir
+	if view && htmlPath != "" {
+		target = htmlPath
+	}
+	// Open (silently ignore errors)
+	openerCommand(custom, target, dir, htmlPath).Run()
+}
+
+// openerCommand builds the command that opens target, a finished recording's
+// directory or HTML. A custom command is split on spaces, with {dir} and
+// {html} replaced by the recording directory and HTML path (the directory if
+// there is no HTML); if neither appears, target is appended. Without a custom
+// command target is opened with the platform's default handler.
+func openerCommand(custom, target, dir, htmlPath string) *exec.Cmd {
+	fields := strings.Fields(custom)
+	if len(fields) == 0 {
+		return exec.Command(platformOpener(), target)
+	}
+	if htmlPath == "" {
+		htmlPath = dir
+	}
+	placeholder := false
+	for i, f := range fields {
+		if strings.Contains(f, "{dir}") || strings.Contains(f, "{html}") {
+			placeholder = true
+			fields[i] = strings.NewReplacer("{dir}", dir, "{html}", htmlPath).Replace(f)
+		}
+	}
+	if !placeholder {
+		fields = append(fields, target)
+	}
+	return exec.Command(fields[0], fields[1:]...)
+}
+
+// platformOpener returns the command that opens a file or directory with
+// the platform's default handler
+func platformOpener() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "explorer"
+	}
+	return "xdg-open"
+}
+
+// openPath opens a file or directory with the platform's default handler
+func openPath(path string) error {
+	return exec.Command(platformOpener(), path).Run()
+}
+
+// formatSize formats a byte count for display (e.g. "12.3 KB")
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// runManageCommand handles the list/open/rm subcommands.
+// Returns false if args is not a subcommand (i.e. it is a command to record).
+func runManageCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	name := args[0]
+	if name != "list" && name != "open" && name != "rm" {
+		return false
+	}
+
+	baseDir, err := getBaseDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	recordings, err := record.ListRecordings(baseDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Failed to list recordings: %v\n", err)
+		os.Exit(1)
+	}
+
+	if name == "list" {
+		if len(recordings) == 0 {
+			fmt.Fprintf(os.Stderr, "No recordings in %s\n", baseDir)
+			return true
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "#\tDATE\tDURATION\tSIZE\tCOMMAND")
+		for i, rec := range recordings {
+			duration, command := "-", rec.Command
+			if rec.Duration > 0 {
+				duration = rec.Duration.String()
+			}
+			if command == "" {
+				command = "(shell)"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, rec.Started.Format("2006-01-02 15:04:05"),
+				duration, formatSize(rec.Size), command)
+		}
+		w.Flush()
+		return true
+	}
+
+	// open / rm take the index shown by list
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "Usage: record-tui %s <index>\n", name)
+		os.Exit(2)
+	}
+	index, err := strconv.Atoi(args[1])
+	if err != nil || index < 1 || index > len(recordings) {
+		fmt.Fprintf(os.Stderr, "Error: No recording #%s (see record-tui list)\n", args[1])
+		os.Exit(1)
+	}
+	rec := recordings[index-1]
+
+	switch name {
+	case "open":
+		path := rec.HTMLPath()
+		if _, err := os.Stat(path); err != nil {
+			// No HTML (e.g. conversion failed): open the directory instead
+			path = rec.Dir
+		}
+		if err := openPath(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to open %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	case "rm":
+		if err := os.RemoveAll(rec.Dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Failed to remove %s: %v\n", rec.Dir, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Removed %s\n", rec.Dir)
+	}
+	return true
+}
+
+// exitConversionError reports that what (e.g. "Conversion") failed with
+// err, with a hint for the errors the converters return, and exits.
+func exitConversionError(what string, err error) {
+	switch {
+	case errors.Is(err, record.ErrLooksBinary):
+		fmt.Fprintf(os.Stderr, "Error: %v; re-run with -sanitize-binary to strip non-printable bytes\n", err)
+	case errors.Is(err, record.ErrSessionNotFound):
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	case errors.Is(err, record.ErrEmptyAfterStrip):
+		fmt.Fprintf(os.Stderr, "Error: %v; nothing was recorded between the script header and footer\n", err)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %s failed: %v\n", what, err)
+	}
+	os.Exit(1)
+}
+
+// splitList splits a comma-separated flag (-env-allow, -toc-exclude) into
+// names. An empty flag gives an empty, non-nil list, e.g. recording no
+// variables.
+func splitList(s string) []string {
+	names := []string{}
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseClock parses a -since/-until offset into the recording, as HH:MM:SS,
+// MM:SS or seconds (e.g. 00:10:00, 10:00 or 600). Empty is 0.
+func parseClock(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("%q is not HH:MM:SS", s)
+	}
+	var seconds float64
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || v < 0 || (i < len(parts)-1 && v != float64(int(v))) {
+			return 0, fmt.Errorf("%q is not HH:MM:SS", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// renderFlags are the flags for how a recording's output is cleaned and
+// rendered, which the streaming page (cleaned in the browser, as recorded)
+// and the cast (replayed as recorded) don't take.
+var renderFlags = []string{
+	"timing", "input", "keep-alt-screen", "stash-alt-screen", "sanitize-binary",
+	"colors", "since", "until", "show-env", "redact", "output-only",
+	"trim-idle", "trim-prompt", "error-nav", "toc-exclude",
+	"toc-exclude-pattern", "collapse-repeats", "show-typed", "line-times",
+	"final-screen", "screen-cols", "screen-rows", "prompt-pattern", "title",
+	"footer-link", "open-graph", "og-image",
+}
+
+// unsupportedFlags returns the renderFlags given on the command line that
+// format ignores: all of them for the streaming page and the cast, none
+// otherwise. Those from the config file are defaults for the formats that
+// take them, left unused by the rest.
+func unsupportedFlags(given map[string]bool, format string) []string {
+	if format != record.FormatStreaming && format != record.FormatCast {
+		return nil
+	}
+	var names []string
+	for _, name := range renderFlags {
+		if given[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// printConversionReport prints a dry-run summary to stdout
+func printConversionReport(path string, report *record.ConversionReport) {
+	fmt.Printf("Dry run: %s (no files written)\n", path)
+	fmt.Printf("  %-28s %d\n", "Bytes in:", report.BytesIn)
+	fmt.Printf("  %-28s %d\n", "Bytes out:", report.BytesOut)
+	fmt.Printf("  %-28s %d\n", "Clear separators inserted:", report.Cleaning.ClearSeparators)
+	fmt.Printf("  %-28s %d\n", "Alt-screen regions dropped:", report.Cleaning.AltScreenRegions)
+	fmt.Printf("  %-28s %d\n", "Scroll regions dropped:", report.Cleaning.ScrollRegions)
+	fmt.Printf("  %-28s %d\n", "Status replies stripped:", report.Cleaning.StatusReports)
+	fmt.Printf("  %-28s %d\n", "Transient status removed:", report.Cleaning.TransientSpans)
+	fmt.Printf("  %-28s %d\n", "Secrets redacted:", report.Cleaning.Secrets)
+	fmt.Printf("  %-28s %d\n", "TOC commands detected:", report.TOCCommands)
+	fmt.Printf("  %-28s %t\n", "Looks like binary output:", report.LooksBinary)
 }
 
 func main() {
+	// Subcommands are checked before flags so "record-tui list" isn't recorded as a command
+	if runManageCommand(os.Args[1:]) {
+		os.Exit(0)
+	}
+
 	convertFlag := flag.String("convert", "", "Convert session.log to HTML (outputs <file>.html)")
-	streamingFlag := flag.Bool("streaming", false, "Generate streaming HTML instead (outputs <file>.streaming.html)")
-	flag.Parse()
+	formatFlag := flag.String("format", record.FormatHTML, "With -convert, output format: "+strings.Join(record.Formats, ", ")+" (outputs <file>.html, .streaming.html, .txt, .svg or .cast)")
+	streamingFlag := flag.Bool("streaming", false, "Generate streaming HTML instead (same as -format streaming)")
+	dryRunFlag := flag.Bool("dry-run", false, "With -convert, report what cleaning would do without writing files")
+	logFlag := flag.String("log", "", "Same as -convert, e.g. -log out.log -timing out.tm -input out.in for a recording made by another tool")
+	timingFlag := flag.String("timing", "", "With -convert, the timing file to use instead of the one next to the log (not with -format streaming or cast)")
+	inputFlag := flag.String("input", "", "With -convert, the input file of what was typed to use instead of the one next to the log (not with -format streaming or cast)")
+	keepAltScreenFlag := flag.Bool("keep-alt-screen", false, "Keep the last frame of full-screen TUIs instead of discarding them (not with -streaming)")
+	stashAltScreenFlag := flag.Bool("stash-alt-screen", false, "Save each discarded full-screen TUI session to alt-screen-N.log next to the HTML, linked from its separator (not with -streaming, -pages or -keep-alt-screen)")
+	openCmdFlag := flag.String("open-cmd", os.Getenv(openerEnv), "Command to open the recording with when it finishes, e.g. 'firefox {html}' ({dir}/{html} placeholders; default: platform file explorer; env "+openerEnv+")")
+	noOpenFlag := flag.Bool("no-open", envBool(noOpenEnv), "Don't open the recording when it finishes (env "+noOpenEnv+"=1)")
+	timeoutFlag := flag.Duration("timeout", 0, "Stop recording after this long, e.g. 30m (for unattended sessions; 0 = no limit)")
+	appendFlag := flag.String("append", "", "Continue the recording in this directory, appending to its session.log and regenerating the HTML")
+	separateStderrFlag := flag.Bool("separate-stderr", false, "Record with a built-in pseudo-terminal instead of script, keeping the command's stderr apart (listed in session.stderr, shown in red in the HTML); stderr is then not a terminal to the command (Linux and macOS)")
+	bannerFlag := flag.Bool("banner", false, "Let script print its \"Script started\" and \"Script done\" messages to the terminal (on macOS, also keeps them in session.log, where they give the recording's duration and exit status)")
+	viewFlag := flag.Bool("view", false, "Open the generated HTML in the browser when recording finishes, instead of the recording directory")
+	sanitizeBinaryFlag := flag.Bool("sanitize-binary", false, "Strip NUL/control bytes from sessions that look like binary output instead of refusing to convert")
+	colorModeFlag := flag.String("colors", "", "With -convert, set to 16 to downsample 256-color and true-color output for viewers that render them poorly (not with -streaming)")
+	overflowFlag := flag.String("overflow", "wrap", "With -format text or svg, how to lay out lines longer than the recorded terminal width: wrap, truncate (with an ellipsis) or none (keep them whole)")
+	sinceFlag := flag.String("since", "", "With -convert, only convert output from this far into the recording, e.g. 00:10:00 (needs session.timing; not with -streaming)")
+	untilFlag := flag.String("until", "", "With -convert, only convert output up to this far into the recording, e.g. 00:15:00 (needs session.timing; not with -streaming)")
+	pagesFlag := flag.Int("pages", 0, "With -convert, split the recording into linked pages of this many commands each, written to <file>.pages/ (needs session.timing and session.input; not with -streaming or -since/-until)")
+	showEnvFlag := flag.Bool("show-env", false, "Show the working directory and shell from session.env in the HTML heading")
+	envAllowFlag := flag.String("env-allow", strings.Join(record.DefaultEnvAllowlist, ","), "Comma-separated environment variables to record in session.env (names that look secret are never recorded)")
+	redactFlag := flag.Bool("redact", false, "Replace secrets (AWS keys, GitHub and bearer tokens, password=... assignments) with [REDACTED] in the HTML (not with -streaming)")
+	outputOnlyFlag := flag.Bool("output-only", false, "With -convert, drop the echo of what was typed, keeping only program output (needs session.timing; not with -streaming or -pages)")
+	trimIdleFlag := flag.Bool("trim-idle", false, "Start the HTML at the prompt of the first typed command, dropping the output before it (needs session.timing; not with -streaming)")
+	trimPromptFlag := flag.Bool("trim-prompt", false, "End the HTML at the last command's output, dropping the bare prompt after it, found as for -prompt-pattern (not with -streaming)")
+	errorNavFlag := flag.Bool("error-nav", false, "Let the HTML viewer jump between error output (red text, \"error\", \"panic\") with n / p (not with -streaming)")
+	tocExcludeFlag := flag.String("toc-exclude", strings.Join(playback.DefaultNoiseCommands, ","), "Comma-separated commands to leave out of the HTML navigation when typed bare, e.g. ls (empty keeps every command)")
+	tocExcludePatternFlag := flag.String("toc-exclude-pattern", "", "Also leave commands matching this regular expression out of the HTML navigation, e.g. '^git (status|diff)'")
+	collapseRepeatsFlag := flag.Bool("collapse-repeats", false, "Show a command run several times in a row once in the HTML navigation, e.g. \"npm test (×10)\"")
+	showTypedFlag := flag.Bool("show-typed", false, "Show what was typed, with keys like <Enter> and ^C spelled out, in a panel below the terminal (needs session.timing and session.input; not with -streaming)")
+	lineTimesFlag := flag.Bool("line-times", false, "Embed when each output line appeared as JSON in the HTML (<script id=\"line-times\">), for syncing narration (needs session.timing; not with -streaming)")
+	finalScreenFlag := flag.Bool("final-screen", false, "With -convert, keep only the last screen of the recording, e.g. a build summary (not with -streaming or -pages)")
+	screenColsFlag := flag.Int("screen-cols", 0, "With -final-screen, the terminal width to replay at (default: the recorded width, or 80)")
+	screenRowsFlag := flag.Int("screen-rows", 0, "With -final-screen, the terminal height to replay at (default: the recorded height, or 24)")
+	promptPatternFlag := flag.String("prompt-pattern", "", "Regular expression matching your shell prompt, for HTML navigation of recordings without timing/input logs, e.g. '^➜ +\\S+ ' (default: bash, zsh and fish prompts)")
+	titleFlag := flag.String("title", "", "HTML page title, with {command}, {date}, {exit} and {duration} replaced from the recording, e.g. '{command} — {date} — exit {exit}' (default: Terminal; not with -streaming)")
+	footerLinkFlag := flag.String("footer-link", "", "Link to show in the HTML footer next to record-tui's, as text=url (not with -streaming)")
+	openGraphFlag := flag.Bool("open-graph", false, "Add Open Graph <meta> tags to the HTML so shared links show a preview card with the command and duration (not with -streaming)")
+	ogImageFlag := flag.String("og-image", "", "With -open-graph, the preview image URL, e.g. where the -format svg snapshot is published (most apps need an absolute URL)")
+	configFlag := flag.String("config", "", "JSON file of defaults for these flags, e.g. {\"redact\": true} (default: ~/.record-tui/"+configFileName+" if it exists; flags given override it)")
+	versionFlag := flag.Bool("version", false, "Print the record-tui version and exit")
+	// "record-tui reprocess [flags] <dir>" takes its flags after the subcommand
+	reprocess := len(os.Args) > 1 && os.Args[1] == "reprocess"
+	if reprocess {
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
+	}
 	args := flag.Args()
 
-	// Handle conversion mode
-	if *convertFlag != "" {
-		var htmlPath string
-		var err error
-		if *streamingFlag {
-			htmlPath, err = record.ConvertSessionToStreamingHTML(*convertFlag, 100000)
-		} else {
-			htmlPath, err = record.ConvertSessionToHTML(*convertFlag)
+	if *versionFlag {
+		fmt.Println("record-tui", appVersion())
+		os.Exit(0)
+	}
+
+	// Flags given on the command line, which override the config file
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	configPath := *configFlag
+	if configPath == "" {
+		configPath, _ = defaultConfigPath()
+	}
+	if configPath != "" {
+		cfg, err := loadConfig(configPath, *configFlag != "")
+		if err == nil {
+			err = applyConfig(flag.CommandLine, cfg)
 		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Conversion failed: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: Invalid config %s: %v\n", configPath, err)
+			os.Exit(1)
+		}
+		dropConfigConflicts(flag.CommandLine, given)
+	}
+
+	format, err := record.ParseFormat(*formatFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid -format: %v\n", err)
+		os.Exit(1)
+	}
+	if *streamingFlag {
+		if format != record.FormatHTML && format != record.FormatStreaming {
+			fmt.Fprintf(os.Stderr, "Error: -streaming can't be used with -format %s\n", format)
 			os.Exit(1)
 		}
+		format = record.FormatStreaming
+	}
+	streaming := format == record.FormatStreaming
+	if *logFlag != "" {
+		if *convertFlag != "" {
+			fmt.Fprintf(os.Stderr, "Error: -log and -convert can't be used together\n")
+			os.Exit(1)
+		}
+		*convertFlag = *logFlag
+	}
+	if (*timingFlag != "" || *inputFlag != "") && *convertFlag == "" {
+		fmt.Fprintf(os.Stderr, "Error: -timing and -input need a session log to convert (-convert or -log)\n")
+		os.Exit(1)
+	}
+	// The streaming page and the cast take the log as recorded
+	if names := unsupportedFlags(given, format); len(names) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: -%s can't be used with -format %s\n", strings.Join(names, ", -"), format)
+		os.Exit(1)
+	}
+	// The config file's defaults are for the formats that take them: its
+	// renderFlags for all but those two, and its -pages for HTML pages,
+	// which reprocess doesn't write
+	if format == record.FormatStreaming || format == record.FormatCast {
+		for _, name := range renderFlags {
+			if !given[name] {
+				resetFlag(flag.CommandLine, name)
+			}
+		}
+	}
+	if !given["pages"] && (format != record.FormatHTML || reprocess) {
+		resetFlag(flag.CommandLine, "pages")
+	}
+
+	if *colorModeFlag != "" && *colorModeFlag != "16" {
+		fmt.Fprintf(os.Stderr, "Error: -colors must be 16 (got %q)\n", *colorModeFlag)
+		os.Exit(1)
+	}
+	switch *overflowFlag {
+	case "wrap", "truncate", "none":
+	default:
+		fmt.Fprintf(os.Stderr, "Error: -overflow must be wrap, truncate or none (got %q)\n", *overflowFlag)
+		os.Exit(1)
+	}
+	since, err := parseClock(*sinceFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid -since: %v\n", err)
+		os.Exit(1)
+	}
+	until, err := parseClock(*untilFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid -until: %v\n", err)
+		os.Exit(1)
+	}
+	if until != 0 && until <= since {
+		fmt.Fprintf(os.Stderr, "Error: -until must be after -since\n")
+		os.Exit(1)
+	}
+	var tocExcludePattern *regexp.Regexp
+	if *tocExcludePatternFlag != "" {
+		tocExcludePattern, err = regexp.Compile(*tocExcludePatternFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid -toc-exclude-pattern: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var promptPattern *regexp.Regexp
+	if *promptPatternFlag != "" {
+		promptPattern, err = regexp.Compile(*promptPatternFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid -prompt-pattern: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	footerLink, err := parseFooterLink(*footerLinkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid -footer-link: %v\n", err)
+		os.Exit(1)
+	}
+	if *pagesFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -pages must be a number of commands per page\n")
+		os.Exit(1)
+	}
+	if *pagesFlag > 0 && (streaming || since != 0 || until != 0) {
+		fmt.Fprintf(os.Stderr, "Error: -pages can't be used with -streaming or -since/-until\n")
+		os.Exit(1)
+	}
+	if *pagesFlag > 0 && format != record.FormatHTML {
+		fmt.Fprintf(os.Stderr, "Error: -pages only writes HTML (got -format %s)\n", format)
+		os.Exit(1)
+	}
+
+	if *outputOnlyFlag && *pagesFlag > 0 {
+		fmt.Fprintf(os.Stderr, "Error: -output-only can't be used with -pages\n")
+		os.Exit(1)
+	}
+	if *stashAltScreenFlag && (*pagesFlag > 0 || *keepAltScreenFlag) {
+		fmt.Fprintf(os.Stderr, "Error: -stash-alt-screen can't be used with -pages or -keep-alt-screen\n")
+		os.Exit(1)
+	}
+	if *finalScreenFlag && *pagesFlag > 0 {
+		fmt.Fprintf(os.Stderr, "Error: -final-screen can't be used with -pages\n")
+		os.Exit(1)
+	}
+	if *screenColsFlag < 0 || *screenRowsFlag < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -screen-cols and -screen-rows must be positive\n")
+		os.Exit(1)
+	}
+
+	convertOpts := record.ConvertOptions{
+		KeepAltScreen:   *keepAltScreenFlag,
+		StashAltScreen:  *stashAltScreenFlag,
+		SanitizeBinary:  *sanitizeBinaryFlag,
+		ColorMode:       *colorModeFlag,
+		Since:           since,
+		Until:           until,
+		Version:         appVersion(),
+		ShowEnv:         *showEnvFlag,
+		Redact:          *redactFlag,
+		OutputOnly:      *outputOnlyFlag,
+		TrimLeadingIdle: *trimIdleFlag,
+		ErrorNav:        *errorNavFlag,
+		Overflow:        *overflowFlag,
+		ShowTyped:       *showTypedFlag,
+		LineTimes:       *lineTimesFlag,
+		FinalScreen:     *finalScreenFlag,
+		ScreenCols:      *screenColsFlag,
+		ScreenRows:      *screenRowsFlag,
+		Title:           *titleFlag,
+		FooterLink:      footerLink,
+		OpenGraph:       *openGraphFlag,
+		OpenGraphImage:  *ogImageFlag,
+
+		TOCExclude:         splitList(*tocExcludeFlag),
+		TOCExcludePattern:  tocExcludePattern,
+		TOCCollapseRepeats: *collapseRepeatsFlag,
+		PromptPattern:      promptPattern,
+		TrimTrailingPrompt: *trimPromptFlag,
+
+		TimingPath: *timingFlag,
+		InputPath:  *inputFlag,
+	}
+
+	// Handle reprocessing: regenerate an existing recording's HTML
+	if reprocess {
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "Usage: record-tui reprocess [flags] <dir>\n")
+			os.Exit(2)
+		}
+		if format != record.FormatHTML || *pagesFlag > 0 {
+			fmt.Fprintf(os.Stderr, "Error: reprocess can't be used with -format, -streaming or -pages\n")
+			os.Exit(1)
+		}
+		htmlPath, err := record.Reprocess(args[0], convertOpts)
+		if err != nil {
+			exitConversionError("Reprocessing", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ HTML regenerated: %s\n", htmlPath)
+		os.Exit(0)
+	}
+
+	// Handle dry-run conversion: report only, write nothing
+	if *convertFlag != "" && *dryRunFlag {
+		report, err := record.DryRunConversion(*convertFlag, convertOpts)
+		if err != nil {
+			exitConversionError("Dry run", err)
+		}
+		printConversionReport(*convertFlag, report)
+		os.Exit(0)
+	}
+
+	// Handle paged conversion: several linked pages and an index
+	if *convertFlag != "" && *pagesFlag > 0 {
+		indexPath, err := record.ConvertSessionToPagedHTML(*convertFlag, "", *pagesFlag, convertOpts)
+		if err != nil {
+			exitConversionError("Conversion", err)
+		}
+		fmt.Fprintf(os.Stderr, "✓ HTML pages generated: %s\n", indexPath)
+		os.Exit(0)
+	}
+
+	// Handle conversion mode
+	if *convertFlag != "" {
+		outputPath, err := record.ConvertSession(*convertFlag, format, convertOpts)
+		if err != nil {
+			exitConversionError("Conversion", err)
+		}
+		if format != record.FormatHTML && format != record.FormatStreaming {
+			fmt.Fprintf(os.Stderr, "✓ %s generated: %s\n", strings.ToUpper(format), outputPath)
+			os.Exit(0)
+		}
+		htmlPath := outputPath
 		fmt.Fprintf(os.Stderr, "✓ HTML generated: %s\n", htmlPath)
 
 		// Try to convert HTML to PDF if to-pdf tool is available
@@ -114,10 +695,15 @@ func main() {
 	// Setup environment for color recording
 	record.SetupRecordingEnvironment()
 
-	// Create recording directory
-	recordingDir, err := getRecordingDir()
+	// Create recording directory, or reuse the one being appended to
+	var recordingDir string
+	if *appendFlag != "" {
+		recordingDir, err = getAppendDir(*appendFlag)
+	} else {
+		recordingDir, err = getRecordingDir()
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Failed to create recording directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: Failed to prepare recording directory: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -125,7 +711,18 @@ func main() {
 
 	// Record the session
 	fmt.Fprintf(os.Stderr, "Recording started. Press Ctrl-D to exit.\n")
-	err = record.RecordSession(sessionLogPath, args)
+	err = record.RecordSession(sessionLogPath, args, record.RecordOptions{
+		Timeout:        *timeoutFlag,
+		Append:         *appendFlag != "",
+		EnvAllowlist:   splitList(*envAllowFlag),
+		SeparateStderr: *separateStderrFlag,
+		Banner:         *bannerFlag,
+	})
+	if errors.Is(err, record.ErrRecordingTimedOut) {
+		// Convert what was captured so far
+		fmt.Fprintf(os.Stderr, "\nRecording stopped after %v timeout.\n", *timeoutFlag)
+		err = nil
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Recording failed: %v\n", err)
 		os.Exit(1)
@@ -138,8 +735,16 @@ func main() {
 	}
 
 	// Convert session.log to HTML
-	htmlPath, err := record.ConvertSessionToHTML(sessionLogPath)
-	if err != nil {
+	htmlPath, err := record.ConvertSessionToHTML(sessionLogPath, convertOpts)
+	if errors.Is(err, record.ErrLooksBinary) {
+		// Still produce a page from a fresh recording, minus the garbage bytes
+		fmt.Fprintf(os.Stderr, "Warning: %v; stripping non-printable bytes from the HTML\n", err)
+		convertOpts.SanitizeBinary = true
+		htmlPath, err = record.ConvertSessionToHTML(sessionLogPath, convertOpts)
+	}
+	if errors.Is(err, record.ErrEmptyAfterStrip) {
+		fmt.Fprintf(os.Stderr, "Note: Nothing was recorded, so no HTML was generated\n")
+	} else if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: HTML conversion failed: %v\n", err)
 		fmt.Fprintf(os.Stderr, "Note: session.log was recorded successfully\n")
 		// Don't exit - recording was successful even if conversion failed
@@ -159,8 +764,10 @@ func main() {
 	// Success message
 	fmt.Fprintf(os.Stderr, "✓ Recording saved to: %s/\n", recordingDir)
 
-	// Open directory in file explorer (interactive terminals only, skip SSH)
-	openRecordingDir(recordingDir)
+	// Open directory (or HTML with -view) in file explorer or custom opener (interactive terminals only, skip SSH)
+	if !*noOpenFlag {
+		openRecordingDir(recordingDir, htmlPath, *openCmdFlag, *viewFlag)
+	}
 
 	os.Exit(0)
 }
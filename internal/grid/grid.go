@@ -0,0 +1,475 @@
+// Package grid implements a minimal in-memory terminal screen.
+// It understands enough of VT100/xterm (cursor movement, erase, insert/delete,
+// scroll margins and SGR colors) to replay cursor-addressed TUI redraws and
+// read back the last visible frame as plain lines of text.
+package grid
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Cell is a single character position on the screen.
+type Cell struct {
+	Char  rune  // 0 if never written (renders as a space)
+	Style Style // Graphic rendition in effect when the character was written
+}
+
+// Screen is a fixed-size terminal grid that terminal output can be written to.
+type Screen struct {
+	cols, rows int
+	cells      [][]Cell
+	row, col   int
+	style      Style
+
+	// Scroll margins (DECSTBM), 0-indexed and inclusive
+	top, bottom int
+
+	// Saved cursor (DECSC / CSI s)
+	savedRow, savedCol int
+	savedStyle         Style
+
+	overflow   Overflow     // See SetOverflow ("" is Wrap)
+	scrollback func([]Cell) // See SetScrollback
+}
+
+// New returns a blank screen of the given size. Sizes below 1 are clamped to 1.
+func New(cols, rows int) *Screen {
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	s := &Screen{cols: cols, rows: rows, bottom: rows - 1}
+	s.cells = make([][]Cell, rows)
+	for i := range s.cells {
+		s.cells[i] = make([]Cell, cols)
+	}
+	return s
+}
+
+// Cols returns the screen width.
+func (s *Screen) Cols() int { return s.cols }
+
+// Rows returns the screen height.
+func (s *Screen) Rows() int { return s.rows }
+
+// Cell returns the cell at the given 0-indexed position.
+func (s *Screen) Cell(row, col int) Cell {
+	return s.cells[row][col]
+}
+
+// SetScrollback sets a function called with each row that scrolls off the
+// top of the screen, oldest first, so output much longer than the screen
+// can be read back without a screen as tall as it. The row is reused once
+// f returns, so copy what's kept.
+func (s *Screen) SetScrollback(f func(row []Cell)) {
+	s.scrollback = f
+}
+
+// Write feeds terminal output to the screen. Incomplete escape sequences at
+// the end of data are discarded.
+func (s *Screen) Write(data string) {
+	for i := 0; i < len(data); {
+		b := data[i]
+		switch {
+		case b == 0x1b:
+			i = s.escape(data, i)
+		case b == '\r':
+			s.col = 0
+			i++
+		case b == '\n' || b == '\v' || b == '\f':
+			s.lineFeed()
+			i++
+		case b == '\b':
+			if s.col > 0 {
+				s.col--
+			}
+			i++
+		case b == '\t':
+			s.col = (s.col/8 + 1) * 8
+			if s.col >= s.cols {
+				s.col = s.cols - 1
+			}
+			i++
+		case b < 0x20 || b == 0x7f:
+			// Other control characters (BEL, etc.) have no visible effect
+			i++
+		default:
+			r, size := utf8.DecodeRuneInString(data[i:])
+			s.put(r)
+			i += size
+		}
+	}
+}
+
+// Lines returns the visible text, one string per row, with trailing blanks
+// trimmed and trailing empty rows dropped.
+func (s *Screen) Lines() []string {
+	lines := make([]string, s.rows)
+	last := -1
+	for r := range s.cells {
+		var b strings.Builder
+		for _, c := range s.cells[r] {
+			if c.Char == 0 {
+				b.WriteByte(' ')
+			} else {
+				b.WriteRune(c.Char)
+			}
+		}
+		lines[r] = strings.TrimRight(b.String(), " ")
+		if lines[r] != "" {
+			last = r
+		}
+	}
+	return lines[:last+1]
+}
+
+// String renders the visible screen as terminal output: rows joined with
+// "\r\n", SGR sequences reproducing colors, trailing blanks and trailing
+// empty rows dropped. Each row ends with the default rendition.
+func (s *Screen) String() string {
+	var out strings.Builder
+	lines := s.Lines()
+	for r := range lines {
+		if r > 0 {
+			out.WriteString("\r\n")
+		}
+		// Render up to the last non-blank cell
+		end := 0
+		for c := range s.cells[r] {
+			if ch := s.cells[r][c].Char; ch != 0 && ch != ' ' {
+				end = c + 1
+			}
+		}
+		current := Style{}
+		for _, cell := range s.cells[r][:end] {
+			if cell.Style != current {
+				out.WriteString("\x1b[0")
+				if sgr := cell.Style.SGR(); sgr != "" {
+					out.WriteString(";" + sgr)
+				}
+				out.WriteString("m")
+				current = cell.Style
+			}
+			if cell.Char == 0 {
+				out.WriteByte(' ')
+			} else {
+				out.WriteRune(cell.Char)
+			}
+		}
+		if current != (Style{}) {
+			out.WriteString("\x1b[0m")
+		}
+	}
+	return out.String()
+}
+
+// put writes a printable character at the cursor, wrapping at the right
+// edge unless told otherwise (see SetOverflow).
+func (s *Screen) put(r rune) {
+	if s.col >= s.cols {
+		switch s.overflow {
+		case Truncate:
+			s.cells[s.row][s.cols-1] = Cell{Char: ellipsis, Style: s.style}
+			return
+		case NoWrap:
+			return
+		}
+		s.col = 0
+		s.lineFeed()
+	}
+	s.cells[s.row][s.col] = Cell{Char: r, Style: s.style}
+	s.col++
+}
+
+// lineFeed moves the cursor down, scrolling the margin region at the bottom.
+func (s *Screen) lineFeed() {
+	if s.row == s.bottom {
+		s.scrollUp(1)
+	} else if s.row < s.rows-1 {
+		s.row++
+	}
+}
+
+// scrollUp scrolls the margin region up by n lines, blanking the bottom.
+// Rows scrolled off the top of the screen go to the scrollback function.
+func (s *Screen) scrollUp(n int) {
+	if s.scrollback != nil && s.top == 0 {
+		for r := 0; r < min(n, s.bottom+1); r++ {
+			s.scrollback(s.cells[r])
+		}
+	}
+	s.deleteLines(s.top, n)
+}
+
+// scrollDown scrolls the margin region down by n lines, blanking the top.
+func (s *Screen) scrollDown(n int) {
+	s.insertLines(s.top, n)
+}
+
+// insertLines inserts n blank lines at row, pushing lines below it down
+// and off the bottom margin.
+func (s *Screen) insertLines(row, n int) {
+	if row < s.top || row > s.bottom {
+		return
+	}
+	if n > s.bottom-row+1 {
+		n = s.bottom - row + 1
+	}
+	// The lines pushed off are blanked and reused
+	gone := append([][]Cell(nil), s.cells[s.bottom-n+1:s.bottom+1]...)
+	copy(s.cells[row+n:s.bottom+1], s.cells[row:s.bottom-n+1])
+	for i, cells := range gone {
+		clear(cells)
+		s.cells[row+i] = cells
+	}
+}
+
+// deleteLines removes n lines at row, pulling lines below it up and
+// blanking lines at the bottom margin.
+func (s *Screen) deleteLines(row, n int) {
+	if row < s.top || row > s.bottom {
+		return
+	}
+	if n > s.bottom-row+1 {
+		n = s.bottom - row + 1
+	}
+	// The lines removed are blanked and reused
+	gone := append([][]Cell(nil), s.cells[row:row+n]...)
+	copy(s.cells[row:s.bottom-n+1], s.cells[row+n:s.bottom+1])
+	for i, cells := range gone {
+		clear(cells)
+		s.cells[s.bottom-n+1+i] = cells
+	}
+}
+
+// clearCells blanks cells [from, to) on the given row.
+func (s *Screen) clearCells(row, from, to int) {
+	if from < 0 {
+		from = 0
+	}
+	if to > s.cols {
+		to = s.cols
+	}
+	for c := from; c < to; c++ {
+		s.cells[row][c] = Cell{}
+	}
+}
+
+// escape handles the escape sequence starting at data[i] (an ESC byte)
+// and returns the index just past it.
+func (s *Screen) escape(data string, i int) int {
+	if i+1 >= len(data) {
+		return len(data)
+	}
+	switch data[i+1] {
+	case '[':
+		return s.csi(data, i+2)
+	case ']', 'P', 'X', '^', '_':
+		// OSC / DCS / SOS / PM / APC: skip until BEL or ST (ESC \)
+		for j := i + 2; j < len(data); j++ {
+			if data[j] == 0x07 {
+				return j + 1
+			}
+			if data[j] == 0x1b && j+1 < len(data) && data[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(data)
+	case '7':
+		s.saveCursor()
+	case '8':
+		s.restoreCursor()
+	case 'c':
+		// RIS: full reset, keeping how the screen was set up
+		overflow, scrollback := s.overflow, s.scrollback
+		*s = *New(s.cols, s.rows)
+		s.overflow, s.scrollback = overflow, scrollback
+	case '(', ')', '*', '+', '#', '%', ' ':
+		// Charset designation and similar: one more byte follows
+		return min(i+3, len(data))
+	}
+	return i + 2
+}
+
+// csi parses a control sequence whose parameters start at data[i] and
+// applies it. Returns the index just past the final byte.
+func (s *Screen) csi(data string, i int) int {
+	start := i
+	for i < len(data) && data[i] >= 0x30 && data[i] <= 0x3f {
+		i++
+	}
+	params := data[start:i]
+	for i < len(data) && data[i] >= 0x20 && data[i] <= 0x2f {
+		i++
+	}
+	if i >= len(data) {
+		return len(data)
+	}
+	final := data[i]
+
+	// Private sequences (\x1b[?25l, \x1b[>c, ...) don't affect the grid
+	if params != "" && (params[0] == '?' || params[0] == '>' || params[0] == '<' || params[0] == '=') {
+		return i + 1
+	}
+
+	args := parseParams(params)
+	// Counts past the screen's size do what the size does, and capping
+	// them keeps cursor arithmetic like s.row+n from overflowing
+	n := min(arg(args, 0, 1), max(s.rows, s.cols))
+
+	switch final {
+	case 'A':
+		s.row = max(s.row-n, 0)
+	case 'B', 'e':
+		s.row = min(s.row+n, s.rows-1)
+	case 'C', 'a':
+		s.col = min(s.col+n, s.cols-1)
+	case 'D':
+		s.col = max(min(s.col, s.cols-1)-n, 0)
+	case 'E':
+		s.row = min(s.row+n, s.rows-1)
+		s.col = 0
+	case 'F':
+		s.row = max(s.row-n, 0)
+		s.col = 0
+	case 'G', '`':
+		s.col = clamp(n-1, 0, s.cols-1)
+	case 'd':
+		s.row = clamp(n-1, 0, s.rows-1)
+	case 'H', 'f':
+		s.row = clamp(arg(args, 0, 1)-1, 0, s.rows-1)
+		s.col = clamp(arg(args, 1, 1)-1, 0, s.cols-1)
+	case 'J':
+		s.eraseDisplay(arg(args, 0, 0))
+	case 'K':
+		s.eraseLine(arg(args, 0, 0))
+	case 'L':
+		s.insertLines(s.row, n)
+	case 'M':
+		s.deleteLines(s.row, n)
+	case '@':
+		s.insertChars(n)
+	case 'P':
+		s.deleteChars(n)
+	case 'X':
+		s.eraseChars(n)
+	case 'S':
+		s.scrollUp(n)
+	case 'T':
+		s.scrollDown(n)
+	case 'm':
+		s.style = s.style.apply(args)
+	case 's':
+		s.saveCursor()
+	case 'u':
+		s.restoreCursor()
+	case 'r':
+		top := arg(args, 0, 1) - 1
+		bottom := arg(args, 1, s.rows) - 1
+		if top < bottom && bottom < s.rows {
+			s.top, s.bottom = top, bottom
+		} else {
+			s.top, s.bottom = 0, s.rows-1
+		}
+		s.row, s.col = 0, 0
+	}
+	return i + 1
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	col := min(s.col, s.cols)
+	switch mode {
+	case 0:
+		s.clearCells(s.row, col, s.cols)
+		for r := s.row + 1; r < s.rows; r++ {
+			s.clearCells(r, 0, s.cols)
+		}
+	case 1:
+		for r := 0; r < s.row; r++ {
+			s.clearCells(r, 0, s.cols)
+		}
+		s.clearCells(s.row, 0, col+1)
+	case 2, 3:
+		for r := 0; r < s.rows; r++ {
+			s.clearCells(r, 0, s.cols)
+		}
+	}
+}
+
+func (s *Screen) eraseLine(mode int) {
+	col := min(s.col, s.cols)
+	switch mode {
+	case 0:
+		s.clearCells(s.row, col, s.cols)
+	case 1:
+		s.clearCells(s.row, 0, col+1)
+	case 2:
+		s.clearCells(s.row, 0, s.cols)
+	}
+}
+
+func (s *Screen) insertChars(n int) {
+	line := s.cells[s.row]
+	col := min(s.col, s.cols-1)
+	n = min(n, s.cols-col)
+	copy(line[col+n:], line[col:s.cols-n])
+	s.clearCells(s.row, col, col+n)
+}
+
+func (s *Screen) deleteChars(n int) {
+	line := s.cells[s.row]
+	col := min(s.col, s.cols-1)
+	n = min(n, s.cols-col)
+	copy(line[col:], line[col+n:])
+	s.clearCells(s.row, s.cols-n, s.cols)
+}
+
+// eraseChars blanks n cells from the cursor (ECH) without moving it or
+// shifting the rest of the line, unlike deleteChars.
+func (s *Screen) eraseChars(n int) {
+	col := min(s.col, s.cols-1)
+	s.clearCells(s.row, col, col+n)
+}
+
+func (s *Screen) saveCursor() {
+	s.savedRow, s.savedCol, s.savedStyle = s.row, s.col, s.style
+}
+
+func (s *Screen) restoreCursor() {
+	s.row, s.col, s.style = s.savedRow, s.savedCol, s.savedStyle
+}
+
+// parseParams splits CSI parameters ("1;31") into integers.
+// Missing or malformed parameters are -1 so arg() can apply defaults.
+func parseParams(params string) []int {
+	if params == "" {
+		return nil
+	}
+	fields := strings.Split(params, ";")
+	args := make([]int, len(fields))
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			v = -1
+		}
+		args[i] = v
+	}
+	return args
+}
+
+// arg returns args[i], or def if it is missing or zero/malformed
+// (CSI cursor parameters treat 0 as the default).
+func arg(args []int, i, def int) int {
+	if i >= len(args) || args[i] <= 0 {
+		return def
+	}
+	return args[i]
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
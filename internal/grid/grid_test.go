@@ -0,0 +1,188 @@
+package grid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScreen_PlainText(t *testing.T) {
+	s := New(80, 24)
+	s.Write("hello\r\nworld")
+
+	want := []string{"hello", "world"}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestScreen_CursorAddressedMenu(t *testing.T) {
+	s := New(40, 10)
+	s.Write("\x1b[H\x1b[2J")
+	s.Write("\x1b[2;5HPick one:")
+	s.Write("\x1b[4;5H  apples")
+	s.Write("\x1b[5;5H  pears")
+	// Redraw the selection marker
+	s.Write("\x1b[4;5H>")
+	s.Write("\x1b[4;5H \x1b[5;5H>")
+
+	want := []string{"", "    Pick one:", "", "      apples", "    > pears"}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestScreen_EraseLineAndDisplay(t *testing.T) {
+	s := New(20, 5)
+	s.Write("aaaaaaaaaa\r\nbbbbbbbbbb\r\ncccccccccc")
+	s.Write("\x1b[1;4H\x1b[K")  // erase to end of line 1
+	s.Write("\x1b[2;4H\x1b[1K") // erase to start of line 2
+	s.Write("\x1b[3;1H\x1b[2K") // erase all of line 3
+
+	want := []string{"aaa", "    bbbbbb"}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+
+	s.Write("\x1b[2J")
+	if got := s.Lines(); len(got) != 0 {
+		t.Errorf("Lines() after clear = %q, want none", got)
+	}
+}
+
+func TestScreen_ScrollsAtBottom(t *testing.T) {
+	s := New(10, 3)
+	s.Write("1\r\n2\r\n3\r\n4")
+
+	want := []string{"2", "3", "4"}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestScreen_ScrollMargins(t *testing.T) {
+	s := New(10, 4)
+	s.Write("\x1b[4;1Hstatus")
+	s.Write("\x1b[1;3r") // rows 1-3 scroll, row 4 pinned
+	s.Write("\x1b[1;1H1\r\n2\r\n3\r\n4")
+
+	want := []string{"2", "3", "4", "status"}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestScreen_WrapsAtRightEdge(t *testing.T) {
+	s := New(5, 3)
+	s.Write("abcdefg")
+
+	want := []string{"abcde", "fg"}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestScreen_InsertDeleteChars(t *testing.T) {
+	s := New(10, 1)
+	s.Write("abcdef\x1b[1;3H\x1b[2P")
+	if got := s.Lines(); got[0] != "abef" {
+		t.Errorf("after delete chars = %q, want %q", got[0], "abef")
+	}
+	s.Write("\x1b[1;2H\x1b[1@X")
+	if got := s.Lines(); got[0] != "aXbef" {
+		t.Errorf("after insert chars = %q, want %q", got[0], "aXbef")
+	}
+}
+
+func TestScreen_EraseChars(t *testing.T) {
+	s := New(10, 1)
+	s.Write("ABCDEFGH\r\x1b[3X")
+	if got := s.Lines(); got[0] != "   DEFGH" {
+		t.Errorf("after erase chars = %q, want %q", got[0], "   DEFGH")
+	}
+
+	// The cursor stays put, so the next write lands on the erased cells
+	s.Write("ok")
+	if got := s.Lines(); got[0] != "ok DEFGH" {
+		t.Errorf("after write = %q, want %q", got[0], "ok DEFGH")
+	}
+
+	// No count erases one; a count past the edge stops there
+	s.Write("\x1b[1;5H\x1b[X")
+	if got := s.Lines(); got[0] != "ok D FGH" {
+		t.Errorf("after default erase = %q, want %q", got[0], "ok D FGH")
+	}
+	s.Write("\x1b[1;7H\x1b[99X")
+	if got := s.Lines(); got[0] != "ok D F" {
+		t.Errorf("after erase past edge = %q, want %q", got[0], "ok D F")
+	}
+}
+
+func TestScreen_HugeCounts(t *testing.T) {
+	s := New(5, 3)
+	for _, final := range "ABCDEFLMPSTX@ade`" {
+		s.Write("\x1b[2;2H\x1b[9223372036854775807" + string(final) + "x")
+	}
+	s.Write("\x1b[2J\x1b[9223372036854775807;9223372036854775807Hz")
+	if got := s.Lines(); got[2] != "    z" {
+		t.Errorf("last row = %q, want %q", got[2], "    z")
+	}
+}
+
+func TestScreen_IgnoresOSCAndPrivateModes(t *testing.T) {
+	s := New(20, 2)
+	s.Write("\x1b]0;window title\x07\x1b[?25lvisible\x1b[?25h")
+
+	want := []string{"visible"}
+	if got := s.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
+
+func TestScreen_StringPreservesColors(t *testing.T) {
+	s := New(20, 2)
+	s.Write("\x1b[1;31mred\x1b[0m plain\r\n\x1b[38;5;208mor\x1b[39mx")
+
+	want := "\x1b[0;1;31mred\x1b[0m plain\r\n\x1b[0;38;5;208mor\x1b[0mx"
+	if got := s.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestStyle_Apply(t *testing.T) {
+	st := Style{}.apply([]int{1, 4, 31, 48, 2, 1, 2, 3})
+	if st.SGR() != "1;4;31;48;2;1;2;3" {
+		t.Errorf("SGR() = %q", st.SGR())
+	}
+	st = st.apply([]int{22, 24, 39})
+	if st.SGR() != "48;2;1;2;3" {
+		t.Errorf("SGR() after resets = %q", st.SGR())
+	}
+	if st.apply([]int{0}) != (Style{}) {
+		t.Error("SGR 0 should reset to default style")
+	}
+}
+
+func TestScreen_Scrollback(t *testing.T) {
+	s := New(10, 3)
+	var scrolled []string
+	s.SetScrollback(func(row []Cell) {
+		var line []rune
+		for _, c := range row {
+			if c.Char != 0 {
+				line = append(line, c.Char)
+			}
+		}
+		scrolled = append(scrolled, string(line))
+	})
+	s.Write("one\r\ntwo\r\nthree\r\nfour")
+
+	// Scrolling margins below the top drop rows instead
+	s.Write("\x1b[2;3r\x1b[3;1H\r\nfive")
+
+	if want := []string{"one"}; !reflect.DeepEqual(scrolled, want) {
+		t.Errorf("scrolled = %q, want %q", scrolled, want)
+	}
+	if want := []string{"two", "four", "five"}; !reflect.DeepEqual(s.Lines(), want) {
+		t.Errorf("Lines() = %q, want %q", s.Lines(), want)
+	}
+}
@@ -0,0 +1,96 @@
+package grid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Overflow is how a line wider than the screen is laid out.
+type Overflow string
+
+const (
+	Wrap     Overflow = "wrap"     // Continue on the next row, as a terminal does (the default)
+	Truncate Overflow = "truncate" // Cut at the right edge, ending the row with an ellipsis
+	NoWrap   Overflow = "none"     // Keep the whole line; the caller makes room for it
+)
+
+// ellipsis marks a row Truncate cut short.
+const ellipsis = '…'
+
+// ParseOverflow returns the Overflow named by name; "" is Wrap.
+func ParseOverflow(name string) (Overflow, error) {
+	switch o := Overflow(name); o {
+	case "":
+		return Wrap, nil
+	case Wrap, Truncate, NoWrap:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown overflow %q (want %s, %s or %s)", name, Wrap, Truncate, NoWrap)
+}
+
+// SetOverflow sets how characters written past the right edge are laid
+// out: Wrap moves them to the next row (the default); Truncate drops them,
+// leaving an ellipsis in the last column; NoWrap drops them too, for a
+// screen already as wide as its widest line (see Width).
+func (s *Screen) SetOverflow(o Overflow) {
+	s.overflow = o
+}
+
+// Width returns how many columns a line of plain text (see
+// session.PlainText) takes, with tabs stopping every 8 columns.
+func Width(line string) int {
+	col := 0
+	for _, r := range line {
+		col += runeWidth(r, col)
+	}
+	return col
+}
+
+// FitLine lays out a line of plain text cols wide: Wrap splits it into
+// rows of at most cols columns, Truncate cuts it to cols columns ending in
+// an ellipsis, and NoWrap keeps it whole. A line that fits is returned as
+// is.
+func FitLine(line string, cols int, o Overflow) []string {
+	if cols < 1 || o == NoWrap || Width(line) <= cols {
+		return []string{line}
+	}
+	var rows []string
+	var row strings.Builder
+	col := 0
+	for _, r := range line {
+		w := runeWidth(r, col)
+		if r == '\t' {
+			// As on a Screen, a tab stops at the right edge
+			w = min(w, cols-col)
+		}
+		if col+w > cols {
+			if o == Truncate {
+				return []string{cutRow(row.String(), cols) + string(ellipsis)}
+			}
+			rows = append(rows, row.String())
+			row.Reset()
+			col = 0
+		}
+		row.WriteRune(r)
+		col += w
+	}
+	return append(rows, row.String())
+}
+
+// cutRow drops characters from the end of row until it takes at most
+// cols-1 columns, leaving room for the ellipsis.
+func cutRow(row string, cols int) string {
+	runes := []rune(row)
+	for len(runes) > 0 && Width(string(runes)) > cols-1 {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes)
+}
+
+// runeWidth returns how many columns r takes when written at col.
+func runeWidth(r rune, col int) int {
+	if r == '\t' {
+		return 8 - col%8
+	}
+	return 1
+}
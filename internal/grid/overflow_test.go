@@ -0,0 +1,57 @@
+package grid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScreen_Overflow(t *testing.T) {
+	for _, tc := range []struct {
+		overflow Overflow
+		want     []string
+	}{
+		{Wrap, []string{"abcde", "fg", "xy"}},
+		{Truncate, []string{"abcd…", "xy"}},
+		{NoWrap, []string{"abcde", "xy"}},
+	} {
+		s := New(5, 4)
+		s.SetOverflow(tc.overflow)
+		s.Write("abcdefg\r\nxy")
+		if got := s.Lines(); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: Lines() = %q, want %q", tc.overflow, got, tc.want)
+		}
+	}
+}
+
+func TestFitLine(t *testing.T) {
+	for _, tc := range []struct {
+		line     string
+		overflow Overflow
+		want     []string
+	}{
+		{"abcdefghijk", Wrap, []string{"abcde", "fghij", "k"}},
+		{"abcdefghijk", Truncate, []string{"abcd…"}},
+		{"abcdefghijk", NoWrap, []string{"abcdefghijk"}},
+		{"abcde", Truncate, []string{"abcde"}},
+		{"", Wrap, []string{""}},
+		// A tab takes the columns up to the next tab stop, or the edge
+		{"ab\tc", Wrap, []string{"ab\t", "c"}},
+		{"ab\tc", Truncate, []string{"ab…"}},
+	} {
+		if got := FitLine(tc.line, 5, tc.overflow); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("FitLine(%q, 5, %s) = %q, want %q", tc.line, tc.overflow, got, tc.want)
+		}
+	}
+}
+
+func TestParseOverflow(t *testing.T) {
+	if o, err := ParseOverflow(""); err != nil || o != Wrap {
+		t.Errorf("ParseOverflow(\"\") = %q, %v; want wrap", o, err)
+	}
+	if o, err := ParseOverflow("truncate"); err != nil || o != Truncate {
+		t.Errorf("ParseOverflow(truncate) = %q, %v", o, err)
+	}
+	if _, err := ParseOverflow("clip"); err == nil {
+		t.Error("ParseOverflow(clip) should fail")
+	}
+}
@@ -0,0 +1,99 @@
+package grid
+
+import "strconv"
+
+// Attribute bits for Style.Attrs, indexed by their SGR code (1 = bold ... 9 = strikethrough).
+const (
+	Bold      uint16 = 1 << 1
+	Dim       uint16 = 1 << 2
+	Italic    uint16 = 1 << 3
+	Underline uint16 = 1 << 4
+	Blink     uint16 = 1 << 5
+	Inverse   uint16 = 1 << 7
+	Hidden    uint16 = 1 << 8
+	Strike    uint16 = 1 << 9
+)
+
+// Style is the graphic rendition (SGR state) of a cell.
+// The zero value is the terminal default.
+type Style struct {
+	FG    string // Foreground SGR parameters (e.g. "31", "38;5;208"), "" = default
+	BG    string // Background SGR parameters (e.g. "44", "48;2;0;0;0"), "" = default
+	Attrs uint16 // Bitmask of Bold, Dim, Italic, ...
+}
+
+// SGR returns the SGR parameters that select this style from the default
+// (e.g. "1;31;44"), or "" for the default style.
+func (st Style) SGR() string {
+	var sgr string
+	add := func(p string) {
+		if sgr != "" {
+			sgr += ";"
+		}
+		sgr += p
+	}
+	for code := 1; code <= 9; code++ {
+		if st.Attrs&(1<<code) != 0 {
+			add(strconv.Itoa(code))
+		}
+	}
+	if st.FG != "" {
+		add(st.FG)
+	}
+	if st.BG != "" {
+		add(st.BG)
+	}
+	return sgr
+}
+
+// apply returns the style after applying the given SGR parameters.
+func (st Style) apply(args []int) Style {
+	if len(args) == 0 {
+		return Style{}
+	}
+	for i := 0; i < len(args); i++ {
+		p := args[i]
+		switch {
+		case p <= 0:
+			st = Style{}
+		case p >= 1 && p <= 9:
+			st.Attrs |= 1 << p
+		case p == 22:
+			st.Attrs &^= Bold | Dim
+		case p >= 23 && p <= 29:
+			st.Attrs &^= 1 << (p - 20)
+		case (p >= 30 && p <= 37) || (p >= 90 && p <= 97):
+			st.FG = strconv.Itoa(p)
+		case p == 39:
+			st.FG = ""
+		case (p >= 40 && p <= 47) || (p >= 100 && p <= 107):
+			st.BG = strconv.Itoa(p)
+		case p == 49:
+			st.BG = ""
+		case p == 38 || p == 48:
+			color, n := extendedColor(args[i:])
+			if p == 38 {
+				st.FG = color
+			} else {
+				st.BG = color
+			}
+			i += n - 1
+		}
+	}
+	return st
+}
+
+// extendedColor parses "38;5;n" / "38;2;r;g;b" (or 48;...) at the start of
+// args, returning the parameter string and how many args it consumed.
+// Malformed sequences consume only the leading 38/48 and yield "".
+func extendedColor(args []int) (string, int) {
+	// Empty parameters (e.g. "38;2;;;") mean 0
+	v := func(i int) string { return strconv.Itoa(max(args[i], 0)) }
+	if len(args) >= 3 && args[1] == 5 {
+		return v(0) + ";5;" + v(2), 3
+	}
+	if len(args) >= 5 && args[1] == 2 {
+		return v(0) + ";2;" + v(2) + ";" + v(3) + ";" + v(4), 5
+	}
+	return "", 1
+}
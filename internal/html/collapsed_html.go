@@ -0,0 +1,135 @@
+package html
+
+import (
+	"html"
+	"strconv"
+)
+
+// collapsedCSS returns the CSS for a page that starts collapsed: while the
+// body has the "collapsed" class, the command list (see collapsedHTML)
+// shows instead of the terminal and its controls.
+func collapsedCSS() string {
+	return `
+    #collapsed-toc {
+      display: none;
+      padding: 24px;
+      font-size: 14px;
+    }
+    body.collapsed #collapsed-toc {
+      display: block;
+    }
+    body.collapsed #loading,
+    body.collapsed #terminal,
+    body.collapsed #viewer-controls,
+    body.collapsed #error-nav {
+      display: none;
+    }
+    #collapsed-toc p {
+      margin-bottom: 12px;
+      font-size: 13px;
+      color: #888888;
+    }
+    #collapsed-toc ol {
+      padding-left: 3em;
+      margin-bottom: 16px;
+    }
+    #collapsed-toc li {
+      padding: 2px 0;
+      color: #666666;
+    }
+    #collapsed-toc a {
+      color: #e0e0e0;
+      text-decoration: none;
+    }
+    #collapsed-toc a:hover,
+    #collapsed-toc a:focus-visible {
+      color: #ffffff;
+      text-decoration: underline;
+    }
+`
+}
+
+// bodyAttrs returns the attributes of the page's <body>: the "collapsed"
+// class if it starts collapsed, so the terminal never flashes into view
+// before the script runs.
+func bodyAttrs(collapsed bool) string {
+	if !collapsed {
+		return ""
+	}
+	return ` class="collapsed"`
+}
+
+// collapsedHTML returns the command list a collapsed page starts with, each
+// command linking to its #input-N anchor, and a link to the whole recording.
+// Returns empty string if there are no entries.
+func collapsedHTML(entries []TOCEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	items := ""
+	for i, e := range entries {
+		label := e.Label
+		if label == "" {
+			label = "(empty)"
+		}
+		items += `
+      <li><a href="#input-` + strconv.Itoa(i) + `">` + html.EscapeString(label) + `</a></li>`
+	}
+	return `
+  <div id="collapsed-toc" role="navigation" aria-label="Commands">
+    <p>` + strconv.Itoa(len(entries)) + ` commands. Pick one to load the recording there.</p>
+    <ol>` + items + `
+    </ol>
+    <a href="#" id="collapsed-all">Show the whole recording</a>
+  </div>
+`
+}
+
+// collapsedJS returns the JavaScript defining startCollapsed(show), which
+// leaves a collapsed page on its command list and calls show() to load and
+// write the recording once a command is picked (or < / > is pressed),
+// going to that command once the 'xterm-ready' event fires (see tocJS).
+// Deep links (#input-N, #line-N) call show() straight away.
+// Returns empty string if the page doesn't start collapsed.
+func collapsedJS(collapsed bool) string {
+	if !collapsed {
+		return ""
+	}
+	return `
+    // Collapsed start: only the command list until one is picked
+    function startCollapsed(show) {
+      var list = document.getElementById('collapsed-toc');
+      var shown = false;
+
+      function reveal(hash) {
+        if (shown) return;
+        shown = true;
+        history.replaceState(null, '', hash || location.pathname + location.search);
+        document.body.classList.remove('collapsed');
+        show();
+      }
+
+      if (!list || /^#(input|line)-\d+$/.test(location.hash)) {
+        reveal(location.hash);
+        return;
+      }
+
+      list.addEventListener('click', function(e) {
+        var link = e.target.closest('a');
+        if (!link) return;
+        e.preventDefault();
+        reveal(link.id === 'collapsed-all' ? '' : link.getAttribute('href'));
+        if (link.id === 'collapsed-all') window.scrollTo(0, 0);
+      });
+
+      // Command navigation keys start from the first command; registered
+      // before the nav's own handler, which has nothing to navigate yet
+      document.addEventListener('keydown', function(e) {
+        if (shown || (e.key !== '<' && e.key !== '>')) return;
+        e.preventDefault();
+        e.stopImmediatePropagation();
+        reveal('#input-0');
+      });
+    }
+`
+}
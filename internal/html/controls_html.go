@@ -0,0 +1,255 @@
+package html
+
+// controlsCSS returns the CSS for the viewer controls and line-number gutter.
+func controlsCSS() string {
+	return `
+    #viewer-controls {
+      position: fixed;
+      bottom: 12px;
+      right: 12px;
+      z-index: 1000;
+      display: flex;
+      gap: 4px;
+      user-select: none;
+    }
+    .viewer-btn {
+      cursor: pointer;
+      background: rgba(30, 30, 30, 0.9);
+      border: 1px solid rgba(212, 212, 212, 0.2);
+      color: #888;
+      padding: 4px 10px;
+      font-size: 12px;
+      font-family: inherit;
+      border-radius: 4px;
+      transition: color 0.15s;
+    }
+    .viewer-btn:hover,
+    .viewer-btn.active {
+      color: #fff;
+    }
+    .viewer-btn.live {
+      color: #e06c75;
+    }
+    body.gutter-on #terminal {
+      padding-left: 5em;
+    }
+    #line-gutter {
+      position: absolute;
+      top: 0;
+      left: 0;
+      width: 4.5em;
+      text-align: right;
+      color: #5a5a5a;
+      font-size: 12px;
+      white-space: pre;
+      pointer-events: none;
+      user-select: none;
+      display: none;
+    }
+    body.gutter-on #line-gutter {
+      display: block;
+    }
+`
+}
+
+// controlsHTML returns the HTML markup for the viewer controls.
+// followToggle adds the follow-mode button (streaming template only);
+// playToggle adds the frame playback button (see framesJS).
+func controlsHTML(followToggle, playToggle bool) string {
+	follow := ""
+	if followToggle {
+		follow = `
+    <button type="button" class="viewer-btn" id="follow-toggle" title="Follow new output (like tail -f)">○ Follow</button>`
+	}
+	if playToggle {
+		follow += `
+    <button type="button" class="viewer-btn" id="play-toggle" title="Replay the recorded frames">▶ Play</button>`
+	}
+	return `
+  <div id="viewer-controls">` + follow + `
+    <button type="button" class="viewer-btn" id="gutter-toggle" title="Toggle line numbers">#</button>
+    <button type="button" class="viewer-btn" id="line-link" title="Copy link to this line">link</button>
+  </div>
+`
+}
+
+// controlsJS returns the JavaScript for the viewer controls.
+// The line-number gutter is off by default; the choice is kept in localStorage.
+// #line-N hashes (1-indexed, matching the gutter) scroll to a rendered row, and
+// the link button copies the current scroll position as such a URL.
+// Requires `xterm` variable and the rowJS helpers to be in scope.
+func controlsJS() string {
+	return `
+    // Line-number gutter
+    (function() {
+      var STORAGE_KEY = 'record-tui:gutter';
+      var toggle = document.getElementById('gutter-toggle');
+      var gutter = document.createElement('div');
+      gutter.id = 'line-gutter';
+      var renderedRows = 0;
+      var renderedHeight = 0;
+
+      function loadPref() {
+        try {
+          return localStorage.getItem(STORAGE_KEY) === 'on';
+        } catch (e) {
+          return false; // storage unavailable (e.g. file:// in some browsers)
+        }
+      }
+
+      function savePref(on) {
+        try {
+          localStorage.setItem(STORAGE_KEY, on ? 'on' : 'off');
+        } catch (e) {}
+      }
+
+      // Number every rendered row, one line of text per terminal row
+      function renderGutter() {
+        var terminalDiv = document.getElementById('terminal');
+        if (!gutter.parentNode) {
+          terminalDiv.style.position = 'relative';
+          terminalDiv.appendChild(gutter);
+        }
+        gutter.style.top = rowTopInTerminal(0) + 'px';
+        var rows = xterm.rows;
+        var cellHeight = getCellHeight();
+        if (rows === renderedRows && cellHeight === renderedHeight) return;
+        var numbers = [];
+        for (var i = 1; i <= rows; i++) {
+          numbers.push(i);
+        }
+        gutter.textContent = numbers.join('\n');
+        gutter.style.lineHeight = cellHeight + 'px';
+        renderedRows = rows;
+        renderedHeight = cellHeight;
+      }
+
+      function setGutter(on) {
+        document.body.classList.toggle('gutter-on', on);
+        toggle.classList.toggle('active', on);
+        if (on) renderGutter();
+      }
+
+      toggle.addEventListener('click', function() {
+        var on = !document.body.classList.contains('gutter-on');
+        savePref(on);
+        setGutter(on);
+      });
+
+      document.addEventListener('xterm-ready', function() {
+        setGutter(loadPref());
+      });
+      function refreshGutter() {
+        if (document.body.classList.contains('gutter-on')) renderGutter();
+      }
+      window.addEventListener('resize', refreshGutter);
+      document.addEventListener('xterm-reflow', refreshGutter);
+    })();
+
+    // #line-N permalinks
+    (function() {
+      var linkBtn = document.getElementById('line-link');
+
+      function scrollToHashLine() {
+        var match = location.hash.match(/^#line-(\d+)$/);
+        if (match) {
+          scrollToRow(Math.max(parseInt(match[1], 10) - 1, 0));
+        }
+      }
+
+      // Row at the top of the viewport, inverse of scrollToRow
+      function currentRow() {
+        var row = Math.floor((window.pageYOffset + scrollMargin() - rowsTop()) / getCellHeight());
+        return Math.min(Math.max(row, 0), Math.max(xterm.rows - 1, 0));
+      }
+
+      function flash(text) {
+        linkBtn.textContent = text;
+        setTimeout(function() { linkBtn.textContent = 'link'; }, 1500);
+      }
+
+      linkBtn.addEventListener('click', function() {
+        var hash = '#line-' + (currentRow() + 1);
+        history.replaceState(null, '', hash);
+        var url = location.href;
+        if (navigator.clipboard && navigator.clipboard.writeText) {
+          navigator.clipboard.writeText(url).then(function() {
+            flash('copied');
+          }, function() {
+            flash('see URL'); // clipboard denied, the address bar has the link
+          });
+        } else {
+          flash('see URL');
+        }
+      });
+
+      document.addEventListener('xterm-ready', scrollToHashLine);
+      window.addEventListener('popstate', scrollToHashLine);
+    })();
+`
+}
+
+// followJS returns the JavaScript for follow mode in the streaming template:
+// while following, output is written as it arrives and the page stays on the
+// newest line. Scrolling up pauses following; scrolling back to the bottom
+// resumes it. Requires FOLLOW, `xterm`, pendingOutput and the rowJS helpers.
+func followJS() string {
+	return `
+    // Follow mode (like tail -f)
+    var following = false;
+    var followPaused = false; // paused by scrolling up, resumes at the bottom
+    var followBtn = document.getElementById('follow-toggle');
+
+    // Cleaned output not yet written to the terminal
+    var pendingOutput = '';
+
+    function flushOutput() {
+      if (!pendingOutput) return;
+      var data = pendingOutput;
+      pendingOutput = '';
+      document.getElementById('loading').style.display = 'none';
+      xterm.write(data, function() {
+        if (following) followBottom();
+      });
+    }
+
+    // Page offset just below the cursor row (the newest output)
+    function contentBottom() {
+      var buffer = xterm.buffer.active;
+      return rowsTop() + (buffer.baseY + buffer.cursorY + 1) * getCellHeight();
+    }
+
+    function followBottom() {
+      window.scrollTo(0, Math.max(0, contentBottom() - window.innerHeight + 20));
+    }
+
+    function setFollowing(on) {
+      following = on;
+      followBtn.textContent = on ? '● Live' : '○ Follow';
+      followBtn.classList.toggle('live', on);
+      if (on && xterm) {
+        flushOutput();
+        followBottom();
+      }
+    }
+
+    followBtn.addEventListener('click', function() {
+      followPaused = false;
+      setFollowing(!following);
+    });
+
+    window.addEventListener('scroll', function() {
+      if (!xterm) return;
+      var atBottom = window.pageYOffset + window.innerHeight >= contentBottom() - 2 * getCellHeight();
+      if (following && !atBottom) {
+        followPaused = true;
+        setFollowing(false);
+      } else if (followPaused && atBottom) {
+        followPaused = false;
+        setFollowing(true);
+      }
+    }, { passive: true });
+
+    setFollowing(FOLLOW);
+`
+}
@@ -0,0 +1,69 @@
+package html
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// xtermCDN is the origin the templates load xterm.js and its stylesheet from.
+const xtermCDN = "https://cdn.jsdelivr.net"
+
+// newNonce returns a random base64-encoded nonce for a Content-Security-Policy.
+// A fresh nonce must be generated for every render.
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating CSP nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// nonceAttr returns the nonce attribute for <script>/<style> tags.
+// Returns empty string if nonce is empty (strict CSP disabled).
+func nonceAttr(nonce string) string {
+	if nonce == "" {
+		return ""
+	}
+	return ` nonce="` + nonce + `"`
+}
+
+// cspMeta returns a Content-Security-Policy <meta> tag that only allows
+// scripts and styles carrying the given nonce, plus xterm.js from the CDN.
+// Returns empty string if nonce is empty (strict CSP disabled).
+func cspMeta(nonce string) string {
+	if nonce == "" {
+		return ""
+	}
+	policy := "default-src 'self'; " +
+		"script-src 'nonce-" + nonce + "' " + xtermCDN + "; " +
+		"style-src 'nonce-" + nonce + "' " + xtermCDN + "; " +
+		"img-src 'self' data:; " +
+		"object-src 'none'; base-uri 'none'"
+	return `
+  <meta http-equiv="Content-Security-Policy" content="` + policy + `">`
+}
+
+// cspJS returns JavaScript that tags <style> elements created at runtime
+// (xterm.js injects its own) with the page nonce so the policy allows them.
+// Must run before the Terminal is opened.
+// Returns empty string if nonce is empty (strict CSP disabled).
+func cspJS(nonce string) string {
+	if nonce == "" {
+		return ""
+	}
+	return `
+    // Strict CSP: xterm.js creates style elements at runtime, give them our nonce
+    (function() {
+      var nonce = '` + nonce + `';
+      var createElement = document.createElement.bind(document);
+      document.createElement = function(tagName, options) {
+        var el = createElement(tagName, options);
+        if (String(tagName).toLowerCase() === 'style') {
+          el.setAttribute('nonce', nonce);
+        }
+        return el;
+      };
+    })();
+`
+}
@@ -0,0 +1,62 @@
+package html
+
+// embeddedCSS returns the CSS for PlaybackOptions.Embedded: a transparent,
+// content-height page so only the terminal shows inside the host's <iframe>.
+func embeddedCSS() string {
+	return `
+    html, body {
+      background-color: transparent;
+      overflow: hidden;
+    }
+`
+}
+
+// embeddedJS returns the JavaScript for PlaybackOptions.Embedded.
+// fitToContainer sizes the terminal's columns to its container (called
+// before content is written, and again when the iframe is resized), and the
+// page height is posted to the host as a {type: 'record-tui:height', height}
+// message so it can size the iframe to fit. containerCols is shared with
+// responsiveJS.
+// Requires `xterm`, `EMBEDDED` and shrinkToContent to be in scope.
+func embeddedJS() string {
+	return `
+    // Columns that fit the terminal's container (0 before it is rendered)
+    function containerCols() {
+      var terminalDiv = document.getElementById('terminal');
+      var screen = terminalDiv.querySelector('.xterm-screen');
+      var cellWidth = screen ? screen.getBoundingClientRect().width / xterm.cols : 0;
+      if (!cellWidth) return 0;
+      return Math.max(20, Math.floor(terminalDiv.clientWidth / cellWidth));
+    }
+
+    // Embedded (iframe) mode
+    function fitToContainer() {
+      var cols = containerCols();
+      if (!cols || cols === xterm.cols) return;
+      // Narrower lines wrap onto more rows: grow first so none scroll out of view
+      var rows = Math.max(xterm.rows, xterm.buffer.active.length) * Math.max(1, Math.ceil(xterm.cols / cols));
+      xterm.resize(cols, rows);
+    }
+
+    function postHeight() {
+      if (window.parent === window) return;
+      window.parent.postMessage({
+        type: 'record-tui:height',
+        height: document.documentElement.scrollHeight,
+      }, '*');
+    }
+
+    if (EMBEDDED) {
+      document.addEventListener('xterm-ready', postHeight);
+      var embeddedResizeTimer;
+      window.addEventListener('resize', function() {
+        clearTimeout(embeddedResizeTimer);
+        embeddedResizeTimer = setTimeout(function() {
+          fitToContainer();
+          shrinkToContent();
+          postHeight();
+        }, 100);
+      });
+    }
+`
+}
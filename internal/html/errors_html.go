@@ -0,0 +1,153 @@
+package html
+
+import (
+	"encoding/json"
+)
+
+// errorNavCSS returns the CSS for the error navigation (see errorNavHTML).
+func errorNavCSS() string {
+	return `
+    #error-nav {
+      position: fixed;
+      bottom: 12px;
+      left: 12px;
+      z-index: 1000;
+      background: rgba(30, 30, 30, 0.9);
+      border: 1px solid rgba(224, 108, 117, 0.4);
+      color: #e06c75;
+      padding: 4px 10px;
+      font-size: 12px;
+      border-radius: 4px;
+      user-select: none;
+      display: none;
+    }
+    #error-nav .nav-btn {
+      font-size: 14px;
+    }
+    #error-pos {
+      margin: 0 4px;
+    }
+    #error-highlight {
+      position: absolute;
+      left: 0;
+      right: 0;
+      background: rgba(224, 108, 117, 0.15);
+      border-left: 3px solid rgba(224, 108, 117, 0.7);
+      pointer-events: none;
+      z-index: 10;
+      display: none;
+    }
+`
+}
+
+// errorNavHTML returns the markup for stepping between error output (see
+// session.FindErrorLines), or empty string if there is none.
+func errorNavHTML(errorLines []int) string {
+	if len(errorLines) == 0 {
+		return ""
+	}
+	return `
+  <div id="error-nav" role="navigation" aria-label="Errors">
+    <span class="nav-btn" id="error-prev" role="button" tabindex="0" aria-label="Previous error" title="Previous error (p)">&lt;</span>
+    <span id="error-pos">errors</span>
+    <span class="nav-btn" id="error-next" role="button" tabindex="0" aria-label="Next error" title="Next error (n)">&gt;</span>
+  </div>
+`
+}
+
+// errorNavJS returns the JavaScript for n / p navigation between error
+// lines. Lines count '\n'-separated lines of the content, so each is found
+// in the buffer by skipping the rows xterm wrapped long lines onto.
+// Requires `xterm` variable and the rowJS helpers to be in scope.
+// Returns empty string if there are no error lines.
+func errorNavJS(errorLines []int) string {
+	if len(errorLines) == 0 {
+		return ""
+	}
+
+	linesJSON, _ := json.Marshal(errorLines)
+
+	return `
+    // Error n / p navigation
+    (function() {
+      var errorLines = ` + string(linesJSON) + `;
+      var errorRows = [];
+      var currentError = -1;
+      var nav = document.getElementById('error-nav');
+      var posEl = document.getElementById('error-pos');
+      var highlight = document.createElement('div');
+      highlight.id = 'error-highlight';
+
+      // Buffer row of each error line: the rows that start a line
+      function resolveErrorRows() {
+        var buffer = xterm.buffer.active;
+        if (!buffer) return;
+        var rows = [];
+        var line = -1;
+        var j = 0;
+        for (var row = 0; row < buffer.length && j < errorLines.length; row++) {
+          var bufferLine = buffer.getLine(row);
+          if (bufferLine && bufferLine.isWrapped) continue;
+          line++;
+          while (j < errorLines.length && errorLines[j] <= line) {
+            rows.push(row);
+            j++;
+          }
+        }
+        errorRows = rows;
+        if (rows.length > 0) {
+          nav.style.display = 'block';
+          updateErrorPos();
+        }
+      }
+      document.addEventListener('xterm-ready', resolveErrorRows);
+      document.addEventListener('xterm-reflow', resolveErrorRows);
+
+      function updateErrorPos() {
+        posEl.textContent = (currentError < 0 ? '-' : currentError + 1) + '/' + errorRows.length +
+          (errorRows.length === 1 ? ' error' : ' errors');
+      }
+
+      function goToError(index) {
+        if (errorRows.length === 0) return;
+        currentError = Math.max(0, Math.min(index, errorRows.length - 1));
+        var row = errorRows[currentError];
+        scrollToRow(row);
+        var terminalDiv = document.getElementById('terminal');
+        if (!highlight.parentNode) {
+          terminalDiv.style.position = 'relative';
+          terminalDiv.appendChild(highlight);
+        }
+        highlight.style.top = rowTopInTerminal(row) + 'px';
+        highlight.style.height = getCellHeight() + 'px';
+        highlight.style.display = 'block';
+        updateErrorPos();
+      }
+
+      function onActivate(el, fn) {
+        el.addEventListener('click', fn);
+        el.addEventListener('keydown', function(e) {
+          if (e.key === 'Enter' || e.key === ' ') {
+            e.preventDefault();
+            fn();
+          }
+        });
+      }
+      onActivate(document.getElementById('error-prev'), function() { goToError(currentError - 1); });
+      onActivate(document.getElementById('error-next'), function() { goToError(currentError + 1); });
+
+      document.addEventListener('keydown', function(e) {
+        if (e.metaKey || e.ctrlKey || e.altKey) return;
+        // Not while typing, e.g. in the command filter
+        if (e.target.tagName === 'INPUT') return;
+        if (e.key === 'n') {
+          e.preventDefault();
+          goToError(currentError + 1);
+        } else if (e.key === 'p') {
+          e.preventDefault();
+          goToError(currentError - 1);
+        }
+      });
+    })();
+`
+}
@@ -0,0 +1,109 @@
+package html
+
+// hasFrameLabels reports whether any frame has a Label to show as a caption.
+func hasFrameLabels(frames []PlaybackFrame) bool {
+	for _, f := range frames {
+		if f.Label != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// captionCSS returns the CSS for the frame caption overlay.
+func captionCSS() string {
+	return `
+    #frame-caption {
+      position: fixed;
+      bottom: 48px;
+      left: 50%;
+      transform: translateX(-50%);
+      z-index: 1000;
+      max-width: 80%;
+      padding: 6px 14px;
+      background: rgba(30, 30, 30, 0.9);
+      border: 1px solid rgba(212, 212, 212, 0.2);
+      border-radius: 4px;
+      color: #e0e0e0;
+      font-size: 14px;
+      text-align: center;
+      pointer-events: none;
+      display: none;
+    }
+`
+}
+
+// captionHTML returns the caption overlay markup, or empty string if no
+// frame has a Label.
+func captionHTML(frames []PlaybackFrame) string {
+	if !hasFrameLabels(frames) {
+		return ""
+	}
+	return `
+  <div id="frame-caption" role="status" aria-live="polite"></div>
+`
+}
+
+// framesJS returns the JavaScript for multi-frame playback. The page shows
+// the last frame as before; the play button (see controlsHTML) replays the
+// frames at their recorded timestamps, captioning each with its label, and
+// stopping jumps back to the last frame.
+// Requires `xterm` and `frames` to be in scope.
+func framesJS() string {
+	return `
+    // Frame playback and captions
+    (function() {
+      if (frames.length === 0) return;
+      var playBtn = document.getElementById('play-toggle');
+      var caption = document.getElementById('frame-caption');
+      var timer = null;
+
+      function showCaption(label) {
+        if (!caption) return;
+        caption.textContent = label || '';
+        caption.style.display = label ? 'block' : 'none';
+      }
+
+      function showFrame(i) {
+        xterm.reset();
+        xterm.write(frames[i].content);
+        showCaption(frames[i].label);
+      }
+
+      function setPlaying(on) {
+        playBtn.textContent = on ? '■ Stop' : '▶ Play';
+        playBtn.classList.toggle('active', on);
+      }
+
+      function stop() {
+        clearTimeout(timer);
+        timer = null;
+        setPlaying(false);
+        showFrame(frames.length - 1);
+      }
+
+      function play() {
+        var i = 0;
+        setPlaying(true);
+        (function step() {
+          showFrame(i);
+          if (i === frames.length - 1) {
+            timer = null;
+            setPlaying(false);
+            return;
+          }
+          var delay = Math.max(0, frames[i + 1].timestamp - frames[i].timestamp) * 1000;
+          i++;
+          timer = setTimeout(step, delay);
+        })();
+      }
+
+      showCaption(frames[frames.length - 1].label);
+      if (playBtn) {
+        playBtn.addEventListener('click', function() {
+          if (timer) stop(); else play();
+        });
+      }
+    })();
+`
+}
@@ -0,0 +1,116 @@
+package html
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden.html with the current output")
+
+// provenancePattern matches the provenance version and timestamp, the only
+// parts of the output that vary between renders and builds (nonces only
+// appear with StrictCSP, which the golden cases don't use).
+var provenancePattern = regexp.MustCompile(`(<meta name="record-tui:(?:version|generated-at)" content=")[^"]*(">)`)
+
+// TestGoldenHTML compares rendered pages against committed golden files, so
+// any change to the templates' HTML, CSS or JS shows up in review.
+// Regenerate with: go test ./internal/html -run TestGoldenHTML -update
+func TestGoldenHTML(t *testing.T) {
+	frames := []PlaybackFrame{
+		{Timestamp: 0, Content: "$ ls\r\n\x1b[34mdir\x1b[0m  file.txt\r\n$ echo \"<done>\"\r\n<done>\r\n"},
+	}
+	toc := []TOCEntry{
+		{Label: "ls", Line: 0},
+		{Label: `echo "<done>"`, Line: 2},
+	}
+	links := []FooterLink{
+		{Text: "swe-swe", URL: "https://github.com/choonkeat/swe-swe"},
+		{Text: "docs", URL: "https://example.com/docs?a=1&b=2"},
+	}
+
+	tests := []struct {
+		name   string
+		render func() (string, error)
+	}{
+		{"embedded", func() (string, error) {
+			return RenderPlaybackHTML(frames, "", FooterLink{}, nil)
+		}},
+		{"embedded_options", func() (string, error) {
+			return RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+				Title:       "My <Session>",
+				Command:     `sh -c "ls <dir>"`,
+				FooterLinks: links,
+				TOC:         toc,
+				Cols:        120,
+				SourceHash:  "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+			})
+		}},
+		{"embedded_iframe", func() (string, error) {
+			return RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+				FooterLinks: links,
+				TOC:         toc,
+				Embedded:    true,
+			})
+		}},
+		{"streaming", func() (string, error) {
+			return RenderStreamingPlaybackHTML(StreamingOptions{DataURL: "./session.log"})
+		}},
+		{"streaming_options", func() (string, error) {
+			return RenderStreamingPlaybackHTML(StreamingOptions{
+				Title:           "My <Session>",
+				Command:         "claude",
+				DataURL:         "/api/recording/123?raw=1&x=2",
+				FooterLinks:     links,
+				Cols:            100,
+				MaxRows:         5000,
+				TOC:             toc,
+				Follow:          true,
+				HideAttribution: true,
+			})
+		}},
+		{"embedded_collapsed", func() (string, error) {
+			return RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+				TOC:       toc,
+				Collapsed: true,
+			})
+		}},
+		{"streaming_collapsed", func() (string, error) {
+			return RenderStreamingPlaybackHTML(StreamingOptions{
+				DataURL:   "./session.log",
+				TOC:       toc,
+				Collapsed: true,
+			})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.render()
+			if err != nil {
+				t.Fatalf("render failed: %v", err)
+			}
+			got = provenancePattern.ReplaceAllString(got, "${1}NORMALIZED${2}")
+
+			path := filepath.Join("testdata", tt.name+".golden.html")
+			if *update {
+				if err := os.MkdirAll("testdata", 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("cannot read golden file (run with -update to create it): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s; if the change is intended, rerun with -update and review the diff", path)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package html
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// lineTimesHTML returns a JSON script element mapping output lines to when
+// they appeared (see toc.LineTimes), for tools syncing narration to the
+// recording, or empty string if there are none. Element i of the array is
+// line i's time in seconds, rounded to milliseconds; read it with
+//
+//	JSON.parse(document.getElementById('line-times').textContent)
+//
+// A script element of type application/json isn't run, so it needs no CSP
+// nonce.
+func lineTimesHTML(times []float64) string {
+	if len(times) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(`
+  <script type="application/json" id="line-times">[`)
+	for i, t := range times {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(math.Round(t*1000)/1000, 'f', -1, 64))
+	}
+	b.WriteString("]</script>\n")
+	return b.String()
+}
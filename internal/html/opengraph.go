@@ -0,0 +1,28 @@
+package html
+
+import "html"
+
+// openGraphMeta returns Open Graph <meta> tags describing the page, so
+// links to it unfurl as preview cards in chat apps: og:title, og:description
+// (description, or one naming command if empty) and, if image is set,
+// og:image with a large-image card.
+func openGraphMeta(title, description, image, command string) string {
+	if description == "" {
+		description = "Terminal recording"
+		if command != "" {
+			description += " of " + command
+		}
+	}
+	card := "summary"
+	tags := `
+  <meta property="og:type" content="website">
+  <meta property="og:title" content="` + html.EscapeString(title) + `">
+  <meta property="og:description" content="` + html.EscapeString(description) + `">`
+	if image != "" {
+		card = "summary_large_image"
+		tags += `
+  <meta property="og:image" content="` + html.EscapeString(image) + `">`
+	}
+	return tags + `
+  <meta name="twitter:card" content="` + card + `">`
+}
@@ -0,0 +1,153 @@
+package html
+
+import (
+	"html"
+	"strconv"
+)
+
+// IndexPage is one page of a recording split into several pages, as listed
+// by RenderIndexHTML.
+type IndexPage struct {
+	URL string     // Page URL, relative to the index (e.g. "page-1.html")
+	TOC []TOCEntry // Commands on the page
+}
+
+// pageNavCSS returns the styles for pageNavHTML.
+func pageNavCSS() string {
+	return `
+    .page-nav {
+      display: flex;
+      gap: 16px;
+      padding: 12px 24px;
+      font-size: 13px;
+    }
+
+    .page-nav a {
+      color: #e0e0e0;
+      text-decoration: none;
+    }
+
+    .page-nav a:hover {
+      color: #ffffff;
+      text-decoration: underline;
+    }
+`
+}
+
+// pageNavHTML returns a bar of links to other pages of the recording (e.g.
+// previous, index, next), or empty string if there are none. Links missing
+// text or URL are skipped. It is shown above and below the terminal, so it
+// uses a class rather than an id.
+func pageNavHTML(links []FooterLink) string {
+	var items string
+	for _, link := range links {
+		if link.Text == "" || link.URL == "" {
+			continue
+		}
+		items += `
+    <a href="` + html.EscapeString(link.URL) + `">` + html.EscapeString(link.Text) + `</a>`
+	}
+	if items == "" {
+		return ""
+	}
+	return `
+  <nav class="page-nav">` + items + `
+  </nav>`
+}
+
+// RenderIndexHTML generates the index page of a recording split into
+// several pages: each page, with its commands linking to them on the page
+// (#input-N, see tocJS). Needs no JavaScript.
+func RenderIndexHTML(title string, pages []IndexPage) (string, error) {
+	if title == "" {
+		title = "Terminal"
+	}
+	escapedTitle := html.EscapeString(title)
+
+	var list string
+	for i, page := range pages {
+		pageURL := html.EscapeString(page.URL)
+		list += `
+    <li><a href="` + pageURL + `">Page ` + strconv.Itoa(i+1) + `</a>`
+		if len(page.TOC) > 0 {
+			list += `
+      <ol>`
+			for j, e := range page.TOC {
+				label := e.Label
+				if label == "" {
+					label = "(empty)"
+				}
+				list += `
+        <li><a href="` + pageURL + `#input-` + strconv.Itoa(j) + `"><code>` + html.EscapeString(label) + `</code></a></li>`
+			}
+			list += `
+      </ol>`
+		}
+		list += `
+    </li>`
+	}
+
+	return `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>` + escapedTitle + `</title>
+  <style>
+    body {
+      margin: 0;
+      background-color: #1e1e1e;
+      color: #d4d4d4;
+      font-family: 'SF Mono', 'Menlo', 'Consolas', 'Monaco', 'Courier New', monospace;
+      line-height: 1.6;
+    }
+
+    h1 {
+      margin: 0;
+      padding: 12px 24px;
+      font-size: 16px;
+      font-weight: normal;
+      border-bottom: 1px solid rgba(212, 212, 212, 0.1);
+    }
+
+    #pages {
+      padding: 12px 24px 12px 48px;
+      font-size: 13px;
+    }
+
+    #pages a {
+      color: #e0e0e0;
+      text-decoration: none;
+    }
+
+    #pages a:hover {
+      color: #ffffff;
+      text-decoration: underline;
+    }
+
+    #pages ol {
+      color: #888888;
+    }
+
+    #footer {
+      margin-top: 24px;
+      padding: 12px 24px;
+      text-align: right;
+      font-size: 12px;
+      color: #888888;
+      border-top: 1px solid rgba(212, 212, 212, 0.1);
+    }
+
+    #footer a {
+      color: #e0e0e0;
+      text-decoration: none;
+    }
+  </style>
+</head>
+<body>
+  <h1>` + escapedTitle + `</h1>
+  <ol id="pages">` + list + `
+  </ol>` + footerDiv(footerHTML(nil, false)) + `
+</body>
+</html>`, nil
+}
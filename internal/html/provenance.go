@@ -0,0 +1,59 @@
+package html
+
+import (
+	"html"
+	"runtime/debug"
+	"time"
+)
+
+// modulePath is record-tui's Go module path, used to look up its version
+// in the build info of whichever binary is rendering.
+const modulePath = "github.com/choonkeat/record-tui"
+
+// provenanceMeta returns <meta> tags recording where the page came from:
+// the SHA-256 of the source session.log, the record-tui version (ver, or
+// the module version if empty) and the generation time. Without a
+// sourceHash only an explicitly given ver is recorded, and without either
+// it returns empty string.
+func provenanceMeta(sourceHash, ver string, generatedAt time.Time) string {
+	if sourceHash == "" {
+		if ver == "" {
+			return ""
+		}
+		return versionMeta(ver)
+	}
+	if ver == "" {
+		ver = version()
+	}
+	return `
+  <meta name="record-tui:source-sha256" content="` + html.EscapeString(sourceHash) + `">` + versionMeta(ver) + `
+  <meta name="record-tui:generated-at" content="` + generatedAt.UTC().Format(time.RFC3339) + `">`
+}
+
+// versionMeta returns the record-tui:version <meta> tag.
+func versionMeta(ver string) string {
+	return `
+  <meta name="record-tui:version" content="` + html.EscapeString(ver) + `">`
+}
+
+// version returns the record-tui module version from the build info:
+// the main module when built as the record-tui binary, or the dependency
+// version when used as a library. Falls back to "(devel)".
+func version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil && dep.Replace.Version != "" {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "(devel)"
+}
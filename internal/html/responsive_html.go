@@ -0,0 +1,39 @@
+package html
+
+// responsiveJS returns the JavaScript for PlaybackOptions.Responsive: after
+// the window is resized, the recording is written again at the number of
+// columns that fits, so its lines reflow instead of being clipped or leaving
+// wide margins. xterm.js only reflows lines it wrapped itself, not the
+// recording's own line breaks, hence the rewrite from the source content.
+// An 'xterm-reflow' event is dispatched once rewritten, for anything that
+// tracks row positions (TOC, line numbers).
+// Requires `xterm`, `content`, `lines`, `estimatedRows`, `RESPONSIVE`,
+// containerCols and shrinkToContent to be in scope.
+func responsiveJS() string {
+	return `
+    // Responsive mode: reflow to the window width
+    function reflowToContainer() {
+      var cols = containerCols();
+      if (!cols || cols === xterm.cols) return;
+      // Enough rows for every line once wrapped, so none scroll out of view
+      var rows = 0;
+      for (var i = 0; i < lines.length; i++) {
+        rows += Math.max(1, Math.ceil(lines[i].length / cols));
+      }
+      xterm.reset();
+      xterm.resize(cols, Math.max(estimatedRows, rows));
+      xterm.write(content, function() {
+        shrinkToContent();
+        document.dispatchEvent(new Event('xterm-reflow'));
+      });
+    }
+
+    if (RESPONSIVE) {
+      var reflowTimer;
+      window.addEventListener('resize', function() {
+        clearTimeout(reflowTimer);
+        reflowTimer = setTimeout(reflowToContainer, 150);
+      });
+    }
+`
+}
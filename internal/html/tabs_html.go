@@ -0,0 +1,180 @@
+package html
+
+import (
+	"encoding/json"
+	"html"
+	"strconv"
+)
+
+// Tab is one recording on a tabbed page, as rendered by RenderTabbedHTML.
+type Tab struct {
+	Label string // Tab label
+	Page  string // The recording's own page (see RenderPlaybackHTMLWithOptions)
+}
+
+// RenderTabbedHTML generates a page showing several recordings, one per
+// tab. Each tab's page is embedded as is and loaded into an <iframe> the
+// first time its tab is shown, so only the recordings looked at start an
+// xterm.js terminal. #tab-N (0-indexed) in the URL opens tab N.
+func RenderTabbedHTML(title string, tabs []Tab) (string, error) {
+	if title == "" {
+		title = "Terminal"
+	}
+	pages := make([]string, len(tabs))
+	for i, tab := range tabs {
+		pages[i] = tab.Page
+	}
+	// json.Marshal escapes <, > and &, so no page can end the <script> early
+	pagesJSON, err := json.Marshal(pages)
+	if err != nil {
+		return "", err
+	}
+
+	var tabBar, panels string
+	for i, tab := range tabs {
+		n := strconv.Itoa(i)
+		label := tab.Label
+		if label == "" {
+			label = "Recording " + strconv.Itoa(i+1)
+		}
+		tabBar += `
+    <button type="button" class="tab" id="tab-button-` + n + `" role="tab" aria-selected="false" aria-controls="tab-panel-` + n + `" tabindex="-1" data-index="` + n + `">` + html.EscapeString(label) + `</button>`
+		panels += `
+  <div class="tab-panel" id="tab-panel-` + n + `" role="tabpanel" aria-labelledby="tab-button-` + n + `" hidden></div>`
+	}
+
+	return `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>` + html.EscapeString(title) + `</title>
+  <style>
+    html, body {
+      margin: 0;
+      height: 100%;
+      background-color: #1e1e1e;
+      color: #d4d4d4;
+      font-family: 'SF Mono', 'Menlo', 'Consolas', 'Monaco', 'Courier New', monospace;
+    }
+
+    body {
+      display: flex;
+      flex-direction: column;
+    }
+
+    #tabs {
+      display: flex;
+      flex-wrap: wrap;
+      gap: 2px;
+      padding: 8px 12px 0;
+      border-bottom: 1px solid rgba(212, 212, 212, 0.2);
+    }
+
+    .tab {
+      padding: 6px 14px;
+      background: transparent;
+      border: 1px solid transparent;
+      border-bottom: none;
+      border-radius: 4px 4px 0 0;
+      color: #888888;
+      font-family: inherit;
+      font-size: 13px;
+      cursor: pointer;
+    }
+
+    .tab:hover {
+      color: #ffffff;
+    }
+
+    .tab[aria-selected="true"] {
+      color: #ffffff;
+      background: rgba(255, 255, 255, 0.06);
+      border-color: rgba(212, 212, 212, 0.2);
+    }
+
+    .tab:focus-visible {
+      outline: 1px solid rgba(255, 200, 50, 0.8);
+    }
+
+    .tab-panel {
+      flex: 1;
+      min-height: 0;
+    }
+
+    .tab-panel iframe {
+      display: block;
+      width: 100%;
+      height: 100%;
+      border: 0;
+    }
+  </style>
+</head>
+<body>
+  <div id="tabs" role="tablist" aria-label="Recordings">` + tabBar + `
+  </div>` + panels + `
+  <script type="application/json" id="tab-pages">` + string(pagesJSON) + `</script>
+  <script>
+    (function() {
+      var pages = JSON.parse(document.getElementById('tab-pages').textContent);
+      var buttons = document.querySelectorAll('.tab');
+      if (buttons.length === 0) return;
+
+      // Show tab i, loading its recording the first time
+      function select(i, focus) {
+        for (var j = 0; j < buttons.length; j++) {
+          var selected = j === i;
+          var panel = document.getElementById('tab-panel-' + j);
+          buttons[j].setAttribute('aria-selected', String(selected));
+          buttons[j].tabIndex = selected ? 0 : -1;
+          panel.hidden = !selected;
+          if (selected && !panel.firstChild) {
+            var frame = document.createElement('iframe');
+            frame.title = buttons[j].textContent;
+            frame.srcdoc = pages[j];
+            panel.appendChild(frame);
+          }
+        }
+        if (focus) buttons[i].focus();
+      }
+
+      // select, remembering the tab in the URL
+      function pick(i, focus) {
+        select(i, focus);
+        history.replaceState(null, '', '#tab-' + i);
+      }
+
+      for (var i = 0; i < buttons.length; i++) {
+        buttons[i].addEventListener('click', function() {
+          pick(parseInt(this.getAttribute('data-index'), 10), false);
+        });
+      }
+
+      // Arrow keys, Home and End move between tabs
+      document.getElementById('tabs').addEventListener('keydown', function(e) {
+        var i = Array.prototype.indexOf.call(buttons, document.activeElement);
+        if (i < 0) return;
+        if (e.key === 'ArrowRight') {
+          i = (i + 1) % buttons.length;
+        } else if (e.key === 'ArrowLeft') {
+          i = (i - 1 + buttons.length) % buttons.length;
+        } else if (e.key === 'Home') {
+          i = 0;
+        } else if (e.key === 'End') {
+          i = buttons.length - 1;
+        } else {
+          return;
+        }
+        e.preventDefault();
+        pick(i, true);
+      });
+
+      var match = location.hash.match(/^#tab-(\d+)$/);
+      var initial = match ? Math.min(parseInt(match[1], 10), buttons.length - 1) : 0;
+      select(initial, false);
+    })();
+  </script>
+</body>
+</html>
+`, nil
+}
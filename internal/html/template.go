@@ -4,43 +4,144 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// PlaybackOptions configures embedded HTML rendering.
+type PlaybackOptions struct {
+	Title       string       // Page title (defaults to "Terminal" if empty)
+	Command     string       // Recorded command, shown as a heading above the terminal (optional)
+	Cwd         string       // Working directory it was recorded in, added to the heading (optional)
+	Shell       string       // Shell it was recorded with, added to the heading (optional)
+	FooterLink  FooterLink   // Optional co-branding link
+	FooterLinks []FooterLink // Additional co-branding links, shown after FooterLink
+	TOC         []TOCEntry   // Optional table-of-contents entries for navigation
+	Cols        uint16       // Terminal columns (0 = estimate from content, 80-240)
+	StrictCSP   bool         // Emit a CSP meta tag and nonce all inline <script>/<style> tags
+
+	HideAttribution bool   // Omit the "generated by record-tui" footer link
+	SourceHash      string // Hex SHA-256 of the source session.log; adds provenance <meta> tags
+	Embedded        bool   // Minimal page for an <iframe>: no footer, TOC or viewer controls; fits the container width
+	ExtraCSS        string // Custom CSS added after the built-in styles (see extraStyle)
+	Responsive      bool   // Fit the columns to the window and re-write the recording when it is resized
+	Version         string // record-tui version for the provenance <meta> tags (empty = module version)
+
+	PageLinks []FooterLink // Links to other pages of a split recording, shown above and below the terminal
+	Minimap   bool         // Show a strip of clickable command ticks along the right edge (with TOC)
+	MaxRows   uint32       // Row cap; longer frames end in a truncation notice (0 = default 100000)
+
+	ErrorLines []int  // Lines of error output (see session.FindErrorLines) to step through with n / p
+	TypedInput string // What was typed (see timing.ReconstructInput), shown in a panel below the terminal
+
+	LineTimes []float64 // When each output line appeared (see toc.LineTimes), embedded as JSON for external tools
+	Collapsed bool      // Start with only the command list, writing the recording once a command is picked (with TOC)
+
+	OpenGraph            bool   // Add Open Graph <meta> tags for link previews (see openGraphMeta)
+	OpenGraphDescription string // og:description (empty = one naming Command)
+	OpenGraphImage       string // og:image URL, e.g. an SVG or PNG thumbnail (optional)
+
+	Timing []TimingEntry // Replay the last frame's content at this pace with the play button (see timingJS)
+}
+
 // RenderPlaybackHTML generates HTML document with terminal display.
 // Encodes frames as base64 to embed directly in the HTML.
 // Title is used for the page title (defaults to "Terminal" if empty).
 // FooterLink optionally adds a co-branding link (e.g., "generated by record-tui x swe-swe").
 // tocEntries optionally adds a floating TOC panel for navigation.
 func RenderPlaybackHTML(frames []PlaybackFrame, title string, footerLink FooterLink, tocEntries []TOCEntry) (string, error) {
-	// Encode frames as base64 to avoid escaping issues
-	framesJSON, err := json.Marshal(frames)
-	if err != nil {
+	return RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+		Title:      title,
+		FooterLink: footerLink,
+		TOC:        tocEntries,
+	})
+}
+
+// RenderPlaybackHTMLWithOptions is like RenderPlaybackHTML but takes a PlaybackOptions
+// for settings beyond title, footer link and TOC.
+func RenderPlaybackHTMLWithOptions(frames []PlaybackFrame, opts PlaybackOptions) (string, error) {
+	var b strings.Builder
+	if err := WritePlaybackHTML(&b, frames, opts); err != nil {
 		return "", err
 	}
+	return b.String(), nil
+}
 
-	framesBase64 := base64.StdEncoding.EncodeToString(framesJSON)
+// WritePlaybackHTML is like RenderPlaybackHTMLWithOptions but writes the
+// document to w, base64-encoding the frames straight into it, so a large
+// recording isn't also held in memory as the encoded string and the page.
+func WritePlaybackHTML(w io.Writer, frames []PlaybackFrame, opts PlaybackOptions) error {
+	// Rows past the cap would be lost from the scrollback without a trace
+	maxRows := int(opts.MaxRows)
+	if maxRows == 0 {
+		maxRows = defaultMaxRows
+	}
+	capped := make([]PlaybackFrame, len(frames))
+	for i, f := range frames {
+		f.Content = truncateRows(f.Content, maxRows)
+		capped[i] = f
+	}
+
+	// Encode frames as base64 to avoid escaping issues
+	framesJSON, err := json.Marshal(capped)
+	if err != nil {
+		return err
+	}
 
 	// Default title
+	title := opts.Title
 	if title == "" {
 		title = "Terminal"
 	}
 	escapedTitle := html.EscapeString(title)
+	footerLink := opts.FooterLink
+	tocEntries, errorLines, typedInput := opts.TOC, opts.ErrorLines, opts.TypedInput
+
+	// Strict CSP: fresh nonce per render, applied to every <script>/<style>
+	var nonce string
+	if opts.StrictCSP {
+		nonce, err = newNonce()
+		if err != nil {
+			return err
+		}
+	}
 
 	// Build footer HTML
-	footerHTML := `generated by <a href="https://github.com/choonkeat/record-tui" target="_blank" rel="noopener noreferrer">record-tui</a>`
-	if footerLink.Text != "" && footerLink.URL != "" {
-		footerHTML += ` x <a href="` + html.EscapeString(footerLink.URL) + `" target="_blank" rel="noopener noreferrer">` + html.EscapeString(footerLink.Text) + `</a>`
+	footer := footerHTML(allFooterLinks(footerLink, opts.FooterLinks), opts.HideAttribution)
+
+	// Embedded in an <iframe>: no fixed-position chrome, which assumes the
+	// page scrolls the full viewport
+	controls, controlsScript, embedStyle := controlsHTML(false, len(frames) > 1 || len(opts.Timing) > 0), controlsJS(), ""
+	playScript := framesJS()
+	if len(opts.Timing) > 0 {
+		playScript = timingJS()
+	}
+	pageNav := pageNavHTML(opts.PageLinks)
+	if opts.Embedded {
+		footer, tocEntries, errorLines, typedInput, pageNav = "", nil, nil, "", ""
+		controls, controlsScript, embedStyle = "", "", embeddedCSS()
+	}
+	shareMeta := ""
+	if opts.OpenGraph {
+		shareMeta = openGraphMeta(title, opts.OpenGraphDescription, opts.OpenGraphImage, opts.Command)
+	}
+	collapsed := opts.Collapsed && len(tocEntries) > 0
+	collapsedList := ""
+	if collapsed {
+		collapsedList = collapsedHTML(tocEntries)
 	}
 
-	htmlDoc := `<!DOCTYPE html>
+	head := `<!DOCTYPE html>
 <html lang="en">
 <head>
   <meta charset="UTF-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">` + cspMeta(nonce) + provenanceMeta(opts.SourceHash, opts.Version, time.Now()) + shareMeta + `
   <title>` + escapedTitle + `</title>
   <!-- xterm.js CSS -->
   <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/css/xterm.css" />
-  <style>
+  <style` + nonceAttr(nonce) + `>
     * {
       margin: 0;
       padding: 0;
@@ -87,27 +188,25 @@ func RenderPlaybackHTML(frames []PlaybackFrame, title string, footerLink FooterL
       font-size: 16px;
       color: #888888;
     }
-` + tocCSS() + `
-  </style>
+` + commandHeadingCSS() + tocCSS() + errorNavCSS() + controlsCSS() + captionCSS() + pageNavCSS() + typedInputCSS() + collapsedCSS() + embedStyle + `
+  </style>` + extraStyle(opts.ExtraCSS, nonce) + `
 </head>
-<body>
+<body` + bodyAttrs(collapsed) + `>` + commandHeading(opts.Command, opts.Cwd, opts.Shell) + pageNav + collapsedList + `
   <div id="loading">Loading...</div>
   <div id="terminal"></div>
-` + tocHTML(tocEntries) + `
-  <div id="footer">
-    ` + footerHTML + `
-  </div>
+` + tocHTML(tocEntries, opts.Minimap) + errorNavHTML(errorLines) + controls + captionHTML(frames) + typedInputHTML(typedInput) + lineTimesHTML(opts.LineTimes) + pageNav + footerDiv(footer) + `
 
   <!-- xterm.js script -->
-  <script src="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/lib/xterm.js"></script>
+  <script src="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/lib/xterm.js"` + nonceAttr(nonce) + `></script>
 
-  <script>
+  <script` + nonceAttr(nonce) + `>` + cspJS(nonce) + `
     // Decode base64-encoded frame data (UTF-8 safe)
-    const framesBase64 = '` + framesBase64 + `';
+    const framesBase64 = '`
+	tail := `';
     const framesJson = new TextDecoder().decode(
       Uint8Array.from(atob(framesBase64), c => c.charCodeAt(0))
     );
-    const frames = JSON.parse(framesJson);
+    const frames = JSON.parse(framesJson);` + timingData(opts.Timing) + `
 
     // Get content to calculate dimensions (use last frame which has all content)
     const content = frames.length > 0 ? frames[frames.length - 1].content : '(No frames to display)';
@@ -125,7 +224,8 @@ func RenderPlaybackHTML(frames []PlaybackFrame, title string, footerLink FooterL
     }
     // Also count newlines as a fallback minimum height
     const lineCount = content.split('\n').length;
-    const estimatedRows = Math.max(maxUsedRow, lineCount, 24);
+    const MAX_ROWS = ` + strconv.Itoa(maxRows) + `;
+    const estimatedRows = Math.min(Math.max(maxUsedRow, lineCount, 24), MAX_ROWS);
 
     // Estimate cols from content
     const normalized = content.split('\r\n').join('\n').split('\r').join('\n');
@@ -134,8 +234,13 @@ func RenderPlaybackHTML(frames []PlaybackFrame, title string, footerLink FooterL
     for (const line of lines) {
       maxLineLength = Math.max(maxLineLength, line.length);
     }
-    // Use max of 240 to avoid excessively wide terminals
-    const contentCols = Math.min(Math.max(maxLineLength, 80), 240);
+    // Use max of 240 to avoid excessively wide terminals,
+    // unless the recorded width is known
+    const recordedCols = ` + strconv.Itoa(int(opts.Cols)) + `;
+    const contentCols = recordedCols > 0 ? recordedCols : Math.min(Math.max(maxLineLength, 80), 240);
+    const EMBEDDED = ` + strconv.FormatBool(opts.Embedded) + `;
+    const RESPONSIVE = ` + strconv.FormatBool(opts.Responsive) + `;
+    const COLLAPSED = ` + strconv.FormatBool(collapsed) + `;
 
     // Initialize xterm.js with dimensions based on actual content usage
     // Read-only terminal: no keyboard/mouse input, but selection allowed for copy
@@ -156,7 +261,6 @@ func RenderPlaybackHTML(frames []PlaybackFrame, title string, footerLink FooterL
       allowProposedApi: true,
       allowAlternateScreen: false,
     });
-    xterm.open(terminalDiv);
 
     // Block keyboard input but allow copy shortcut to pass through to browser
     xterm.attachCustomKeyEventHandler((event) => {
@@ -180,15 +284,28 @@ func RenderPlaybackHTML(frames []PlaybackFrame, title string, footerLink FooterL
     // Block wheel events - let the page scroll instead of terminal
     xterm.attachCustomWheelEventHandler(() => false);
 
-    // Hide loading indicator and display content
-    document.getElementById('loading').style.display = 'none';
-    xterm.write(content);
+    // Hide loading indicator and display content: straight away, or once a
+    // command is picked from the list on a collapsed page
+    function showRecording() {
+      xterm.open(terminalDiv);
+      document.getElementById('loading').style.display = 'none';
+      if (EMBEDDED || RESPONSIVE) fitToContainer();
+      xterm.write(content);
+
+      setTimeout(() => {
+        if (!xterm.buffer.active) return;
+        shrinkToContent();
+
+        // Scroll to the top so content is visible from the start
+        xterm.scrollToTop();
+        document.dispatchEvent(new Event('xterm-ready'));
+      }, 0);
+    }
 
     // After rendering, check actual rendered height and resize if needed
     // This handles cases where TUI positioning might have created empty rows
-    setTimeout(() => {
+    function shrinkToContent() {
       const buffer = xterm.buffer.active;
-      if (!buffer) return;
 
       // Find the last row that has any non-whitespace content
       let lastContentRow = 0;
@@ -209,18 +326,31 @@ func RenderPlaybackHTML(frames []PlaybackFrame, title string, footerLink FooterL
       const actualHeight = Math.max(lastContentRow, cursorRow, 1);
 
       // Only resize if we found less content than allocated
-      if (actualHeight < estimatedRows) {
-        xterm.resize(contentCols, actualHeight);
+      if (actualHeight < xterm.rows) {
+        xterm.resize(xterm.cols, actualHeight);
       }
+    }
 
-      // Scroll to the top so content is visible from the start
-      xterm.scrollToTop();
-      document.dispatchEvent(new Event('xterm-ready'));
-    }, 0);
-` + tocJS(tocEntries) + `
+    if (COLLAPSED) {
+      startCollapsed(showRecording);
+    } else {
+      showRecording();
+    }
+` + collapsedJS(collapsed) + rowJS() + controlsScript + tocJS(tocEntries) + errorNavJS(errorLines) + playScript + embeddedJS() + responsiveJS() + `
   </script>
 </body>
 </html>`
 
-	return htmlDoc, nil
+	if _, err := io.WriteString(w, head); err != nil {
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := enc.Write(framesJSON); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, tail)
+	return err
 }
@@ -9,12 +9,26 @@ import (
 
 // StreamingOptions configures streaming HTML rendering.
 type StreamingOptions struct {
-	Title      string     // Page title (defaults to "Terminal" if empty)
-	DataURL    string     // URL to fetch session data from
-	FooterLink FooterLink // Optional co-branding link
-	Cols       uint16     // Terminal columns (0 = auto-detect, default 240)
-	MaxRows    uint32     // Maximum initial rows before auto-resize (0 = default 100000)
-	TOC        []TOCEntry // Optional table-of-contents entries for navigation
+	Title       string       // Page title (defaults to "Terminal" if empty)
+	Command     string       // Recorded command, shown as a heading above the terminal (optional)
+	DataURL     string       // URL to fetch session data from
+	FooterLink  FooterLink   // Optional co-branding link
+	FooterLinks []FooterLink // Additional co-branding links, shown after FooterLink
+	Cols        uint16       // Terminal columns (0 = auto-detect, default 240)
+	MaxRows     uint32       // Row cap and initial rows before auto-resize; longer output ends in a truncation notice (0 = default 100000; not when tailing)
+	Scrollback  uint32       // xterm.js scrollback when tailing: rows kept above the viewport, older ones dropped (0 = default 10000)
+	TOC         []TOCEntry   // Optional table-of-contents entries for navigation
+	StrictCSP   bool         // Emit a CSP meta tag and nonce all inline <script>/<style> tags
+	Follow      bool         // Start in follow mode (keep the newest output in view)
+	Minimap     bool         // Show a strip of clickable command ticks along the right edge (with TOC)
+	Collapsed   bool         // Start with only the command list, fetching the recording once a command is picked (with TOC)
+
+	HideAttribution bool   // Omit the "generated by record-tui" footer link
+	ExtraCSS        string // Custom CSS added after the built-in styles (see extraStyle)
+
+	// WebSocketURL tails session data over a WebSocket instead of fetching
+	// DataURL, for watching a recording in progress (see tailSession)
+	WebSocketURL string
 }
 
 // RenderStreamingPlaybackHTML generates an HTML document that streams terminal data from a URL.
@@ -29,6 +43,7 @@ func RenderStreamingPlaybackHTML(opts StreamingOptions) (string, error) {
 	}
 	escapedTitle := html.EscapeString(title)
 	escapedDataURL := html.EscapeString(opts.DataURL)
+	escapedWebSocketURL := html.EscapeString(opts.WebSocketURL)
 
 	// Terminal dimensions (0 = auto-detect)
 	cols := opts.Cols
@@ -41,26 +56,49 @@ func RenderStreamingPlaybackHTML(opts StreamingOptions) (string, error) {
 	// This avoids clipping issues with progressive writes and resize
 	rows := opts.MaxRows
 	if rows == 0 {
-		rows = 100000 // Default if not specified
+		rows = defaultMaxRows
 	}
 	scrollback := uint32(0)
+	if opts.WebSocketURL != "" {
+		// A tailed stream has no end to cap or resize to: the terminal is
+		// sized to the viewport (see fitTailRows), older rows scrolling into
+		// the scrollback, so its memory stays bounded
+		rows = tailRows
+		scrollback = opts.Scrollback
+		if scrollback == 0 {
+			scrollback = defaultScrollback
+		}
+	}
 	autoResizeEnabled := true
 
+	// Strict CSP: fresh nonce per render, applied to every <script>/<style>
+	var nonce string
+	if opts.StrictCSP {
+		var err error
+		nonce, err = newNonce()
+		if err != nil {
+			return "", err
+		}
+	}
+
 	// Build footer HTML
-	footerHTML := `generated by <a href="https://github.com/choonkeat/record-tui" target="_blank" rel="noopener noreferrer">record-tui</a>`
-	if opts.FooterLink.Text != "" && opts.FooterLink.URL != "" {
-		footerHTML += ` x <a href="` + html.EscapeString(opts.FooterLink.URL) + `" target="_blank" rel="noopener noreferrer">` + html.EscapeString(opts.FooterLink.Text) + `</a>`
+	footer := footerHTML(allFooterLinks(opts.FooterLink, opts.FooterLinks), opts.HideAttribution)
+
+	collapsed := opts.Collapsed && len(opts.TOC) > 0
+	collapsedList := ""
+	if collapsed {
+		collapsedList = collapsedHTML(opts.TOC)
 	}
 
 	htmlDoc := `<!DOCTYPE html>
 <html lang="en">
 <head>
   <meta charset="UTF-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">` + cspMeta(nonce) + `
   <title>` + escapedTitle + `</title>
   <!-- xterm.js CSS -->
   <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/css/xterm.css" />
-  <style>
+  <style` + nonceAttr(nonce) + `>
     * {
       margin: 0;
       padding: 0;
@@ -107,28 +145,29 @@ func RenderStreamingPlaybackHTML(opts StreamingOptions) (string, error) {
       color: #ffffff;
       text-decoration: underline;
     }
-` + tocCSS() + `
-  </style>
+` + commandHeadingCSS() + tocCSS() + controlsCSS() + collapsedCSS() + `
+  </style>` + extraStyle(opts.ExtraCSS, nonce) + `
 </head>
-<body>
+<body` + bodyAttrs(collapsed) + `>` + commandHeading(opts.Command, "", "") + collapsedList + `
   <div id="loading">Loading...</div>
   <div id="terminal"></div>
-` + tocHTML(opts.TOC) + `
-  <div id="footer">
-    ` + footerHTML + `
-  </div>
+` + tocHTML(opts.TOC, opts.Minimap) + controlsHTML(true, false) + footerDiv(footer) + `
 
   <!-- xterm.js script -->
-  <script src="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/lib/xterm.js"></script>
+  <script src="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/lib/xterm.js"` + nonceAttr(nonce) + `></script>
 
-  <script>
+  <script` + nonceAttr(nonce) + `>` + cspJS(nonce) + `
     // Data URL to fetch session content from
     const DATA_URL = '` + escapedDataURL + `';
+    // WebSocket URL to tail session content from instead (empty = fetch DATA_URL)
+    const WEBSOCKET_URL = '` + escapedWebSocketURL + `';
     // Terminal dimensions (from server metadata or defaults)
     const TERM_COLS = ` + fmt.Sprintf("%d", cols) + `;
     const TERM_ROWS = ` + fmt.Sprintf("%d", rows) + `;
     const TERM_SCROLLBACK = ` + fmt.Sprintf("%d", scrollback) + `;
     const AUTO_RESIZE = ` + fmt.Sprintf("%t", autoResizeEnabled) + `;
+    const FOLLOW = ` + fmt.Sprintf("%t", opts.Follow) + `;
+    const COLLAPSED = ` + fmt.Sprintf("%t", collapsed) + `;
     var xterm; // declared at top level so tocJS can access it
 
     // ============================================================
@@ -137,37 +176,178 @@ func RenderStreamingPlaybackHTML(opts StreamingOptions) (string, error) {
     // ============================================================
 ` + js.CleanerCoreJS + `
 
+` + followJS() + rowLimiterJS() + `
+    // Reconnect attempts after a dropped stream, with exponential backoff
+    const FETCH_MAX_RETRIES = 5;
+    const FETCH_RETRY_BASE_MS = 500;
+
     /**
      * Fetch session data and write to xterm all at once (like embedded).
-     * This avoids progressive write issues with resize.
+     * This avoids progressive write issues with resize. In follow mode
+     * output is written as it arrives instead (see flushOutput).
+     *
+     * If the connection drops mid-stream, resume from the last received byte
+     * with a Range request. Servers that ignore Range (200 instead of 206)
+     * resend everything, so the bytes we already have are skipped.
      */
     async function streamSession(url, xterm) {
-      const response = await fetch(url);
-      if (!response.ok) {
-        throw new Error('Failed to fetch ' + url + ': ' + response.status + ' ' + response.statusText);
+      const loadingDiv = document.getElementById('loading');
+      const decoder = createLogDecoder();
+
+      // With no scrollback, rows past TERM_ROWS would scroll the start of
+      // the recording away: cap them with a notice instead
+      const limiter = createRowLimiter(TERM_ROWS, (chunk) => {
+        pendingOutput += chunk;
+        if (following) flushOutput();
+      });
+      const cleaner = createStreamingCleaner((chunk) => limiter.write(chunk));
+
+      let received = 0; // bytes fed to the decoder so far
+      let retries = 0;
+
+      while (true) {
+        try {
+          const headers = received > 0 ? { 'Range': 'bytes=' + received + '-' } : {};
+          const response = await fetch(url, { headers: headers });
+          if (received > 0 && response.status === 416) {
+            // Dropped right after the last byte: nothing left to fetch
+            break;
+          }
+          if (!response.ok) {
+            const err = new Error('Failed to fetch ' + url + ': ' + response.status + ' ' + response.statusText);
+            // Client errors won't fix themselves; server errors may
+            err.fatal = response.status < 500;
+            throw err;
+          }
+
+          let skip = (received > 0 && response.status !== 206) ? received : 0;
+          if (retries > 0) {
+            loadingDiv.textContent = 'Loading...';
+          }
+
+          const reader = response.body.getReader();
+          while (true) {
+            const result = await reader.read();
+            if (result.done) break;
+            let bytes = result.value;
+            if (skip > 0) {
+              const n = Math.min(skip, bytes.length);
+              skip -= n;
+              bytes = bytes.subarray(n);
+              if (bytes.length === 0) continue;
+            }
+            received += bytes.length;
+            retries = 0; // made progress, reset the backoff
+            cleaner.write(decoder.decode(bytes));
+          }
+          break;
+        } catch (err) {
+          if (err.fatal || retries >= FETCH_MAX_RETRIES) {
+            throw err;
+          }
+          retries++;
+          loadingDiv.textContent = 'Reconnecting... (attempt ' + retries + ' of ' + FETCH_MAX_RETRIES + ')';
+          loadingDiv.style.display = 'block';
+          await new Promise((resolve) => setTimeout(resolve, FETCH_RETRY_BASE_MS * Math.pow(2, retries - 1)));
+        }
       }
+      cleaner.write(decoder.end());
+      cleaner.end();
+      limiter.end();
 
-      const loadingDiv = document.getElementById('loading');
+      // Write remaining content (all of it unless following, like embedded template does)
+      flushOutput();
+    }
 
-      // Collect all content first
-      const reader = response.body.getReader();
-      const decoder = new TextDecoder();
-      let allContent = '';
+    // Reconnect delay after the WebSocket closes, doubling up to the max
+    const WS_RETRY_BASE_MS = 500;
+    const WS_RETRY_MAX_MS = 10000;
+    // Quiet time before output held back by the cleaner is shown
+    const WS_IDLE_FLUSH_MS = 200;
 
+    /**
+     * Tail session data over a WebSocket, for watching a recording in
+     * progress: each message is written as it arrives, and the page stays
+     * on the newest line while following. The stream has no end, so the
+     * terminal keeps its initial size, and output the cleaner holds back
+     * (to detect a header or footer) is flushed once the stream goes quiet.
+     * Nor is it capped: the terminal is as tall as the viewport (see
+     * fitTailRows), and past it the oldest rows scroll into the
+     * TERM_SCROLLBACK rows of scrollback and are then dropped, keeping
+     * memory bounded while the newest output stays in view.
+     *
+     * When the connection closes, reconnect with exponential backoff,
+     * resuming after the last received byte (the offset query parameter).
+     */
+    function tailSession(url) {
+      const loadingDiv = document.getElementById('loading');
+      const decoder = createLogDecoder();
       const cleaner = createStreamingCleaner((chunk) => {
-        allContent += chunk;
+        pendingOutput += chunk;
+        flushOutput();
       });
 
-      while (true) {
-        const result = await reader.read();
-        if (result.done) break;
-        cleaner.write(decoder.decode(result.value, { stream: true }));
+      let received = 0; // bytes fed to the decoder so far
+      let retries = 0;
+      let ready = false;
+      let idleTimer = null;
+
+      function connect() {
+        // Relative URLs are resolved against the page, http(s) becoming ws(s)
+        const wsURL = new URL(url, window.location.href);
+        wsURL.protocol = wsURL.protocol.replace(/^http/, 'ws');
+        if (received > 0) {
+          wsURL.searchParams.set('offset', received);
+        }
+
+        const ws = new WebSocket(wsURL.href);
+        ws.binaryType = 'arraybuffer';
+        ws.onopen = () => {
+          retries = 0;
+          if (received === 0) {
+            loadingDiv.textContent = 'Waiting for output...';
+          } else {
+            loadingDiv.style.display = 'none';
+          }
+        };
+        ws.onmessage = (event) => {
+          const bytes = typeof event.data === 'string'
+            ? new TextEncoder().encode(event.data)
+            : new Uint8Array(event.data);
+          received += bytes.length;
+          cleaner.write(decoder.decode(bytes));
+          clearTimeout(idleTimer);
+          idleTimer = setTimeout(() => cleaner.flush(), WS_IDLE_FLUSH_MS);
+          if (!ready) {
+            ready = true;
+            setTimeout(function() {
+              document.dispatchEvent(new Event('xterm-ready'));
+            }, 100);
+          }
+        };
+        ws.onclose = () => {
+          const delay = Math.min(WS_RETRY_BASE_MS * Math.pow(2, retries), WS_RETRY_MAX_MS);
+          retries++;
+          loadingDiv.textContent = 'Disconnected, reconnecting...';
+          loadingDiv.style.display = 'block';
+          setTimeout(connect, delay);
+        };
       }
-      cleaner.end();
+      connect();
+    }
 
-      // Write all content at once (like embedded template does)
-      loadingDiv.style.display = 'none';
-      xterm.write(allContent);
+    /**
+     * Size the terminal of a tailed session to the viewport, in whole rows
+     * of the height the terminal renders them at.
+     */
+    function fitTailRows() {
+      const screen = xterm.element && xterm.element.querySelector('.xterm-screen');
+      const rowHeight = screen ? screen.getBoundingClientRect().height / xterm.rows : 0;
+      if (!rowHeight) return;
+      const rows = Math.max(1, Math.floor(window.innerHeight / rowHeight));
+      if (rows !== xterm.rows) {
+        xterm.resize(TERM_COLS, rows);
+      }
     }
 
     // Main initialization
@@ -215,6 +395,13 @@ func RenderStreamingPlaybackHTML(opts StreamingOptions) (string, error) {
       // Block wheel events - let the page scroll instead of terminal
       xterm.attachCustomWheelEventHandler(() => false);
 
+      if (WEBSOCKET_URL) {
+        fitTailRows();
+        window.addEventListener('resize', fitTailRows);
+        tailSession(WEBSOCKET_URL);
+        return;
+      }
+
       try {
         await streamSession(DATA_URL, xterm);
 
@@ -243,9 +430,13 @@ func RenderStreamingPlaybackHTML(opts StreamingOptions) (string, error) {
               xterm.resize(TERM_COLS, actualHeight);
             }
 
-            // Scroll to top and reset page position
+            // Scroll to top and reset page position (or stay on the newest output)
             xterm.scrollToTop();
-            window.scrollTo(0, 0);
+            if (following) {
+              followBottom();
+            } else {
+              window.scrollTo(0, 0);
+            }
             document.dispatchEvent(new Event('xterm-ready'));
           }, 100);
         } else {
@@ -260,8 +451,13 @@ func RenderStreamingPlaybackHTML(opts StreamingOptions) (string, error) {
       }
     }
 
-    main();
-` + tocJS(opts.TOC) + `
+    // A collapsed page fetches the recording once a command is picked
+    if (COLLAPSED) {
+      startCollapsed(main);
+    } else {
+      main();
+    }
+` + collapsedJS(collapsed) + rowJS() + controlsJS() + tocJS(opts.TOC) + `
   </script>
 </body>
 </html>`
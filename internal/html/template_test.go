@@ -3,6 +3,7 @@ package html
 import (
 	"encoding/base64"
 	"encoding/json"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -126,6 +127,105 @@ func TestRenderPlaybackHTML_MultipleFrames(t *testing.T) {
 	}
 }
 
+func TestRenderPlaybackHTML_FrameLabels(t *testing.T) {
+	frames := []PlaybackFrame{
+		{Timestamp: 0.0, Content: "$ make build", Label: "Build"},
+		{Timestamp: 2.5, Content: "$ make build\n$ make test", Label: "Test"},
+	}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, nil)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+
+	if !strings.Contains(html, `id="frame-caption"`) {
+		t.Error("HTML should contain the frame caption overlay")
+	}
+	if !strings.Contains(html, `id="play-toggle"`) {
+		t.Error("HTML should contain the play button for multiple frames")
+	}
+	if !strings.Contains(html, "caption.textContent = label") {
+		t.Error("captions should be set as text, not HTML")
+	}
+}
+
+func TestRenderPlaybackHTML_SingleFrameNoPlayback(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0.0, Content: "Hello World"}}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, nil)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+
+	if strings.Contains(html, `id="play-toggle"`) {
+		t.Error("a single frame should not have a play button")
+	}
+	if strings.Contains(html, `id="frame-caption"`) {
+		t.Error("frames without labels should not have a caption overlay")
+	}
+}
+
+func TestPlaybackFrame_LabelOmittedFromJSON(t *testing.T) {
+	framesJSON, err := json.Marshal([]PlaybackFrame{{Timestamp: 1.5, Content: "x"}})
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	if want := `[{"timestamp":1.5,"content":"x"}]`; string(framesJSON) != want {
+		t.Errorf("got %s, want %s", framesJSON, want)
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_PageLinks(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0.0, Content: "Hello World"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+		PageLinks: []FooterLink{
+			{Text: "← Previous", URL: "page-1.html"},
+			{Text: "Page 2 of 3", URL: "index.html?a=1&b=2"},
+			{Text: "", URL: "skipped.html"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	if got := strings.Count(html, `<nav class="page-nav">`); got != 2 {
+		t.Errorf("expected page links above and below the terminal, got %d", got)
+	}
+	if !strings.Contains(html, `<a href="index.html?a=1&amp;b=2">Page 2 of 3</a>`) {
+		t.Error("page link URLs should be escaped")
+	}
+	if strings.Contains(html, "skipped.html") {
+		t.Error("links without text should be skipped")
+	}
+
+	html, _ = RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{})
+	if strings.Contains(html, `<nav class="page-nav">`) {
+		t.Error("no page links should render no nav")
+	}
+}
+
+func TestRenderIndexHTML(t *testing.T) {
+	html, err := RenderIndexHTML("session.log", []IndexPage{
+		{URL: "page-1.html", TOC: []TOCEntry{{Label: "ls", Line: 0}, {Label: "echo <b>", Line: 3}}},
+		{URL: "page-2.html"},
+	})
+	if err != nil {
+		t.Fatalf("RenderIndexHTML failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`<title>session.log</title>`,
+		`<a href="page-1.html">Page 1</a>`,
+		`<a href="page-1.html#input-1"><code>echo &lt;b&gt;</code></a>`,
+		`<a href="page-2.html">Page 2</a>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("index should contain %s", want)
+		}
+	}
+}
+
 func TestRenderPlaybackHTML_ANSICodes(t *testing.T) {
 	frames := []PlaybackFrame{
 		{
@@ -232,6 +332,72 @@ func TestRenderPlaybackHTML_WithFooterLink(t *testing.T) {
 	}
 }
 
+func TestRenderPlaybackHTML_MultipleFooterLinks(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+		FooterLink: FooterLink{Text: "repo", URL: "https://example.com/repo"},
+		FooterLinks: []FooterLink{
+			{Text: "docs", URL: "https://example.com/docs"},
+			{Text: "", URL: "https://example.com/skipped"},
+			{Text: "video", URL: "https://example.com/video"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	// record-tui first, then FooterLink, then FooterLinks in order
+	want := `>record-tui</a> x <a href="https://example.com/repo" target="_blank" rel="noopener noreferrer">repo</a>` +
+		` x <a href="https://example.com/docs" target="_blank" rel="noopener noreferrer">docs</a>` +
+		` x <a href="https://example.com/video" target="_blank" rel="noopener noreferrer">video</a>`
+	if !strings.Contains(html, want) {
+		t.Errorf("Footer should list all links joined by ' x '")
+	}
+	if strings.Contains(html, "skipped") {
+		t.Errorf("Footer should skip links without text")
+	}
+}
+
+func TestRenderPlaybackHTML_HideAttribution(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{HideAttribution: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	if strings.Contains(html, `>record-tui</a>`) || strings.Contains(html, "generated by") {
+		t.Errorf("Footer should not have record-tui attribution")
+	}
+	// Nothing left to show: no empty footer bar
+	if strings.Contains(html, `<div id="footer">`) {
+		t.Errorf("Footer should be omitted when empty")
+	}
+}
+
+func TestRenderPlaybackHTML_HideAttributionWithFooterLink(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+		FooterLink:      FooterLink{Text: "swe-swe", URL: "https://github.com/choonkeat/swe-swe"},
+		HideAttribution: true,
+	})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	if strings.Contains(html, `>record-tui</a>`) {
+		t.Errorf("Footer should not have record-tui link")
+	}
+	if strings.Contains(html, `</a> x <a`) {
+		t.Errorf("Footer should not have ' x ' separator")
+	}
+	if !strings.Contains(html, `>swe-swe</a>`) {
+		t.Errorf("Footer should still have custom link")
+	}
+}
+
 func TestRenderPlaybackHTML_FooterLinkEscaping(t *testing.T) {
 	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
 
@@ -255,6 +421,264 @@ func TestRenderPlaybackHTML_FooterLinkEscaping(t *testing.T) {
 	}
 }
 
+func TestRenderPlaybackHTML_CommandHeading(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{Command: `sh -c "echo <hi>"`})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `Recording of: <code>sh -c &#34;echo &lt;hi&gt;&#34;</code>`) {
+		t.Error("HTML should contain the escaped command heading")
+	}
+
+	plain, _ := RenderPlaybackHTML(frames, "", FooterLink{}, nil)
+	if strings.Contains(plain, `id="command-heading"`) {
+		t.Error("HTML should not contain a command heading without a command")
+	}
+
+	withEnv, _ := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{Cwd: "/tmp/<x>", Shell: "/bin/zsh"})
+	if !strings.Contains(withEnv, `Recording in <code>/tmp/&lt;x&gt;</code> (shell <code>/bin/zsh</code>)`) {
+		t.Error("HTML should show the working directory and shell in the heading")
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_Embedded(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{TOC: toc, Embedded: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	for _, id := range []string{`id="footer"`, `id="nav-indicator"`, `id="viewer-controls"`} {
+		if strings.Contains(html, id) {
+			t.Errorf("embedded HTML should not contain %s", id)
+		}
+	}
+	if !strings.Contains(html, "const EMBEDDED = true;") {
+		t.Error("embedded HTML should enable the iframe JS")
+	}
+	if !strings.Contains(html, "background-color: transparent;") {
+		t.Error("embedded HTML should have a transparent background")
+	}
+	if !strings.Contains(html, "'record-tui:height'") {
+		t.Error("embedded HTML should post its height to the host page")
+	}
+
+	full, _ := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{TOC: toc})
+	if !strings.Contains(full, "const EMBEDDED = false;") || !strings.Contains(full, `id="nav-indicator"`) {
+		t.Error("full-page HTML should keep its navigation")
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_Responsive(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{TOC: toc, Responsive: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	if !strings.Contains(html, "const RESPONSIVE = true;") {
+		t.Error("responsive HTML should enable reflow on resize")
+	}
+	if !strings.Contains(html, "xterm.write(content, function()") {
+		t.Error("reflow should re-write the source content")
+	}
+	if !strings.Contains(html, "addEventListener('xterm-reflow', resolveRows)") {
+		t.Error("TOC rows should be resolved again after a reflow")
+	}
+
+	fixed, _ := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{})
+	if !strings.Contains(fixed, "const RESPONSIVE = false;") {
+		t.Error("reflow should be off by default")
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_Minimap(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "$ ls\n$ pwd\n"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}, {Label: "pwd", Line: 1}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{TOC: toc, Minimap: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `<div id="nav-minimap" aria-hidden="true"></div>`) {
+		t.Error("HTML should contain the minimap strip")
+	}
+	if !strings.Contains(html, "resolvedRows[i] / totalRows * 100") {
+		t.Error("minimap ticks should be placed from the resolved rows")
+	}
+
+	for _, opts := range []PlaybackOptions{{TOC: toc}, {Minimap: true}} {
+		html, _ := RenderPlaybackHTMLWithOptions(frames, opts)
+		if strings.Contains(html, `id="nav-minimap"`) {
+			t.Errorf("minimap needs both Minimap and a TOC, got it with %+v", opts)
+		}
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_Collapsed(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "$ ls\n$ echo <hi>\n"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}, {Label: "echo <hi>", Line: 1}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{TOC: toc, Collapsed: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `<body class="collapsed">`) {
+		t.Error("the page should start collapsed")
+	}
+	if !strings.Contains(html, `<li><a href="#input-1">echo &lt;hi&gt;</a></li>`) {
+		t.Error("each command should link to its #input-N anchor, escaped")
+	}
+	if !strings.Contains(html, "const COLLAPSED = true;") || !strings.Contains(html, "startCollapsed(showRecording)") {
+		t.Error("writing the recording should wait for a command to be picked")
+	}
+
+	for _, opts := range []PlaybackOptions{{TOC: toc}, {Collapsed: true}, {TOC: toc, Collapsed: true, Embedded: true}} {
+		html, _ := RenderPlaybackHTMLWithOptions(frames, opts)
+		if strings.Contains(html, `id="collapsed-toc"`) || !strings.Contains(html, "const COLLAPSED = false;") {
+			t.Errorf("no collapsed start expected with %+v", opts)
+		}
+	}
+}
+
+func TestRenderStreamingPlaybackHTML_Collapsed(t *testing.T) {
+	toc := []TOCEntry{{Label: "ls", Line: 0}}
+
+	html, err := RenderStreamingPlaybackHTML(StreamingOptions{DataURL: "./session.log", TOC: toc, Collapsed: true})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+	if !strings.Contains(html, `<body class="collapsed">`) || !strings.Contains(html, `<a href="#input-0">ls</a>`) {
+		t.Error("the page should start with the command list")
+	}
+	if !strings.Contains(html, "startCollapsed(main)") {
+		t.Error("fetching the recording should wait for a command to be picked")
+	}
+
+	html, _ = RenderStreamingPlaybackHTML(StreamingOptions{DataURL: "./session.log", Collapsed: true})
+	if strings.Contains(html, `id="collapsed-toc"`) {
+		t.Error("no collapsed start expected without a TOC")
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_ErrorNav(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "$ make\n\x1b[31mfailed\x1b[0m\n"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{ErrorLines: []int{1}})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `<div id="error-nav" role="navigation" aria-label="Errors">`) {
+		t.Error("HTML should contain the error navigation")
+	}
+	if !strings.Contains(html, "var errorLines = [1];") {
+		t.Error("error lines should be passed to the page")
+	}
+	if !strings.Contains(html, "e.key === 'n'") || !strings.Contains(html, "e.key === 'p'") {
+		t.Error("n and p should step through errors")
+	}
+
+	for _, opts := range []PlaybackOptions{{}, {ErrorLines: []int{1}, Embedded: true}} {
+		html, _ := RenderPlaybackHTMLWithOptions(frames, opts)
+		if strings.Contains(html, `id="error-nav"`) {
+			t.Errorf("no error navigation expected with %+v", opts)
+		}
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_TypedInput(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "$ echo <hi>\n<hi>\n"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{TypedInput: "echo <hi><Enter>\n^C"})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `<details id="typed-input">`) {
+		t.Error("HTML should contain the typed input panel")
+	}
+	if !strings.Contains(html, "<pre>echo &lt;hi&gt;&lt;Enter&gt;\n^C</pre>") {
+		t.Error("typed input should be escaped into the panel")
+	}
+
+	for _, opts := range []PlaybackOptions{{}, {TypedInput: "ls<Enter>\n", Embedded: true}} {
+		html, _ := RenderPlaybackHTMLWithOptions(frames, opts)
+		if strings.Contains(html, `<details id="typed-input">`) {
+			t.Errorf("no typed input panel expected with %+v", opts)
+		}
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_LineTimes(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "$ ls\nfile1\n"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{LineTimes: []float64{0, 1.5, 2.0004}, Embedded: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `<script type="application/json" id="line-times">[0,1.5,2]</script>`) {
+		t.Error("HTML should embed the line times as JSON, rounded to milliseconds")
+	}
+
+	html, _ = RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{})
+	if strings.Contains(html, `id="line-times"`) {
+		t.Error("no line times expected without LineTimes")
+	}
+}
+
+// embeddedTiming decodes the playbackTiming a page embeds, or returns nil
+func embeddedTiming(t *testing.T, html string) [][]any {
+	t.Helper()
+	m := regexp.MustCompile(`const playbackTiming = JSON\.parse\(atob\('([^']*)'\)\);`).FindStringSubmatch(html)
+	if m == nil {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		t.Fatalf("timing is not base64: %v", err)
+	}
+	var entries [][]any
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("timing is not JSON: %v", err)
+	}
+	return entries
+}
+
+func TestRenderPlaybackHTMLWithOptions_Timing(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "$ ls\r\nfile1\r\n"}}
+	timing := []TimingEntry{
+		{Type: 'O', Delay: 0, ByteCount: 6},
+		{Type: 'I', Delay: 0.5000004, ByteCount: 3},
+		{Type: 'O', Delay: 0.25, ByteCount: 7},
+	}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{Timing: timing})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	got, _ := json.Marshal(embeddedTiming(t, html))
+	if want := `[["O",0,6],["I",0.5,3],["O",0.25,7]]`; string(got) != want {
+		t.Errorf("embedded timing = %s, want %s", got, want)
+	}
+	// One frame, but the timing can be replayed
+	if !strings.Contains(html, `id="play-toggle"`) {
+		t.Error("HTML should have a play button with Timing")
+	}
+	if !strings.Contains(html, "// Playback at the recorded pace") || strings.Contains(html, "// Frame playback and captions") {
+		t.Error("timing playback should replace frame playback")
+	}
+
+	html, _ = RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{})
+	if embeddedTiming(t, html) != nil || strings.Contains(html, `id="play-toggle"`) {
+		t.Error("no timing or play button expected without Timing")
+	}
+}
+
 func TestRenderPlaybackHTML_ContainsLoadingIndicator(t *testing.T) {
 	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
 
@@ -312,6 +736,59 @@ func TestRenderPlaybackHTML_WithTOC(t *testing.T) {
 	}
 }
 
+func TestRenderPlaybackHTML_TOCAccessibility(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, toc)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`id="nav-prev" role="button" tabindex="0" aria-label="Previous command"`,
+		`id="nav-next" role="button" tabindex="0" aria-label="Next command"`,
+		`id="nav-toggle" role="button" tabindex="0" aria-expanded="false" aria-controls="nav-list"`,
+		`id="nav-list" role="menu"`,
+		`item.setAttribute('role', 'menuitem')`,
+		`e.key === 'ArrowDown'`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML should contain %s", want)
+		}
+	}
+	// Tab must move focus, not jump between commands
+	if strings.Contains(html, `e.key === 'Tab'`) {
+		t.Error("nav JS should not capture the Tab key")
+	}
+}
+
+func TestRenderPlaybackHTML_TOCFilter(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}, {Label: "npm test", Line: 1}}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, toc)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+	for _, want := range []string{
+		`<input type="search" class="nav-filter" id="nav-filter"`,
+		`aria-label="Filter commands"`,
+		`function fuzzyMatch(query, label)`,
+		`filterEl.addEventListener('input', applyFilter)`,
+		// Keyboard navigation skips the filtered-out items
+		`var items = visibleItems();`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML should contain %s", want)
+		}
+	}
+	// Typing < or > in the filter box must not jump between commands
+	if !strings.Contains(html, `if (e.target === filterEl) return;`) {
+		t.Error("nav JS should leave keys typed in the filter box alone")
+	}
+}
+
 func TestRenderPlaybackHTML_WithoutTOC(t *testing.T) {
 	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
 
@@ -326,6 +803,77 @@ func TestRenderPlaybackHTML_WithoutTOC(t *testing.T) {
 	}
 }
 
+func TestRenderPlaybackHTML_LineGutterToggle(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, nil)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+
+	// Gutter toggle is available even without TOC entries
+	if !strings.Contains(html, `id="gutter-toggle"`) {
+		t.Error("HTML should contain the line-number gutter toggle")
+	}
+	// Nav and gutter share the same row-height measurement
+	if strings.Count(html, "function getCellHeight()") != 1 {
+		t.Error("HTML should define getCellHeight exactly once")
+	}
+	// Off by default: the gutter only shows once the body class is set
+	if strings.Contains(html, `<body class="gutter-on"`) {
+		t.Error("line-number gutter should be off by default")
+	}
+}
+
+func TestRenderPlaybackHTML_LinePermalinks(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, nil)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+
+	// #line-N works without TOC entries, alongside the #input-N nav anchors
+	if !strings.Contains(html, `id="line-link"`) {
+		t.Error("HTML should contain the copy-link button")
+	}
+	if !strings.Contains(html, `#line-(\d+)`) {
+		t.Error("HTML should handle #line-N hashes")
+	}
+	if strings.Count(html, "function scrollToRow(") != 1 {
+		t.Error("HTML should define scrollToRow exactly once")
+	}
+}
+
+func TestRenderPlaybackHTML_RowMathFromXtermScreen(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "$ ls\r\n"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, toc)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+
+	// Rows are measured from the xterm screen, not the terminal element's
+	// top, so content or padding above the rows doesn't shift them
+	if !strings.Contains(html, "highlight.style.top = rowTopInTerminal(row) + 'px'") {
+		t.Error("nav highlight should be positioned with rowTopInTerminal")
+	}
+	if !strings.Contains(html, "gutter.style.top = rowTopInTerminal(0) + 'px'") {
+		t.Error("line-number gutter should start at row 0 of the xterm screen")
+	}
+	if !strings.Contains(html, "var targetY = rowsTop() + (row * getCellHeight())") {
+		t.Error("scrollToRow should measure from rowsTop")
+	}
+	// Fixed headers declared with scroll-padding-top are left clear
+	if !strings.Contains(html, "scrollPaddingTop") {
+		t.Error("scrolling should account for the page's scroll-padding-top")
+	}
+	if strings.Contains(html, "terminalDiv.getBoundingClientRect().top + window.pageYOffset") {
+		t.Error("no row math should use the terminal element's own top")
+	}
+}
+
 func TestRenderPlaybackHTML_TOCLabelEscaping(t *testing.T) {
 	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
 	toc := []TOCEntry{
@@ -343,3 +891,250 @@ func TestRenderPlaybackHTML_TOCLabelEscaping(t *testing.T) {
 		t.Error("TOC label should be escaped")
 	}
 }
+
+func TestRenderPlaybackHTMLWithOptions_StrictCSP(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+	toc := []TOCEntry{{Label: "ls", Line: 0}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{TOC: toc, StrictCSP: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	if !strings.Contains(html, `http-equiv="Content-Security-Policy"`) {
+		t.Fatal("HTML should contain CSP meta tag")
+	}
+	nonce := extractNonce(t, html)
+	if !strings.Contains(html, "script-src 'nonce-"+nonce+"'") {
+		t.Error("CSP script-src should allow the nonce")
+	}
+	if !strings.Contains(html, "style-src 'nonce-"+nonce+"'") {
+		t.Error("CSP style-src should allow the nonce")
+	}
+	assertAllTagsNonced(t, html, nonce)
+}
+
+func TestRenderPlaybackHTMLWithOptions_StrictCSPNonceIsPerRender(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html1, _ := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{StrictCSP: true})
+	html2, _ := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{StrictCSP: true})
+
+	if extractNonce(t, html1) == extractNonce(t, html2) {
+		t.Error("Each render should generate a fresh nonce")
+	}
+}
+
+func TestRenderPlaybackHTML_NoCSPByDefault(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTML(frames, "", FooterLink{}, nil)
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTML failed: %v", err)
+	}
+
+	if strings.Contains(html, "Content-Security-Policy") {
+		t.Error("HTML should not contain CSP meta tag unless StrictCSP is set")
+	}
+	if strings.Contains(html, "nonce=") {
+		t.Error("HTML should not contain nonce attributes unless StrictCSP is set")
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_SourceHash(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+	hash := strings.Repeat("ab", 32)
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{SourceHash: hash})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	if !strings.Contains(html, `<meta name="record-tui:source-sha256" content="`+hash+`">`) {
+		t.Error("HTML should contain the source hash meta tag")
+	}
+	if !strings.Contains(html, `<meta name="record-tui:version" content="`) {
+		t.Error("HTML should contain the version meta tag")
+	}
+	if !strings.Contains(html, `<meta name="record-tui:generated-at" content="`) {
+		t.Error("HTML should contain the generation time meta tag")
+	}
+
+	// Without a hash there is no provenance block
+	plain, _ := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{})
+	if strings.Contains(plain, "record-tui:source-sha256") {
+		t.Error("HTML should not contain provenance meta tags unless SourceHash is set")
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_Version(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{SourceHash: strings.Repeat("ab", 32), Version: "v1.2.3"})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	if !strings.Contains(html, `<meta name="record-tui:version" content="v1.2.3">`) {
+		t.Error("HTML should record the given version")
+	}
+
+	// A version is recorded even without a source hash
+	html, _ = RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{Version: "v1.2.3"})
+	if !strings.Contains(html, `<meta name="record-tui:version" content="v1.2.3">`) {
+		t.Error("HTML should record the given version without SourceHash")
+	}
+	if strings.Contains(html, "record-tui:generated-at") {
+		t.Error("HTML should not contain the other provenance tags without SourceHash")
+	}
+}
+
+func TestRenderStreamingPlaybackHTML_StrictCSP(t *testing.T) {
+	html, err := RenderStreamingPlaybackHTML(StreamingOptions{
+		DataURL:   "./session.log",
+		TOC:       []TOCEntry{{Label: "ls", Line: 0}},
+		StrictCSP: true,
+	})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+
+	nonce := extractNonce(t, html)
+	assertAllTagsNonced(t, html, nonce)
+}
+
+func TestRenderStreamingPlaybackHTML_ResumesDroppedStream(t *testing.T) {
+	html, err := RenderStreamingPlaybackHTML(StreamingOptions{DataURL: "./session.log"})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"'Range': 'bytes=' + received + '-'",
+		"response.status !== 206",
+		"FETCH_MAX_RETRIES",
+		"Reconnecting...",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("streaming HTML should contain %q", want)
+		}
+	}
+}
+
+// extractNonce returns the nonce from the first nonce="..." attribute in html.
+func extractNonce(t *testing.T, html string) string {
+	t.Helper()
+	marker := `nonce="`
+	idx := strings.Index(html, marker)
+	if idx == -1 {
+		t.Fatal("HTML should contain a nonce attribute")
+	}
+	rest := html[idx+len(marker):]
+	return rest[:strings.Index(rest, `"`)]
+}
+
+// assertAllTagsNonced checks every <script> and <style> tag carries the nonce.
+func assertAllTagsNonced(t *testing.T, html, nonce string) {
+	t.Helper()
+	for _, tag := range []string{"<script", "<style"} {
+		count := strings.Count(html, tag)
+		nonced := strings.Count(html, tag+` nonce="`+nonce+`"`) +
+			strings.Count(html, tag+` src="https://cdn.jsdelivr.net/npm/@xterm/xterm@5.5.0/lib/xterm.js" nonce="`+nonce+`"`)
+		if count == 0 || count != nonced {
+			t.Errorf("expected all %d %s tags to carry nonce, got %d", count, tag, nonced)
+		}
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_ExtraCSS(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+	css := "#command-heading { color: #ff6600; }"
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{ExtraCSS: css, StrictCSP: true})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	cssIndex := strings.Index(html, css)
+	if cssIndex < 0 {
+		t.Fatal("HTML should contain the extra CSS")
+	}
+	// After the built-in styles so it can override them
+	if builtin := strings.Index(html, "#command-heading {"); builtin > cssIndex {
+		t.Error("extra CSS should come after the built-in styles")
+	}
+	if cssIndex > strings.Index(html, "</head>") {
+		t.Error("extra CSS should be in the head")
+	}
+	assertAllTagsNonced(t, html, extractNonce(t, html))
+}
+
+func TestRenderPlaybackHTMLWithOptions_ExtraCSSCannotCloseStyle(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+		ExtraCSS: "body{}</style><script>alert(1)</script></STYLE ><style>",
+	})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	// Only the built-in and extra <style> elements are closed
+	if n := strings.Count(strings.ToLower(html), "</style"); n != 2 {
+		t.Errorf("extra CSS should not be able to close the <style> element, got %d closing tags", n)
+	}
+	if !strings.Contains(html, `body{}<\/style><script>alert(1)</script><\/STYLE ><style>`) {
+		t.Error("</style in extra CSS should be escaped")
+	}
+}
+
+func TestRenderStreamingPlaybackHTML_ExtraCSS(t *testing.T) {
+	html, err := RenderStreamingPlaybackHTML(StreamingOptions{
+		DataURL:  "./session.log",
+		ExtraCSS: "html, body { background-color: #002b36; }",
+	})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+
+	if !strings.Contains(html, "html, body { background-color: #002b36; }") {
+		t.Error("HTML should contain the extra CSS")
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_OpenGraph(t *testing.T) {
+	frames := []PlaybackFrame{{Timestamp: 0, Content: "test"}}
+
+	html, err := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{
+		Title:          "deploy <prod>",
+		Command:        "make deploy",
+		OpenGraph:      true,
+		OpenGraphImage: "https://example.com/rec.svg?a=1&b=2",
+	})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+	for _, want := range []string{
+		`<meta property="og:title" content="deploy &lt;prod&gt;">`,
+		`<meta property="og:description" content="Terminal recording of make deploy">`,
+		`<meta property="og:image" content="https://example.com/rec.svg?a=1&amp;b=2">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML should contain %s", want)
+		}
+	}
+
+	html, _ = RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{OpenGraph: true, OpenGraphDescription: "make test (4m12s)"})
+	if !strings.Contains(html, `<meta property="og:description" content="make test (4m12s)">`) {
+		t.Error("HTML should use the given description")
+	}
+	if strings.Contains(html, "og:image") || !strings.Contains(html, `content="summary"`) {
+		t.Error("HTML should have a summary card without an image")
+	}
+
+	// Off by default
+	plain, _ := RenderPlaybackHTMLWithOptions(frames, PlaybackOptions{})
+	if strings.Contains(plain, "og:") {
+		t.Error("HTML should not contain Open Graph tags unless OpenGraph is set")
+	}
+}
@@ -0,0 +1,117 @@
+package html
+
+import (
+	"encoding/base64"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TimingEntry is one entry of a recording's timing file (see
+// timing.Entry): output, input, header or signal.
+type TimingEntry struct {
+	Type      byte    // 'O', 'I', 'H' or 'S'
+	Delay     float64 // Seconds since the previous entry
+	ByteCount int     // Bytes of output or input
+}
+
+// timingData returns the script line declaring playbackTiming, the timing
+// entries as [type, delay, byteCount] arrays, JSON base64-encoded like the
+// frames, or empty string if there are none.
+func timingData(entries []TimingEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`["` + timingType(e.Type) + `",`)
+		b.WriteString(strconv.FormatFloat(math.Round(e.Delay*1e6)/1e6, 'f', -1, 64))
+		b.WriteString("," + strconv.Itoa(e.ByteCount) + "]")
+	}
+	b.WriteByte(']')
+	return `
+    // Recorded timing (see timingJS): [type, delay, byteCount] per entry
+    const playbackTiming = JSON.parse(atob('` + base64.StdEncoding.EncodeToString([]byte(b.String())) + `'));`
+}
+
+// timingType returns an entry type as a one-letter JSON-safe string.
+func timingType(t byte) string {
+	if t < 'A' || t > 'Z' {
+		return "?"
+	}
+	return string(rune(t))
+}
+
+// timingJS returns the JavaScript replaying the recording at its recorded
+// pace from playbackTiming (see timingData), in place of framesJS: the play
+// button (see controlsHTML) resets the terminal and writes the last frame's
+// content chunk by chunk, each output entry's byte count after its delay.
+// Delays under 10ms are not waited for. Content the timing doesn't account
+// for (e.g. after cleaning) is written at the end, and stopping shows the
+// whole content again.
+// Requires `xterm`, `frames` and `playbackTiming` to be in scope.
+func timingJS() string {
+	return `
+    // Playback at the recorded pace
+    (function() {
+      if (frames.length === 0) return;
+      var playBtn = document.getElementById('play-toggle');
+      if (!playBtn) return;
+      var content = frames[frames.length - 1].content;
+      var bytes = new TextEncoder().encode(content);
+      var timer = null;
+
+      function setPlaying(on) {
+        playBtn.textContent = on ? '■ Stop' : '▶ Play';
+        playBtn.classList.toggle('active', on);
+      }
+
+      function finish() {
+        timer = null;
+        setPlaying(false);
+      }
+
+      function stop() {
+        clearTimeout(timer);
+        finish();
+        xterm.reset();
+        xterm.write(content);
+      }
+
+      function play() {
+        var i = 0;
+        var offset = 0;
+        xterm.reset();
+        setPlaying(true);
+        (function step() {
+          while (i < playbackTiming.length && offset < bytes.length) {
+            var entry = playbackTiming[i++];
+            if (entry[0] === 'O') {
+              // xterm.js joins multi-byte characters split across writes
+              var end = Math.min(offset + entry[2], bytes.length);
+              xterm.write(bytes.subarray(offset, end));
+              offset = end;
+            }
+            var next = playbackTiming[i];
+            if (next && next[1] >= 0.01) {
+              timer = setTimeout(step, next[1] * 1000);
+              return;
+            }
+          }
+          if (offset < bytes.length) {
+            xterm.write(bytes.subarray(offset));
+          }
+          finish();
+        })();
+      }
+
+      playBtn.addEventListener('click', function() {
+        if (timer) stop(); else play();
+      });
+    })();
+`
+}
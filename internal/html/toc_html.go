@@ -64,12 +64,34 @@ func tocCSS() string {
     .nav-compact {
       cursor: pointer;
     }
-    .nav-list {
+    .nav-btn:focus-visible,
+    .nav-toggle:focus-visible,
+    .nav-list-item:focus-visible {
+      outline: 1px solid rgba(255, 200, 50, 0.8);
+      outline-offset: 1px;
+    }
+    .nav-list,
+    .nav-filter {
       display: none;
     }
-    #nav-indicator.expanded .nav-list {
+    #nav-indicator.expanded .nav-list,
+    #nav-indicator.expanded .nav-filter {
       display: block;
     }
+    .nav-filter {
+      width: calc(100% - 20px);
+      margin: 0 10px 6px;
+      padding: 4px 6px;
+      background: rgba(0, 0, 0, 0.3);
+      border: 1px solid rgba(212, 212, 212, 0.2);
+      border-radius: 3px;
+      color: #d4d4d4;
+      font-family: inherit;
+      font-size: 12px;
+    }
+    .nav-filter:focus {
+      outline: 1px solid rgba(255, 200, 50, 0.8);
+    }
     .nav-list-item {
       padding: 4px 14px;
       cursor: pointer;
@@ -100,30 +122,128 @@ func tocCSS() string {
       transition: top 0.15s ease;
       display: none;
     }
+    #nav-minimap {
+      position: fixed;
+      top: 56px;
+      bottom: 48px;
+      right: 4px;
+      width: 10px;
+      z-index: 999;
+      background: rgba(212, 212, 212, 0.05);
+      border-radius: 2px;
+      display: none;
+    }
+    .minimap-tick {
+      position: absolute;
+      left: 0;
+      right: 0;
+      height: 3px;
+      margin-top: -1px;
+      background: rgba(212, 212, 212, 0.35);
+      cursor: pointer;
+    }
+    .minimap-tick:hover {
+      background: #fff;
+    }
+    .minimap-tick.active {
+      background: rgba(255, 200, 50, 0.9);
+    }
 `
 }
 
 // tocHTML returns the HTML markup for the navigation indicator.
+// minimap adds a strip along the right edge with a tick per command.
 // Returns empty string if there are no TOC entries.
-func tocHTML(entries []TOCEntry) string {
+func tocHTML(entries []TOCEntry, minimap bool) string {
 	if len(entries) == 0 {
 		return ""
 	}
-	return `
-  <div id="nav-indicator">
+	minimapDiv := ""
+	if minimap {
+		// Mouse shortcut for the nav, which is what keyboard users get
+		minimapDiv = `
+  <div id="nav-minimap" aria-hidden="true"></div>`
+	}
+	return minimapDiv + `
+  <div id="nav-indicator" role="navigation" aria-label="Commands">
     <span class="nav-compact" id="nav-compact">
-      <span class="nav-btn" id="nav-prev" title="Previous command (&lt;)">&lt;</span>
-      <span class="nav-pos" id="nav-pos"></span>
-      <span class="nav-label" id="nav-label"></span>
-      <span class="nav-btn" id="nav-next" title="Next command (&gt;)">&gt;</span>
+      <span class="nav-btn" id="nav-prev" role="button" tabindex="0" aria-label="Previous command" title="Previous command (&lt;)">&lt;</span>
+      <span class="nav-toggle" id="nav-toggle" role="button" tabindex="0" aria-expanded="false" aria-controls="nav-list" aria-label="Show all commands">
+        <span class="nav-pos" id="nav-pos"></span>
+        <span class="nav-label" id="nav-label"></span>
+      </span>
+      <span class="nav-btn" id="nav-next" role="button" tabindex="0" aria-label="Next command" title="Next command (&gt;)">&gt;</span>
     </span>
-    <div class="nav-list" id="nav-list"></div>
+    <input type="search" class="nav-filter" id="nav-filter" placeholder="Filter commands" aria-label="Filter commands" aria-controls="nav-list" autocomplete="off" spellcheck="false">
+    <div class="nav-list" id="nav-list" role="menu" aria-label="Commands"></div>
   </div>
 `
 }
 
+// rowJS returns the getCellHeight(), rowsTop(), rowTopInTerminal() and
+// scrollToRow() helpers shared by the nav, the line-number gutter and #line-N
+// links. Row positions are measured from the xterm screen, which can sit
+// below the top of the terminal element, and scrolling leaves room for the
+// page's scroll-padding-top (e.g. under a fixed header).
+// Requires `xterm` variable to be in scope.
+func rowJS() string {
+	return `
+    // Rendered height of one terminal row in CSS pixels
+    function getCellHeight() {
+      var terminalDiv = document.getElementById('terminal');
+      var xtermScreen = terminalDiv.querySelector('.xterm-screen');
+      if (xtermScreen && xterm.buffer.active) {
+        var totalRows = xterm.rows;
+        if (totalRows > 0) {
+          return xtermScreen.getBoundingClientRect().height / totalRows;
+        }
+      }
+      return 17;
+    }
+
+    // Element whose top is row 0: the xterm screen once rendered
+    function rowsElement() {
+      var terminalDiv = document.getElementById('terminal');
+      return terminalDiv.querySelector('.xterm-screen') || terminalDiv;
+    }
+
+    // Page Y of the top of row 0
+    function rowsTop() {
+      return rowsElement().getBoundingClientRect().top + window.pageYOffset;
+    }
+
+    // Top of the given row relative to the terminal element's padding box,
+    // for elements positioned absolutely inside it
+    function rowTopInTerminal(row) {
+      var terminalDiv = document.getElementById('terminal');
+      var offset = rowsElement().getBoundingClientRect().top -
+        terminalDiv.getBoundingClientRect().top - terminalDiv.clientTop;
+      return offset + row * getCellHeight();
+    }
+
+    // Space to leave above a row scrolled to the top of the viewport
+    function scrollMargin() {
+      var padding = parseFloat(getComputedStyle(document.documentElement).scrollPaddingTop);
+      return 20 + (isNaN(padding) ? 0 : padding);
+    }
+
+    // Scroll the page so the given 0-indexed terminal row is near the top
+    function scrollToRow(row) {
+      var targetY = rowsTop() + (row * getCellHeight());
+      window.scrollTo(0, Math.max(0, targetY - scrollMargin()));
+    }
+`
+}
+
 // tocJS returns the JavaScript for < > keyboard navigation between user inputs.
-// Requires `xterm` variable to be in scope (the Terminal instance).
+// The nav controls also work from the keyboard: Enter/Space activate them,
+// and the expanded list is navigated with the arrow keys, Home and End.
+// The filter box above the expanded list hides the commands whose label
+// doesn't contain what is typed as a subsequence (so "nt" finds "npm test");
+// it is cleared when the list collapses.
+// The minimap (see tocHTML), if present, places a tick at each command's
+// share of the buffer, highlighting the current one.
+// Requires `xterm` variable and the rowJS helpers to be in scope.
 // Returns empty string if there are no TOC entries.
 func tocJS(entries []TOCEntry) string {
 	if len(entries) == 0 {
@@ -143,6 +263,9 @@ func tocJS(entries []TOCEntry) string {
       var highlight = document.createElement('div');
       highlight.id = 'nav-highlight';
       var navList = document.getElementById('nav-list');
+      var toggleEl = document.getElementById('nav-toggle');
+      var filterEl = document.getElementById('nav-filter');
+      var minimap = document.getElementById('nav-minimap');
       var expanded = false;
 
       // Resolve actual rendered row for each entry by searching the xterm buffer.
@@ -207,36 +330,20 @@ func tocJS(entries []TOCEntry) string {
         if (rows.length > 0) {
           indicator.style.display = 'block';
           buildList();
+          buildMinimap();
           updateIndicator();
-          // Check URL hash on load
-          var match = location.hash.match(/^#input-(\d+)$/);
-          if (match) {
-            navigateTo(parseInt(match[1], 10), false);
-          }
         }
       }
-      document.addEventListener('xterm-ready', resolveRows);
-
-      function getCellHeight() {
-        var terminalDiv = document.getElementById('terminal');
-        var xtermScreen = terminalDiv.querySelector('.xterm-screen');
-        if (xtermScreen && xterm.buffer.active) {
-          var totalRows = xterm.rows;
-          if (totalRows > 0) {
-            return xtermScreen.getBoundingClientRect().height / totalRows;
-          }
+      document.addEventListener('xterm-ready', function() {
+        resolveRows();
+        // Check URL hash on load
+        var match = location.hash.match(/^#input-(\d+)$/);
+        if (match && resolvedRows && resolvedRows.length > 0) {
+          navigateTo(parseInt(match[1], 10), false);
         }
-        return 17;
-      }
-
-      function scrollToRow(row) {
-        var terminalDiv = document.getElementById('terminal');
-        var termRect = terminalDiv.getBoundingClientRect();
-        var termTop = termRect.top + window.pageYOffset;
-        var cellHeight = getCellHeight();
-        var targetY = termTop + (row * cellHeight);
-        window.scrollTo(0, Math.max(0, targetY - 20));
-      }
+      });
+      // Rows move when the recording is reflowed (responsive mode)
+      document.addEventListener('xterm-reflow', resolveRows);
 
       function highlightRow(row) {
         var terminalDiv = document.getElementById('terminal');
@@ -244,12 +351,22 @@ func tocJS(entries []TOCEntry) string {
           terminalDiv.style.position = 'relative';
           terminalDiv.appendChild(highlight);
         }
-        var cellHeight = getCellHeight();
-        highlight.style.top = (row * cellHeight) + 'px';
-        highlight.style.height = cellHeight + 'px';
+        highlight.style.top = rowTopInTerminal(row) + 'px';
+        highlight.style.height = getCellHeight() + 'px';
         highlight.style.display = 'block';
       }
 
+      // Run fn on click, and on Enter/Space like a native button
+      function onActivate(el, fn) {
+        el.addEventListener('click', fn);
+        el.addEventListener('keydown', function(e) {
+          if (e.key === 'Enter' || e.key === ' ') {
+            e.preventDefault();
+            fn.call(el, e);
+          }
+        });
+      }
+
       function buildList() {
         navList.innerHTML = '';
         for (var i = 0; i < tocEntries.length; i++) {
@@ -257,7 +374,9 @@ func tocJS(entries []TOCEntry) string {
           item.className = 'nav-list-item';
           item.textContent = (i + 1) + '. ' + (tocEntries[i].label || '(empty)');
           item.setAttribute('data-index', i);
-          item.addEventListener('click', function(e) {
+          item.setAttribute('role', 'menuitem');
+          item.setAttribute('tabindex', '-1');
+          onActivate(item, function(e) {
             e.stopPropagation();
             var idx = parseInt(this.getAttribute('data-index'), 10);
             collapseList();
@@ -267,30 +386,111 @@ func tocJS(entries []TOCEntry) string {
         }
       }
 
+      // One tick per command, at its row's share of the buffer
+      function buildMinimap() {
+        if (!minimap) return;
+        minimap.innerHTML = '';
+        var totalRows = Math.max(xterm.buffer.active.length, 1);
+        for (var i = 0; i < resolvedRows.length; i++) {
+          var tick = document.createElement('div');
+          tick.className = 'minimap-tick';
+          tick.style.top = (resolvedRows[i] / totalRows * 100) + '%';
+          tick.title = (i + 1) + '. ' + (tocEntries[i].label || '(empty)');
+          tick.setAttribute('data-index', i);
+          tick.addEventListener('click', function() {
+            collapseList();
+            navigateTo(parseInt(this.getAttribute('data-index'), 10));
+          });
+          minimap.appendChild(tick);
+        }
+        minimap.style.display = 'block';
+      }
+
+      function updateMinimapActive() {
+        if (!minimap) return;
+        var ticks = minimap.querySelectorAll('.minimap-tick');
+        for (var i = 0; i < ticks.length; i++) {
+          if (i === currentIndex) {
+            ticks[i].classList.add('active');
+          } else {
+            ticks[i].classList.remove('active');
+          }
+        }
+      }
+
       function updateListActive() {
         var items = navList.querySelectorAll('.nav-list-item');
         for (var i = 0; i < items.length; i++) {
           if (i === currentIndex) {
             items[i].classList.add('active');
+            items[i].setAttribute('aria-current', 'true');
           } else {
             items[i].classList.remove('active');
+            items[i].removeAttribute('aria-current');
           }
         }
       }
 
+      // The list items the filter leaves shown
+      function visibleItems() {
+        return Array.prototype.filter.call(navList.querySelectorAll('.nav-list-item'), function(item) {
+          return !item.hidden;
+        });
+      }
+
+      // Move keyboard focus to shown list item i (clamped)
+      function focusItem(i) {
+        var items = visibleItems();
+        if (items.length === 0) return;
+        items[Math.max(0, Math.min(i, items.length - 1))].focus();
+      }
+
+      // Whether the characters of query appear in label in order, ignoring case
+      function fuzzyMatch(query, label) {
+        query = query.toLowerCase();
+        label = label.toLowerCase();
+        var j = 0;
+        for (var i = 0; i < label.length && j < query.length; i++) {
+          if (label[i] === query[j]) j++;
+        }
+        return j === query.length;
+      }
+
+      // Show only the commands matching the filter box
+      function applyFilter() {
+        var query = filterEl.value.trim();
+        var items = navList.querySelectorAll('.nav-list-item');
+        for (var i = 0; i < items.length; i++) {
+          items[i].hidden = !fuzzyMatch(query, tocEntries[i].label || '');
+        }
+      }
+
+      function clearFilter() {
+        if (filterEl.value === '') return;
+        filterEl.value = '';
+        applyFilter();
+      }
+
       function toggleExpand() {
         expanded = !expanded;
+        toggleEl.setAttribute('aria-expanded', String(expanded));
         if (expanded) {
           indicator.classList.add('expanded');
           updateListActive();
         } else {
           indicator.classList.remove('expanded');
+          clearFilter();
         }
       }
 
       function collapseList() {
+        var hadFocus = navList.contains(document.activeElement) || document.activeElement === filterEl;
         expanded = false;
+        clearFilter();
+        toggleEl.setAttribute('aria-expanded', 'false');
         indicator.classList.remove('expanded');
+        // The list is hidden now: keep keyboard focus on the nav
+        if (hadFocus) toggleEl.focus();
       }
 
       function updateIndicator() {
@@ -298,11 +498,15 @@ func tocJS(entries []TOCEntry) string {
         if (currentIndex < 0) {
           posEl.textContent = '-/' + resolvedRows.length;
           labelEl.textContent = '';
+          toggleEl.setAttribute('aria-label', 'Show all ' + resolvedRows.length + ' commands');
         } else {
           posEl.textContent = (currentIndex + 1) + '/' + resolvedRows.length;
           labelEl.textContent = tocEntries[currentIndex].label || '';
+          toggleEl.setAttribute('aria-label', 'Command ' + (currentIndex + 1) + ' of ' + resolvedRows.length +
+            ': ' + (tocEntries[currentIndex].label || '(empty)') + '. Show all commands');
         }
         if (expanded) updateListActive();
+        updateMinimapActive();
       }
 
       function navigateTo(index, pushState) {
@@ -327,15 +531,67 @@ func tocJS(entries []TOCEntry) string {
         navigateTo(currentIndex - 1);
       }
 
-      document.getElementById('nav-prev').addEventListener('click', function(e) { e.stopPropagation(); goPrev(); });
-      document.getElementById('nav-next').addEventListener('click', function(e) { e.stopPropagation(); goNext(); });
+      onActivate(document.getElementById('nav-prev'), function(e) { e.stopPropagation(); goPrev(); });
+      onActivate(document.getElementById('nav-next'), function(e) { e.stopPropagation(); goNext(); });
       document.getElementById('nav-compact').addEventListener('click', toggleExpand);
+      toggleEl.addEventListener('keydown', function(e) {
+        if (e.key === 'Enter' || e.key === ' ' || (e.key === 'ArrowDown' && !expanded)) {
+          e.preventDefault();
+          toggleExpand();
+          if (expanded) focusItem(Math.max(currentIndex, 0));
+        }
+      });
+
+      // Typing filters the list; Down moves into it and Enter picks the first match
+      filterEl.addEventListener('input', applyFilter);
+      filterEl.addEventListener('keydown', function(e) {
+        if (e.key === 'ArrowDown') {
+          e.preventDefault();
+          focusItem(0);
+        } else if (e.key === 'Enter') {
+          e.preventDefault();
+          var items = visibleItems();
+          if (items.length > 0) items[0].click();
+        }
+      });
+
+      // Arrow keys move between the shown items of the expanded list (Up
+      // from the first goes back to the filter box), and typing a character
+      // there goes to the filter box
+      navList.addEventListener('keydown', function(e) {
+        var items = visibleItems();
+        var i = items.indexOf(document.activeElement);
+        if (e.key === 'ArrowDown') {
+          focusItem(i + 1);
+        } else if (e.key === 'ArrowUp') {
+          if (i <= 0) {
+            filterEl.focus();
+          } else {
+            focusItem(i - 1);
+          }
+        } else if (e.key === 'Home') {
+          focusItem(0);
+        } else if (e.key === 'End') {
+          focusItem(items.length - 1);
+        } else {
+          if (e.key.length === 1 && e.key !== '<' && e.key !== '>' && e.key !== ' ' &&
+              !e.metaKey && !e.ctrlKey && !e.altKey) {
+            // The character lands in the newly focused box
+            filterEl.focus();
+          }
+          return;
+        }
+        e.preventDefault();
+      });
 
+      // < and > jump between commands; Tab is left alone so keyboard users
+      // can move focus through the controls
       document.addEventListener('keydown', function(e) {
         if (e.key === 'Escape' && expanded) {
           collapseList();
           return;
         }
+        if (e.target === filterEl) return;
         if (e.key === '<') {
           e.preventDefault();
           collapseList();
@@ -344,14 +600,6 @@ func tocJS(entries []TOCEntry) string {
           e.preventDefault();
           collapseList();
           goNext();
-        } else if (e.key === 'Tab') {
-          e.preventDefault();
-          collapseList();
-          if (e.shiftKey) {
-            goPrev();
-          } else {
-            goNext();
-          }
         }
       });
 
@@ -366,10 +614,9 @@ func tocJS(entries []TOCEntry) string {
       // Track scroll position to update current index
       window.addEventListener('scroll', function() {
         if (!resolvedRows || resolvedRows.length === 0) return;
-        var terminalDiv = document.getElementById('terminal');
-        var termTop = terminalDiv.getBoundingClientRect().top + window.pageYOffset;
+        var termTop = rowsTop();
         var cellHeight = getCellHeight();
-        var scrollTop = window.pageYOffset + 40;
+        var scrollTop = window.pageYOffset + scrollMargin() + 20;
 
         var idx = -1;
         for (var i = resolvedRows.length - 1; i >= 0; i--) {
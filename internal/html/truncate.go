@@ -0,0 +1,113 @@
+package html
+
+import (
+	"strconv"
+	"strings"
+)
+
+// defaultMaxRows is the row cap when PlaybackOptions.MaxRows or
+// StreamingOptions.MaxRows is 0.
+const defaultMaxRows = 100000
+
+// defaultScrollback is the xterm.js scrollback of a tailed stream when
+// StreamingOptions.Scrollback is 0.
+const defaultScrollback = 10000
+
+// tailRows is the initial terminal height of a tailed stream, until it is
+// fitted to the viewport.
+const tailRows = 24
+
+// truncationNotice returns the line that replaces rows cut by truncateRows.
+// Must match truncationNotice in rowLimiterJS.
+func truncationNotice(omitted int) string {
+	return "\r\x1b[0m──────── output truncated (" + strconv.Itoa(omitted) + " rows omitted) ────────"
+}
+
+// truncateRows caps content at maxRows lines. Longer content keeps its
+// first maxRows-1 lines, and the rest is replaced by a truncation notice,
+// so the cut is visible instead of output silently going missing. Lines
+// are counted by '\n'; TUI cursor movement and wrapping can make the
+// rendered row count differ.
+func truncateRows(content string, maxRows int) string {
+	if maxRows < 1 {
+		maxRows = 1
+	}
+	end := 0 // end of the kept lines
+	for kept := 0; kept < maxRows-1; kept++ {
+		i := strings.IndexByte(content[end:], '\n')
+		if i < 0 {
+			return content
+		}
+		end += i + 1
+	}
+
+	rest := content[end:]
+	omitted := strings.Count(rest, "\n")
+	if !strings.HasSuffix(rest, "\n") {
+		omitted++ // a last line without a newline
+	}
+	if omitted <= 1 {
+		return content // the last line fits
+	}
+	return content[:end] + truncationNotice(omitted)
+}
+
+// rowLimiterJS returns createRowLimiter(maxRows, emit), the streaming
+// counterpart of truncateRows: text written to it is passed to emit until
+// maxRows-1 lines have gone through. Then the next line is held back until
+// it is known whether more follow, and once they do everything else is
+// only counted, for the notice emitted by end().
+func rowLimiterJS() string {
+	return `
+    function truncationNotice(omitted) {
+      return '\r\x1b[0m──────── output truncated (' + omitted + ' rows omitted) ────────';
+    }
+
+    function createRowLimiter(maxRows, emit) {
+      maxRows = Math.max(1, maxRows);
+      let kept = 0;          // lines passed to emit
+      let held = '';         // text after the kept lines, until it overflows
+      let truncated = false;
+      let omitted = 0;       // newlines dropped once truncated
+      let lastChar = '';     // last character dropped
+
+      return {
+        write(chunk) {
+          if (truncated) {
+            omitted += chunk.split('\n').length - 1;
+            if (chunk) lastChar = chunk[chunk.length - 1];
+            return;
+          }
+          while (kept < maxRows - 1 && chunk) {
+            const i = chunk.indexOf('\n');
+            if (i < 0) {
+              emit(chunk);
+              return;
+            }
+            emit(chunk.substring(0, i + 1));
+            kept++;
+            chunk = chunk.substring(i + 1);
+          }
+          if (!chunk) return;
+          held += chunk;
+          const i = held.indexOf('\n');
+          if (i >= 0 && i < held.length - 1) {
+            // More than one line past the cap: drop them all
+            truncated = true;
+            omitted = held.split('\n').length - 1;
+            lastChar = held[held.length - 1];
+            held = '';
+          }
+        },
+        end() {
+          if (truncated) {
+            emit(truncationNotice(omitted + (lastChar === '\n' ? 0 : 1)));
+          } else if (held) {
+            emit(held);
+            held = '';
+          }
+        },
+      };
+    }
+`
+}
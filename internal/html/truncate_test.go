@@ -0,0 +1,92 @@
+package html
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTruncateRows(t *testing.T) {
+	tests := []struct {
+		content string
+		maxRows int
+		want    string
+	}{
+		{"a\nb\nc\n", 3, "a\nb\nc\n"},
+		{"a\nb\nc", 3, "a\nb\nc"},
+		{"a\nb\nc\nd\ne\n", 3, "a\nb\n" + truncationNotice(3)},
+		{"a\nb\nc\nd\ne", 3, "a\nb\n" + truncationNotice(3)},
+		{"a\nb\n", 1, truncationNotice(2)},
+	}
+	for _, tt := range tests {
+		if got := truncateRows(tt.content, tt.maxRows); got != tt.want {
+			t.Errorf("truncateRows(%q, %d) = %q, want %q", tt.content, tt.maxRows, got, tt.want)
+		}
+	}
+}
+
+func TestRenderPlaybackHTMLWithOptions_MaxRows(t *testing.T) {
+	content := strings.Repeat("line\r\n", 10)
+
+	html, err := RenderPlaybackHTMLWithOptions([]PlaybackFrame{{Content: content}}, PlaybackOptions{MaxRows: 4})
+	if err != nil {
+		t.Fatalf("RenderPlaybackHTMLWithOptions failed: %v", err)
+	}
+
+	startMarker := "const framesBase64 = '"
+	startIdx := strings.Index(html, startMarker) + len(startMarker)
+	endIdx := strings.Index(html[startIdx:], "'")
+	decoded, _ := base64.StdEncoding.DecodeString(html[startIdx : startIdx+endIdx])
+	var frames []PlaybackFrame
+	if err := json.Unmarshal(decoded, &frames); err != nil {
+		t.Fatalf("Failed to unmarshal frames JSON: %v", err)
+	}
+
+	want := strings.Repeat("line\r\n", 3) + "\r\x1b[0m──────── output truncated (7 rows omitted) ────────"
+	if frames[0].Content != want {
+		t.Errorf("Content = %q, want %q", frames[0].Content, want)
+	}
+	if !strings.Contains(html, "const MAX_ROWS = 4;") {
+		t.Error("terminal rows should be capped too")
+	}
+}
+
+func TestRenderStreamingPlaybackHTML_MaxRows(t *testing.T) {
+	html, err := RenderStreamingPlaybackHTML(StreamingOptions{DataURL: "./session.log", MaxRows: 50})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+	if !strings.Contains(html, "createRowLimiter(TERM_ROWS,") || !strings.Contains(html, "limiter.end();") {
+		t.Error("streamed output should be capped at TERM_ROWS")
+	}
+}
+
+func TestRenderStreamingPlaybackHTML_Scrollback(t *testing.T) {
+	html, err := RenderStreamingPlaybackHTML(StreamingOptions{DataURL: "./session.log", Scrollback: 500})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+	if !strings.Contains(html, "const TERM_SCROLLBACK = 0;") || !strings.Contains(html, "const TERM_ROWS = 100000;") {
+		t.Error("fetched output should have no scrollback, being capped at TERM_ROWS")
+	}
+
+	html, err = RenderStreamingPlaybackHTML(StreamingOptions{WebSocketURL: "/tail"})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+	if !strings.Contains(html, "const TERM_SCROLLBACK = 10000;") || !strings.Contains(html, "scrollback: TERM_SCROLLBACK,") {
+		t.Error("a tailed terminal should default to 10000 rows of scrollback")
+	}
+	if !strings.Contains(html, "const TERM_ROWS = 24;") || !strings.Contains(html, "fitTailRows();") {
+		t.Error("a tailed terminal should be sized to the viewport, not MaxRows")
+	}
+
+	html, err = RenderStreamingPlaybackHTML(StreamingOptions{WebSocketURL: "/tail", Scrollback: 500})
+	if err != nil {
+		t.Fatalf("RenderStreamingPlaybackHTML failed: %v", err)
+	}
+	if !strings.Contains(html, "const TERM_SCROLLBACK = 500;") {
+		t.Error("terminal should have the given scrollback")
+	}
+}
@@ -0,0 +1,45 @@
+package html
+
+import "html"
+
+// typedInputCSS returns the CSS for the typed input panel (see
+// typedInputHTML).
+func typedInputCSS() string {
+	return `
+    #typed-input {
+      margin: 24px 24px 0;
+      border: 1px solid rgba(212, 212, 212, 0.2);
+      border-radius: 4px;
+      font-size: 13px;
+    }
+
+    #typed-input summary {
+      padding: 8px 12px;
+      color: #888888;
+      cursor: pointer;
+    }
+
+    #typed-input pre {
+      padding: 8px 12px 12px;
+      color: #e5c07b;
+      white-space: pre-wrap;
+      word-break: break-all;
+    }
+`
+}
+
+// typedInputHTML returns a collapsible panel below the terminal showing
+// what was typed (see timing.ReconstructInput), kept apart from the program
+// output so keystrokes are easy to follow, or empty string if there is
+// none.
+func typedInputHTML(typed string) string {
+	if typed == "" {
+		return ""
+	}
+	return `
+  <details id="typed-input">
+    <summary>Typed input</summary>
+    <pre>` + html.EscapeString(typed) + `</pre>
+  </details>
+`
+}
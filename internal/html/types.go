@@ -1,9 +1,16 @@
 package html
 
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
 // PlaybackFrame represents a single frame of terminal content at a specific timestamp
 type PlaybackFrame struct {
-	Timestamp float64 `json:"timestamp"` // Time in seconds (cumulative from start)
-	Content   string  `json:"content"`   // Terminal content (with ANSI codes preserved)
+	Timestamp float64 `json:"timestamp"`       // Time in seconds (cumulative from start)
+	Content   string  `json:"content"`         // Terminal content (with ANSI codes preserved)
+	Label     string  `json:"label,omitempty"` // Optional chapter title, shown as a caption while the frame plays
 }
 
 // FooterLink represents a co-branding link in the footer
@@ -12,6 +19,96 @@ type FooterLink struct {
 	URL  string // Link URL (e.g., "https://github.com/choonkeat/swe-swe")
 }
 
+// footerHTML returns the inner HTML of the page footer: the record-tui
+// attribution followed by the co-branding links, joined by " x ".
+// Links missing text or URL are skipped.
+// Returns empty string if there is nothing to show.
+func footerHTML(footerLinks []FooterLink, hideAttribution bool) string {
+	var parts []string
+	if !hideAttribution {
+		parts = append(parts, `generated by <a href="https://github.com/choonkeat/record-tui" target="_blank" rel="noopener noreferrer">record-tui</a>`)
+	}
+	for _, link := range footerLinks {
+		if link.Text != "" && link.URL != "" {
+			parts = append(parts, `<a href="`+html.EscapeString(link.URL)+`" target="_blank" rel="noopener noreferrer">`+html.EscapeString(link.Text)+`</a>`)
+		}
+	}
+	return strings.Join(parts, " x ")
+}
+
+// allFooterLinks returns the single FooterLink (kept for backward
+// compatibility) followed by FooterLinks.
+func allFooterLinks(footerLink FooterLink, footerLinks []FooterLink) []FooterLink {
+	return append([]FooterLink{footerLink}, footerLinks...)
+}
+
+// footerDiv wraps footer content in the #footer element, or returns empty
+// string so no empty footer bar is drawn.
+func footerDiv(content string) string {
+	if content == "" {
+		return ""
+	}
+	return `
+  <div id="footer">
+    ` + content + `
+  </div>`
+}
+
+// commandHeading returns the "Recording of: <command>" heading shown above
+// the terminal, followed by the working directory and shell it was recorded
+// in when known, or empty string if all are empty.
+func commandHeading(command, cwd, shell string) string {
+	if command == "" && cwd == "" && shell == "" {
+		return ""
+	}
+	heading := "Recording"
+	if command != "" {
+		heading += ` of: <code>` + html.EscapeString(command) + `</code>`
+	}
+	if cwd != "" {
+		heading += ` in <code>` + html.EscapeString(cwd) + `</code>`
+	}
+	if shell != "" {
+		heading += ` (shell <code>` + html.EscapeString(shell) + `</code>)`
+	}
+	return `
+  <div id="command-heading">` + heading + `</div>`
+}
+
+// commandHeadingCSS returns the styles for commandHeading.
+func commandHeadingCSS() string {
+	return `
+    #command-heading {
+      padding: 12px 24px;
+      font-size: 13px;
+      color: #888888;
+      border-bottom: 1px solid rgba(212, 212, 212, 0.1);
+    }
+
+    #command-heading code {
+      color: #e0e0e0;
+      font-family: inherit;
+    }
+`
+}
+
+// styleEndPattern matches an end tag that would close a <style> element early.
+var styleEndPattern = regexp.MustCompile(`(?i)<(/style)`)
+
+// extraStyle returns a <style> element holding caller-supplied CSS, placed
+// after the built-in styles so it can override them, or empty string if css
+// is empty. "</style" is escaped as "<\/style" (the same text to CSS) so the
+// CSS can't break out of the element.
+func extraStyle(css, nonce string) string {
+	if css == "" {
+		return ""
+	}
+	return `
+  <style` + nonceAttr(nonce) + `>
+` + styleEndPattern.ReplaceAllString(css, `<\$1`) + `
+  </style>`
+}
+
 // TOCEntry represents a navigation point in the terminal recording.
 type TOCEntry struct {
 	Label string `json:"label"` // What the user typed (e.g., "npm test")
@@ -7,12 +7,13 @@
  * Environment-agnostic: no Node.js or browser-specific code.
  */
 
-// Clear sequence separator - must match Go's ClearSeparator in clear.go:9
+// Clear sequence separator - must match Go's ClearSeparator in clear.go
+// (SGR resets around the text keep stale colors out of it)
 // Using Unicode escape \u2500 for box-drawing character '─' (U+2500)
 // Both Go strings and JS strings are UTF-8/UTF-16, so this works correctly in:
 // - Node.js test harness (reading files as UTF-8)
 // - Browser streaming (TextDecoder with UTF-8)
-const CLEAR_SEPARATOR = '\n\n\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500 terminal cleared \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\n\n';
+const CLEAR_SEPARATOR = '\x1b[0m\n\n\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500 terminal cleared \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\x1b[0m\n\n';
 
 // Clear sequence pattern - must match Go's clearPattern in clear.go
 // Matches: \x1b[H\x1b[2J, \x1b[H\x1b[3J, \x1b[2J\x1b[H, \x1b[3J\x1b[H, \x1b[2J, \x1b[3J
@@ -20,12 +21,299 @@ const CLEAR_SEPARATOR = '\n\n\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500 te
 const clearPattern = /\x1b\[(?:1;1)?H\x1b\[(?:0?J|[23]J)|\x1b\[[23]J\x1b\[H|\x1b\[[23]J/g;
 
 // Alt screen separator - must match Go's AltScreenSeparator in clear.go
-const ALT_SCREEN_SEPARATOR = '\n\n\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500 alternate screen \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\n\n';
+const ALT_SCREEN_SEPARATOR = '\x1b[0m\n\n\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500 alternate screen \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\x1b[0m\n\n';
+// Written when the content ends inside the alternate screen - must match Go's UnexitedAltScreenSeparator
+const UNEXITED_ALT_SCREEN_SEPARATOR = '\x1b[0m\n\n\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500 alternate screen (not exited) \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\x1b[0m\n\n';
 
 // Alt screen pattern - must match Go's altScreenPattern in clear.go
 // Matches: \x1b[?1049h, \x1b[?1049l, \x1b[?47h, \x1b[?47l, \x1b[?1047h, \x1b[?1047l
 const altScreenPattern = /\x1b\[\?(1049|47|1047)([hl])/g;
 
+// Scroll region separator - must match Go's ScrollRegionSeparator in clear.go
+const SCROLL_REGION_SEPARATOR = '\x1b[0m\n\n\u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500 scroll region \u2500\u2500\u2500\u2500\u2500\u2500\u2500\u2500\x1b[0m\n\n';
+
+// Scroll region patterns - must match Go's scrollRegionSetPattern, scrollRegionResetPattern
+// and cursorAddressPattern in clear.go
+const scrollRegionSetPattern = /\x1b\[\d+;\d+r/;
+const scrollRegionResetPattern = /\x1b\[;?r/;
+
+// Must match Go's streamScrollRegionSize and scrollRegionResetOverlap in stream.go
+const SCROLL_REGION_SIZE = 1 << 20;
+const SCROLL_REGION_RESET_OVERLAP = 3;
+const cursorAddressPattern = /\x1b\[\d+;\d+[Hf]/g;
+
+// Must match Go's scrollRegionMinCursorAddresses in clear.go
+const SCROLL_REGION_MIN_CURSOR_ADDRESSES = 10;
+
+/**
+ * Report whether a scroll region is dominated by absolute cursor addressing.
+ * Matches Go's isTUIRedraw in clear.go.
+ */
+function isTUIRedraw(region) {
+  const moves = (region.match(cursorAddressPattern) || []).length;
+  const newlines = (region.match(/\n/g) || []).length;
+  return moves >= SCROLL_REGION_MIN_CURSOR_ADDRESSES && moves > newlines;
+}
+
+// Status report pattern - must match Go's statusReportPattern in reports.go
+// Matches terminal replies leaked into the output, raw or echoed as ^[:
+// \x1b[24;80R (cursor position), \x1b[?62;1;6c and \x1b[>1;10;0c (device attributes)
+const statusReportPattern = /(?:\x1b|\^\[)\[(?:\??\d+;\d+R|\?\d+(?:;\d+)*c|>\d+;\d+;\d+c)/g;
+
+/**
+ * Remove cursor position reports and device attribute responses.
+ * Matches Go's StripStatusReports in reports.go.
+ */
+function stripStatusReports(text) {
+  return text.replace(statusReportPattern, '');
+}
+
+// Cursor save/restore and erase sequences - must match Go's in transient.go
+const CURSOR_SAVE_SEQS = ['\x1b[s', '\x1b7'];
+const CURSOR_RESTORE_SEQS = ['\x1b[u', '\x1b8'];
+const ERASE_SEQS = ['\x1b[K', '\x1b[0K', '\x1b[2K', '\x1b[J', '\x1b[0J'];
+const cursorSavePattern = /\x1b\[s|\x1b7/;
+
+/**
+ * Return the length of whichever of seqs starts text at i, or 0.
+ * Matches Go's seqAt in transient.go.
+ */
+function seqAt(text, i, seqs) {
+  for (const seq of seqs) {
+    if (text.startsWith(seq, i)) return seq.length;
+  }
+  return 0;
+}
+
+/**
+ * Remove transient status text drawn with cursor save/restore (e.g. a
+ * spinner), keeping the erase that wiped it. A span runs from a save
+ * through frames returning to the saved position, on one line, up to a
+ * restore immediately followed by an erase.
+ * Matches Go's NeutralizeTransientSequences in transient.go.
+ */
+function neutralizeTransientSequences(text) {
+  let result = '';
+  let lastEnd = 0;
+  let start = -1; // save starting the current chain, or -1
+  for (let i = 0; i < text.length;) {
+    const save = seqAt(text, i, CURSOR_SAVE_SEQS);
+    if (save > 0) {
+      start = i; // a save elsewhere on the line restarts the chain
+      i += save;
+      continue;
+    }
+    if (start < 0) {
+      i++;
+      continue;
+    }
+    const restore = seqAt(text, i, CURSOR_RESTORE_SEQS);
+    if (text[i] === '\n') {
+      start = -1;
+      i++;
+    } else if (restore > 0) {
+      i += restore;
+      if (seqAt(text, i, ERASE_SEQS) > 0) {
+        result += text.slice(lastEnd, start);
+        lastEnd = i;
+        start = -1;
+      } else {
+        // Saving at the restored position continues the chain
+        i += seqAt(text, i, CURSOR_SAVE_SEQS);
+      }
+    } else {
+      i++;
+    }
+  }
+  return lastEnd === 0 ? text : result + text.slice(lastEnd);
+}
+
+// 8-bit C1 control introducers and their 7-bit escape forms - must match
+// Go's c1SevenBit in c1.go: CSI (U+009B), ST (U+009C) and OSC (U+009D)
+const C1_SEVEN_BIT = { '\u009b': '\x1b[', '\u009c': '\x1b\\', '\u009d': '\x1b]' };
+const c1Pattern = /[\u009b-\u009d]/g;
+
+/**
+ * Rewrite 8-bit C1 control introducers to their 7-bit escape forms, which
+ * are the only ones cleaning recognizes. Matches Go's NormalizeC1Controls
+ * in c1.go; a lone C1 byte (invalid UTF-8) reaches here as its code point
+ * when decoded with createLogDecoder.
+ */
+function normalizeC1Controls(text) {
+  return text.replace(c1Pattern, (c) => C1_SEVEN_BIT[c]);
+}
+
+/**
+ * Report the length of the UTF-8 sequence starting at bytes[i], as Go's
+ * utf8.DecodeRune accepts them: 0 if it is invalid, or -1 if bytes end
+ * before it does.
+ */
+function utf8SequenceLength(bytes, i) {
+  const b = bytes[i];
+  if (b < 0x80) return 1;
+  let size, lo = 0x80, hi = 0xbf;
+  if (b >= 0xc2 && b <= 0xdf) {
+    size = 2;
+  } else if (b >= 0xe0 && b <= 0xef) {
+    size = 3;
+    if (b === 0xe0) lo = 0xa0;
+    if (b === 0xed) hi = 0x9f;
+  } else if (b >= 0xf0 && b <= 0xf4) {
+    size = 4;
+    if (b === 0xf0) lo = 0x90;
+    if (b === 0xf4) hi = 0x8f;
+  } else {
+    return 0;
+  }
+  for (let k = 1; k < size; k++) {
+    if (i + k >= bytes.length) return -1;
+    const c = bytes[i + k];
+    if (c < (k === 1 ? lo : 0x80) || c > (k === 1 ? hi : 0xbf)) return 0;
+  }
+  return size;
+}
+
+// Must match Go's utf16SniffUnits in encoding.go
+const UTF16_SNIFF_UNITS = 8;
+
+/**
+ * Report the UTF-16 encoding bytes start with ('utf-16le' or 'utf-16be')
+ * and how long its byte order mark is, or null if they aren't UTF-16.
+ * Matches Go's DecodeText in encoding.go: a byte order mark, or without
+ * one, UTF16_SNIFF_UNITS ASCII characters leaving every other byte NUL.
+ */
+function utf16Encoding(bytes) {
+  if (bytes[0] === 0xff && bytes[1] === 0xfe) return { encoding: 'utf-16le', bom: 2 };
+  if (bytes[0] === 0xfe && bytes[1] === 0xff) return { encoding: 'utf-16be', bom: 2 };
+  for (const [encoding, lo] of [['utf-16le', 0], ['utf-16be', 1]]) {
+    let ascii = bytes.length >= 2 * UTF16_SNIFF_UNITS;
+    for (let i = 0; ascii && i < 2 * UTF16_SNIFF_UNITS; i += 2) {
+      const c = bytes[i + lo];
+      ascii = bytes[i + 1 - lo] === 0 && c !== 0 && c < 0x80;
+    }
+    if (ascii) return { encoding: encoding, bom: 0 };
+  }
+  return null;
+}
+
+/**
+ * Create a decoder for session log bytes, used in place of TextDecoder so
+ * the text matches what Go cleans (see Go's DecodeText in encoding.go): a
+ * leading UTF-8 byte order mark is dropped, UTF-16 is decoded, and a lone
+ * C1 control byte (0x9B, 0x9C or 0x9D, not valid UTF-8 on its own) becomes
+ * its code point instead of U+FFFD, for normalizeC1Controls to rewrite.
+ *
+ * @returns {{decode: function(Uint8Array): string, end: function(): string}}
+ *
+ * decode() holds back the first bytes until the encoding is known, and a
+ * character cut short at the end of its bytes; end() returns what is
+ * still held back.
+ */
+function createLogDecoder() {
+  const utf8 = new TextDecoder('utf-8', { ignoreBOM: true });
+  let utf16 = null;
+  let utf16Odd = false; // whether an odd number of UTF-16 bytes were decoded
+  let sniffed = false;
+  let pending = new Uint8Array(0);
+
+  function decodeUTF8(bytes, final) {
+    let text = '';
+    let segmentStart = 0;
+    let i = 0;
+    while (i < bytes.length) {
+      const size = utf8SequenceLength(bytes, i);
+      if (size < 0 && !final) break; // cut short, wait for the rest
+      if (size > 0) {
+        i += size;
+        continue;
+      }
+      if (bytes[i] >= 0x9b && bytes[i] <= 0x9d) {
+        text += utf8.decode(bytes.subarray(segmentStart, i)) + String.fromCharCode(bytes[i]);
+        segmentStart = i + 1;
+      }
+      i++;
+    }
+    pending = bytes.slice(i);
+    return text + utf8.decode(bytes.subarray(segmentStart, i));
+  }
+
+  function decode(bytes, final) {
+    if (pending.length > 0) {
+      const all = new Uint8Array(pending.length + bytes.length);
+      all.set(pending);
+      all.set(bytes, pending.length);
+      bytes = all;
+      pending = new Uint8Array(0);
+    }
+    if (!sniffed) {
+      if (bytes.length < 2 * UTF16_SNIFF_UNITS && !final) {
+        pending = bytes;
+        return '';
+      }
+      sniffed = true;
+      const found = utf16Encoding(bytes);
+      if (found) {
+        utf16 = new TextDecoder(found.encoding, { ignoreBOM: true });
+        bytes = bytes.subarray(found.bom);
+      } else if (bytes[0] === 0xef && bytes[1] === 0xbb && bytes[2] === 0xbf) {
+        bytes = bytes.subarray(3);
+      }
+    }
+    if (!utf16) return decodeUTF8(bytes, final);
+
+    utf16Odd = utf16Odd !== (bytes.length % 2 === 1);
+    const text = utf16.decode(bytes, { stream: true });
+    // A trailing odd byte is dropped, as Go does
+    return final && !utf16Odd ? text + utf16.decode() : text;
+  }
+
+  return {
+    decode: (bytes) => decode(bytes, false),
+    end: () => decode(new Uint8Array(0), true),
+  };
+}
+
+// ConPTY prefix pattern - must match Go's conptyPrefixPattern in cleaner.go
+const conptyPrefixPattern = /^(?:\x1b\[\??[0-9;]*[a-zA-Z])+/;
+
+// Header/footer line patterns for the util-linux and macOS script variants -
+// must match Go's patterns in cleaner.go
+const scriptStartedPattern = /^Script started(?: on |, output (?:log )?file is )/;
+const scriptDonePattern = /^Script done(?: on |, output (?:log )?file is )/;
+const commandLinePattern = /^Command:/;
+const footerPattern = /^(?:Saving session|Command exit status)/;
+
+/**
+ * Normalize a line for header/footer detection.
+ * Matches Go's metadataLine in cleaner.go: drops the \r of a CRLF line ending
+ * and any leading ConPTY escape sequences.
+ */
+function metadataLine(line) {
+  if (line.endsWith('\r')) {
+    line = line.slice(0, -1);
+  }
+  return line.replace(conptyPrefixPattern, '');
+}
+
+/**
+ * Report whether a line is a footer line added by the script command.
+ * Matches Go's isFooterLine in cleaner.go.
+ */
+function isFooterLine(line) {
+  line = metadataLine(line);
+  return footerPattern.test(line) || scriptDonePattern.test(line);
+}
+
+/**
+ * Drop footer lines left inside session content (e.g. from a nested recording).
+ * Matches Go's withoutFooterLines in cleaner.go.
+ */
+function stripFooterLines(text) {
+  const lines = text.split('\n');
+  const kept = lines.filter((line) => !isFooterLine(line));
+  return kept.length === lines.length ? text : kept.join('\n');
+}
+
 /**
  * Strip header lines from session content.
  * Matches Go's cleaner.go:17-24 exactly.
@@ -38,8 +326,8 @@ function stripHeader(text) {
   // Find where actual content starts (skip header)
   // The header consists of "Script started on..." followed by "Command: ..."
   for (let i = 0; i < lines.length && i < 5; i++) {
-    const line = lines[i];
-    if (line.startsWith('Script started on') || line.startsWith('Command:')) {
+    const line = metadataLine(lines[i]);
+    if (scriptStartedPattern.test(line) || commandLinePattern.test(line)) {
       startIndex = i + 1;
     }
   }
@@ -66,14 +354,11 @@ function stripFooter(text) {
   let footerStartIndex = lines.length;
   let hasFooterMarker = false;
   for (let i = lines.length - 1; i >= 0; i--) {
-    const line = lines[i];
     // Check if this line is a footer marker (must start with the marker text)
-    if (line.startsWith('Saving session') ||
-        line.startsWith('Command exit status') ||
-        line.startsWith('Script done on')) {
+    if (isFooterLine(lines[i])) {
       hasFooterMarker = true;
       footerStartIndex = i;
-    } else if (hasFooterMarker && line.trim() === '') {
+    } else if (hasFooterMarker && lines[i].trim() === '') {
       // Only treat empty lines as footer if we already found a footer marker
       footerStartIndex = i;
     } else if (footerStartIndex < lines.length) {
@@ -88,11 +373,13 @@ function stripFooter(text) {
     endIndex--;
   }
 
+  // Drop a dangling \r left at the very end (CRLF line endings), as Go does
   if (endIndex >= lines.length) {
-    return text; // No footer found
+    return text.endsWith('\r') ? text.slice(0, -1) : text; // No footer found
   }
 
-  return lines.slice(0, endIndex).join('\n');
+  const result = lines.slice(0, endIndex).join('\n');
+  return result.endsWith('\r') ? result.slice(0, -1) : result;
 }
 
 /**
@@ -107,6 +394,8 @@ function stripFooter(text) {
  *   cleaner.write(chunk1);
  *   cleaner.write(chunk2);
  *   cleaner.end();
+ *
+ * A stream that stays open (tailed live) calls cleaner.flush() when idle.
  */
 function createStreamingCleaner(onOutput) {
   // Header state - buffer first few lines to detect and strip header
@@ -126,6 +415,13 @@ function createStreamingCleaner(onOutput) {
   let inAltScreen = false;
   let altScreenHadContentBefore = false;
 
+  // Scroll region state - buffer a region until its reset to decide whether it is a TUI redraw
+  let inScrollRegion = false;
+  let scrollRegionBuffer = '';
+  let discardingScrollRegion = false; // decided as a redraw past SCROLL_REGION_SIZE
+  let scrollRegionHadContentBefore = false;
+  let pendingScrollRegionSeparator = false;
+
   // Buffer for incomplete escape sequences at chunk boundaries
   let escapeBuffer = '';
 
@@ -133,6 +429,12 @@ function createStreamingCleaner(onOutput) {
   let trailingBuffer = '';
   const TRAILING_SIZE = 500;
 
+  // Session join state - an appended log (script -a) holds several sessions,
+  // whose content is joined with \r\n (skipping sessions with no content)
+  let hadSessionContent = false;
+  let joinPending = false;
+  let pendingJoinText = '';
+
   /**
    * Process text for clear sequences, respecting streaming state.
    * Updates hasEmittedContent and pendingSeparator as side effects.
@@ -289,79 +591,288 @@ function createStreamingCleaner(onOutput) {
   }
 
   /**
-   * Feed a chunk of data to the cleaner.
-   * May invoke onOutput zero or more times.
+   * Emit text outside a discarded scroll region, prefixed by a pending separator.
    */
-  function write(chunk) {
-    // Prepend any buffered incomplete escape sequence
-    let text = escapeBuffer + chunk;
-    escapeBuffer = '';
+  function emitOutsideScrollRegion(text) {
+    if (text.trim() === '') return text;
+    scrollRegionHadContentBefore = true;
+    if (pendingScrollRegionSeparator) {
+      pendingScrollRegionSeparator = false;
+      return SCROLL_REGION_SEPARATOR + text;
+    }
+    return text;
+  }
 
-    // Check for incomplete escape sequence at end (escape sequences are typically <10 bytes)
-    const lastEsc = text.lastIndexOf('\x1b');
-    if (lastEsc >= 0 && lastEsc > text.length - 10) {
-      // Might be incomplete, buffer it for next chunk
-      escapeBuffer = text.slice(lastEsc);
-      text = text.slice(0, lastEsc);
+  /**
+   * Decide the fate of a complete scroll region (set through reset, or end of stream).
+   * TUI redraws are discarded and leave a pending separator; anything else is kept.
+   */
+  function finishScrollRegion(region) {
+    if (isTUIRedraw(region)) {
+      if (scrollRegionHadContentBefore) {
+        pendingScrollRegionSeparator = true;
+      }
+      return '';
+    }
+    return emitOutsideScrollRegion(region);
+  }
+
+  /**
+   * Decide the fate of a scroll region grown past SCROLL_REGION_SIZE on what came so far.
+   * A TUI redraw is discarded up to its reset; anything else is emitted and no longer buffered.
+   * Matches Go's decideScrollRegion.
+   */
+  function decideScrollRegion() {
+    const region = scrollRegionBuffer;
+    if (isTUIRedraw(region)) {
+      if (scrollRegionHadContentBefore) {
+        pendingScrollRegionSeparator = true;
+      }
+      discardingScrollRegion = true;
+      scrollRegionBuffer = region.slice(-SCROLL_REGION_RESET_OVERLAP);
+      return '';
+    }
+    inScrollRegion = false;
+    scrollRegionBuffer = '';
+    return emitOutsideScrollRegion(region);
+  }
+
+  /**
+   * Process text for scroll region (DECSTBM) sequences, respecting streaming state.
+   * Content from a set (\x1b[top;bottomr) to the next reset (\x1b[r) is buffered,
+   * then discarded if it looks like a cursor-addressed TUI redraw.
+   * Matches Go's NeutralizeScrollRegionSequences.
+   */
+  function processForScrollRegion(text) {
+    if (!text) return '';
+
+    if (inScrollRegion) {
+      // Only the new text, and the end of the buffer in case the reset straddles them
+      const from = Math.max(0, scrollRegionBuffer.length - SCROLL_REGION_RESET_OVERLAP);
+      scrollRegionBuffer += text;
+      const reset = scrollRegionResetPattern.exec(scrollRegionBuffer.slice(from));
+      if (!reset) {
+        if (discardingScrollRegion) {
+          scrollRegionBuffer = scrollRegionBuffer.slice(-SCROLL_REGION_RESET_OVERLAP);
+        } else if (scrollRegionBuffer.length > SCROLL_REGION_SIZE) {
+          return decideScrollRegion();
+        }
+        return ''; // Still inside region - keep buffering
+      }
+      const regionEnd = from + reset.index + reset[0].length;
+      const region = scrollRegionBuffer.slice(0, regionEnd);
+      const rest = scrollRegionBuffer.slice(regionEnd);
+      inScrollRegion = false;
+      scrollRegionBuffer = '';
+      if (discardingScrollRegion) {
+        discardingScrollRegion = false;
+        return processForScrollRegion(rest);
+      }
+      return finishScrollRegion(region) + processForScrollRegion(rest);
+    }
+
+    const set = scrollRegionSetPattern.exec(text);
+    if (!set) {
+      return emitOutsideScrollRegion(text);
+    }
+
+    const before = emitOutsideScrollRegion(text.slice(0, set.index));
+    inScrollRegion = true;
+    return before + processForScrollRegion(text.slice(set.index));
+  }
+
+  /**
+   * Clean and emit session content (header and footer already stripped),
+   * joining it to the previous session's content if needed.
+   */
+  function emitBody(text) {
+    if (joinPending) {
+      // Only join once this session turns out to have content
+      pendingJoinText += text;
+      if (pendingJoinText.trim() === '') return;
+      text = '\r\n' + pendingJoinText;
+      pendingJoinText = '';
+      joinPending = false;
+    }
+    if (text.trim() !== '') hadSessionContent = true;
+
+    let processed = processForClears(neutralizeTransientSequences(stripStatusReports(normalizeC1Controls(text))));
+    processed = processForAltScreen(processed);
+    processed = processForScrollRegion(processed);
+    if (processed) onOutput(processed);
+  }
+
+  /**
+   * Emit the rest of a session, minus its footer.
+   * Matches Go's sessionBody in cleaner.go.
+   */
+  function finishSession(text) {
+    emitBody(stripFooterLines(stripFooter(text)));
+    // A session with no content is skipped, as Go does
+    pendingJoinText = '';
+    joinPending = hadSessionContent;
+  }
+
+  /**
+   * Find the start of the line beginning a new script session, from the
+   * second line on if skipFirst is set. Matches Go's scriptSessions in
+   * cleaner.go. Returns -1 if none.
+   */
+  function findSessionStart(text, skipFirst) {
+    let lineStart = 0;
+    if (skipFirst) {
+      lineStart = text.indexOf('\n') + 1;
+      if (lineStart === 0) return -1;
+    }
+    while (lineStart < text.length) {
+      const lineEnd = text.indexOf('\n', lineStart);
+      const line = lineEnd < 0 ? text.slice(lineStart) : text.slice(lineStart, lineEnd);
+      if (scriptStartedPattern.test(metadataLine(line))) {
+        return lineStart;
+      }
+      if (lineEnd < 0) break;
+      lineStart = lineEnd + 1;
     }
+    return -1;
+  }
 
+  /**
+   * Process text following what was already fed. Strips each session's
+   * header (buffering until there are enough lines to detect it) and keeps
+   * a trailing buffer, cut at a line start, for footer detection.
+   * With final set, everything buffered is flushed.
+   */
+  function feed(text, final) {
     // Handle header - buffer until we have enough lines
+    let nextSession = '';
     if (!headerStripped) {
       headerBuffer += text;
-      text = '';
 
-      // Count newlines to determine if we have enough for header detection
+      // Count newlines to determine if we have enough for header detection,
+      // unless the next session already starts (its header isn't ours)
       const newlineCount = (headerBuffer.match(/\n/g) || []).length;
-      if (newlineCount >= HEADER_LINES_THRESHOLD) {
-        headerBuffer = stripHeader(headerBuffer);
-        headerStripped = true;
-        text = headerBuffer;
-        headerBuffer = '';
-      } else {
+      const next = findSessionStart(headerBuffer, true);
+      if (!final && next < 0 && newlineCount < HEADER_LINES_THRESHOLD) {
         return; // Need more data for header detection
       }
+      if (next > 0) {
+        nextSession = headerBuffer.slice(next);
+        headerBuffer = headerBuffer.slice(0, next);
+      }
+      text = stripHeader(headerBuffer);
+      headerStripped = true;
+      headerBuffer = '';
     }
 
     // Add to trailing buffer
-    text = trailingBuffer + text;
+    text = trailingBuffer + text + nextSession;
     trailingBuffer = '';
 
-    // Keep trailing portion for footer detection at end
+    // Another script session (appended log): finish this one, start the next
+    const sessionStart = findSessionStart(text);
+    if (sessionStart >= 0) {
+      finishSession(text.slice(0, sessionStart));
+      headerStripped = false;
+      feed(text.slice(sessionStart), final);
+      return;
+    }
+
+    if (final) {
+      finishSession(text);
+      return;
+    }
+
+    // Keep trailing portion for footer detection at end, starting at a line
+    // so that a session start line is always seen whole
     if (text.length > TRAILING_SIZE) {
-      const toEmit = text.slice(0, -TRAILING_SIZE);
-      trailingBuffer = text.slice(-TRAILING_SIZE);
-      let processed = processForClears(toEmit);
-      processed = processForAltScreen(processed);
-      if (processed) onOutput(processed);
+      let cut = text.lastIndexOf('\n', text.length - TRAILING_SIZE) + 1;
+      if (cut === 0) {
+        // A long line: cut mid-line, but before any cursor save (or one the
+        // cut would split) so transient status text is seen whole, and not
+        // inside a run of escape sequences (a clear can be two in a row)
+        cut = text.length - TRAILING_SIZE;
+        const save = text.slice(0, cut + 2).search(cursorSavePattern);
+        if (save >= 0) cut = save;
+        let esc;
+        while (cut > 0 && (esc = text.lastIndexOf('\x1b', cut - 1)) >= 0 && esc > cut - 10) {
+          cut = esc;
+        }
+      }
+      trailingBuffer = text.slice(cut);
+      emitBody(stripFooterLines(text.slice(0, cut)));
     } else {
       trailingBuffer = text;
     }
   }
 
+  /**
+   * Feed a chunk of data to the cleaner.
+   * May invoke onOutput zero or more times.
+   */
+  function write(chunk) {
+    // Prepend any buffered incomplete escape sequence
+    let text = escapeBuffer + chunk;
+    escapeBuffer = '';
+
+    // Check for incomplete escape sequence at end (escape sequences are typically <10 bytes),
+    // or one started by an 8-bit CSI or OSC introducer (see normalizeC1Controls)
+    const lastEsc = Math.max(text.lastIndexOf('\x1b'), text.lastIndexOf('\u009b'), text.lastIndexOf('\u009d'));
+    if (lastEsc >= 0 && lastEsc > text.length - 10) {
+      // Might be incomplete, buffer it for next chunk
+      escapeBuffer = text.slice(lastEsc);
+      text = text.slice(0, lastEsc);
+    }
+
+    feed(text, false);
+  }
+
   /**
    * Signal end of stream. Processes remaining buffered data.
    * Must be called to flush final content.
    */
   function end() {
-    // Combine all remaining buffers
-    let text = trailingBuffer + escapeBuffer;
-
-    // If header wasn't stripped yet (very small input), strip it now
-    if (!headerStripped) {
-      text = headerBuffer + text;
-      text = stripHeader(text);
+    const text = escapeBuffer;
+    escapeBuffer = '';
+    feed(text, true);
+
+    // A scroll region still open at end of stream runs to the end
+    if (inScrollRegion) {
+      const region = scrollRegionBuffer;
+      inScrollRegion = false;
+      scrollRegionBuffer = '';
+      if (!discardingScrollRegion) {
+        const processed = finishScrollRegion(region);
+        if (processed) onOutput(processed);
+      }
+      discardingScrollRegion = false;
     }
 
-    // Strip footer from final content
-    text = stripFooter(text);
+    // Ended inside the alternate screen: say so after what came before
+    if (inAltScreen && altScreenHadContentBefore) {
+      onOutput(UNEXITED_ALT_SCREEN_SEPARATOR);
+    }
+  }
 
-    // Process for clears and alt screen, then emit
-    let processed = processForClears(text);
-    processed = processForAltScreen(processed);
-    if (processed) onOutput(processed);
+  /**
+   * Emit what is held back for header and footer detection without ending
+   * the stream, so a live stream shows its newest output while idle.
+   * Writing may continue after a flush; footer lines that arrive later are
+   * still dropped (stripFooterLines).
+   */
+  function flush() {
+    if (!headerStripped) {
+      // Wait for the first line whole, to tell whether it is a header
+      if (headerBuffer.indexOf('\n') < 0) return;
+      trailingBuffer = stripHeader(headerBuffer);
+      headerStripped = true;
+      headerBuffer = '';
+    }
+    const text = trailingBuffer;
+    trailingBuffer = '';
+    if (text) emitBody(stripFooterLines(text));
   }
 
-  return { write, end };
+  return { write, end, flush };
 }
 
 // Export for Node.js (CommonJS) - ignored in browser
@@ -369,10 +880,17 @@ if (typeof module !== 'undefined' && module.exports) {
   module.exports = {
     CLEAR_SEPARATOR,
     ALT_SCREEN_SEPARATOR,
+    SCROLL_REGION_SEPARATOR,
     clearPattern,
     altScreenPattern,
+    statusReportPattern,
     stripHeader,
+    stripStatusReports,
+    neutralizeTransientSequences,
     stripFooter,
+    stripFooterLines,
+    normalizeC1Controls,
+    createLogDecoder,
     createStreamingCleaner
   };
 }
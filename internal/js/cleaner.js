@@ -8,9 +8,11 @@
 
 const {
   CLEAR_SEPARATOR,
+  SCROLL_REGION_SEPARATOR,
   clearPattern,
   stripHeader,
   stripFooter,
+  createLogDecoder,
   createStreamingCleaner
 } = require('./cleaner-core.js');
 
@@ -20,6 +22,7 @@ module.exports = {
   clearPattern,
   stripHeader,
   stripFooter,
+  createLogDecoder,
   createStreamingCleaner
 };
 
@@ -37,6 +40,20 @@ if (require.main === module) {
   const footerResult = stripFooter(footerTest);
   console.log('stripFooter test:', footerResult === 'hello world' ? 'PASS' : 'FAIL');
 
+  // Test CRLF line endings
+  const crlfTest = 'Script started on Wed Dec 31 12:10:34 2025\r\nCommand: bash\r\nhello world\r\n\r\nScript done on Wed Dec 31 12:11:22 2025\r\n';
+  console.log('CRLF header/footer test:', stripFooter(stripHeader(crlfTest)) === 'hello world' ? 'PASS' : 'FAIL');
+
+  // Test ConPTY sequences glued to header
+  const conptyTest = '\x1b[?9001h\x1b[?1004hScript started on 2026-01-12 06:41:43+00:00\r\nhello world';
+  console.log('ConPTY header test:', stripHeader(conptyTest) === 'hello world' ? 'PASS' : 'FAIL');
+
+  // Test macOS header variants (TTY suffix, start/done messages)
+  const ttyTest = 'Script started on Tue Oct  8 09:41:22 2024 on /dev/ttys003\nCommand: zsh\nhello world\n\nSaving session...\nCommand exit status: 0\nScript done on Tue Oct  8 09:42:02 2024 on /dev/ttys003\n';
+  console.log('macOS TTY header/footer test:', stripFooter(stripHeader(ttyTest)) === 'hello world' ? 'PASS' : 'FAIL');
+  const messageTest = 'Script started, output file is typescript\nhello world\n\nScript done, output file is typescript\n';
+  console.log('script message header/footer test:', stripFooter(stripHeader(messageTest)) === 'hello world' ? 'PASS' : 'FAIL');
+
   // === Streaming Tests ===
   console.log('\nRunning streaming cleaner tests...');
 
@@ -124,5 +141,56 @@ if (require.main === module) {
     'streaming no clears'
   );
 
+  // Test 9: Scroll region TUI redraw discarded
+  let redraw = '\x1b[1;24r';
+  for (let row = 1; row <= 12; row++) {
+    redraw += '\x1b[' + row + ';1HPID ' + row;
+  }
+  redraw += '\x1b[r';
+  verifyChunkIndependence(
+    'before\n' + redraw + '\nafter',
+    'before\n' + SCROLL_REGION_SEPARATOR + '\nafter',
+    'streaming scroll region redraw'
+  );
+
+  // Test 10: Scroll region with ordinary scrolling output kept
+  const pinned = '\x1b[1;23rline 1\nline 2\n\x1b[24;1Hprogress\x1b[r';
+  verifyChunkIndependence(
+    'before\n' + pinned + '\nafter',
+    'before\n' + pinned + '\nafter',
+    'streaming scroll region kept'
+  );
+
+  // Test 11: 8-bit CSI clear normalized to its 7-bit form
+  verifyChunkIndependence(
+    'before\u009b2Jafter',
+    'before' + CLEAR_SEPARATOR + 'after',
+    'streaming 8-bit CSI clear'
+  );
+
+  // Test 12: Lone C1 bytes decoded to their code points, UTF-8 around them kept
+  const logDecoder = createLogDecoder();
+  const decoded = [0x9b, 0x32, 0x4a, 0xe2, 0x9c, 0x93, 0xc2, 0x9d]
+    .map((b) => logDecoder.decode(Uint8Array.of(b))).join('') + logDecoder.end();
+  console.log('log decoder lone C1 bytes:', decoded === '\u009b2J\u2713\u009d' ? 'PASS' : 'FAIL');
+
+  // Test 13: UTF-16 (here with a byte order mark) decoded, a UTF-8 byte order mark dropped
+  const utf16Decoder = createLogDecoder();
+  const utf16Bytes = Uint8Array.from([0xff, 0xfe, ...Array.from('hello, caf\u00e9!', (c) => [c.charCodeAt(0), 0]).flat()]);
+  const utf16Text = utf16Decoder.decode(utf16Bytes.subarray(0, 5)) + utf16Decoder.decode(utf16Bytes.subarray(5)) + utf16Decoder.end();
+  const bomDecoder = createLogDecoder();
+  const bomText = bomDecoder.decode(Uint8Array.of(0xef, 0xbb, 0xbf, 0x68, 0x69)) + bomDecoder.end();
+  console.log('log decoder UTF-16 and BOM:', utf16Text === 'hello, caf\u00e9!' && bomText === 'hi' ? 'PASS' : 'FAIL');
+
+  // Test 14: Flush shows held back output of an open stream
+  const live = [];
+  const liveCleaner = createStreamingCleaner((c) => live.push(c));
+  liveCleaner.write('Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n$ ');
+  liveCleaner.flush();
+  const flushed = live.join('');
+  liveCleaner.write('ls\nfile\n\nScript done on Wed Dec 31 12:11:22 2025\n');
+  liveCleaner.flush();
+  console.log('streaming flush:', flushed === '$ ' && live.join('') === '$ ls\nfile\n\n' ? 'PASS' : 'FAIL');
+
   console.log('\nSelf-test complete.');
 }
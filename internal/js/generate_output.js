@@ -7,7 +7,7 @@
 
 const fs = require('fs');
 const path = require('path');
-const { createStreamingCleaner } = require('./cleaner.js');
+const { createLogDecoder, createStreamingCleaner } = require('./cleaner.js');
 
 // Seeded PRNG for reproducible random chunk sizes
 // Allow reproducible runs via SEED env var
@@ -25,19 +25,22 @@ function getRandomChunkSize() {
 }
 
 /**
- * Process content using streaming cleaner with random chunk sizes.
+ * Process content bytes using streaming cleaner with random chunk sizes,
+ * decoded as the browser does (createLogDecoder).
  * This proves the streaming API produces identical output regardless of chunking.
  */
 function processWithStreaming(content) {
   const chunks = [];
+  const decoder = createLogDecoder();
   const cleaner = createStreamingCleaner((c) => chunks.push(c));
 
   let offset = 0;
   while (offset < content.length) {
     const size = getRandomChunkSize();
-    cleaner.write(content.slice(offset, offset + size));
+    cleaner.write(decoder.decode(content.subarray(offset, offset + size)));
     offset += size;
   }
+  cleaner.write(decoder.end());
   cleaner.end();
 
   return chunks.join('');
@@ -82,11 +85,10 @@ function main() {
       continue;
     }
 
-    // Read file as UTF-8 to match Go's string handling (Go strings are UTF-8)
-    // Session logs are text output from terminals, which use UTF-8
+    // Read file as bytes, decoded as the browser does (see processWithStreaming)
     let content;
     try {
-      content = fs.readFileSync(realPath, 'utf8');
+      content = fs.readFileSync(realPath);
     } catch (err) {
       console.error(`Failed to read file "${realPath}": ${err.message}`);
       continue;
@@ -99,8 +101,8 @@ function main() {
     // Format: "{input_length} bytes\n{cleaned_content}"
     // Write header and content separately to avoid any concatenation issues
     const outputPath = path.join(OUTPUT_DIR, file + '.js.output');
-    // Use Buffer.byteLength to get byte count (not character count) to match Go's len([]byte)
-    const inputByteLength = Buffer.byteLength(content, 'utf8');
+    // Byte count (not character count) to match Go's len([]byte)
+    const inputByteLength = content.length;
     const header = `${inputByteLength} bytes\n`;
     try {
       fs.writeFileSync(outputPath, header, 'utf8');
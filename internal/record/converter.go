@@ -1,15 +1,419 @@
 package record
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"time"
 
 	"github.com/choonkeat/record-tui/internal/logfile"
+	"github.com/choonkeat/record-tui/internal/session"
+	"github.com/choonkeat/record-tui/internal/timing"
+	"github.com/choonkeat/record-tui/internal/toc"
 	"github.com/choonkeat/record-tui/playback"
 )
 
+// ConvertOptions configures optional conversion behavior.
+type ConvertOptions struct {
+	KeepAltScreen  bool   // Keep the last frame of full-screen TUIs instead of discarding them
+	SanitizeBinary bool   // Strip NUL/control bytes from content that looks binary instead of refusing it
+	ColorMode      string // "16" downsamples 256-color and true-color output (see playback.Options.ColorMode)
+	Version        string // record-tui version recorded in the HTML (see playback.Options.Version)
+	ShowEnv        bool   // Show the working directory and shell from session.env in the heading
+	Redact         bool   // Replace secrets (tokens, keys, passwords) with [REDACTED] (see session.RedactSecrets)
+	ErrorNav       bool   // Let the viewer step through error output with n / p (see session.FindErrorLines)
+	ShowTyped      bool   // Show what was typed in a panel below the terminal (see timing.ReconstructInput)
+	LineTimes      bool   // Embed when each line appeared, for syncing narration (see playback.Options.LineTimes)
+
+	// Title is the page title, with {command}, {date}, {exit} and
+	// {duration} replaced from the session log's header and footer (see
+	// expandTitle). Empty keeps playback's default.
+	Title string
+
+	// FooterLink, if set, is shown in the footer next to the record-tui
+	// attribution (see playback.Options.FooterLink).
+	FooterLink playback.FooterLink
+
+	// OpenGraph adds Open Graph <meta> tags for link previews, described
+	// from the header and footer as "Terminal recording of <command>
+	// (<duration>)", with OpenGraphImage, if set, as the preview image (see
+	// playback.Options.OpenGraph).
+	OpenGraph      bool
+	OpenGraphImage string
+
+	// Overflow is how the text and SVG formats lay out lines longer than
+	// the recorded terminal width (80 if unknown): "wrap" as the terminal
+	// did (the default), "truncate" with an ellipsis, or "none" to keep
+	// them whole (see playback.SVGOptions.Overflow).
+	Overflow string
+
+	// StashAltScreen saves each discarded full-screen TUI session's raw
+	// output to alt-screen-N.log next to the HTML, and links it from the
+	// session's separator. Ignored with KeepAltScreen.
+	StashAltScreen bool
+
+	// OutputOnly drops the echo of what was typed, keeping only program
+	// output (see timing.OutputOnly). This needs the timing file alongside
+	// the log, recorded with input logging.
+	OutputOnly bool
+
+	// TrimLeadingIdle drops the output written before the first command was
+	// typed (see timing.TrimLeadingIdle), so the page starts at its prompt
+	// instead of after a login banner or idle screen. This needs the timing
+	// file alongside the log. Ignored with Since/Until, which pick their
+	// own start.
+	TrimLeadingIdle bool
+
+	// Since and Until convert only the output written in that window of
+	// the recording (Until 0 = to the end), e.g. the 5 minutes where a bug
+	// happened. This needs the timing file alongside the log, and the page
+	// has no table of contents.
+	Since time.Duration
+	Until time.Duration
+
+	// TOCExclude leaves commands typed exactly as one of these (e.g.
+	// playback.DefaultNoiseCommands) out of the table of contents, and
+	// TOCExcludePattern, if set, those it matches (see playback.TOCOptions).
+	TOCExclude        []string
+	TOCExcludePattern *regexp.Regexp
+
+	// TOCCollapseRepeats merges a command run several times in a row into
+	// one table of contents entry (see playback.TOCOptions.CollapseRepeats).
+	TOCCollapseRepeats bool
+
+	// PromptPattern matches the shell prompt that marks commands in the
+	// table of contents of a recording without timing and input files (see
+	// playback.TOCOptions.PromptPattern). nil uses the bash, zsh and fish
+	// defaults.
+	PromptPattern *regexp.Regexp
+
+	// FinalScreen keeps only the last screen of the recording (see
+	// playback.FinalScreen), e.g. a build summary, replayed at ScreenCols x
+	// ScreenRows (0 = the recorded size from session.meta, or 80 x 24). The
+	// page has no table of contents.
+	FinalScreen bool
+	ScreenCols  int
+	ScreenRows  int
+
+	// TrimTrailingPrompt drops the bare prompt the recording ends waiting
+	// at, found with PromptPattern (see playback.TrimTrailingPrompt), so
+	// the page doesn't end looking unfinished.
+	TrimTrailingPrompt bool
+
+	// TimingPath and InputPath, if set, name the timing and input files
+	// to use instead of those alongside the log (see
+	// logfile.CompanionPath), e.g. for a recording made by another tool.
+	// Each must exist and be readable (see checkCompanionFiles).
+	TimingPath string
+	InputPath  string
+}
+
+// timingPath returns the session log's timing file: TimingPath if set,
+// otherwise session.timing alongside it.
+func (o ConvertOptions) timingPath(sessionLogPath string) string {
+	if o.TimingPath != "" {
+		return o.TimingPath
+	}
+	return logfile.CompanionPath(sessionLogPath, ".timing")
+}
+
+// inputPath returns the session log's input file: InputPath if set,
+// otherwise session.input alongside it.
+func (o ConvertOptions) inputPath(sessionLogPath string) string {
+	if o.InputPath != "" {
+		return o.InputPath
+	}
+	return logfile.CompanionPath(sessionLogPath, ".input")
+}
+
+// checkCompanionFiles checks that ConvertOptions.TimingPath and InputPath,
+// if set, can be read, so a mistyped path fails instead of silently
+// leaving out what they'd add. The error names the file.
+func checkCompanionFiles(o ConvertOptions) error {
+	for _, f := range []struct{ name, path string }{
+		{"timing", o.TimingPath},
+		{"input", o.InputPath},
+	} {
+		if f.path == "" {
+			continue
+		}
+		file, err := os.Open(f.path)
+		if err != nil {
+			return fmt.Errorf("cannot read %s file: %w", f.name, err)
+		}
+		file.Close()
+	}
+	return nil
+}
+
+// redact replaces secrets in text the page shows besides the terminal
+// content (heading, title, navigation labels, typed input) if Redact is
+// set, as the content itself is (see session.RedactSecrets).
+func (o ConvertOptions) redact(text string) string {
+	if !o.Redact {
+		return text
+	}
+	return session.RedactSecrets(text, nil)
+}
+
+// redactTOC is redact for table of contents labels.
+func (o ConvertOptions) redactTOC(entries []playback.TOCEntry) []playback.TOCEntry {
+	if !o.Redact {
+		return entries
+	}
+	for i := range entries {
+		entries[i].Label = o.redact(entries[i].Label)
+	}
+	return entries
+}
+
+// hasTimeWindow reports whether Since or Until is set.
+func (o ConvertOptions) hasTimeWindow() bool {
+	return o.Since != 0 || o.Until != 0
+}
+
+// tocOptions returns the playback.TOCOptions for the table of contents.
+func (o ConvertOptions) tocOptions() playback.TOCOptions {
+	return playback.TOCOptions{
+		Exclude:         o.TOCExclude,
+		ExcludePattern:  o.TOCExcludePattern,
+		CollapseRepeats: o.TOCCollapseRepeats,
+		PromptPattern:   o.PromptPattern,
+	}
+}
+
+// hasTOC reports whether the page gets a table of contents: not for a time
+// window (its offsets are into the whole recording), nor output-only (its
+// entries are what was typed), nor the final screen (its lines are gone).
+func (o ConvertOptions) hasTOC() bool {
+	return !o.hasTimeWindow() && !o.OutputOnly && !o.FinalScreen
+}
+
+// cleanContent cleans session content for the page with StripMetadata,
+// or replays it for its last screen with ConvertOptions.FinalScreen, then
+// drops the trailing prompt with ConvertOptions.TrimTrailingPrompt.
+func cleanContent(sessionLogPath, content string, altScreenLink func(int) string, o ConvertOptions) string {
+	if o.FinalScreen {
+		cols, rows := o.ScreenCols, o.ScreenRows
+		if meta, err := ReadSessionMeta(sessionLogPath); err == nil {
+			if cols == 0 {
+				cols = meta.Cols
+			}
+			if rows == 0 {
+				rows = meta.Rows
+			}
+		}
+		return trimTrailingPrompt(playback.FinalScreen(content, cols, rows, playback.StripOptions{Redact: o.Redact}), o)
+	}
+	return trimTrailingPrompt(playback.StripMetadata(content, playback.StripOptions{
+		KeepAltScreen: o.KeepAltScreen,
+		AltScreenLink: altScreenLink,
+		Redact:        o.Redact,
+	}), o)
+}
+
+// trimTrailingPrompt drops cleaned content's trailing bare prompt if
+// ConvertOptions.TrimTrailingPrompt is set.
+func trimTrailingPrompt(cleanedContent string, o ConvertOptions) string {
+	if !o.TrimTrailingPrompt {
+		return cleanedContent
+	}
+	return playback.TrimTrailingPrompt(cleanedContent, o.PromptPattern)
+}
+
+// ErrLooksBinary is returned when the cleaned session content looks like
+// binary data (e.g. `cat /bin/ls` was recorded) and ConvertOptions.SanitizeBinary
+// is not set. Such content would render as a broken page.
+var ErrLooksBinary = errors.New("session.log looks like binary output")
+
+// Errors the converters wrap, so callers can tell failures apart with
+// errors.Is.
+var (
+	// ErrSessionNotFound is returned when the session log to convert
+	// doesn't exist.
+	ErrSessionNotFound = errors.New("session.log not found")
+
+	// ErrEmptyAfterStrip is returned when nothing is left of the session
+	// log once its script header and footer are stripped and it is cleaned,
+	// e.g. a recording that was stopped straight away.
+	ErrEmptyAfterStrip = errors.New("session.log appears to be empty after metadata stripping")
+
+	// ErrRenderFailed is returned when the HTML can't be generated from the
+	// cleaned content.
+	ErrRenderFailed = errors.New("failed to generate HTML")
+)
+
+// checkBinary refuses or sanitizes cleaned content that looks binary.
+func checkBinary(cleanedContent string, o ConvertOptions) (string, error) {
+	if !session.LooksBinary(cleanedContent) {
+		return cleanedContent, nil
+	}
+	if !o.SanitizeBinary {
+		return "", ErrLooksBinary
+	}
+	return session.SanitizeBinary(cleanedContent), nil
+}
+
+// sourceHash returns the hex SHA-256 of the (uncompressed) session.log
+// content, recorded in the HTML for provenance.
+func sourceHash(sessionContent []byte) string {
+	sum := sha256.Sum256(sessionContent)
+	return hex.EncodeToString(sum[:])
+}
+
+// sessionOutput returns the session content to clean: all of it, or the
+// output written in a time window (ConvertOptions.Since/Until), without the
+// typed input's echo with ConvertOptions.OutputOnly, and from the first
+// command's line with ConvertOptions.TrimLeadingIdle. Any of them needs the
+// timing file alongside the log. Stderr output recorded separately (see
+// RecordOptions.SeparateStderr) is colored. Also returns how many lines
+// were trimmed from the start, counted as the TOC numbers lines (see
+// toc.FromCommands), by which TOC lines move up.
+func sessionOutput(sessionLogPath string, sessionContent []byte, o ConvertOptions) (string, int, error) {
+	if !o.hasTimeWindow() && !o.OutputOnly && !o.TrimLeadingIdle {
+		return markStderr(sessionLogPath, string(sessionContent)), 0, nil
+	}
+
+	timingFile, err := os.Open(o.timingPath(sessionLogPath))
+	if err != nil {
+		switch {
+		case o.OutputOnly:
+			return "", 0, fmt.Errorf("output-only conversion needs the timing file: %w", err)
+		case o.hasTimeWindow():
+			return "", 0, fmt.Errorf("a time window needs the timing file: %w", err)
+		default:
+			return "", 0, fmt.Errorf("trimming leading idle time needs the timing file: %w", err)
+		}
+	}
+	defer timingFile.Close()
+
+	entries, err := timing.Parse(timingFile)
+	if err != nil {
+		return "", 0, fmt.Errorf("cannot parse timing file: %w", err)
+	}
+
+	output := []byte(session.StripMetadataOnly(string(sessionContent)))
+	if o.OutputOnly {
+		output, entries = timing.OutputOnly(entries, output)
+	}
+	if !o.hasTimeWindow() {
+		trimmedLines := 0
+		if o.TrimLeadingIdle {
+			trimmed, offset := timing.TrimLeadingIdle(entries, output)
+			// Numbered as the TOC numbers the line the command is at
+			trimmedLines = toc.FromCommands([]timing.Command{{OutputByteOffset: offset}}, bytes.NewReader(output))[0].Line
+			output = trimmed
+		}
+		return string(output), trimmedLines, nil
+	}
+
+	slice := timing.SliceByTime(entries, output, o.Since, o.Until)
+	if len(slice) == 0 {
+		until := "the end"
+		if o.Until != 0 {
+			until = o.Until.String()
+		}
+		return "", 0, fmt.Errorf("no output recorded between %s and %s", o.Since, until)
+	}
+	return string(slice), 0, nil
+}
+
+// errorLines returns the error lines of cleaned content for the viewer's
+// n / p navigation if ConvertOptions.ErrorNav is set, otherwise nil.
+func errorLines(cleanedContent string, o ConvertOptions) []int {
+	if !o.ErrorNav {
+		return nil
+	}
+	return session.FindErrorLines(cleanedContent)
+}
+
+// typedInput returns what was typed, from the timing and input files
+// alongside the log, for the page's typed input panel if
+// ConvertOptions.ShowTyped is set, otherwise "". Like the table of
+// contents, the panel is left out if the files can't be read.
+func typedInput(sessionLogPath string, o ConvertOptions) string {
+	if !o.ShowTyped {
+		return ""
+	}
+	timingFile, err := os.Open(o.timingPath(sessionLogPath))
+	if err != nil {
+		return ""
+	}
+	defer timingFile.Close()
+	entries, err := timing.Parse(timingFile)
+	if err != nil {
+		return ""
+	}
+	input, err := logfile.ReadFile(o.inputPath(sessionLogPath))
+	if err != nil {
+		return ""
+	}
+	return playback.ReconstructInput(entries, input)
+}
+
+// lineTimes returns when each line of the page appeared, from the timing
+// file alongside the log, if ConvertOptions.LineTimes is set, otherwise
+// nil. Like the table of contents, they are left out if the timing file
+// can't be read or the page's lines aren't the recording's (see hasTOC),
+// and lines trimmed from the start are dropped.
+func lineTimes(sessionLogPath string, sessionContent []byte, trimmedLines int, o ConvertOptions) []float64 {
+	if !o.LineTimes || !o.hasTOC() {
+		return nil
+	}
+	timingFile, err := os.Open(o.timingPath(sessionLogPath))
+	if err != nil {
+		return nil
+	}
+	defer timingFile.Close()
+	entries, err := timing.Parse(timingFile)
+	if err != nil {
+		return nil
+	}
+	times := playback.LineTimes(entries, bytes.NewReader(sessionContent))
+	if trimmedLines >= len(times) {
+		return nil
+	}
+	return times[trimmedLines:]
+}
+
+// altScreenFile names the file the n-th stashed full-screen TUI session is
+// saved to (see ConvertOptions.StashAltScreen).
+func altScreenFile(n int) string {
+	return fmt.Sprintf("alt-screen-%d.log", n)
+}
+
+// stashAltScreen saves the full-screen TUI sessions in content to dir (see
+// ConvertOptions.StashAltScreen) and returns the StripOptions.AltScreenLink
+// to them, or nil if they aren't stashed.
+func stashAltScreen(content string, dir string, o ConvertOptions) (func(int) string, error) {
+	if !o.StashAltScreen || o.KeepAltScreen {
+		return nil, nil
+	}
+	for i, region := range playback.AltScreenRegions(content) {
+		if o.Redact {
+			region = session.RedactSecrets(region, nil)
+		}
+		if err := os.WriteFile(filepath.Join(dir, altScreenFile(i+1)), []byte(region), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write alternate screen content: %w", err)
+		}
+	}
+	return altScreenFile, nil
+}
+
+// convertOptions returns the first of opts, or the zero value.
+func convertOptions(opts []ConvertOptions) ConvertOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return ConvertOptions{}
+}
+
 // ConvertSessionToHTML reads a session.log file, strips metadata, and generates HTML output.
 //
 // This function:
@@ -22,11 +426,22 @@ import (
 // If timing and input files are found alongside the session log, a table-of-contents
 // is generated and embedded in the HTML for navigation.
 //
-// Returns the path to the generated HTML file, or error if any step fails
-func ConvertSessionToHTML(sessionLogPath string) (string, error) {
+// Content that looks like binary data fails with ErrLooksBinary unless
+// ConvertOptions.SanitizeBinary is set. With ConvertOptions.Since/Until, only
+// the output written in that window of the recording is converted.
+//
+// Returns the path to the generated HTML file, or error if any step fails:
+// ErrSessionNotFound, ErrEmptyAfterStrip and ErrRenderFailed are wrapped for
+// errors.Is.
+func ConvertSessionToHTML(sessionLogPath string, opts ...ConvertOptions) (string, error) {
+	o := convertOptions(opts)
+
 	// Validate input file exists
 	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("session.log not found: %s", sessionLogPath)
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
+	}
+	if err := checkCompanionFiles(o); err != nil {
+		return "", err
 	}
 
 	// Read session.log file (transparently handles .log.gz)
@@ -35,10 +450,24 @@ func ConvertSessionToHTML(sessionLogPath string) (string, error) {
 		return "", fmt.Errorf("cannot read session.log: %w", err)
 	}
 
+	content, trimmedLines, err := sessionOutput(sessionLogPath, sessionContent, o)
+	if err != nil {
+		return "", err
+	}
+
+	altScreenLink, err := stashAltScreen(content, filepath.Dir(sessionLogPath), o)
+	if err != nil {
+		return "", err
+	}
+
 	// Strip session metadata (Script started/done lines from `script` command)
-	cleanedContent := playback.StripMetadata(string(sessionContent))
+	cleanedContent := cleanContent(sessionLogPath, content, altScreenLink, o)
 	if cleanedContent == "" {
-		return "", fmt.Errorf("session.log appears to be empty after metadata stripping")
+		return "", ErrEmptyAfterStrip
+	}
+	cleanedContent, err = checkBinary(cleanedContent, o)
+	if err != nil {
+		return "", err
 	}
 
 	// Create playback frame with all content at timestamp 0.0 (static display)
@@ -49,33 +478,27 @@ func ConvertSessionToHTML(sessionLogPath string) (string, error) {
 		},
 	}
 
-	// Try to generate TOC from timing/input files
-	tocEntries := buildTOC(sessionLogPath, sessionContent)
-
-	// Generate HTML using xterm.js
-	opts := playback.Options{TOC: tocEntries}
-	htmlContent, err := playback.RenderHTML(frames, opts)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate HTML: %w", err)
-	}
-
 	// Determine output path (same as input but with .html extension)
 	outputPath := sessionLogPath + ".html"
 
-	// Write HTML to file
-	err = os.WriteFile(outputPath, []byte(htmlContent), 0644)
-	if err != nil {
-		return "", fmt.Errorf("failed to write HTML file: %w", err)
+	// Generate HTML using xterm.js, straight into the file
+	if err := writeHTMLFile(outputPath, frames, pageOptions(sessionLogPath, sessionContent, cleanedContent, trimmedLines, o)); err != nil {
+		return "", err
 	}
 
 	return outputPath, nil
 }
 
 // ConvertSessionToHTMLWithPath is like ConvertSessionToHTML but allows specifying output path
-func ConvertSessionToHTMLWithPath(sessionLogPath string, outputPath string) (string, error) {
+func ConvertSessionToHTMLWithPath(sessionLogPath string, outputPath string, opts ...ConvertOptions) (string, error) {
+	o := convertOptions(opts)
+
 	// Validate input file exists
 	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("session.log not found: %s", sessionLogPath)
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
+	}
+	if err := checkCompanionFiles(o); err != nil {
+		return "", err
 	}
 
 	// Resolve to absolute paths
@@ -95,10 +518,24 @@ func ConvertSessionToHTMLWithPath(sessionLogPath string, outputPath string) (str
 		return "", fmt.Errorf("cannot read session.log: %w", err)
 	}
 
+	content, trimmedLines, err := sessionOutput(sessionPath, sessionContent, o)
+	if err != nil {
+		return "", err
+	}
+
+	altScreenLink, err := stashAltScreen(content, filepath.Dir(outPath), o)
+	if err != nil {
+		return "", err
+	}
+
 	// Strip metadata
-	cleanedContent := playback.StripMetadata(string(sessionContent))
+	cleanedContent := cleanContent(sessionPath, content, altScreenLink, o)
 	if cleanedContent == "" {
-		return "", fmt.Errorf("session.log appears to be empty after metadata stripping")
+		return "", ErrEmptyAfterStrip
+	}
+	cleanedContent, err = checkBinary(cleanedContent, o)
+	if err != nil {
+		return "", err
 	}
 
 	// Create playback frame
@@ -109,23 +546,83 @@ func ConvertSessionToHTMLWithPath(sessionLogPath string, outputPath string) (str
 		},
 	}
 
-	// Try to generate TOC from timing/input files
-	tocEntries := buildTOC(sessionPath, sessionContent)
-
 	// Generate HTML
-	opts := playback.Options{TOC: tocEntries}
-	htmlContent, err := playback.RenderHTML(frames, opts)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate HTML: %w", err)
+	if err := writeHTMLFile(outPath, frames, pageOptions(sessionPath, sessionContent, cleanedContent, trimmedLines, o)); err != nil {
+		return "", err
 	}
 
-	// Write HTML
-	err = os.WriteFile(outPath, []byte(htmlContent), 0644)
+	return outPath, nil
+}
+
+// writeHTMLFile renders frames to a page at path as it is generated (see
+// playback.RenderHTMLTo), so a large recording's page isn't held in memory
+// whole. The page is written to a temporary file next to path, which
+// replaces path once it's complete, so a page already there is kept if
+// this one fails. Returns ErrRenderFailed (wrapped for errors.Is) if
+// rendering failed.
+func writeHTMLFile(path string, frames []playback.Frame, opts playback.Options) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
 	if err != nil {
-		return "", fmt.Errorf("failed to write HTML file: %w", err)
+		return fmt.Errorf("failed to write HTML file: %w", err)
 	}
+	w := bufio.NewWriter(f)
+	err = playback.RenderHTMLTo(w, frames, opts)
+	// The buffer keeps the file's first write error, telling a failed
+	// write apart from a failed render
+	if flushErr := w.Flush(); flushErr != nil {
+		err = fmt.Errorf("failed to write HTML file: %w", flushErr)
+	} else if err != nil {
+		err = fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	// CreateTemp makes it private: give it the mode os.WriteFile would
+	if chmodErr := f.Chmod(0644); err == nil && chmodErr != nil {
+		err = fmt.Errorf("failed to write HTML file: %w", chmodErr)
+	}
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write HTML file: %w", closeErr)
+	}
+	if err == nil {
+		if renameErr := os.Rename(f.Name(), path); renameErr != nil {
+			err = fmt.Errorf("failed to write HTML file: %w", renameErr)
+		}
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
 
-	return outPath, nil
+// pageOptions returns the options ConvertSessionToHTML renders a session
+// log's page with: its heading, table of contents (see hasTOC) and the
+// extras ConvertOptions turn on. cleanedContent is what the page shows.
+func pageOptions(sessionLogPath string, sessionContent []byte, cleanedContent string, trimmedLines int, o ConvertOptions) playback.Options {
+	// Try to generate TOC from timing/input files
+	var tocEntries []playback.TOCEntry
+	if o.hasTOC() {
+		tocEntries = buildTOC(sessionLogPath, sessionContent, trimmedLines, o)
+	}
+
+	cwd, shell := recordedContext(sessionLogPath, o)
+	command := session.ExtractCommand(string(sessionContent))
+	return playback.Options{
+		Title:      o.redact(expandTitle(o.Title, sessionContent)),
+		FooterLink: o.FooterLink,
+		Command:    o.redact(command),
+		Cwd:        cwd,
+		Shell:      shell,
+		TOC:        o.redactTOC(tocEntries),
+		Cols:       recordedCols(sessionLogPath),
+		SourceHash: sourceHash(sessionContent),
+		ColorMode:  o.ColorMode,
+		Version:    o.Version,
+		ErrorLines: errorLines(cleanedContent, o),
+		TypedInput: o.redact(typedInput(sessionLogPath, o)),
+		LineTimes:  lineTimes(sessionLogPath, sessionContent, trimmedLines, o),
+
+		OpenGraph:            o.OpenGraph,
+		OpenGraphDescription: o.redact(shareDescription(sessionContent)),
+		OpenGraphImage:       o.OpenGraphImage,
+	}
 }
 
 // ConvertSessionToStreamingHTML generates streaming HTML that fetches session data via JavaScript.
@@ -137,26 +634,30 @@ func ConvertSessionToHTMLWithPath(sessionLogPath string, outputPath string) (str
 func ConvertSessionToStreamingHTML(sessionLogPath string, maxRows uint32) (string, error) {
 	// Validate input file exists
 	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("session.log not found: %s", sessionLogPath)
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
 	}
 
 	// Try to generate TOC from timing/input files
 	var tocEntries []playback.TOCEntry
+	var command string
 	sessionContent, err := logfile.ReadFile(sessionLogPath)
 	if err == nil {
-		tocEntries = buildTOC(sessionLogPath, sessionContent)
+		tocEntries = buildTOC(sessionLogPath, sessionContent, 0, ConvertOptions{})
+		command = session.ExtractCommand(string(sessionContent))
 	}
 
 	// Generate streaming HTML that references the log file
 	logFileName := filepath.Base(sessionLogPath)
 	htmlContent, err := playback.RenderStreamingHTML(playback.StreamingOptions{
 		Title:   logFileName,
+		Command: command,
 		DataURL: "./" + logFileName,
+		Cols:    recordedCols(sessionLogPath),
 		MaxRows: maxRows,
 		TOC:     tocEntries,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to generate streaming HTML: %w", err)
+		return "", fmt.Errorf("%w: streaming: %w", ErrRenderFailed, err)
 	}
 
 	// Output path: session.log.streaming.html
@@ -171,26 +672,96 @@ func ConvertSessionToStreamingHTML(sessionLogPath string, maxRows uint32) (strin
 	return outputPath, nil
 }
 
+// ConversionReport summarizes what converting a session log would do.
+type ConversionReport struct {
+	BytesIn     int                   // Raw session.log size (after gzip decompression)
+	BytesOut    int                   // Content size after stripping and neutralizing
+	Cleaning    session.CleaningStats // Separators inserted and regions discarded
+	TOCCommands int                   // Commands detected from timing/input files, or shell prompts
+	LooksBinary bool                  // Cleaned content looks like binary data (see ErrLooksBinary)
+}
+
+// DryRunConversion runs the cleaning pipeline on a session log and reports
+// what ConvertSessionToHTML would do, without writing any files.
+func DryRunConversion(sessionLogPath string, opts ...ConvertOptions) (*ConversionReport, error) {
+	o := convertOptions(opts)
+
+	// Validate input file exists
+	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
+	}
+	if err := checkCompanionFiles(o); err != nil {
+		return nil, err
+	}
+
+	// Read session.log file (transparently handles .log.gz)
+	sessionContent, err := logfile.ReadFile(sessionLogPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read session.log: %w", err)
+	}
+
+	content, trimmedLines, err := sessionOutput(sessionLogPath, sessionContent, o)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same pipeline as playback.StripMetadata, keeping the stats
+	cleanedContent, stats := session.StripMetadataWithStats(content, session.CleanOptions{
+		KeepAltScreen: o.KeepAltScreen,
+		Redact:        o.Redact,
+	})
+
+	report := &ConversionReport{
+		BytesIn:     len(sessionContent),
+		BytesOut:    len(cleanedContent),
+		Cleaning:    stats,
+		LooksBinary: session.LooksBinary(cleanedContent),
+	}
+	if o.hasTOC() {
+		report.TOCCommands = len(buildTOC(sessionLogPath, sessionContent, trimmedLines, o))
+	}
+	return report, nil
+}
+
 // buildTOC attempts to build TOC entries from timing and input files alongside the session log.
-// Returns nil if timing or input files are not found or cannot be parsed.
+// Returns nil if they cannot be parsed. If either is not found, entries are
+// guessed from shell prompts in the output (see playback.BuildTOCFromPrompts).
 //
 // Expected file naming convention:
 //   - session.log      → session.timing, session.input
 //   - session-UUID.log → session-UUID.timing, session-UUID.input
-func buildTOC(sessionLogPath string, sessionContent []byte) []playback.TOCEntry {
-	timingPath := logfile.CompanionPath(sessionLogPath, ".timing")
-	inputPath := logfile.CompanionPath(sessionLogPath, ".input")
+//
+// Entries move up by trimmedLines, the lines trimmed from the start of the
+// content (see ConvertOptions.TrimLeadingIdle), and those on the trimmed
+// lines are dropped. o picks the commands left
+// out (see ConvertOptions.TOCExclude), and with TimingPath and InputPath
+// the files used instead.
+func buildTOC(sessionLogPath string, sessionContent []byte, trimmedLines int, o ConvertOptions) []playback.TOCEntry {
+	tocOpts := o.tocOptions()
+	timingPath := o.timingPath(sessionLogPath)
+	inputPath := o.inputPath(sessionLogPath)
 
+	var entries []playback.TOCEntry
 	timingFile, err := os.Open(timingPath)
-	if err != nil {
-		return nil
+	if err == nil {
+		defer timingFile.Close()
 	}
-	defer timingFile.Close()
-
-	inputBytes, err := os.ReadFile(inputPath)
-	if err != nil {
-		return nil
+	inputBytes, inputErr := os.ReadFile(inputPath)
+	if err != nil || inputErr != nil {
+		// Without typed commands, fall back to lines starting with a prompt
+		entries = playback.BuildTOCFromPrompts(string(sessionContent), tocOpts)
+	} else {
+		entries = playback.BuildTOC(timingFile, inputBytes, bytes.NewReader(sessionContent), tocOpts)
 	}
-
-	return playback.BuildTOC(timingFile, inputBytes, bytes.NewReader(sessionContent))
+	if trimmedLines == 0 {
+		return entries
+	}
+	var kept []playback.TOCEntry
+	for _, e := range entries {
+		if e.Line >= trimmedLines {
+			e.Line -= trimmedLines
+			kept = append(kept, e)
+		}
+	}
+	return kept
 }
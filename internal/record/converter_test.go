@@ -1,12 +1,20 @@
 package record
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/choonkeat/record-tui/internal/logfile"
+	"github.com/choonkeat/record-tui/playback"
 )
 
 // TestConvertSessionToHTML_WithSimpleSession tests conversion of a simple recorded session
@@ -81,8 +89,28 @@ func TestConvertSessionToHTML_FileNotFound(t *testing.T) {
 		t.Errorf("Expected error for non-existent file, got none")
 	}
 
-	if !strings.Contains(err.Error(), "not found") {
-		t.Errorf("Error message should mention 'not found', got: %v", err)
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), nonexistentPath) {
+		t.Errorf("Error message should name the missing file, got: %v", err)
+	}
+}
+
+func TestConvertSessionToHTML_EmptyAfterStrip(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"\nScript done on 2026-01-12 06:41:44+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+
+	if _, err := ConvertSessionToHTML(sessionLogPath); !errors.Is(err, ErrEmptyAfterStrip) {
+		t.Errorf("ConvertSessionToHTML: expected ErrEmptyAfterStrip, got %v", err)
+	}
+	outPath := filepath.Join(t.TempDir(), "out.html")
+	if _, err := ConvertSessionToHTMLWithPath(sessionLogPath, outPath); !errors.Is(err, ErrEmptyAfterStrip) {
+		t.Errorf("ConvertSessionToHTMLWithPath: expected ErrEmptyAfterStrip, got %v", err)
 	}
 }
 
@@ -127,6 +155,55 @@ Script done on Wed Dec 31 12:11:00 2025
 	t.Logf("✓ Generated HTML file: %d bytes", fileInfo.Size())
 }
 
+func TestConvertSessionToHTML_EmbedsSourceHash(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	sessionContent := "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n$ ls\nfile.txt\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create test session.log: %v", err)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("Cannot read HTML file: %v", err)
+	}
+
+	// sha256sum of the session.log bytes
+	sum := sha256.Sum256([]byte(sessionContent))
+	want := `<meta name="record-tui:source-sha256" content="` + hex.EncodeToString(sum[:]) + `">`
+	if !strings.Contains(string(htmlBytes), want) {
+		t.Errorf("HTML should contain %s", want)
+	}
+}
+
+func TestConvertSessionToHTML_TitleAndFooterLink(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make test\"]\nok\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create test session.log: %v", err)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, ConvertOptions{
+		Title:      "CI: {command}",
+		FooterLink: playback.FooterLink{Text: "my-team", URL: "https://example.com/team"},
+	})
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("Cannot read HTML file: %v", err)
+	}
+	for _, want := range []string{"<title>CI: make test</title>", "https://example.com/team", "my-team"} {
+		if !strings.Contains(string(htmlBytes), want) {
+			t.Errorf("HTML should contain %q", want)
+		}
+	}
+}
+
 // TestConvertSessionToHTMLWithPath_CustomOutputPath tests specifying custom output path
 func TestConvertSessionToHTMLWithPath_CustomOutputPath(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -280,6 +357,329 @@ func TestConvertSessionToHTML_WithTimingAndInput(t *testing.T) {
 	if !strings.Contains(htmlString, `"npm test"`) {
 		t.Error("HTML should contain 'npm test' in navigation data")
 	}
+	if !strings.Contains(htmlString, `Recording of: <code>bash</code>`) {
+		t.Error("HTML should show the recorded command")
+	}
+}
+
+func TestConvertSessionToHTML_ShowTyped(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	files := map[string]string{
+		"session.log": "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+			"$ ls\nfile1\n$ ^C\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n",
+		"session.timing": "O 0.010 2\nI 0.500 3\nO 0.010 12\nI 1.000 1\nO 0.010 3\n",
+		"session.input":  "ls\r\x03",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, ConvertOptions{ShowTyped: true})
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("Failed to read HTML: %v", err)
+	}
+	if !strings.Contains(string(htmlBytes), "<pre>ls&lt;Enter&gt;\n^C</pre>") {
+		t.Error("HTML should show the typed input with keys annotated")
+	}
+
+	// Off by default
+	htmlPath, _ = ConvertSessionToHTML(sessionLogPath)
+	htmlBytes, _ = os.ReadFile(htmlPath)
+	if strings.Contains(string(htmlBytes), `<details id="typed-input">`) {
+		t.Error("typed input panel should be opt-in")
+	}
+}
+
+func TestConvertSessionToHTML_RedactsPageText(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	bearer := "abcdefghijklmnop123"
+	ghToken := "ghp_" + strings.Repeat("a1B2", 9)
+	cmd1 := "curl -H 'Authorization: Bearer " + bearer + "' example.com"
+	cmd2 := "export GITHUB_TOKEN=" + ghToken
+	output := "$ " + cmd1 + "\r\nok\r\n$ " + cmd2 + "\r\n"
+	files := map[string]string{
+		"session.log": "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"" + cmd2 + "\"]\n" +
+			output + "Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n",
+		"session.timing": fmt.Sprintf("O 0.010 2\nI 0.500 %d\nO 0.010 %d\nI 1.000 %d\nO 0.010 %d\n",
+			len(cmd1)+1, len(cmd1)+2+4+2, len(cmd2)+1, len(cmd2)+2),
+		"session.input": cmd1 + "\r" + cmd2 + "\r",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	o := ConvertOptions{Redact: true, ShowTyped: true, OpenGraph: true, Title: "{command}"}
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, o)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ := os.ReadFile(htmlPath)
+	page := string(htmlBytes)
+	if !strings.Contains(page, "curl -H") {
+		t.Fatal("HTML should have the commands in its navigation")
+	}
+	for _, secret := range []string{bearer, ghToken} {
+		if strings.Contains(page, secret) {
+			t.Errorf("HTML contains secret %q", secret)
+		}
+	}
+
+	// Without Redact the labels are as typed
+	htmlPath, _ = ConvertSessionToHTML(sessionLogPath)
+	htmlBytes, _ = os.ReadFile(htmlPath)
+	if !strings.Contains(string(htmlBytes), bearer) {
+		t.Error("navigation should keep the command as typed without Redact")
+	}
+}
+
+// TestConvertSessionToHTML_TimeWindow tests converting only part of a recording
+func TestConvertSessionToHTML_LineTimes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "$ ls\nfile1\n$ npm test\nPASS\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+	timingContent := "O 0.500 5\nI 1.000 3\nO 0.500 6\nI 2.000 9\nO 0.250 16\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "session.timing"), []byte(timingContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.timing: %v", err)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, ConvertOptions{LineTimes: true})
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ := os.ReadFile(htmlPath)
+	if !strings.Contains(string(htmlBytes), `<script type="application/json" id="line-times">[0.5,2,4.25,4.25]</script>`) {
+		t.Error("HTML should embed when each line appeared")
+	}
+
+	// Off by default
+	htmlPath, _ = ConvertSessionToHTML(sessionLogPath)
+	htmlBytes, _ = os.ReadFile(htmlPath)
+	if strings.Contains(string(htmlBytes), `id="line-times"`) {
+		t.Error("no line times expected without ConvertOptions.LineTimes")
+	}
+}
+
+func TestConvertSessionToHTML_TimeWindow(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ ls\nfile1\nfile2\n$ npm test\nPASS\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+	timingContent := "O 0.010 5\nI 60.000 3\nO 0.010 12\nI 60.000 9\nO 0.010 16\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "session.timing"), []byte(timingContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.timing: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "session.input"), []byte("ls\rnpm test\r"), 0644); err != nil {
+		t.Fatalf("Failed to create session.input: %v", err)
+	}
+
+	o := ConvertOptions{Since: time.Minute, Until: 2 * time.Minute}
+	content, _, err := sessionOutput(sessionLogPath, []byte(sessionContent), o)
+	if err != nil {
+		t.Fatalf("sessionOutput failed: %v", err)
+	}
+	if want := "file1\nfile2\n"; content != want {
+		t.Errorf("sliced content = %q, want %q", content, want)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, o)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ := os.ReadFile(htmlPath)
+	if strings.Contains(string(htmlBytes), `id="nav-indicator"`) {
+		t.Error("a time window should have no navigation (offsets are into the whole recording)")
+	}
+
+	// A window with nothing in it is an error, not an empty page
+	_, err = ConvertSessionToHTML(sessionLogPath, ConvertOptions{Since: time.Hour})
+	if err == nil || !strings.Contains(err.Error(), "no output recorded") {
+		t.Errorf("expected no-output error, got %v", err)
+	}
+
+	// Without a timing file there is no way to slice
+	os.Remove(filepath.Join(tmpDir, "session.timing"))
+	if _, err := ConvertSessionToHTML(sessionLogPath, o); err == nil || !strings.Contains(err.Error(), "timing file") {
+		t.Errorf("expected timing file error, got %v", err)
+	}
+}
+
+func TestConvertSessionToHTML_OutputOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ cat ~/secret-plans.txt\r\nhello\r\n$ \n" +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	files := map[string]string{
+		"session.log":    sessionContent,
+		"session.timing": "O 0.1 2\nI 1.0 23\nO 0.0 24\nO 0.1 7\nO 0.1 2\n",
+		"session.input":  "cat ~/secret-plans.txt\r",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	o := ConvertOptions{OutputOnly: true}
+	content, _, err := sessionOutput(sessionLogPath, []byte(sessionContent), o)
+	if err != nil {
+		t.Fatalf("sessionOutput failed: %v", err)
+	}
+	if want := "$ \r\nhello\r\n$ "; content != want {
+		t.Errorf("output-only content = %q, want %q", content, want)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, o)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ := os.ReadFile(htmlPath)
+	if strings.Contains(string(htmlBytes), `id="nav-indicator"`) {
+		t.Error("an output-only page should have no navigation (it lists what was typed)")
+	}
+
+	if _, err := ConvertSessionToPagedHTML(sessionLogPath, "", 1, o); err == nil {
+		t.Error("paged conversion should refuse output-only")
+	}
+
+	os.Remove(filepath.Join(tmpDir, "session.timing"))
+	if _, err := ConvertSessionToHTML(sessionLogPath, o); err == nil || !strings.Contains(err.Error(), "timing file") {
+		t.Errorf("expected timing file error, got %v", err)
+	}
+}
+
+func TestConvertSessionToHTML_TrimLeadingIdle(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	// No header, so the timing file's offsets are the TOC's too. The banner
+	// looks like a prompt
+	sessionContent := "$ motd\r\nWelcome!\r\n$ ls\r\na.txt\r\n$ \n" +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	files := map[string]string{
+		"session.log":    sessionContent,
+		"session.timing": "O 0.1 8\nO 0.1 10\nO 30.0 2\nI 1.0 3\nO 0.0 4\nO 0.1 7\nO 0.1 2\n",
+		"session.input":  "ls\r",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	o := ConvertOptions{TrimLeadingIdle: true}
+	content, trimmedLines, err := sessionOutput(sessionLogPath, []byte(sessionContent), o)
+	if err != nil {
+		t.Fatalf("sessionOutput failed: %v", err)
+	}
+	if want := "$ ls\r\na.txt\r\n$ "; content != want {
+		t.Errorf("trimmed content = %q, want %q", content, want)
+	}
+	if trimmedLines != 2 {
+		t.Errorf("trimmedLines = %d, want 2", trimmedLines)
+	}
+
+	// The TOC points into the trimmed content
+	toc := buildTOC(sessionLogPath, []byte(sessionContent), trimmedLines, ConvertOptions{})
+	if len(toc) != 1 || toc[0].Label != "ls" || toc[0].Line != 0 {
+		t.Errorf("TOC = %+v, want ls on line 0", toc)
+	}
+	if toc := buildTOC(sessionLogPath, []byte(sessionContent), trimmedLines, ConvertOptions{TOCExclude: playback.DefaultNoiseCommands}); toc != nil {
+		t.Errorf("TOC = %+v, want ls left out", toc)
+	}
+	// Prompts guessed on the trimmed lines are dropped, not moved to the top
+	os.Remove(filepath.Join(tmpDir, "session.input"))
+	toc = buildTOC(sessionLogPath, []byte(sessionContent), trimmedLines, ConvertOptions{})
+	if len(toc) != 1 || toc[0].Label != "ls" || toc[0].Line != 0 {
+		t.Errorf("prompt TOC = %+v, want ls on line 0", toc)
+	}
+
+	// Trimmed lines are counted as the TOC counts them: an index (ESC D)
+	// moves down a line too
+	indexedLogPath := filepath.Join(tmpDir, "indexed.log")
+	indexedContent := "Welcome!\x1bD\r\n$ ls\r\na.txt\r\n$ \n"
+	for name, data := range map[string]string{
+		"indexed.log":    indexedContent,
+		"indexed.timing": "O 0.1 12\nO 30.0 2\nI 1.0 3\nO 0.0 4\nO 0.1 7\nO 0.1 2\n",
+		"indexed.input":  "ls\r",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if _, trimmedLines, err := sessionOutput(indexedLogPath, []byte(indexedContent), o); err != nil || trimmedLines != 2 {
+		t.Errorf("indexed: trimmedLines = %d, %v, want 2", trimmedLines, err)
+	} else if toc := buildTOC(indexedLogPath, []byte(indexedContent), trimmedLines, ConvertOptions{}); len(toc) != 1 || toc[0].Line != 0 {
+		t.Errorf("indexed: TOC = %+v, want ls on line 0", toc)
+	}
+
+	if _, err := ConvertSessionToHTML(sessionLogPath, o); err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+
+	os.Remove(filepath.Join(tmpDir, "session.timing"))
+	if _, err := ConvertSessionToHTML(sessionLogPath, o); err == nil || !strings.Contains(err.Error(), "timing file") {
+		t.Errorf("expected timing file error, got %v", err)
+	}
+}
+
+func TestConvertSessionToHTML_StashAltScreen(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	vim := "\x1b[?1049h\x1b[H\x1b[2Jtoken=Zx9Qw7Lm2Rt5Yp8Kd3Hs6Vb1\x1b[?1049l"
+	sessionContent := "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n$ vim notes\n" + vim +
+		"$ echo done\ndone\nScript done on Wed Dec 31 12:11:00 2025\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	os.Mkdir(outDir, 0755)
+	_, err := ConvertSessionToHTMLWithPath(sessionLogPath, filepath.Join(outDir, "page.html"), ConvertOptions{StashAltScreen: true, Redact: true})
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTMLWithPath failed: %v", err)
+	}
+
+	// Stashed next to the output, redacted like the page
+	stashed, err := os.ReadFile(filepath.Join(outDir, "alt-screen-1.log"))
+	if err != nil {
+		t.Fatalf("alt-screen-1.log not written: %v", err)
+	}
+	if want := "\x1b[?1049h\x1b[H\x1b[2Jtoken=[REDACTED]\x1b[?1049l"; string(stashed) != want {
+		t.Errorf("alt-screen-1.log = %q, want %q", stashed, want)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "alt-screen-2.log")); err == nil {
+		t.Error("only one alternate screen region should be stashed")
+	}
+
+	// Nothing stashed when the TUI frame is kept instead
+	if _, err := ConvertSessionToHTML(sessionLogPath, ConvertOptions{StashAltScreen: true, KeepAltScreen: true}); err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "alt-screen-1.log")); err == nil {
+		t.Error("alt-screen-1.log should not be written with KeepAltScreen")
+	}
+
+	if _, err := ConvertSessionToPagedHTML(sessionLogPath, "", 1, ConvertOptions{StashAltScreen: true}); err == nil {
+		t.Error("paged conversion should refuse StashAltScreen")
+	}
 }
 
 // TestConvertSessionToHTML_WithoutTimingFiles tests graceful degradation when no timing files exist
@@ -308,6 +708,63 @@ func TestConvertSessionToHTML_WithoutTimingFiles(t *testing.T) {
 }
 
 // TestCompanionPath tests the path derivation helper (moved to internal/logfile)
+func TestConvertSessionToHTML_ExplicitTimingAndInput(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "> ls\nfile1\n> npm test\nPASS\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+
+	// Named as another tool might, in another directory
+	otherDir := t.TempDir()
+	timingPath := filepath.Join(otherDir, "out.tm")
+	if err := os.WriteFile(timingPath, []byte("O 0.010 2\nI 0.500 3\nO 0.010 10\nI 1.000 9\nO 0.010 5\n"), 0644); err != nil {
+		t.Fatalf("Failed to create timing file: %v", err)
+	}
+	inputPath := filepath.Join(otherDir, "keys.txt")
+	if err := os.WriteFile(inputPath, []byte("ls\rnpm test\r"), 0644); err != nil {
+		t.Fatalf("Failed to create input file: %v", err)
+	}
+
+	o := ConvertOptions{TimingPath: timingPath, InputPath: inputPath}
+	toc := buildTOC(sessionLogPath, []byte(sessionContent), 0, o)
+	if len(toc) != 2 || toc[0].Label != "ls" || toc[1].Label != "npm test" {
+		t.Errorf("TOC = %+v, want ls and npm test from the given files", toc)
+	}
+	if toc := buildTOC(sessionLogPath, []byte(sessionContent), 0, ConvertOptions{}); toc != nil {
+		t.Errorf("TOC = %+v, want none without the files", toc)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, o)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ := os.ReadFile(htmlPath)
+	if !strings.Contains(string(htmlBytes), `"npm test"`) {
+		t.Error("HTML should contain 'npm test' in navigation data")
+	}
+
+	// A missing file fails, naming which one
+	missing := filepath.Join(otherDir, "nope.input")
+	for _, o := range []ConvertOptions{{TimingPath: missing}, {TimingPath: timingPath, InputPath: missing}} {
+		want := "cannot read timing file"
+		if o.InputPath == missing {
+			want = "cannot read input file"
+		}
+		_, err := ConvertSessionToHTML(sessionLogPath, o)
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), missing) {
+			t.Errorf("got %v, want %q naming %s", err, want, missing)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("got %v, want os.ErrNotExist", err)
+		}
+	}
+	if _, err := DryRunConversion(sessionLogPath, ConvertOptions{InputPath: missing}); err == nil {
+		t.Error("dry run should fail on a missing input file")
+	}
+}
+
 func TestCompanionPath(t *testing.T) {
 	tests := []struct {
 		logPath  string
@@ -329,3 +786,216 @@ func TestCompanionPath(t *testing.T) {
 		}
 	}
 }
+
+// TestDryRunConversion_ReportsWithoutWriting verifies the dry-run report and that no HTML is written
+func TestDryRunConversion_ReportsWithoutWriting(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\nfirst\x1b[2Jsecond\nScript done on Wed Dec 31 12:11:00 2025\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create test session.log: %v", err)
+	}
+
+	report, err := DryRunConversion(sessionLogPath)
+	if err != nil {
+		t.Fatalf("DryRunConversion failed: %v", err)
+	}
+
+	if report.BytesIn != len(sessionContent) {
+		t.Errorf("BytesIn = %d, want %d", report.BytesIn, len(sessionContent))
+	}
+	if report.BytesOut == 0 || report.BytesOut >= report.BytesIn {
+		t.Errorf("BytesOut = %d, want between 0 and %d", report.BytesOut, report.BytesIn)
+	}
+	if report.Cleaning.ClearSeparators != 1 {
+		t.Errorf("ClearSeparators = %d, want 1", report.Cleaning.ClearSeparators)
+	}
+	if report.TOCCommands != 0 {
+		t.Errorf("TOCCommands = %d, want 0 (no timing file)", report.TOCCommands)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Dry run should not write files, found %d entries", len(entries))
+	}
+}
+
+// TestDryRunConversion_FileNotFound tests error handling for missing session.log
+func TestDryRunConversion_FileNotFound(t *testing.T) {
+	_, err := DryRunConversion("/tmp/nonexistent-session-" + t.Name() + ".log")
+	if !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound, got: %v", err)
+	}
+}
+
+func TestConvertSessionToHTML_LooksBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+
+	garbage := strings.Repeat("\x7fELF\x02\x01\x01\x00\x00\x00\xff\xfe\x03\x00>\x00", 200)
+	sessionContent := "Script started on Wed Dec 31 12:10:34 2025\nCommand: cat /bin/ls\n" +
+		garbage + "\nScript done on Wed Dec 31 12:11:22 2025\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to write session.log: %v", err)
+	}
+
+	// Refused by default, without writing a broken page
+	if _, err := ConvertSessionToHTML(sessionLogPath); !errors.Is(err, ErrLooksBinary) {
+		t.Fatalf("expected ErrLooksBinary, got %v", err)
+	}
+	if _, err := os.Stat(sessionLogPath + ".html"); !os.IsNotExist(err) {
+		t.Error("no HTML should be written for binary content")
+	}
+
+	report, err := DryRunConversion(sessionLogPath)
+	if err != nil {
+		t.Fatalf("DryRunConversion failed: %v", err)
+	}
+	if !report.LooksBinary {
+		t.Error("dry-run report should flag binary content")
+	}
+
+	// Sanitized on request
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, ConvertOptions{SanitizeBinary: true})
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML with SanitizeBinary failed: %v", err)
+	}
+	if _, err := os.Stat(htmlPath); err != nil {
+		t.Errorf("expected HTML at %s: %v", htmlPath, err)
+	}
+}
+
+func TestConvertSessionToHTML_FinalScreen(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"compiling main.go\r\ncompiling util.go\r\n\x1b[H\x1b[2JBuild OK\r\n2 files\r\n" +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+
+	o := ConvertOptions{FinalScreen: true, ScreenCols: 40, ScreenRows: 5}
+	if _, err := ConvertSessionToHTML(sessionLogPath, o); err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+
+	// Output cleared before the final screen is dropped
+	txtPath, err := ConvertSessionToText(sessionLogPath, o)
+	if err != nil {
+		t.Fatalf("ConvertSessionToText failed: %v", err)
+	}
+	txt, _ := os.ReadFile(txtPath)
+	if want := "Build OK\n2 files\n"; string(txt) != want {
+		t.Errorf("text = %q, want %q", txt, want)
+	}
+
+	if _, err := ConvertSessionToPagedHTML(sessionLogPath, "", 1, o); err == nil {
+		t.Error("paged conversion should refuse FinalScreen")
+	}
+}
+
+func TestConvertSessionToHTML_TrimTrailingPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ make\r\nBuild OK\r\n$ \n" +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+
+	txtPath, err := ConvertSessionToText(sessionLogPath, ConvertOptions{TrimTrailingPrompt: true})
+	if err != nil {
+		t.Fatalf("ConvertSessionToText failed: %v", err)
+	}
+	txt, _ := os.ReadFile(txtPath)
+	if want := "$ make\nBuild OK\n"; string(txt) != want {
+		t.Errorf("text = %q, want %q", txt, want)
+	}
+
+	txtPath, err = ConvertSessionToText(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ConvertSessionToText failed: %v", err)
+	}
+	txt, _ = os.ReadFile(txtPath)
+	if want := "$ make\nBuild OK\n$ \n"; string(txt) != want {
+		t.Errorf("text = %q, want the prompt kept by default", txt)
+	}
+}
+
+func TestBuildTOC_PromptFallback(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ go build\r\n$ go test ./...\r\nok\r\n$ \n" +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+
+	// No timing or input file: commands come from the prompts
+	toc := buildTOC(sessionLogPath, []byte(sessionContent), 0, ConvertOptions{})
+	if len(toc) != 2 || toc[0].Label != "go build" || toc[1].Label != "go test ./..." || toc[1].Line != 1 {
+		t.Errorf("TOC = %+v, want go build and go test ./... from the prompts", toc)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ := os.ReadFile(htmlPath)
+	if !strings.Contains(string(htmlBytes), `id="nav-indicator"`) {
+		t.Error("HTML should have navigation from the prompts")
+	}
+}
+
+func TestBuildTOC_PromptPattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "➜  ~ git status\r\nclean\r\n➜  ~ "
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+
+	if toc := buildTOC(sessionLogPath, []byte(sessionContent), 0, ConvertOptions{}); toc != nil {
+		t.Errorf("TOC = %+v, want none with the default prompts", toc)
+	}
+	o := ConvertOptions{PromptPattern: regexp.MustCompile(`^➜ +\S+ `)}
+	toc := buildTOC(sessionLogPath, []byte(sessionContent), 0, o)
+	if len(toc) != 1 || toc[0].Label != "git status" || toc[0].Line != 0 {
+		t.Errorf("TOC = %+v, want git status on line 0", toc)
+	}
+}
+
+func TestWriteHTMLFile_KeepsPageOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.html")
+	if err := os.WriteFile(path, []byte("previous page"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A NaN timestamp can't be encoded, so rendering fails part way
+	err := writeHTMLFile(path, []playback.Frame{{Timestamp: math.NaN(), Content: "x"}}, playback.Options{})
+	if !errors.Is(err, ErrRenderFailed) {
+		t.Fatalf("writeHTMLFile = %v, want ErrRenderFailed", err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "previous page" {
+		t.Errorf("page = %q, want the previous one kept", content)
+	}
+
+	if err := writeHTMLFile(path, []playback.Frame{{Content: "hello"}}, playback.Options{}); err != nil {
+		t.Fatalf("writeHTMLFile: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("directory has %d files, want only the page", len(entries))
+	}
+}
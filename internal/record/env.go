@@ -0,0 +1,119 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/choonkeat/record-tui/internal/logfile"
+)
+
+// SessionEnv is the context a recording was made in, written to session.env
+// (JSON) next to session.log at record time, for reproducing it later.
+// Only allowlisted environment variables are kept (see CaptureSessionEnv):
+// the full environment is never written, as it often holds secrets.
+type SessionEnv struct {
+	Cwd     string            `json:"cwd"`           // Working directory ("" if unknown)
+	Shell   string            `json:"shell"`         // $SHELL, the shell script runs by default
+	Command string            `json:"command"`       // Recorded command ("" for the default shell)
+	Env     map[string]string `json:"env,omitempty"` // Allowlisted environment variables that were set
+}
+
+// DefaultEnvAllowlist is the environment variables recorded when
+// RecordOptions.EnvAllowlist is nil: terminal and locale settings that
+// affect how output looks, none of them secret.
+var DefaultEnvAllowlist = []string{
+	"TERM", "COLORTERM", "LANG", "LC_ALL", "LC_CTYPE", "TZ", "EDITOR", "PAGER",
+}
+
+// secretNameParts are substrings of environment variable names that are
+// never recorded, even when allowlisted.
+var secretNameParts = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH", "SESSION", "COOKIE"}
+
+// looksSecret reports whether an environment variable name suggests its
+// value is a secret, e.g. GITHUB_TOKEN or AWS_SECRET_ACCESS_KEY.
+func looksSecret(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(upper, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvPath returns the session.env path for a session log
+// (session.log or session.log.gz).
+func EnvPath(sessionLogPath string) string {
+	return logfile.CompanionPath(sessionLogPath, ".env")
+}
+
+// CaptureSessionEnv describes the context of a recording of args starting
+// now: the working directory, $SHELL and the variables in allowlist (nil =
+// DefaultEnvAllowlist) that are set. Names that look secret (see
+// looksSecret) are skipped even if allowlisted.
+func CaptureSessionEnv(args []string, allowlist []string) SessionEnv {
+	if allowlist == nil {
+		allowlist = DefaultEnvAllowlist
+	}
+	cwd, _ := os.Getwd()
+	env := SessionEnv{
+		Cwd:     cwd,
+		Shell:   os.Getenv("SHELL"),
+		Command: strings.Join(args, " "),
+	}
+	for _, name := range allowlist {
+		if looksSecret(name) {
+			continue
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			if env.Env == nil {
+				env.Env = make(map[string]string)
+			}
+			env.Env[name] = value
+		}
+	}
+	return env
+}
+
+// WriteSessionEnv writes env as JSON to path. The file is only readable by
+// its owner, as working directories and variables can be personal.
+func WriteSessionEnv(path string, env SessionEnv) error {
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("cannot write session.env: %w", err)
+	}
+	return nil
+}
+
+// ReadSessionEnv reads the session.env next to a session log.
+// Recordings made before session.env existed return an error
+// satisfying os.IsNotExist.
+func ReadSessionEnv(sessionLogPath string) (*SessionEnv, error) {
+	data, err := os.ReadFile(EnvPath(sessionLogPath))
+	if err != nil {
+		return nil, err
+	}
+	var env SessionEnv
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("cannot parse session.env: %w", err)
+	}
+	return &env, nil
+}
+
+// recordedContext returns the working directory and shell from a session
+// log's session.env when ConvertOptions.ShowEnv is set, or empty strings.
+func recordedContext(sessionLogPath string, o ConvertOptions) (cwd, shell string) {
+	if !o.ShowEnv {
+		return "", ""
+	}
+	env, err := ReadSessionEnv(sessionLogPath)
+	if err != nil {
+		return "", ""
+	}
+	return env.Cwd, env.Shell
+}
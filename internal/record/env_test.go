@@ -0,0 +1,82 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaptureSessionEnv_Allowlist(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	t.Setenv("LANG", "en_US.UTF-8")
+	t.Setenv("GITHUB_TOKEN", "ghp_secret")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "also-secret")
+
+	env := CaptureSessionEnv([]string{"make", "test"}, nil)
+	if env.Shell != "/bin/zsh" || env.Command != "make test" {
+		t.Errorf("Shell = %q, Command = %q", env.Shell, env.Command)
+	}
+	if cwd, _ := os.Getwd(); env.Cwd != cwd {
+		t.Errorf("Cwd = %q, want %q", env.Cwd, cwd)
+	}
+	if env.Env["LANG"] != "en_US.UTF-8" {
+		t.Errorf("default allowlist should record LANG, got %v", env.Env)
+	}
+	if _, ok := env.Env["GITHUB_TOKEN"]; ok {
+		t.Error("variables outside the allowlist should not be recorded")
+	}
+
+	// Secret-looking names are skipped even when allowlisted
+	env = CaptureSessionEnv(nil, []string{"LANG", "GITHUB_TOKEN", "AWS_SECRET_ACCESS_KEY"})
+	if len(env.Env) != 1 || env.Env["LANG"] == "" {
+		t.Errorf("expected only LANG, got %v", env.Env)
+	}
+}
+
+func TestSessionEnv_RoundTrip(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	env := SessionEnv{Cwd: "/home/me/project", Shell: "/bin/bash", Env: map[string]string{"TERM": "xterm-256color"}}
+
+	if err := WriteSessionEnv(EnvPath(sessionLogPath), env); err != nil {
+		t.Fatalf("WriteSessionEnv failed: %v", err)
+	}
+	info, err := os.Stat(EnvPath(sessionLogPath))
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("session.env mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	got, err := ReadSessionEnv(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ReadSessionEnv failed: %v", err)
+	}
+	if got.Cwd != env.Cwd || got.Shell != env.Shell || got.Env["TERM"] != "xterm-256color" {
+		t.Errorf("ReadSessionEnv = %+v, want %+v", got, env)
+	}
+}
+
+func TestConvertSessionToHTML_ShowEnv(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n$ ls\nfile1\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+	if err := WriteSessionEnv(EnvPath(sessionLogPath), SessionEnv{Cwd: "/srv/app", Shell: "/bin/zsh"}); err != nil {
+		t.Fatalf("WriteSessionEnv failed: %v", err)
+	}
+
+	for _, showEnv := range []bool{false, true} {
+		htmlPath, err := ConvertSessionToHTML(sessionLogPath, ConvertOptions{ShowEnv: showEnv})
+		if err != nil {
+			t.Fatalf("ConvertSessionToHTML failed: %v", err)
+		}
+		htmlBytes, _ := os.ReadFile(htmlPath)
+		shown := strings.Contains(string(htmlBytes), `Recording of: <code>bash</code> in <code>/srv/app</code> (shell <code>/bin/zsh</code>)`)
+		if shown != showEnv {
+			t.Errorf("ShowEnv %v: heading shows session.env = %v", showEnv, shown)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package record
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/choonkeat/record-tui/internal/logfile"
+	"github.com/choonkeat/record-tui/playback"
+)
+
+// EstimateHTMLSize returns about how many bytes ConvertSessionToHTML (with
+// no options) would write for a session log, without encoding its content
+// into the page: the page is rendered around empty content, and the
+// cleaned content's size once JSON- and base64-encoded, as the page embeds
+// it, is added. Content past the row cap (see playback.Options.MaxRows)
+// is still counted.
+//
+// Returns ErrSessionNotFound or ErrEmptyAfterStrip (wrapped for errors.Is)
+// as ConvertSessionToHTML would.
+func EstimateHTMLSize(sessionLogPath string) (int, error) {
+	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
+		return 0, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
+	}
+	sessionContent, err := logfile.ReadFile(sessionLogPath)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read session.log: %w", err)
+	}
+	o := ConvertOptions{}
+	content, trimmedLines, err := sessionOutput(sessionLogPath, sessionContent, o)
+	if err != nil {
+		return 0, err
+	}
+	cleanedContent := cleanContent(sessionLogPath, content, nil, o)
+	if cleanedContent == "" {
+		return 0, ErrEmptyAfterStrip
+	}
+
+	empty := []playback.Frame{{Timestamp: 0}}
+	emptyJSON, err := json.Marshal(empty)
+	if err != nil {
+		return 0, err
+	}
+	page, err := playback.RenderHTML(empty, pageOptions(sessionLogPath, sessionContent, cleanedContent, trimmedLines, o))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	framesLen := len(emptyJSON) + jsonStringLen(cleanedContent)
+	return len(page) - base64.StdEncoding.EncodedLen(len(emptyJSON)) + base64.StdEncoding.EncodedLen(framesLen), nil
+}
+
+// jsonStringLen returns how many bytes json.Marshal adds to encode s as
+// a string, beyond the quotes: escapes included, invalid UTF-8 replaced
+// with U+FFFD as RenderHTML does.
+func jsonStringLen(s string) int {
+	n := 0
+	invalid := false // In a run of invalid bytes, which becomes one U+FFFD
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if r == utf8.RuneError && size == 1 {
+			if !invalid {
+				n += len("\uFFFD")
+			}
+			invalid = true
+			continue
+		}
+		invalid = false
+		switch {
+		case r == '"' || r == '\\' || r == '\n' || r == '\r' || r == '\t' || r == '\b' || r == '\f':
+			n += 2
+		case r < 0x20 || r == '<' || r == '>' || r == '&' || r == '\u2028' || r == '\u2029':
+			n += len(`\u0000`)
+		default:
+			n += size
+		}
+	}
+	return n
+}
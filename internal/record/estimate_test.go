@@ -0,0 +1,68 @@
+package record
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJSONStringLen(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"plain ascii",
+		"\x1b[1;31merror\x1b[0m\r\n\ttab \"quoted\" back\\slash",
+		"<script>&amp;</script>",
+		"héllo ✓ 😀   ",
+		"bad \xff\xfe bytes \xc3",
+		"\x00\x01\b\f\x7f",
+	} {
+		b, _ := json.Marshal(strings.ToValidUTF8(s, "�"))
+		if got, want := jsonStringLen(s), len(b)-2; got != want {
+			t.Errorf("jsonStringLen(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestEstimateHTMLSize(t *testing.T) {
+	dir := t.TempDir()
+	sessionLogPath := filepath.Join(dir, "session.log")
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make\"]\n" +
+		strings.Repeat("$ make\r\n\x1b[1;32m✓ built <target> & more\x1b[0m\r\n", 2000) +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	os.WriteFile(sessionLogPath, []byte(content), 0644)
+
+	estimate, err := EstimateHTMLSize(sessionLogPath)
+	if err != nil {
+		t.Fatalf("EstimateHTMLSize: %v", err)
+	}
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML: %v", err)
+	}
+	info, err := os.Stat(htmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Within 1% of the real page
+	actual := int(info.Size())
+	if diff := actual - estimate; diff < -actual/100 || diff > actual/100 {
+		t.Errorf("estimate %d bytes, actual %d bytes", estimate, actual)
+	}
+}
+
+func TestEstimateHTMLSize_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := EstimateHTMLSize(filepath.Join(dir, "missing.log")); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("missing log: got %v, want ErrSessionNotFound", err)
+	}
+
+	empty := filepath.Join(dir, "session.log")
+	os.WriteFile(empty, []byte("Script started on Wed Dec 31 12:10:34 2025\nScript done on Wed Dec 31 12:11:22 2025\n"), 0644)
+	if _, err := EstimateHTMLSize(empty); !errors.Is(err, ErrEmptyAfterStrip) {
+		t.Errorf("empty log: got %v, want ErrEmptyAfterStrip", err)
+	}
+}
@@ -0,0 +1,205 @@
+package record
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/choonkeat/record-tui/internal/grid"
+	"github.com/choonkeat/record-tui/internal/logfile"
+	"github.com/choonkeat/record-tui/internal/session"
+	"github.com/choonkeat/record-tui/internal/svg"
+	"github.com/choonkeat/record-tui/internal/timing"
+	"github.com/choonkeat/record-tui/playback"
+)
+
+// Output formats ConvertSession can write.
+const (
+	FormatHTML      = "html"      // Self-contained page (see ConvertSessionToHTML)
+	FormatStreaming = "streaming" // Page fetching session.log (see ConvertSessionToStreamingHTML)
+	FormatText      = "text"      // Plain text (see ConvertSessionToText)
+	FormatSVG       = "svg"       // Static colored snapshot (see ConvertSessionToSVG)
+	FormatCast      = "cast"      // asciinema cast (see ConvertSessionToCast)
+)
+
+// Formats lists the output formats ConvertSession can write, in the order
+// they are listed to users. "asciicast" is also accepted for FormatCast.
+var Formats = []string{FormatHTML, FormatStreaming, FormatText, FormatSVG, FormatCast}
+
+// ErrUnknownFormat is returned by ConvertSession for a format not in Formats.
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// ParseFormat returns the output format named by name (case-insensitive),
+// or ErrUnknownFormat listing the supported ones.
+func ParseFormat(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "asciicast" {
+		return FormatCast, nil
+	}
+	for _, f := range Formats {
+		if name == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q (supported: %s)", ErrUnknownFormat, name, strings.Join(Formats, ", "))
+}
+
+// ConvertSession converts a session log to format (see ParseFormat),
+// written next to it as session.log.html, session.log.streaming.html,
+// session.log.txt, session.log.svg or session.log.cast. Returns the path
+// written. The streaming page and the cast ignore opts, and write the
+// output unredacted, so they fail with ConvertOptions.Redact.
+func ConvertSession(sessionLogPath, format string, opts ...ConvertOptions) (string, error) {
+	f, err := ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if convertOptions(opts).Redact && (f == FormatStreaming || f == FormatCast) {
+		return "", fmt.Errorf("%s output can't be redacted", f)
+	}
+	switch f {
+	case FormatStreaming:
+		return ConvertSessionToStreamingHTML(sessionLogPath, 100000)
+	case FormatText:
+		return ConvertSessionToText(sessionLogPath, opts...)
+	case FormatSVG:
+		return ConvertSessionToSVG(sessionLogPath, opts...)
+	case FormatCast:
+		return ConvertSessionToCast(sessionLogPath)
+	}
+	return ConvertSessionToHTML(sessionLogPath, opts...)
+}
+
+// cleanedSession reads and cleans a session log for the text and SVG
+// formats, as ConvertSessionToHTML does for its page.
+func cleanedSession(sessionLogPath string, o ConvertOptions) (string, error) {
+	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
+	}
+	if err := checkCompanionFiles(o); err != nil {
+		return "", err
+	}
+	sessionContent, err := logfile.ReadFile(sessionLogPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read session.log: %w", err)
+	}
+	content, _, err := sessionOutput(sessionLogPath, sessionContent, o)
+	if err != nil {
+		return "", err
+	}
+	cleanedContent := cleanContent(sessionLogPath, content, nil, o)
+	if cleanedContent == "" {
+		return "", ErrEmptyAfterStrip
+	}
+	return checkBinary(cleanedContent, o)
+}
+
+// ConvertSessionToText writes a session log's output as plain text to
+// session.log.txt, cleaned as for the HTML and with escape sequences
+// removed (see session.PlainText), for grepping or pasting into an issue.
+// Lines longer than the recorded terminal width are laid out as
+// ConvertOptions.Overflow says. ConvertOptions apply as they do to
+// ConvertSessionToHTML, except those for the page itself (table of
+// contents, typed input, error navigation).
+func ConvertSessionToText(sessionLogPath string, opts ...ConvertOptions) (string, error) {
+	o := convertOptions(opts)
+	overflow, err := grid.ParseOverflow(o.Overflow)
+	if err != nil {
+		return "", err
+	}
+	cleanedContent, err := cleanedSession(sessionLogPath, o)
+	if err != nil {
+		return "", err
+	}
+	cols := int(recordedCols(sessionLogPath))
+	if cols == 0 {
+		cols = svg.DefaultCols
+	}
+	var lines []string
+	for _, line := range strings.Split(session.PlainText(cleanedContent), "\n") {
+		lines = append(lines, grid.FitLine(line, cols, overflow)...)
+	}
+	text := session.EnsureTrailingNewline(strings.Join(lines, "\n"))
+
+	outputPath := sessionLogPath + ".txt"
+	if err := os.WriteFile(outputPath, []byte(text), 0644); err != nil {
+		return "", fmt.Errorf("failed to write text file: %w", err)
+	}
+	return outputPath, nil
+}
+
+// ConvertSessionToSVG writes a session log's output as a static SVG
+// snapshot (see playback.RenderSVG) to session.log.svg, at the recorded
+// terminal width. ConvertOptions apply as for ConvertSessionToText.
+func ConvertSessionToSVG(sessionLogPath string, opts ...ConvertOptions) (string, error) {
+	o := convertOptions(opts)
+	cleanedContent, err := cleanedSession(sessionLogPath, o)
+	if err != nil {
+		return "", err
+	}
+	if o.ColorMode == "16" {
+		cleanedContent = session.DownsampleColors(cleanedContent)
+	}
+	svgContent, err := playback.RenderSVG(cleanedContent, playback.SVGOptions{
+		Cols:     int(recordedCols(sessionLogPath)),
+		Overflow: o.Overflow,
+	})
+	if err != nil {
+		return "", fmt.Errorf("%w: svg: %w", ErrRenderFailed, err)
+	}
+
+	outputPath := sessionLogPath + ".svg"
+	if err := os.WriteFile(outputPath, []byte(svgContent), 0644); err != nil {
+		return "", fmt.Errorf("failed to write SVG file: %w", err)
+	}
+	return outputPath, nil
+}
+
+// ConvertSessionToCast writes a session log as an asciicast v2 cast (see
+// timing.WriteAsciicast) to session.log.cast, for `asciinema play`. With
+// the timing file alongside the log, output replays at its recorded pace;
+// without it, all at once. The output is written as recorded, not cleaned:
+// a cast player is a terminal, so clears and full-screen TUIs replay as
+// they happened.
+func ConvertSessionToCast(sessionLogPath string) (string, error) {
+	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
+	}
+	sessionContent, err := logfile.ReadFile(sessionLogPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read session.log: %w", err)
+	}
+	output := []byte(session.StripMetadataOnly(string(sessionContent)))
+	if len(bytes.TrimSpace(output)) == 0 {
+		return "", ErrEmptyAfterStrip
+	}
+
+	var entries []timing.Entry
+	if timingFile, err := logfile.Open(logfile.CompanionPath(sessionLogPath, ".timing")); err == nil {
+		entries, err = timing.Parse(timingFile)
+		timingFile.Close()
+		if err != nil {
+			return "", fmt.Errorf("cannot parse timing file: %w", err)
+		}
+	}
+
+	header := timing.AsciicastHeader{Title: session.ExtractCommand(string(sessionContent))}
+	if meta, err := ReadSessionMeta(sessionLogPath); err == nil {
+		header.Width, header.Height = meta.Cols, meta.Rows
+		if !meta.Started.IsZero() {
+			header.Timestamp = meta.Started.Unix()
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := timing.WriteAsciicast(&buf, header, entries, output); err != nil {
+		return "", fmt.Errorf("%w: cast: %w", ErrRenderFailed, err)
+	}
+	outputPath := sessionLogPath + ".cast"
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+		return "", fmt.Errorf("failed to write cast file: %w", err)
+	}
+	return outputPath, nil
+}
@@ -0,0 +1,163 @@
+package record
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFormatSession writes a session.log with colored output and its
+// timing file to a temp dir, returning the log's path.
+func writeFormatSession(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ ls\r\n\x1b[34mfile1\x1b[0m\r\n" +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	if err := os.WriteFile(sessionLogPath, []byte(sessionContent), 0644); err != nil {
+		t.Fatalf("Failed to create session.log: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "session.timing"), []byte("O 0.010 6\nO 1.500 15\n"), 0644); err != nil {
+		t.Fatalf("Failed to create session.timing: %v", err)
+	}
+	return sessionLogPath
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]string{
+		"html": FormatHTML, "streaming": FormatStreaming, "TEXT": FormatText,
+		"svg": FormatSVG, "cast": FormatCast, "asciicast": FormatCast,
+	} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	_, err := ParseFormat("pdf")
+	if !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("expected ErrUnknownFormat, got %v", err)
+	}
+	if !strings.Contains(err.Error(), strings.Join(Formats, ", ")) {
+		t.Errorf("error should list the supported formats: %v", err)
+	}
+}
+
+func TestConvertSession_OutputPaths(t *testing.T) {
+	sessionLogPath := writeFormatSession(t)
+	for format, ext := range map[string]string{
+		FormatHTML: ".html", FormatStreaming: ".streaming.html",
+		FormatText: ".txt", FormatSVG: ".svg", FormatCast: ".cast",
+	} {
+		path, err := ConvertSession(sessionLogPath, format)
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		if path != sessionLogPath+ext {
+			t.Errorf("%s: wrote %s, want %s", format, path, sessionLogPath+ext)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+
+	if _, err := ConvertSession(sessionLogPath, "pdf"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestConvertSession_RedactUnsupported(t *testing.T) {
+	sessionLogPath := writeFormatSession(t)
+	for _, format := range []string{FormatStreaming, FormatCast} {
+		if _, err := ConvertSession(sessionLogPath, format, ConvertOptions{Redact: true}); err == nil {
+			t.Errorf("%s: Redact should be an error, not ignored", format)
+		}
+	}
+	if _, err := os.Stat(sessionLogPath + ".cast"); !os.IsNotExist(err) {
+		t.Error("no cast should be written")
+	}
+}
+
+func TestConvertSessionToText(t *testing.T) {
+	path, err := ConvertSessionToText(writeFormatSession(t))
+	if err != nil {
+		t.Fatalf("ConvertSessionToText failed: %v", err)
+	}
+	text, _ := os.ReadFile(path)
+	if want := "$ ls\nfile1\n"; string(text) != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestConvertSessionToText_Overflow(t *testing.T) {
+	for _, tc := range []struct {
+		overflow string
+		want     string
+	}{
+		{"", "$ echo\n0123456789\n01234\n"},
+		{"truncate", "$ echo\n012345678…\n"},
+		{"none", "$ echo\n012345678901234\n"},
+	} {
+		sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+		content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+			"$ echo\r\n012345678901234\r\n" +
+			"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+		if err := os.WriteFile(sessionLogPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteSessionMeta(MetaPath(sessionLogPath), SessionMeta{Cols: 10, Rows: 5}); err != nil {
+			t.Fatal(err)
+		}
+		path, err := ConvertSessionToText(sessionLogPath, ConvertOptions{Overflow: tc.overflow})
+		if err != nil {
+			t.Fatalf("%q: ConvertSessionToText failed: %v", tc.overflow, err)
+		}
+		text, _ := os.ReadFile(path)
+		if string(text) != tc.want {
+			t.Errorf("%q: text = %q, want %q", tc.overflow, text, tc.want)
+		}
+	}
+
+	if _, err := ConvertSessionToText(writeFormatSession(t), ConvertOptions{Overflow: "clip"}); err == nil {
+		t.Error("unknown overflow should be an error")
+	}
+}
+
+func TestConvertSessionToSVG(t *testing.T) {
+	path, err := ConvertSessionToSVG(writeFormatSession(t))
+	if err != nil {
+		t.Fatalf("ConvertSessionToSVG failed: %v", err)
+	}
+	svg, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(svg), "<svg") || !strings.Contains(string(svg), "file1") {
+		t.Errorf("unexpected SVG: %.200s", svg)
+	}
+}
+
+func TestConvertSessionToCast(t *testing.T) {
+	path, err := ConvertSessionToCast(writeFormatSession(t))
+	if err != nil {
+		t.Fatalf("ConvertSessionToCast failed: %v", err)
+	}
+	cast, _ := os.ReadFile(path)
+	want := `{"version":2,"width":80,"height":24,"title":"bash"}
+[0.01,"o","$ ls\r\n"]
+[1.51,"o","\u001b[34mfile1\u001b[0m\r"]
+`
+	if string(cast) != want {
+		t.Errorf("cast:\n%s\nwant:\n%s", cast, want)
+	}
+}
+
+func TestConvertSession_EmptyAfterStrip(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	os.WriteFile(sessionLogPath, []byte("Script started on 2026-01-12 06:41:43+00:00\nScript done on 2026-01-12 06:45:00+00:00\n"), 0644)
+	for _, format := range []string{FormatText, FormatSVG, FormatCast} {
+		if _, err := ConvertSession(sessionLogPath, format); !errors.Is(err, ErrEmptyAfterStrip) {
+			t.Errorf("%s: expected ErrEmptyAfterStrip, got %v", format, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/choonkeat/record-tui/internal/logfile"
+)
+
+// SessionMeta is the recording geometry and context, written to session.meta
+// (JSON) next to session.log at record time. `script` doesn't reliably store
+// the terminal size, so this is the source of truth for the HTML width.
+type SessionMeta struct {
+	Cols    int       `json:"cols"`       // Terminal columns (0 if unknown)
+	Rows    int       `json:"rows"`       // Terminal rows (0 if unknown)
+	Started time.Time `json:"start_time"` // When recording started
+	Command string    `json:"command"`    // Recorded command ("" for the default shell)
+}
+
+// MetaPath returns the session.meta path for a session log
+// (session.log or session.log.gz).
+func MetaPath(sessionLogPath string) string {
+	return logfile.CompanionPath(sessionLogPath, ".meta")
+}
+
+// CaptureSessionMeta describes a recording of args starting now, sized from
+// the controlling terminal, falling back to the COLUMNS/LINES environment
+// variables when stdin isn't a terminal.
+func CaptureSessionMeta(args []string) SessionMeta {
+	cols, rows, ok := terminalSize(int(os.Stdin.Fd()))
+	if !ok {
+		cols, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+		rows, _ = strconv.Atoi(os.Getenv("LINES"))
+	}
+	return SessionMeta{
+		Cols:    max(cols, 0),
+		Rows:    max(rows, 0),
+		Started: time.Now(),
+		Command: strings.Join(args, " "),
+	}
+}
+
+// WriteSessionMeta writes meta as JSON to path.
+func WriteSessionMeta(path string, meta SessionMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("cannot write session.meta: %w", err)
+	}
+	return nil
+}
+
+// ReadSessionMeta reads the session.meta next to a session log.
+// Recordings made before session.meta existed return an error
+// satisfying os.IsNotExist.
+func ReadSessionMeta(sessionLogPath string) (*SessionMeta, error) {
+	data, err := os.ReadFile(MetaPath(sessionLogPath))
+	if err != nil {
+		return nil, err
+	}
+	var meta SessionMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("cannot parse session.meta: %w", err)
+	}
+	return &meta, nil
+}
+
+// recordedCols returns the terminal width from a session log's session.meta,
+// or 0 (auto-detect) if there is none.
+func recordedCols(sessionLogPath string) uint16 {
+	meta, err := ReadSessionMeta(sessionLogPath)
+	if err != nil || meta.Cols <= 0 || meta.Cols > math.MaxUint16 {
+		return 0
+	}
+	return uint16(meta.Cols)
+}
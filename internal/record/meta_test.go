@@ -0,0 +1,87 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSessionMeta_RoundTrip(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	started := time.Date(2026, 1, 12, 6, 41, 43, 0, time.UTC)
+	meta := SessionMeta{Cols: 132, Rows: 43, Started: started, Command: "claude --resume"}
+
+	if err := WriteSessionMeta(MetaPath(sessionLogPath), meta); err != nil {
+		t.Fatalf("WriteSessionMeta failed: %v", err)
+	}
+	got, err := ReadSessionMeta(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ReadSessionMeta failed: %v", err)
+	}
+	if got.Cols != 132 || got.Rows != 43 || got.Command != "claude --resume" || !got.Started.Equal(started) {
+		t.Errorf("ReadSessionMeta = %+v, want %+v", got, meta)
+	}
+
+	// Compressed logs share the same session.meta
+	if MetaPath(sessionLogPath+".gz") != MetaPath(sessionLogPath) {
+		t.Errorf("MetaPath differs for .log.gz: %s", MetaPath(sessionLogPath+".gz"))
+	}
+}
+
+func TestReadSessionMeta_Missing(t *testing.T) {
+	_, err := ReadSessionMeta(filepath.Join(t.TempDir(), "session.log"))
+	if !os.IsNotExist(err) {
+		t.Errorf("expected a not-exist error, got %v", err)
+	}
+}
+
+func TestCaptureSessionMeta_EnvFallback(t *testing.T) {
+	// go test's stdin is not a terminal, so the environment is used
+	if _, _, ok := terminalSize(int(os.Stdin.Fd())); ok {
+		t.Skip("stdin is a terminal")
+	}
+	t.Setenv("COLUMNS", "100")
+	t.Setenv("LINES", "30")
+
+	meta := CaptureSessionMeta([]string{"vim", "notes.txt"})
+	if meta.Cols != 100 || meta.Rows != 30 {
+		t.Errorf("got %dx%d, want 100x30", meta.Cols, meta.Rows)
+	}
+	if meta.Command != "vim notes.txt" {
+		t.Errorf("Command = %q", meta.Command)
+	}
+	if meta.Started.IsZero() {
+		t.Error("Started should be set")
+	}
+}
+
+func TestConvertSessionToHTML_UsesRecordedCols(t *testing.T) {
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	if err := os.WriteFile(sessionLogPath, []byte("Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n$ ls\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ := os.ReadFile(htmlPath)
+	if !strings.Contains(string(htmlBytes), "const recordedCols = 0;") {
+		t.Error("without session.meta the width should be estimated from content")
+	}
+
+	if err := WriteSessionMeta(MetaPath(sessionLogPath), SessionMeta{Cols: 132, Rows: 43}); err != nil {
+		t.Fatal(err)
+	}
+	htmlPath, err = ConvertSessionToHTML(sessionLogPath)
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, _ = os.ReadFile(htmlPath)
+	if !strings.Contains(string(htmlBytes), "const recordedCols = 132;") {
+		t.Error("HTML should use the width from session.meta")
+	}
+}
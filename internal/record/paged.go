@@ -0,0 +1,222 @@
+package record
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/choonkeat/record-tui/internal/logfile"
+	"github.com/choonkeat/record-tui/internal/session"
+	"github.com/choonkeat/record-tui/internal/timing"
+	"github.com/choonkeat/record-tui/internal/toc"
+	"github.com/choonkeat/record-tui/playback"
+)
+
+// pagedDirSuffix is appended to the session log path to name the directory
+// ConvertSessionToPagedHTML writes its pages to.
+const pagedDirSuffix = ".pages"
+
+// outputPage is the part of the session output on one page, and the
+// commands typed in it (offsets relative to the page).
+type outputPage struct {
+	content  string
+	commands []timing.Command
+}
+
+// splitByCommands splits output into pages of commandsPerPage commands
+// each. A page starts at the beginning of the line its first command was
+// typed on, so the prompt stays with the command; output before the first
+// command goes on the first page.
+func splitByCommands(output string, commands []timing.Command, commandsPerPage int) []outputPage {
+	var pages []outputPage
+	start := 0
+	for i := 0; i < len(commands) || i == 0; i += commandsPerPage {
+		end := len(output)
+		if next := i + commandsPerPage; next < len(commands) {
+			offset := min(commands[next].OutputByteOffset, len(output))
+			end = max(start, strings.LastIndexByte(output[:offset], '\n')+1)
+		}
+
+		var pageCommands []timing.Command
+		for _, cmd := range commands[i:min(i+commandsPerPage, len(commands))] {
+			cmd.OutputByteOffset = max(0, min(cmd.OutputByteOffset, len(output))-start)
+			pageCommands = append(pageCommands, cmd)
+		}
+		pages = append(pages, outputPage{content: output[start:end], commands: pageCommands})
+		start = end
+	}
+	return pages
+}
+
+// pageFileName returns the file name of page n (1-indexed).
+func pageFileName(n int) string {
+	return "page-" + strconv.Itoa(n) + ".html"
+}
+
+// pageLinks returns the previous, index and next links for page n of total.
+func pageLinks(n, total int) []playback.FooterLink {
+	var links []playback.FooterLink
+	if n > 1 {
+		links = append(links, playback.FooterLink{Text: "← Previous", URL: pageFileName(n - 1)})
+	}
+	links = append(links, playback.FooterLink{
+		Text: fmt.Sprintf("Page %d of %d", n, total),
+		URL:  "index.html",
+	})
+	if n < total {
+		links = append(links, playback.FooterLink{Text: "Next →", URL: pageFileName(n + 1)})
+	}
+	return links
+}
+
+// ConvertSessionToPagedHTML splits a long recording into pages of
+// commandsPerPage commands each, so no single page is slow to load. The
+// pages (page-1.html, page-2.html, ...) link to each other and to an
+// index.html listing every page's commands, all written to a
+// <session.log>.pages directory.
+//
+// Commands come from timingPath and the session.input file alongside the
+// log (or ConvertOptions.InputPath), as for the table of contents; an empty
+// timingPath uses ConvertOptions.TimingPath or the timing file alongside
+// the log. The timing file must match the log (see
+// timing.Validate). Commands left out by ConvertOptions.TOCExclude are
+// neither listed nor counted. ConvertOptions.Since/Until, OutputOnly,
+// StashAltScreen and FinalScreen are not supported.
+//
+// Returns the path to index.html, or error if any step fails.
+func ConvertSessionToPagedHTML(sessionLogPath, timingPath string, commandsPerPage int, opts ...ConvertOptions) (string, error) {
+	o := convertOptions(opts)
+	if commandsPerPage < 1 {
+		return "", fmt.Errorf("commands per page must be at least 1, got %d", commandsPerPage)
+	}
+	if o.hasTimeWindow() {
+		return "", fmt.Errorf("a time window can't be split into pages")
+	}
+	if o.OutputOnly {
+		return "", fmt.Errorf("pages are split and listed by typed command, so can't be output-only")
+	}
+	if o.StashAltScreen && !o.KeepAltScreen {
+		return "", fmt.Errorf("full-screen TUI output can't be stashed for pages")
+	}
+	if o.FinalScreen {
+		return "", fmt.Errorf("the final screen is a single page, so can't be split into pages")
+	}
+	if err := checkCompanionFiles(o); err != nil {
+		return "", err
+	}
+	if timingPath == "" {
+		timingPath = o.timingPath(sessionLogPath)
+	}
+
+	sessionContent, err := logfile.ReadFile(sessionLogPath)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: %s", ErrSessionNotFound, sessionLogPath)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot read session.log: %w", err)
+	}
+
+	timingFile, err := os.Open(timingPath)
+	if err != nil {
+		return "", fmt.Errorf("pages need the timing file: %w", err)
+	}
+	defer timingFile.Close()
+	entries, err := timing.Parse(timingFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse timing file: %w", err)
+	}
+
+	inputContent, err := os.ReadFile(o.inputPath(sessionLogPath))
+	if err != nil {
+		return "", fmt.Errorf("pages need the input file: %w", err)
+	}
+
+	output := session.StripMetadataOnly(string(sessionContent))
+	if err := timing.Validate(entries, len(output)); err != nil {
+		return "", err
+	}
+	commands := timing.ExtractCommands(entries, []byte(session.StripMetadataOnly(string(inputContent))))
+	if len(o.TOCExclude) > 0 || o.TOCExcludePattern != nil {
+		commands = timing.FilterCommands(commands, timing.ExcludeCommands(o.TOCExclude, o.TOCExcludePattern))
+	}
+	commands = timing.MarkChapters(commands, "")
+	if o.TrimLeadingIdle {
+		trimmed, offset := timing.TrimLeadingIdle(entries, []byte(output))
+		output = string(trimmed)
+		for i := range commands {
+			commands[i].OutputByteOffset = max(0, commands[i].OutputByteOffset-offset)
+		}
+	}
+
+	outputDir := sessionLogPath + pagedDirSuffix
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create pages directory: %w", err)
+	}
+
+	command := o.redact(session.ExtractCommand(string(sessionContent)))
+	title := filepath.Base(sessionLogPath)
+	if o.Title != "" {
+		title = o.redact(expandTitle(o.Title, sessionContent))
+	}
+	cwd, shell := recordedContext(sessionLogPath, o)
+	pages := splitByCommands(output, commands, commandsPerPage)
+	index := make([]playback.IndexPage, len(pages))
+	for i, page := range pages {
+		n := i + 1
+		cleanedContent := playback.StripMetadata(page.content, playback.StripOptions{
+			KeepAltScreen: o.KeepAltScreen,
+			Redact:        o.Redact,
+		})
+		cleanedContent, err = checkBinary(cleanedContent, o)
+		if err != nil {
+			return "", fmt.Errorf("page %d: %w", n, err)
+		}
+
+		var tocEntries []playback.TOCEntry
+		pageTOC := toc.FromCommands(page.commands, strings.NewReader(page.content))
+		if o.TOCCollapseRepeats {
+			pageTOC = toc.CollapseRepeats(pageTOC)
+		}
+		for _, e := range pageTOC {
+			tocEntries = append(tocEntries, playback.TOCEntry{Label: o.redact(e.Label), Line: e.Line})
+		}
+
+		htmlContent, err := playback.RenderHTML([]playback.Frame{{Timestamp: 0.0, Content: cleanedContent}}, playback.Options{
+			Title:      fmt.Sprintf("%s (page %d of %d)", title, n, len(pages)),
+			Command:    command,
+			Cwd:        cwd,
+			Shell:      shell,
+			FooterLink: o.FooterLink,
+			TOC:        tocEntries,
+			Cols:       recordedCols(sessionLogPath),
+			SourceHash: sourceHash(sessionContent),
+			ColorMode:  o.ColorMode,
+			Version:    o.Version,
+			PageLinks:  pageLinks(n, len(pages)),
+			ErrorLines: errorLines(cleanedContent, o),
+
+			OpenGraph:            o.OpenGraph,
+			OpenGraphDescription: o.redact(shareDescription(sessionContent)),
+			OpenGraphImage:       o.OpenGraphImage,
+		})
+		if err != nil {
+			return "", fmt.Errorf("%w for page %d: %w", ErrRenderFailed, n, err)
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, pageFileName(n)), []byte(htmlContent), 0644); err != nil {
+			return "", fmt.Errorf("failed to write HTML file: %w", err)
+		}
+		index[i] = playback.IndexPage{URL: pageFileName(n), TOC: tocEntries}
+	}
+
+	indexContent, err := playback.RenderIndexHTML(title, index)
+	if err != nil {
+		return "", fmt.Errorf("%w: index: %w", ErrRenderFailed, err)
+	}
+	indexPath := filepath.Join(outputDir, "index.html")
+	if err := os.WriteFile(indexPath, []byte(indexContent), 0644); err != nil {
+		return "", fmt.Errorf("failed to write HTML file: %w", err)
+	}
+	return indexPath, nil
+}
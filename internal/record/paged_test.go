@@ -0,0 +1,115 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/choonkeat/record-tui/internal/timing"
+)
+
+// writePagedSession writes a session with three commands (ls, pwd, npm test)
+// and its timing and input files, returning the session.log path.
+func writePagedSession(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	sessionLogPath := filepath.Join(tmpDir, "session.log")
+	files := map[string]string{
+		"session.log": "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+			"$ ls\nfile1\n$ pwd\n/tmp\n$ npm test\nPASS\n" +
+			"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n",
+		"session.timing": "O 0.010 2\nI 0.500 3\nO 0.010 9\nO 0.010 2\nI 0.500 4\nO 0.010 9\nO 0.010 2\nI 0.500 9\nO 0.010 14\n",
+		"session.input":  "ls\rpwd\rnpm test\r",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	return sessionLogPath
+}
+
+func TestSplitByCommands(t *testing.T) {
+	output := "$ ls\nfile1\n$ pwd\n/tmp\n$ npm test\nPASS\n"
+	commands := []timing.Command{
+		{Text: "ls", OutputByteOffset: 2},
+		{Text: "pwd", OutputByteOffset: 13},
+		{Text: "npm test", OutputByteOffset: 24},
+	}
+
+	pages := splitByCommands(output, commands, 2)
+
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(pages))
+	}
+	if want := "$ ls\nfile1\n$ pwd\n/tmp\n"; pages[0].content != want {
+		t.Errorf("page 1 = %q, want %q", pages[0].content, want)
+	}
+	if want := "$ npm test\nPASS\n"; pages[1].content != want {
+		t.Errorf("page 2 = %q, want %q (starting at the prompt)", pages[1].content, want)
+	}
+	if got := pages[1].commands; len(got) != 1 || got[0].OutputByteOffset != 2 {
+		t.Errorf("page 2 commands = %+v, want npm test at offset 2", got)
+	}
+
+	// No commands: everything on one page
+	if pages := splitByCommands(output, nil, 2); len(pages) != 1 || pages[0].content != output {
+		t.Errorf("expected the whole output on one page, got %+v", pages)
+	}
+}
+
+func TestConvertSessionToPagedHTML(t *testing.T) {
+	sessionLogPath := writePagedSession(t)
+
+	indexPath, err := ConvertSessionToPagedHTML(sessionLogPath, "", 2)
+	if err != nil {
+		t.Fatalf("ConvertSessionToPagedHTML failed: %v", err)
+	}
+	if want := filepath.Join(sessionLogPath+".pages", "index.html"); indexPath != want {
+		t.Errorf("index path = %q, want %q", indexPath, want)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(sessionLogPath+".pages", name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(b)
+	}
+
+	index := read("index.html")
+	for _, want := range []string{`href="page-1.html#input-1"><code>pwd</code>`, `href="page-2.html#input-0"><code>npm test</code>`} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index should contain %s", want)
+		}
+	}
+
+	page1, page2 := read("page-1.html"), read("page-2.html")
+	if !strings.Contains(page1, `<a href="page-2.html">Next →</a>`) || strings.Contains(page1, "← Previous") {
+		t.Error("page 1 should link to the next page only")
+	}
+	if !strings.Contains(page2, `<a href="page-1.html">← Previous</a>`) || strings.Contains(page2, "Next →") {
+		t.Error("page 2 should link to the previous page only")
+	}
+	if !strings.Contains(page2, `<a href="index.html">Page 2 of 2</a>`) {
+		t.Error("pages should link to the index")
+	}
+	if !strings.Contains(page2, `"npm test"`) || strings.Contains(page2, `"pwd"`) {
+		t.Error("page 2 navigation should hold only its own commands")
+	}
+}
+
+func TestConvertSessionToPagedHTML_Errors(t *testing.T) {
+	sessionLogPath := writePagedSession(t)
+
+	if _, err := ConvertSessionToPagedHTML(sessionLogPath, "", 0); err == nil {
+		t.Error("expected an error for 0 commands per page")
+	}
+
+	os.Remove(filepath.Join(filepath.Dir(sessionLogPath), "session.input"))
+	if _, err := ConvertSessionToPagedHTML(sessionLogPath, "", 2); err == nil || !strings.Contains(err.Error(), "input file") {
+		t.Errorf("expected input file error, got %v", err)
+	}
+}
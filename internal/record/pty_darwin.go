@@ -0,0 +1,47 @@
+//go:build darwin
+
+package record
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ioctls reading and setting a terminal's termios.
+const (
+	ioctlGetTermios = syscall.TIOCGETA
+	ioctlSetTermios = syscall.TIOCSETA
+)
+
+// openPTY opens a new pseudo-terminal: ptm is its master side, which the
+// recorder reads output from and writes input to, and pts the terminal the
+// recorded command runs on.
+func openPTY() (ptm, pts *os.File, err error) {
+	ptm, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var name [128]byte
+	for _, req := range []uintptr{syscall.TIOCPTYGRANT, syscall.TIOCPTYUNLK} {
+		if err := ioctl(ptm.Fd(), req, 0); err != nil {
+			ptm.Close()
+			return nil, nil, err
+		}
+	}
+	if err := ioctl(ptm.Fd(), syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name))); err != nil {
+		ptm.Close()
+		return nil, nil, err
+	}
+	end := bytes.IndexByte(name[:], 0)
+	if end < 0 {
+		end = len(name)
+	}
+	pts, err = os.OpenFile(string(name[:end]), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		ptm.Close()
+		return nil, nil, err
+	}
+	return ptm, pts, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package record
+
+import "context"
+
+// recordSeparateStderr is unsupported on this platform.
+func recordSeparateStderr(ctx context.Context, outputPath string, args []string, o RecordOptions) (int, error) {
+	return 1, ErrSeparateStderrUnsupported
+}
@@ -0,0 +1,195 @@
+//go:build linux || darwin
+
+package record
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// outputDrainPeriod is how long recordSeparateStderr keeps reading output
+// after the command exits, in case something it left running still holds
+// its terminal open.
+var outputDrainPeriod = time.Second
+
+// recordSeparateStderr records args (or $SHELL) as `script` would, but on
+// a pseudo-terminal of its own with the command's stderr on a pipe, so
+// that what it writes there is listed in session.stderr (see StderrPath).
+// The command is stopped as RecordSessionContext describes once ctx is
+// done or o.Timeout elapses.
+//
+// Only stdin and stdout are a terminal to the command, so some programs
+// drop colors or progress output on stderr. And the streams are read
+// separately, so output written to both at once may interleave differently
+// than on a terminal.
+//
+// Returns the command's exit code, and context.Cause(ctx) if ctx (or the
+// timeout) stopped it.
+func recordSeparateStderr(ctx context.Context, outputPath string, args []string, o RecordOptions) (int, error) {
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, o.Timeout, ErrRecordingTimedOut)
+		defer cancel()
+	}
+	command := args
+	if len(command) == 0 {
+		shell := os.Getenv("SHELL")
+		if shell == "" {
+			shell = "/bin/sh"
+		}
+		command = []string{shell}
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if o.Append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	logFile, err := os.OpenFile(outputPath, flags, 0644)
+	if err != nil {
+		return 1, err
+	}
+	defer logFile.Close()
+	stderrFile, err := os.OpenFile(StderrPath(outputPath), flags, 0644)
+	if err != nil {
+		return 1, err
+	}
+	defer stderrFile.Close()
+	info, err := logFile.Stat()
+	if err != nil {
+		return 1, err
+	}
+
+	ptm, pts, err := openPTY()
+	if err != nil {
+		return 1, fmt.Errorf("cannot open a pseudo-terminal: %w", err)
+	}
+	defer ptm.Close()
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		pts.Close()
+		return 1, err
+	}
+	defer stderrR.Close()
+	cols, rows, sized := terminalSize(int(os.Stdin.Fd()))
+	if sized {
+		setTerminalSize(ptm, cols, rows)
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdin = pts
+	cmd.Stdout = pts
+	cmd.Stderr = stderrW
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = killGracePeriod
+
+	l := &streamLog{log: logFile, stderr: stderrFile, offset: info.Size()}
+	l.writeHeader(time.Now(), args, cols, rows)
+	err = cmd.Start()
+	pts.Close()
+	stderrW.Close()
+	if err != nil {
+		return 1, err
+	}
+
+	if restore, err := makeRaw(int(os.Stdin.Fd())); err == nil {
+		defer restore()
+	}
+	go io.Copy(ptm, os.Stdin)
+	defer forwardResizes(ptm)()
+
+	copied := make(chan struct{})
+	go func() {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() { defer wg.Done(); l.copyStream(os.Stdout, ptm, false) }()
+		go func() { defer wg.Done(); l.copyStream(os.Stderr, stderrR, true) }()
+		wg.Wait()
+		close(copied)
+	}()
+
+	err = cmd.Wait()
+	select {
+	case <-copied:
+	case <-time.After(outputDrainPeriod):
+	}
+	l.writeFooter(time.Now(), max(exitCode(err), 0))
+
+	if ctx.Err() != nil {
+		return exitCode(err), context.Cause(ctx)
+	}
+	if err != nil {
+		return exitCode(err), fmt.Errorf("command failed: %w", err)
+	}
+	return 0, nil
+}
+
+// forwardResizes resizes the pseudo-terminal ptm whenever the terminal on
+// stdin is resized, until the returned stop function is called.
+func forwardResizes(ptm *os.File) (stop func()) {
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	go func() {
+		for range resized {
+			if cols, rows, ok := terminalSize(int(os.Stdin.Fd())); ok {
+				setTerminalSize(ptm, cols, rows)
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(resized)
+		close(resized)
+	}
+}
+
+// setTerminalSize sets the size of the terminal f is (either side of a
+// pseudo-terminal) via TIOCSWINSZ.
+func setTerminalSize(f *os.File, cols, rows int) error {
+	ws := struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}{Row: uint16(rows), Col: uint16(cols)}
+	return ioctl(f.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+}
+
+// makeRaw puts the terminal on fd into raw mode, as cfmakeraw(3) does, so
+// keys go to the recorded command unprocessed. Returns a function that
+// restores its settings, or an error if fd isn't a terminal.
+func makeRaw(fd int) (restore func(), err error) {
+	var saved syscall.Termios
+	if err := ioctl(uintptr(fd), ioctlGetTermios, uintptr(unsafe.Pointer(&saved))); err != nil {
+		return nil, err
+	}
+	raw := saved
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(&raw))); err != nil {
+		return nil, err
+	}
+	return func() {
+		ioctl(uintptr(fd), ioctlSetTermios, uintptr(unsafe.Pointer(&saved)))
+	}, nil
+}
+
+// ioctl performs the ioctl request req on fd.
+func ioctl(fd, req, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
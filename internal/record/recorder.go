@@ -1,27 +1,86 @@
 package record
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
+// RecordOptions configures optional recording behavior.
+type RecordOptions struct {
+	// Timeout stops the recording after this long (0 = no limit):
+	// `script` gets SIGTERM, then SIGKILL if it hasn't exited after
+	// killGracePeriod. Output captured so far is kept.
+	Timeout time.Duration
+
+	// Append continues an existing session log (script -a) instead of
+	// overwriting it. The session.meta and session.env of the first
+	// recording are kept.
+	Append bool
+
+	// EnvAllowlist is the environment variables recorded in session.env
+	// (nil = DefaultEnvAllowlist). Names that look secret are never
+	// recorded (see CaptureSessionEnv).
+	EnvAllowlist []string
+
+	// SeparateStderr records without `script`, on a pseudo-terminal of its
+	// own with the command's stderr on a pipe, listing what it wrote there
+	// in session.stderr (see StderrPath) so it can be shown apart. Linux
+	// and macOS only (see ErrSeparateStderrUnsupported).
+	SeparateStderr bool
+
+	// Banner lets `script` print its "Script started, output log file is
+	// ..." and "Script done" messages to the terminal, which it is
+	// otherwise told not to (-q). BSD script (macOS) also leaves its start
+	// and done lines, with the command's exit status, out of the session
+	// log when quiet, so the recording lists no duration there.
+	Banner bool
+}
+
+// ErrRecordingTimedOut is returned when RecordOptions.Timeout stopped the
+// recording. The session log holds the output up to that point and can
+// be converted as usual.
+var ErrRecordingTimedOut = errors.New("recording stopped after timeout")
+
+// killGracePeriod is how long `script` has to exit after SIGTERM before it
+// is killed.
+var killGracePeriod = 5 * time.Second
+
 // RecordSession executes the `script` command to record a terminal session.
 // The `script` command reads from stdin and writes terminal output to a file.
+// The terminal size is captured to session.meta beforehand (see SessionMeta),
+// and the working directory, shell and allowlisted environment variables to
+// session.env (see SessionEnv).
 //
 // Args:
 //   - outputPath: Path to the session.log file to create
 //   - args: Command and arguments to execute within the session
 //           If empty, script will use the default shell
 //
-// Returns error if script command fails or cannot be executed
-func RecordSession(outputPath string, args []string) error {
-	// Build command: script <outputPath> [additional args]
-	cmdArgs := []string{outputPath}
-	cmdArgs = append(cmdArgs, args...)
+// A recorded command that exits non-zero is still a recording, so its exit
+// status is not an error here: util-linux script isn't asked for it, and
+// BSD script's is ignored. Use RecordSessionDetailed for it.
+//
+// Returns ErrRecordingTimedOut if opts[0].Timeout stopped the recording,
+// or another error if script command fails or cannot be executed
+func RecordSession(outputPath string, args []string, opts ...RecordOptions) error {
+	o := recordOptions(opts)
+	writeSessionMeta(outputPath, args, o)
+	writeSessionEnv(outputPath, args, o)
+	if o.SeparateStderr {
+		_, err := recordSeparateStderr(context.Background(), outputPath, args, o)
+		return ignoreExitStatus(err)
+	}
 
-	cmd := exec.Command("script", cmdArgs...)
+	cmd := exec.Command("script", scriptArgs(outputPath, args, o, false)...)
 
 	// Inherit stdin/stdout/stderr so user can interact with the recorded session
 	cmd.Stdin = os.Stdin
@@ -29,27 +88,53 @@ func RecordSession(outputPath string, args []string) error {
 	cmd.Stderr = os.Stderr
 
 	// Execute script command
-	err := cmd.Run()
+	timedOut, err := runScript(cmd, o.Timeout)
+	if timedOut {
+		return ErrRecordingTimedOut
+	}
+	if !utilLinuxScript() {
+		// BSD script exits with the command's status
+		err = ignoreExitStatus(err)
+	}
 	if err != nil {
-		// script returns exit code 0 normally, so any error is a real problem
 		return fmt.Errorf("script command failed: %w", err)
 	}
 
 	return nil
 }
 
+// ignoreExitStatus returns nil if err only reports the recorded command's
+// non-zero exit status, and err otherwise.
+func ignoreExitStatus(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
+
 // RecordSessionDetailed is like RecordSession but returns more info about execution
-// Returns: exit code, error
-func RecordSessionDetailed(outputPath string, args []string) (int, error) {
-	cmdArgs := []string{outputPath}
-	cmdArgs = append(cmdArgs, args...)
+// Returns: exit code, error (ErrRecordingTimedOut if the timeout stopped it)
+//
+// The exit code is the recorded command's: BSD script exits with it, and
+// util-linux script is passed --return to do the same.
+func RecordSessionDetailed(outputPath string, args []string, opts ...RecordOptions) (int, error) {
+	o := recordOptions(opts)
+	writeSessionMeta(outputPath, args, o)
+	writeSessionEnv(outputPath, args, o)
+	if o.SeparateStderr {
+		return recordSeparateStderr(context.Background(), outputPath, args, o)
+	}
 
-	cmd := exec.Command("script", cmdArgs...)
+	cmd := exec.Command("script", scriptArgs(outputPath, args, o, true)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
-	err := cmd.Run()
+	timedOut, err := runScript(cmd, o.Timeout)
+	if timedOut {
+		return exitCode(err), ErrRecordingTimedOut
+	}
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
@@ -61,3 +146,205 @@ func RecordSessionDetailed(outputPath string, args []string) (int, error) {
 
 	return 0, nil
 }
+
+// RecordSessionContext is like RecordSessionDetailed but stops the
+// recording when ctx is done, for programs embedding record-tui that manage
+// its lifecycle: `script` gets SIGTERM, so it restores the terminal and
+// flushes its log, then SIGKILL if it hasn't exited after killGracePeriod.
+// The session log keeps the output up to that point and can be converted
+// as usual.
+//
+// Returns the exit code, and ctx's error (e.g. context.Canceled) if ctx
+// stopped the recording, or ErrRecordingTimedOut if opts[0].Timeout did.
+func RecordSessionContext(ctx context.Context, outputPath string, args []string, opts ...RecordOptions) (int, error) {
+	o := recordOptions(opts)
+	writeSessionMeta(outputPath, args, o)
+	writeSessionEnv(outputPath, args, o)
+	if o.SeparateStderr {
+		return recordSeparateStderr(ctx, outputPath, args, o)
+	}
+
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, o.Timeout, ErrRecordingTimedOut)
+		defer cancel()
+	}
+
+	err := runScriptContext(ctx, scriptCommand(ctx, scriptArgs(outputPath, args, o, true)))
+	if ctx.Err() != nil {
+		return exitCode(err), context.Cause(ctx)
+	}
+	if err != nil {
+		return exitCode(err), fmt.Errorf("script command failed: %w", err)
+	}
+	return 0, nil
+}
+
+// scriptCommand returns the `script` command with args, attached to the
+// terminal and stopped as RecordSessionContext describes once ctx is done.
+func scriptCommand(ctx context.Context, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "script", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = killGracePeriod
+	return cmd
+}
+
+// runScriptContext runs cmd (see scriptCommand) to completion and, if ctx
+// stopped it by a signal `script` didn't handle, resets the terminal it may
+// have left in raw mode. Returns the error from cmd.Wait.
+func runScriptContext(ctx context.Context, cmd *exec.Cmd) error {
+	err := cmd.Run()
+	if ctx.Err() != nil && exitCode(err) == -1 {
+		restoreTerminal()
+	}
+	return err
+}
+
+// scriptArgs builds the script arguments: [-a] [-q] <outputPath> [args...]
+// for BSD script, or [-a] [--quiet] [--return] [-c "<args>"] <outputPath>
+// for util-linux script (see utilLinuxScript), with --return if
+// exitStatus: whether script should exit with the command's status.
+func scriptArgs(outputPath string, args []string, o RecordOptions, exitStatus bool) []string {
+	var cmdArgs []string
+	if o.Append {
+		cmdArgs = append(cmdArgs, "-a")
+	}
+	if utilLinuxScript() {
+		if !o.Banner {
+			cmdArgs = append(cmdArgs, "--quiet")
+		}
+		if exitStatus {
+			// --return: exit with the command's status, as BSD script does
+			cmdArgs = append(cmdArgs, "--return")
+		}
+		if len(args) > 0 {
+			cmdArgs = append(cmdArgs, "-c", shellJoin(args))
+		}
+		return append(cmdArgs, outputPath)
+	}
+	if !o.Banner {
+		cmdArgs = append(cmdArgs, "-q") // BSD script has no --quiet
+	}
+	cmdArgs = append(cmdArgs, outputPath)
+	return append(cmdArgs, args...)
+}
+
+// utilLinuxScript reports whether the `script` on PATH is util-linux's,
+// which takes the command as a -c string and exits 0 whatever it returned
+// unless given --return, rather than BSD's (macOS), which takes it as
+// arguments and exits with its status. Probed once, from `script --help`;
+// a util-linux script too old for --return is treated as BSD's.
+var utilLinuxScript = sync.OnceValue(probeUtilLinuxScript)
+
+func probeUtilLinuxScript() bool {
+	if runtime.GOOS == "darwin" {
+		return false
+	}
+	out, _ := exec.Command("script", "--help").CombinedOutput()
+	return bytes.Contains(out, []byte("--return"))
+}
+
+// shellJoin quotes args for `sh -c`, which util-linux script runs its -c
+// command with.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// writeSessionMeta records the session geometry next to outputPath, unless
+// appending to a recording that already has it.
+// Best effort: without session.meta the HTML falls back to guessing the
+// width from content, so a failure here shouldn't stop the recording.
+func writeSessionMeta(outputPath string, args []string, o RecordOptions) {
+	metaPath := MetaPath(outputPath)
+	if o.Append {
+		if _, err := os.Stat(metaPath); err == nil {
+			return
+		}
+	}
+	WriteSessionMeta(metaPath, CaptureSessionMeta(args))
+}
+
+// writeSessionEnv records the session context next to outputPath, unless
+// appending to a recording that already has it. Best effort, like
+// writeSessionMeta: session.env is only informational.
+func writeSessionEnv(outputPath string, args []string, o RecordOptions) {
+	envPath := EnvPath(outputPath)
+	if o.Append {
+		if _, err := os.Stat(envPath); err == nil {
+			return
+		}
+	}
+	WriteSessionEnv(envPath, CaptureSessionEnv(args, o.EnvAllowlist))
+}
+
+// recordOptions returns the first of opts, or the zero value.
+func recordOptions(opts []RecordOptions) RecordOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return RecordOptions{}
+}
+
+// runScript runs cmd to completion, or stops it once timeout elapses
+// (0 = no limit): SIGTERM first so `script` restores the terminal and
+// flushes its log, then SIGKILL after killGracePeriod.
+// Returns whether the timeout fired, and the error from cmd.Wait.
+func runScript(cmd *exec.Cmd, timeout time.Duration) (bool, error) {
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	if timeout <= 0 {
+		return false, cmd.Wait()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return false, err
+	case <-timer.C:
+	}
+
+	cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case err := <-done:
+		return true, err
+	case <-time.After(killGracePeriod):
+	}
+	cmd.Process.Kill()
+	err := <-done
+	// script had no chance to take the terminal out of raw mode
+	restoreTerminal()
+	return true, err
+}
+
+// restoreTerminal resets the terminal on stdin to sane settings.
+func restoreTerminal() {
+	stty := exec.Command("stty", "sane")
+	stty.Stdin = os.Stdin
+	stty.Run()
+}
+
+// exitCode returns the exit status from a cmd.Wait error
+// (0 for nil, -1 if the process was killed by a signal).
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
@@ -1,10 +1,16 @@
 package record
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 // skipIfNotMacOS skips the test if not running on macOS.
@@ -126,3 +132,304 @@ func TestRecordSessionDetailed_ReturnCode(t *testing.T) {
 
 	t.Logf("✓ RecordSessionDetailed completed with exit code: %d", exitCode)
 }
+
+// TestRunScript_Timeout verifies a command running past the timeout is stopped
+func TestRunScript_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs POSIX signals")
+	}
+
+	start := time.Now()
+	timedOut, _ := runScript(exec.Command("sleep", "10"), 50*time.Millisecond)
+	if !timedOut {
+		t.Error("expected the timeout to fire")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("sleep should have been stopped by SIGTERM, took %v", elapsed)
+	}
+}
+
+// TestRunScript_KillsAfterGracePeriod verifies SIGKILL follows an ignored SIGTERM
+func TestRunScript_KillsAfterGracePeriod(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs POSIX signals")
+	}
+	defer func(d time.Duration) { killGracePeriod = d }(killGracePeriod)
+	killGracePeriod = 100 * time.Millisecond
+
+	timedOut, err := runScript(exec.Command("sh", "-c", `trap "" TERM; while :; do sleep 0.05; done`), 50*time.Millisecond)
+	if !timedOut {
+		t.Error("expected the timeout to fire")
+	}
+	if exitCode(err) != -1 {
+		t.Errorf("expected the process to be killed by a signal, got %v", err)
+	}
+}
+
+// TestRunScript_NoTimeout verifies commands finishing in time are unaffected
+func TestRunScript_NoTimeout(t *testing.T) {
+	timedOut, err := runScript(exec.Command("true"), time.Minute)
+	if timedOut || err != nil {
+		t.Errorf("got (%v, %v), want (false, nil)", timedOut, err)
+	}
+	if _, err := runScript(exec.Command("false"), 0); exitCode(err) != 1 {
+		t.Errorf("exit code = %d, want 1", exitCode(err))
+	}
+}
+
+// TestRecordSessionContext_Cancel verifies cancelling stops a long recording
+// and keeps the output captured so far
+func TestRecordSessionContext_Cancel(t *testing.T) {
+	skipIfNotMacOS(t)
+
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(500*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := RecordSessionContext(ctx, outputPath, []string{"sh", "-c", "echo partial; sleep 30"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("recording should have stopped on cancel, took %v", elapsed)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil || !strings.Contains(string(content), "partial") {
+		t.Errorf("expected partial output in session.log, got %q (%v)", content, err)
+	}
+}
+
+// TestRunScriptContext_Cancel verifies the same with Linux script syntax
+func TestRunScriptContext_Cancel(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skipf("Skipping test on %s: uses Linux script syntax", runtime.GOOS)
+	}
+	if _, err := exec.LookPath("script"); err != nil {
+		t.Skip("script not installed")
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(500*time.Millisecond, cancel)
+
+	start := time.Now()
+	cmd := scriptCommand(ctx, []string{"-q", "-c", "echo partial; sleep 30", outputPath})
+	cmd.Stdin, cmd.Stdout = nil, nil
+	runScriptContext(ctx, cmd)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("recording should have stopped on cancel, took %v", elapsed)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil || !strings.Contains(string(content), "partial") {
+		t.Errorf("expected partial output in session.log, got %q (%v)", content, err)
+	}
+}
+
+// TestRecordSessionContext_Timeout verifies RecordOptions.Timeout reports
+// ErrRecordingTimedOut rather than a context error
+func TestRecordSessionContext_Timeout(t *testing.T) {
+	skipIfNotMacOS(t)
+
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	_, err := RecordSessionContext(context.Background(), outputPath, []string{"sleep", "30"},
+		RecordOptions{Timeout: 200 * time.Millisecond})
+	if !errors.Is(err, ErrRecordingTimedOut) {
+		t.Errorf("expected ErrRecordingTimedOut, got %v", err)
+	}
+}
+
+// TestScriptArgs verifies -a is passed when appending
+// useUtilLinuxScript makes scriptArgs build arguments for util-linux
+// script if on, BSD script otherwise, for the rest of the test
+func useUtilLinuxScript(t *testing.T, on bool) {
+	saved := utilLinuxScript
+	utilLinuxScript = func() bool { return on }
+	t.Cleanup(func() { utilLinuxScript = saved })
+}
+
+func TestScriptArgs(t *testing.T) {
+	useUtilLinuxScript(t, false)
+	got := strings.Join(scriptArgs("session.log", []string{"echo", "hi"}, RecordOptions{}, true), " ")
+	if got != "-q session.log echo hi" {
+		t.Errorf("got %q", got)
+	}
+	got = strings.Join(scriptArgs("session.log", nil, RecordOptions{Append: true, Banner: true}, true), " ")
+	if got != "-a session.log" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestScriptArgs_UtilLinux(t *testing.T) {
+	useUtilLinuxScript(t, true)
+	got := scriptArgs("session.log", []string{"sh", "-c", "echo 'hi'"}, RecordOptions{Append: true}, true)
+	want := []string{"-a", "--quiet", "--return", "-c", `'sh' '-c' 'echo '\''hi'\'''`, "session.log"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got = scriptArgs("session.log", nil, RecordOptions{Banner: true}, true)
+	if strings.Join(got, " ") != "--return session.log" {
+		t.Errorf("got %q", got)
+	}
+	got = scriptArgs("session.log", nil, RecordOptions{}, false)
+	if strings.Join(got, " ") != "--quiet session.log" {
+		t.Errorf("got %q", got)
+	}
+}
+
+// fakeUtilLinuxScript is a stand-in for util-linux script: it runs the -c
+// command with its output going to the log file, and exits with the
+// command's status only if given --return
+const fakeUtilLinuxScript = `#!/bin/sh
+if [ "$1" = "--help" ]; then
+  echo " -e, --return                  return exit code of the child process"
+  exit 0
+fi
+ret= cmd=
+while [ $# -gt 1 ]; do
+  case "$1" in
+    --return) ret=1 ;;
+    -c) shift; cmd=$1 ;;
+  esac
+  shift
+done
+: >>"$1" || exit 1
+sh -c "$cmd" >"$1"
+status=$?
+[ -n "$ret" ] && exit $status
+exit 0
+`
+
+// TestRecordSessionDetailed_UtilLinuxExitCode verifies a failing recorded
+// command's exit code is reported through util-linux script
+func TestRecordSessionDetailed_UtilLinuxExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skipf("Skipping test on %s: the fake script stands in for util-linux", runtime.GOOS)
+	}
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "script"), []byte(fakeUtilLinuxScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	useUtilLinuxScript(t, probeUtilLinuxScript())
+	if !utilLinuxScript() {
+		t.Fatal("the fake script should be detected as util-linux script")
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	code, err := RecordSessionDetailed(outputPath, []string{"sh", "-c", "echo failing; exit 3"})
+	if code != 3 || err == nil {
+		t.Errorf("RecordSessionDetailed = %d, %v; want exit code 3 and an error", code, err)
+	}
+	content, _ := os.ReadFile(outputPath)
+	if !strings.Contains(string(content), "failing") {
+		t.Errorf("expected the command's output in session.log, got %q", content)
+	}
+
+	code, err = RecordSessionDetailed(outputPath, []string{"true"})
+	if code != 0 || err != nil {
+		t.Errorf("RecordSessionDetailed = %d, %v; want 0, nil", code, err)
+	}
+}
+
+// TestRecordSession_FailingCommand verifies a recorded command that exits
+// non-zero still records successfully, while script failing is an error
+func TestRecordSession_FailingCommand(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skipf("Skipping test on %s: the fake script stands in for util-linux", runtime.GOOS)
+	}
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "script"), []byte(fakeUtilLinuxScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	useUtilLinuxScript(t, probeUtilLinuxScript())
+
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	if err := RecordSession(outputPath, []string{"sh", "-c", "echo hi; exit 3"}); err != nil {
+		t.Fatalf("RecordSession: %v", err)
+	}
+	content, _ := os.ReadFile(outputPath)
+	if !strings.Contains(string(content), "hi") {
+		t.Errorf("expected the command's output in session.log, got %q", content)
+	}
+
+	if err := RecordSession(filepath.Join(t.TempDir(), "missing", "session.log"), []string{"true"}); err == nil {
+		t.Error("expected an error when script cannot write the log")
+	}
+
+	useUtilLinuxScript(t, false)
+	binDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "script"), []byte("#!/bin/sh\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if err := RecordSession(outputPath, []string{"false"}); err != nil {
+		t.Errorf("RecordSession with BSD script exiting with the command's status: %v", err)
+	}
+}
+
+// argsRecordingScript is a stand-in for script that saves the arguments
+// it was run with, one per line, to $SCRIPT_ARGS_FILE
+const argsRecordingScript = `#!/bin/sh
+[ "$1" = "--help" ] && exit 0
+printf '%s\n' "$@" >"$SCRIPT_ARGS_FILE"
+`
+
+// TestRecordSession_Quiet verifies script is told to be quiet unless
+// RecordOptions.Banner is set, with the flag its platform's script takes
+func TestRecordSession_Quiet(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test on windows: the fake script is a shell script")
+	}
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "script"), []byte(argsRecordingScript), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	argsFile := filepath.Join(t.TempDir(), "args")
+	t.Setenv("SCRIPT_ARGS_FILE", argsFile)
+
+	for _, tc := range []struct {
+		utilLinux bool
+		quiet     string
+	}{
+		{utilLinux: true, quiet: "--quiet"},
+		{utilLinux: false, quiet: "-q"},
+	} {
+		useUtilLinuxScript(t, tc.utilLinux)
+		for _, banner := range []bool{false, true} {
+			outputPath := filepath.Join(t.TempDir(), "session.log")
+			if err := RecordSession(outputPath, []string{"true"}, RecordOptions{Banner: banner}); err != nil {
+				t.Fatalf("RecordSession: %v", err)
+			}
+			data, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			args := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if slices.Contains(args, tc.quiet) == banner {
+				t.Errorf("util-linux %t, Banner %t: script got %q", tc.utilLinux, banner, args)
+			}
+		}
+	}
+}
+
+// TestWriteSessionMeta_AppendKeepsExisting verifies appending doesn't
+// overwrite the first recording's session.meta
+func TestWriteSessionMeta_AppendKeepsExisting(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	if err := WriteSessionMeta(MetaPath(outputPath), SessionMeta{Cols: 123, Rows: 45}); err != nil {
+		t.Fatal(err)
+	}
+
+	writeSessionMeta(outputPath, []string{"bash"}, RecordOptions{Append: true})
+
+	meta, err := ReadSessionMeta(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Cols != 123 || meta.Rows != 45 {
+		t.Errorf("session.meta was overwritten: %+v", meta)
+	}
+}
@@ -0,0 +1,174 @@
+package record
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/choonkeat/record-tui/internal/logfile"
+	"github.com/choonkeat/record-tui/internal/session"
+)
+
+// RecordingDirFormat is the time layout of recording directory names
+// (~/.record-tui/YYYYMMDD-HHMMSS/).
+const RecordingDirFormat = "20060102-150405"
+
+// Recording describes a past recording directory.
+type Recording struct {
+	Dir      string        // Absolute path of the recording directory
+	Started  time.Time     // Parsed from the directory name
+	Duration time.Duration // From the log header/footer timestamps (0 if unknown)
+	Command  string        // Recorded command from the log header ("" if unknown)
+	Size     int64         // Total size of files in the directory, in bytes
+}
+
+// HTMLPath returns the path of the recording's generated HTML.
+func (r Recording) HTMLPath() string {
+	return filepath.Join(r.Dir, "session.log.html")
+}
+
+// ListRecordings returns the recordings under baseDir, newest first.
+// Directories whose names aren't recording timestamps are skipped.
+// A missing baseDir yields no recordings.
+func ListRecordings(baseDir string) ([]Recording, error) {
+	entries, err := os.ReadDir(baseDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recordings []Recording
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		started, err := time.ParseInLocation(RecordingDirFormat, entry.Name(), time.Local)
+		if err != nil {
+			continue
+		}
+		rec := Recording{
+			Dir:     filepath.Join(baseDir, entry.Name()),
+			Started: started,
+			Size:    dirSize(filepath.Join(baseDir, entry.Name())),
+		}
+		if head, tail, err := headTail(filepath.Join(rec.Dir, "session.log"), 4096); err == nil {
+			rec.Command, rec.Duration = parseScriptMetadata(head, tail)
+		}
+		recordings = append(recordings, rec)
+	}
+
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].Started.After(recordings[j].Started)
+	})
+	return recordings, nil
+}
+
+// dirSize returns the total size of regular files under dir.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// headTail returns up to n bytes from the start and end of a log file
+// without reading the whole file (gzip logs are streamed through).
+func headTail(path string, n int) (string, string, error) {
+	rc, err := logfile.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer rc.Close()
+
+	head := make([]byte, n)
+	m, err := io.ReadFull(rc, head)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Whole file fits in the head
+		return string(head[:m]), string(head[:m]), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	// Plain files can seek straight to the tail
+	if f, ok := rc.(*os.File); ok {
+		if _, err := f.Seek(-int64(n), io.SeekEnd); err == nil {
+			tail, err := io.ReadAll(f)
+			return string(head), string(tail), err
+		}
+	}
+
+	// Otherwise keep a sliding window of the last n bytes
+	tail := make([]byte, 0, 2*n)
+	buf := make([]byte, 32*1024)
+	for {
+		m, err := rc.Read(buf)
+		tail = append(tail, buf[:m]...)
+		if len(tail) > n {
+			tail = append(tail[:0], tail[len(tail)-n:]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return string(head), string(tail), nil
+}
+
+var (
+	// Linux: Script started on 2026-01-12 06:41:43+00:00 [COMMAND="claude" TERM="xterm-256color"]
+	// macOS: Script started on Wed Dec 31 12:10:34 2025
+	//        Script started on Tue Oct  8 09:41:22 2024 on /dev/ttys003
+	scriptStartedPattern = regexp.MustCompile(`Script started on ([^\r\n\[]+)`)
+	scriptDonePattern    = regexp.MustCompile(`Script done on ([^\r\n\[]+)`)
+)
+
+// scriptTimeLayouts are the timestamp formats written by `script`.
+var scriptTimeLayouts = []string{
+	"2006-01-02 15:04:05-07:00", // util-linux
+	"Mon Jan _2 15:04:05 2006",  // macOS/BSD
+}
+
+// parseScriptMetadata extracts the recorded command and the session duration
+// from the header (head) and footer (tail) of a session log.
+func parseScriptMetadata(head, tail string) (string, time.Duration) {
+	command := session.ExtractCommand(head)
+
+	var duration time.Duration
+	started, ok1 := findScriptTime(scriptStartedPattern, head)
+	done, ok2 := findScriptTime(scriptDonePattern, tail)
+	if ok1 && ok2 && !done.Before(started) {
+		duration = done.Sub(started)
+	}
+	return command, duration
+}
+
+func findScriptTime(pattern *regexp.Regexp, s string) (time.Time, bool) {
+	m := pattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false
+	}
+	// Drop the TTY some macOS versions add (" on /dev/ttys003")
+	value, _, _ := strings.Cut(m[1], " on ")
+	value = strings.TrimSpace(value)
+	for _, layout := range scriptTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,128 @@
+package record
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseScriptMetadata_Linux(t *testing.T) {
+	head := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"claude --resume\" TERM=\"xterm-256color\"]\nhello\n"
+	tail := "bye\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+
+	command, duration := parseScriptMetadata(head, tail)
+	if command != "claude --resume" {
+		t.Errorf("command = %q, want %q", command, "claude --resume")
+	}
+	if duration != 3*time.Minute+17*time.Second {
+		t.Errorf("duration = %v, want 3m17s", duration)
+	}
+}
+
+func TestParseScriptMetadata_MacOS(t *testing.T) {
+	head := "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n$ ls\n"
+	tail := "$ exit\n\nSaving session...\nScript done on Wed Dec 31 12:11:22 2025\n"
+
+	command, duration := parseScriptMetadata(head, tail)
+	if command != "bash" {
+		t.Errorf("command = %q, want %q", command, "bash")
+	}
+	if duration != 48*time.Second {
+		t.Errorf("duration = %v, want 48s", duration)
+	}
+}
+
+func TestParseScriptMetadata_MacOSWithTTY(t *testing.T) {
+	head := "Script started on Tue Oct  8 09:41:22 2024 on /dev/ttys003\nCommand: zsh\n% ls\n"
+	tail := "% exit\n\nSaving session...\nCommand exit status: 0\nScript done on Tue Oct  8 09:42:02 2024 on /dev/ttys003\n"
+
+	_, duration := parseScriptMetadata(head, tail)
+	if duration != 40*time.Second {
+		t.Errorf("duration = %v, want 40s", duration)
+	}
+}
+
+func TestParseScriptMetadata_MissingFooter(t *testing.T) {
+	// Interrupted recordings have no footer: duration is unknown
+	command, duration := parseScriptMetadata("Script started on 2026-01-12 06:41:43+00:00\n", "partial output")
+	if command != "" || duration != 0 {
+		t.Errorf("got (%q, %v), want empty command and zero duration", command, duration)
+	}
+}
+
+func TestListRecordings(t *testing.T) {
+	baseDir := t.TempDir()
+
+	writeLog := func(name, content string) {
+		dir := filepath.Join(baseDir, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "session.log"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeLog("20260101-090000", "Script started on Wed Dec 31 12:10:34 2025\nCommand: vim\n"+
+		strings.Repeat("x", 10000)+"\nScript done on Wed Dec 31 12:11:22 2025\n")
+	writeLog("20260102-090000", "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"ls\"]\n")
+	// Not a recording directory
+	os.MkdirAll(filepath.Join(baseDir, "notes"), 0755)
+
+	recordings, err := ListRecordings(baseDir)
+	if err != nil {
+		t.Fatalf("ListRecordings failed: %v", err)
+	}
+	if len(recordings) != 2 {
+		t.Fatalf("expected 2 recordings, got %d", len(recordings))
+	}
+
+	// Newest first
+	if filepath.Base(recordings[0].Dir) != "20260102-090000" {
+		t.Errorf("first recording = %s, want newest", recordings[0].Dir)
+	}
+	if recordings[0].Command != "ls" || recordings[0].Duration != 0 {
+		t.Errorf("unexpected metadata for unfinished recording: %+v", recordings[0])
+	}
+
+	// Footer is found past the head of a larger log
+	old := recordings[1]
+	if old.Command != "vim" || old.Duration != 48*time.Second {
+		t.Errorf("unexpected metadata: command=%q duration=%v", old.Command, old.Duration)
+	}
+	if old.Size < 10000 {
+		t.Errorf("size = %d, want at least the log size", old.Size)
+	}
+	if old.HTMLPath() != filepath.Join(old.Dir, "session.log.html") {
+		t.Errorf("HTMLPath() = %q", old.HTMLPath())
+	}
+}
+
+func TestListRecordings_MissingBaseDir(t *testing.T) {
+	recordings, err := ListRecordings(filepath.Join(t.TempDir(), "nope"))
+	if err != nil || recordings != nil {
+		t.Errorf("got (%v, %v), want no recordings and no error", recordings, err)
+	}
+}
+
+func TestHeadTail_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("HEAD" + strings.Repeat(".", 100000) + "TAIL"))
+	gz.Close()
+	f.Close()
+
+	head, tail, err := headTail(path, 16)
+	if err != nil {
+		t.Fatalf("headTail failed: %v", err)
+	}
+	if !strings.HasPrefix(head, "HEAD") || !strings.HasSuffix(tail, "TAIL") || len(tail) != 16 {
+		t.Errorf("headTail = (%q, %q)", head, tail)
+	}
+}
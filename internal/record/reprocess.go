@@ -0,0 +1,27 @@
+package record
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Reprocess regenerates the HTML of the recording in dir, e.g. after
+// upgrading record-tui, without recording anything: its session.log (or
+// session.log.gz, for a compressed recording) is converted again with the
+// current cleaning and, if the timing and input logs are alongside it, table
+// of contents logic. The page is written to session.log.html in dir,
+// replacing the old one.
+//
+// Returns the path to the generated HTML file, or error if conversion
+// fails: ErrSessionNotFound if dir has no session log.
+func Reprocess(dir string, opts ...ConvertOptions) (string, error) {
+	sessionLogPath := filepath.Join(dir, "session.log")
+	if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
+		sessionLogPath += ".gz"
+		if _, err := os.Stat(sessionLogPath); os.IsNotExist(err) {
+			return "", fmt.Errorf("%w in %s", ErrSessionNotFound, dir)
+		}
+	}
+	return ConvertSessionToHTMLWithPath(sessionLogPath, Recording{Dir: dir}.HTMLPath(), opts...)
+}
@@ -0,0 +1,68 @@
+package record
+
+import (
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReprocess(t *testing.T) {
+	dir := t.TempDir()
+	sessionContent := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ ls\nfile1\nfile2\n$ npm test\nPASS\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	files := map[string]string{
+		"session.log":      sessionContent,
+		"session.timing":   "O 0.010 4\nI 0.500 3\nO 0.010 18\nI 1.000 9\nO 0.010 5\n",
+		"session.input":    "ls\rnpm test\r",
+		"session.log.html": "stale page",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	htmlPath, err := Reprocess(dir)
+	if err != nil {
+		t.Fatalf("Reprocess failed: %v", err)
+	}
+	if htmlPath != filepath.Join(dir, "session.log.html") {
+		t.Errorf("htmlPath = %q, want session.log.html in the recording directory", htmlPath)
+	}
+	htmlBytes, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("Failed to read HTML: %v", err)
+	}
+	if html := string(htmlBytes); strings.Contains(html, "stale page") || !strings.Contains(html, `"npm test"`) {
+		t.Errorf("expected a regenerated page with navigation, got %d bytes", len(html))
+	}
+}
+
+func TestReprocess_Gzip(t *testing.T) {
+	dir := t.TempDir()
+	f, err := os.Create(filepath.Join(dir, "session.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte("Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"ls\"]\nfile1\n"))
+	gz.Close()
+	f.Close()
+
+	htmlPath, err := Reprocess(dir)
+	if err != nil {
+		t.Fatalf("Reprocess failed: %v", err)
+	}
+	if filepath.Base(htmlPath) != "session.log.html" {
+		t.Errorf("htmlPath = %q, want session.log.html", htmlPath)
+	}
+}
+
+func TestReprocess_NoSessionLog(t *testing.T) {
+	if _, err := Reprocess(t.TempDir()); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected missing session.log error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/choonkeat/record-tui/internal/logfile"
+	"github.com/choonkeat/record-tui/playback"
+)
+
+// ErrSeparateStderrUnsupported is returned when RecordOptions.SeparateStderr
+// is set on a platform without pseudo-terminal support here (only Linux
+// and macOS have it).
+var ErrSeparateStderrUnsupported = errors.New("recording stderr separately is only supported on Linux and macOS")
+
+// StderrPath returns the session.stderr path for a session log
+// (session.log or session.log.gz): the spans of session.log the command
+// wrote to stderr, in a recording made with RecordOptions.SeparateStderr
+// (see playback.ParseStreamRanges).
+func StderrPath(sessionLogPath string) string {
+	return logfile.CompanionPath(sessionLogPath, ".stderr")
+}
+
+// ReadStderrRanges reads the session.stderr next to a session log.
+// Returns an error if there is none, i.e. stderr wasn't recorded
+// separately.
+func ReadStderrRanges(sessionLogPath string) ([]playback.StreamRange, error) {
+	f, err := os.Open(StderrPath(sessionLogPath))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return playback.ParseStreamRanges(f)
+}
+
+// markStderr colors the stderr output in raw session.log content, if the
+// recording has a session.stderr (see playback.MarkStderr). Recordings
+// made that way have no timing file, so content is always the whole log.
+func markStderr(sessionLogPath, content string) string {
+	ranges, err := ReadStderrRanges(sessionLogPath)
+	if err != nil {
+		return content
+	}
+	return playback.MarkStderr(content, ranges)
+}
+
+// streamLog writes a session.log in `script` format from a command's
+// stdout and stderr, as they arrive, listing the spans stderr wrote in
+// session.stderr. Safe for concurrent use by the two streams' copiers.
+type streamLog struct {
+	mu     sync.Mutex
+	log    io.Writer
+	stderr io.Writer
+	offset int64 // Bytes of log written, including any appended to
+
+	// Whether the last stderr byte was a \r, for crlfWriter
+	stderrCR bool
+}
+
+// writeHeader writes the "Script started on" line util-linux script
+// writes, so the log cleans like any other.
+func (l *streamLog) writeHeader(started time.Time, args []string, cols, rows int) error {
+	var attrs []string
+	if len(args) > 0 {
+		attrs = append(attrs, `COMMAND="`+strings.Join(args, " ")+`"`)
+	}
+	if term := os.Getenv("TERM"); term != "" {
+		attrs = append(attrs, `TERM="`+term+`"`)
+	}
+	if cols > 0 && rows > 0 {
+		attrs = append(attrs, `COLUMNS="`+strconv.Itoa(cols)+`"`, `LINES="`+strconv.Itoa(rows)+`"`)
+	}
+	return l.write(fmt.Sprintf("Script started on %s [%s]\n", started.Format(scriptTimeLayout), strings.Join(attrs, " ")), false)
+}
+
+// writeFooter writes the "Script done on" line util-linux script writes.
+func (l *streamLog) writeFooter(done time.Time, exitStatus int) error {
+	return l.write(fmt.Sprintf("\nScript done on %s [COMMAND_EXIT_CODE=\"%d\"]\n", done.Format(scriptTimeLayout), exitStatus), false)
+}
+
+// scriptTimeLayout is how util-linux script writes times in its header
+// and footer.
+const scriptTimeLayout = "2006-01-02 15:04:05-07:00"
+
+// write appends text to the log, listing it in session.stderr if it came
+// from stderr.
+func (l *streamLog) write(text string, fromStderr bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := io.WriteString(l.log, text); err != nil {
+		return err
+	}
+	if fromStderr {
+		if _, err := fmt.Fprintf(l.stderr, "%d %d\n", l.offset, len(text)); err != nil {
+			return err
+		}
+	}
+	l.offset += int64(len(text))
+	return nil
+}
+
+// copyStream copies a stream of the command's output from src to the
+// terminal (dst) and the log until src ends. stderr, from a pipe rather
+// than the pseudo-terminal, gets its line feeds turned into \r\n as the
+// terminal would have, since the real terminal is in raw mode.
+func (l *streamLog) copyStream(dst io.Writer, src io.Reader, fromStderr bool) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			text := string(buf[:n])
+			if fromStderr {
+				text = l.crlf(text)
+			}
+			io.WriteString(dst, text)
+			l.write(text, fromStderr)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// crlf turns the line feeds in stderr text that aren't already preceded by
+// a \r (possibly at the end of the previous text) into \r\n.
+func (l *streamLog) crlf(text string) string {
+	var sb strings.Builder
+	prevCR := l.stderrCR
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' && !prevCR {
+			sb.WriteByte('\r')
+		}
+		sb.WriteByte(text[i])
+		prevCR = text[i] == '\r'
+	}
+	l.stderrCR = prevCR
+	return sb.String()
+}
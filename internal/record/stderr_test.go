@@ -0,0 +1,130 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/choonkeat/record-tui/internal/session"
+	"github.com/choonkeat/record-tui/playback"
+)
+
+// skipIfNoPTY skips the test where recordSeparateStderr is unsupported.
+func skipIfNoPTY(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skipf("Skipping test on %s: no pseudo-terminal support", runtime.GOOS)
+	}
+}
+
+func TestStreamLog(t *testing.T) {
+	var log, stderr strings.Builder
+	l := &streamLog{log: &log, stderr: &stderr}
+	at := time.Date(2026, 1, 12, 6, 41, 43, 0, time.UTC)
+
+	l.writeHeader(at, []string{"make", "test"}, 80, 24)
+	l.write("ok\r\n", false)
+	l.write(l.crlf("warn: x\n"), true)
+	l.write(l.crlf("a\r"), true)
+	l.write(l.crlf("\nb\n"), true)
+	l.writeFooter(at, 2)
+
+	header := `Script started on 2026-01-12 06:41:43+00:00 [COMMAND="make test" `
+	if !strings.HasPrefix(log.String(), header) || !session.IsHeaderLine(strings.SplitN(log.String(), "\n", 2)[0]) {
+		t.Errorf("log should start with a script header, got %q", log.String())
+	}
+	if got := session.StripMetadata(log.String(), session.CleanOptions{}); got != "ok\r\nwarn: x\r\na\r\nb" {
+		t.Errorf("cleaned log = %q", got)
+	}
+
+	ranges, err := playback.ParseStreamRanges(strings.NewReader(stderr.String()))
+	if err != nil {
+		t.Fatalf("ParseStreamRanges: %v", err)
+	}
+	var fromStderr string
+	for _, r := range ranges {
+		fromStderr += log.String()[r.Offset : r.Offset+r.Length]
+	}
+	if want := "warn: x\r\na\r\nb\r\n"; fromStderr != want {
+		t.Errorf("stderr ranges cover %q, want %q", fromStderr, want)
+	}
+}
+
+func TestSessionOutput_MarksStderr(t *testing.T) {
+	dir := t.TempDir()
+	sessionLogPath := filepath.Join(dir, "session.log")
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make\"]\nok\r\nboom\r\n"
+	os.WriteFile(sessionLogPath, []byte(content), 0644)
+
+	got, _, err := sessionOutput(sessionLogPath, []byte(content), ConvertOptions{})
+	if err != nil || got != content {
+		t.Fatalf("without session.stderr: got %q, %v", got, err)
+	}
+
+	offset := strings.Index(content, "boom")
+	os.WriteFile(StderrPath(sessionLogPath), []byte(fmt.Sprintf("%d 6\n", offset)), 0644)
+	got, _, err = sessionOutput(sessionLogPath, []byte(content), ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ok\r\n" + playback.StderrColor + "boom\r\n"; !strings.Contains(got, want) {
+		t.Errorf("stderr not colored: %q", got)
+	}
+}
+
+func TestRecordSessionDetailed_SeparateStderr(t *testing.T) {
+	skipIfNoPTY(t)
+
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	code, err := RecordSessionDetailed(outputPath, []string{"sh", "-c", "echo out; echo err >&2; exit 3"},
+		RecordOptions{SeparateStderr: true})
+	if code != 3 || err == nil {
+		t.Errorf("RecordSessionDetailed() = %d, %v, want exit code 3 and an error", code, err)
+	}
+
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleaned := session.StripMetadata(string(content), session.CleanOptions{})
+	// The streams are read separately, so either may come first
+	if !strings.Contains(cleaned, "out") || !strings.Contains(cleaned, "err") {
+		t.Errorf("cleaned log = %q, want both streams", cleaned)
+	}
+	if !strings.Contains(string(content), `[COMMAND_EXIT_CODE="3"]`) {
+		t.Errorf("log should end with a script footer, got %q", content)
+	}
+
+	ranges, err := ReadStderrRanges(outputPath)
+	if err != nil {
+		t.Fatalf("ReadStderrRanges: %v", err)
+	}
+	frames := playback.StreamFrames(string(content), ranges)
+	if frames[0].Content != "out" || frames[1].Content != "err" {
+		t.Errorf("stdout = %q, stderr = %q", frames[0].Content, frames[1].Content)
+	}
+}
+
+func TestRecordSessionContext_SeparateStderrTimeout(t *testing.T) {
+	skipIfNoPTY(t)
+
+	outputPath := filepath.Join(t.TempDir(), "session.log")
+	start := time.Now()
+	_, err := RecordSessionContext(context.Background(), outputPath, []string{"sh", "-c", "echo partial; sleep 30"},
+		RecordOptions{SeparateStderr: true, Timeout: 500 * time.Millisecond})
+	if !errors.Is(err, ErrRecordingTimedOut) {
+		t.Errorf("expected ErrRecordingTimedOut, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("recording should have stopped on timeout, took %v", elapsed)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil || !strings.Contains(string(content), "partial") {
+		t.Errorf("expected partial output in session.log, got %q (%v)", content, err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package record
+
+// terminalSize is unsupported on this platform; callers fall back to
+// COLUMNS/LINES.
+func terminalSize(fd int) (cols, rows int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin
+
+package record
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalSize returns the size of the terminal on fd via TIOCGWINSZ.
+// ok is false if fd isn't a terminal.
+func terminalSize(fd int) (cols, rows int, ok bool) {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 || ws.Col == 0 {
+		return 0, 0, false
+	}
+	return int(ws.Col), int(ws.Row), true
+}
@@ -0,0 +1,89 @@
+package record
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// exitStatusPattern matches the command's exit status in a session log
+// footer:
+//
+//	Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_CODE="0"]  (util-linux)
+//	Command exit status: 0                                           (macOS)
+//
+// COMMAND_EXIT_STATUS is matched too, as logs from older versions of
+// record-tui's stderr capture have it.
+var exitStatusPattern = regexp.MustCompile(`COMMAND_EXIT_(?:CODE|STATUS)="(\d+)"|Command exit status: (\d+)`)
+
+// parseExitStatus returns the last exit status in a session log's footer
+// (tail), or "" if it has none.
+func parseExitStatus(tail string) string {
+	matches := exitStatusPattern.FindAllStringSubmatch(tail, -1)
+	if matches == nil {
+		return ""
+	}
+	m := matches[len(matches)-1]
+	return m[1] + m[2]
+}
+
+// titleMetadataBytes is how much of each end of a session log metadataEnds
+// returns, as ListRecordings reads.
+const titleMetadataBytes = 4096
+
+// metadataEnds returns the ends of a session log holding its header and
+// footer.
+func metadataEnds(sessionContent []byte) (head, tail string) {
+	content := string(sessionContent)
+	if len(content) <= titleMetadataBytes {
+		return content, content
+	}
+	return content[:titleMetadataBytes], content[len(content)-titleMetadataBytes:]
+}
+
+// expandTitle resolves the placeholders in a ConvertOptions.Title template
+// from the session log's header and footer:
+//
+//	{command}   the recorded command (see session.ExtractCommand)
+//	{date}      the day recording started, e.g. 2026-01-12
+//	{exit}      the command's exit status
+//	{duration}  how long the recording took, e.g. 4m12s
+//
+// A placeholder the log has no value for is replaced with nothing. The
+// result is plain text; the page escapes it.
+func expandTitle(template string, sessionContent []byte) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+	head, tail := metadataEnds(sessionContent)
+	command, duration := parseScriptMetadata(head, tail)
+	var date, took string
+	if started, ok := findScriptTime(scriptStartedPattern, head); ok {
+		date = started.Format(time.DateOnly)
+	}
+	if duration > 0 {
+		took = duration.Round(time.Second).String()
+	}
+	return strings.NewReplacer(
+		"{command}", command,
+		"{date}", date,
+		"{exit}", parseExitStatus(tail),
+		"{duration}", took,
+	).Replace(template)
+}
+
+// shareDescription describes a recording for link previews (see
+// playback.Options.OpenGraph) from its header and footer, e.g.
+// "Terminal recording of make test (4m12s)". Empty if the log names no
+// command, leaving playback's default.
+func shareDescription(sessionContent []byte) string {
+	command, duration := parseScriptMetadata(metadataEnds(sessionContent))
+	if command == "" {
+		return ""
+	}
+	description := "Terminal recording of " + command
+	if duration > 0 {
+		description += " (" + duration.Round(time.Second).String() + ")"
+	}
+	return description
+}
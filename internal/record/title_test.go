@@ -0,0 +1,80 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandTitle(t *testing.T) {
+	linux := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"deploy\" TERM=\"xterm-256color\"]\nok\n" +
+		"Script done on 2026-01-12 06:45:55+00:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+	macOS := "Script started on Tue Oct  8 09:41:22 2024 on /dev/ttys003\nCommand: make\n% make\n" +
+		"\nSaving session...\nCommand exit status: 2\nScript done on Tue Oct  8 09:42:02 2024 on /dev/ttys003\n"
+
+	tests := []struct {
+		name, template, content, want string
+	}{
+		{"linux", "{command} — {date} — exit {exit}", linux, "deploy — 2026-01-12 — exit 0"},
+		{"duration", "{command} took {duration}", linux, "deploy took 4m12s"},
+		{"macOS", "{command} {date} exit {exit} in {duration}", macOS, "make 2024-10-08 exit 2 in 40s"},
+		{"older stderr capture", "exit {exit}", strings.Replace(linux, "COMMAND_EXIT_CODE", "COMMAND_EXIT_STATUS", 1), "exit 0"},
+		{"no footer", "{command} exit {exit} {duration}", "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"vim\"]\nx\n", "vim exit  "},
+		{"no placeholders", "My recording", linux, "My recording"},
+		{"unknown placeholder", "{host}: {command}", linux, "{host}: deploy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandTitle(tt.template, []byte(tt.content)); got != tt.want {
+				t.Errorf("expandTitle(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTitle_LongLog(t *testing.T) {
+	// The footer is searched for at the end, past the header
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"build\"]\n" +
+		strings.Repeat("output line\n", 1000) +
+		"Script done on 2026-01-12 06:41:53+00:00 [COMMAND_EXIT_CODE=\"1\"]\n"
+	if got, want := expandTitle("{command}: exit {exit} after {duration}", []byte(content)), "build: exit 1 after 10s"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestConvertSessionToHTML_TitleEscaped(t *testing.T) {
+	sessionLogPath := filepath.Join(t.TempDir(), "session.log")
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"cat <x> & y\"]\nok\n" +
+		"Script done on 2026-01-12 06:41:44+00:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+	if err := os.WriteFile(sessionLogPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	htmlPath, err := ConvertSessionToHTML(sessionLogPath, ConvertOptions{Title: "{command} ({exit})"})
+	if err != nil {
+		t.Fatalf("ConvertSessionToHTML failed: %v", err)
+	}
+	htmlBytes, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<title>cat &lt;x&gt; &amp; y (0)</title>"; !strings.Contains(string(htmlBytes), want) {
+		t.Errorf("HTML should contain %s", want)
+	}
+}
+
+func TestShareDescription(t *testing.T) {
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make test\"]\nok\n" +
+		"Script done on 2026-01-12 06:45:55+00:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+	if got, want := shareDescription([]byte(content)), "Terminal recording of make test (4m12s)"; got != want {
+		t.Errorf("shareDescription() = %q, want %q", got, want)
+	}
+	interrupted := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make test\"]\nok\n"
+	if got, want := shareDescription([]byte(interrupted)), "Terminal recording of make test"; got != want {
+		t.Errorf("without a footer: got %q, want %q", got, want)
+	}
+	if got := shareDescription([]byte("Script started on 2026-01-12 06:41:43+00:00\n$ ls\n")); got != "" {
+		t.Errorf("without a command: got %q, want empty", got)
+	}
+}
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// escapeSequenceRegex matches ANSI escape sequences (CSI, OSC and two-byte
+// ESC sequences), which are expected in terminal output and not counted as garbage.
+const escapeSequenceRegex = `\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[ -~]`
+
+var (
+	escapeSequencePattern = regexp.MustCompile(escapeSequenceRegex)
+	// Anchored variant for matching at a known ESC position
+	escapeSequencePrefix = regexp.MustCompile(`^(?:` + escapeSequenceRegex + `)`)
+)
+
+const (
+	// binarySampleSize is the size of each window sampled by LooksBinary.
+	binarySampleSize = 4096
+	// binarySamples is how many windows are sampled across the content.
+	binarySamples = 16
+	// binaryThreshold is the share of garbage characters that marks a window as binary.
+	// Plain text is ~0%; executables and compressed data are well above 30%.
+	binaryThreshold = 0.3
+)
+
+// LooksBinary reports whether content appears to contain binary data
+// (e.g. from `cat /bin/ls`) rather than terminal output.
+// It samples windows across the content; if any window has a high share of
+// NUL bytes, stray control characters or invalid UTF-8 once ANSI escape
+// sequences are removed, the content is considered binary.
+func LooksBinary(content string) bool {
+	if len(content) <= binarySampleSize*binarySamples {
+		for start := 0; start < len(content); start += binarySampleSize {
+			if windowLooksBinary(content[start:min(start+binarySampleSize, len(content))]) {
+				return true
+			}
+		}
+		return false
+	}
+	step := (len(content) - binarySampleSize) / (binarySamples - 1)
+	for i := 0; i < binarySamples; i++ {
+		start := i * step
+		if windowLooksBinary(content[start : start+binarySampleSize]) {
+			return true
+		}
+	}
+	return false
+}
+
+func windowLooksBinary(window string) bool {
+	text := escapeSequencePattern.ReplaceAllString(window, "")
+	var total, garbage int
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		// Windows cut runes at their edges; don't count those as garbage
+		edge := i < utf8.UTFMax || len(text)-i <= utf8.UTFMax
+		if isGarbageRune(r, size) && !(edge && r == utf8.RuneError) {
+			garbage++
+		}
+		total++
+		i += size
+	}
+	// Too little text left to judge (e.g. a window of pure escape sequences)
+	if total < 64 {
+		return false
+	}
+	return float64(garbage)/float64(total) > binaryThreshold
+}
+
+// isGarbageRune reports whether r is unexpected in terminal output.
+func isGarbageRune(r rune, size int) bool {
+	switch {
+	case r == utf8.RuneError && size == 1:
+		return true // invalid UTF-8
+	case r == '\t' || r == '\n' || r == '\r' || r == '\b' || r == '\a' || r == 0x0e || r == 0x0f:
+		return false // tab, newlines, backspace, bell, charset shifts
+	case r < 0x20 || r == 0x7f:
+		return true // NUL and other C0 controls, DEL
+	case r >= 0x80 && r <= 0x9f:
+		return true // C1 controls
+	}
+	return false
+}
+
+// SanitizeBinary removes characters that LooksBinary counts as garbage
+// (NUL and stray control characters, invalid UTF-8), keeping ANSI escape
+// sequences and ordinary text intact.
+func SanitizeBinary(content string) string {
+	var b strings.Builder
+	b.Grow(len(content))
+	for i := 0; i < len(content); {
+		if content[i] == 0x1b {
+			if loc := escapeSequencePrefix.FindStringIndex(content[i:]); loc != nil {
+				b.WriteString(content[i : i+loc[1]])
+				i += loc[1]
+				continue
+			}
+			// Lone or malformed ESC
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if !isGarbageRune(r, size) {
+			b.WriteString(content[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
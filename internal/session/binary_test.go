@@ -0,0 +1,62 @@
+package session
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLooksBinary_Text(t *testing.T) {
+	tests := map[string]string{
+		"empty":      "",
+		"plain":      "$ ls -la\r\ntotal 0\r\ndrwxr-xr-x  2 user  staff  64 Jan  1 00:00 .\r\n",
+		"colors":     strings.Repeat("\x1b[1;31mred\x1b[0m \x1b[38;5;208morange\x1b[0m\r\n", 200),
+		"unicode":    strings.Repeat("héllo wörld ✓ 日本語 — ok\r\n", 200),
+		"tui redraw": strings.Repeat("\x1b[H\x1b[2J\x1b[?25l\x1b[3;5H┌──────┐\x1b]0;title\x07\b\b\t", 300),
+	}
+	for name, content := range tests {
+		if LooksBinary(content) {
+			t.Errorf("%s: LooksBinary = true, want false", name)
+		}
+	}
+}
+
+func TestLooksBinary_Binary(t *testing.T) {
+	garbage := string([]byte{0x7f, 'E', 'L', 'F', 0x02, 0x01, 0x01, 0x00, 0x00, 0x00, 0xff, 0xfe, 0x03, 0x00, 0x3e, 0x00})
+	dump := strings.Repeat(garbage, 100)
+
+	if !LooksBinary(dump) {
+		t.Error("LooksBinary(binary dump) = false, want true")
+	}
+
+	// A binary dump in the middle of a long, otherwise normal session
+	text := strings.Repeat("$ echo hello\r\nhello\r\n", 10000)
+	if !LooksBinary(text + "$ cat /bin/ls\r\n" + strings.Repeat(dump, 20) + text) {
+		t.Error("LooksBinary should detect a binary dump inside a long session")
+	}
+	if LooksBinary(text + text) {
+		t.Error("LooksBinary(long text session) = true, want false")
+	}
+}
+
+func TestLooksBinary_RealExecutable(t *testing.T) {
+	data, err := os.ReadFile("/bin/ls")
+	if err != nil {
+		t.Skip("no /bin/ls on this system")
+	}
+	if !LooksBinary(string(data)) {
+		t.Error("LooksBinary(/bin/ls) = false, want true")
+	}
+}
+
+func TestSanitizeBinary(t *testing.T) {
+	input := "ok \x1b[31mred\x1b[0m\x00\x01\xff\x1b]0;t\x07\tend\r\n\x1b"
+	want := "ok \x1b[31mred\x1b[0m\x1b]0;t\x07\tend\r\n"
+
+	if got := SanitizeBinary(input); got != want {
+		t.Errorf("SanitizeBinary() = %q, want %q", got, want)
+	}
+	if got := SanitizeBinary("héllo ✓"); got != "héllo ✓" {
+		t.Errorf("SanitizeBinary should keep valid UTF-8, got %q", got)
+	}
+}
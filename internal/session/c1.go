@@ -0,0 +1,117 @@
+package session
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// c1SevenBit maps 8-bit C1 control introducers to their 7-bit escape forms.
+var c1SevenBit = map[rune]string{
+	0x9b: "\x1b[",  // CSI
+	0x9c: "\x1b\\", // ST
+	0x9d: "\x1b]",  // OSC
+}
+
+// c1Span is a C1 control in content and its 7-bit replacement.
+type c1Span struct {
+	start, end int
+	repl       string
+}
+
+// NormalizeC1Controls rewrites 8-bit C1 control introducers to their 7-bit
+// escape forms: CSI (0x9B) to "\x1b[", OSC (0x9D) to "\x1b]" and ST (0x9C)
+// to "\x1b\\". Cleaning only recognizes the 7-bit forms, so a program
+// emitting 0x9B2J would otherwise slip past clear and alternate screen
+// handling. Both a lone byte (not valid UTF-8 on its own) and the UTF-8
+// encoded code point (U+009B) are rewritten; the same byte inside a
+// multi-byte character (e.g. the 0x9C of "✓") is left alone.
+func NormalizeC1Controls(content string) string {
+	spans := c1Spans(content)
+	if len(spans) == 0 {
+		return content
+	}
+
+	var result strings.Builder
+	result.Grow(len(content) + len(spans))
+	lastEnd := 0
+	for _, span := range spans {
+		result.WriteString(content[lastEnd:span.start])
+		result.WriteString(span.repl)
+		lastEnd = span.end
+	}
+	result.WriteString(content[lastEnd:])
+	return result.String()
+}
+
+// normalizeC1WithOffsets is like NormalizeC1Controls but also returns an
+// OffsetMapper. An offset at a rewritten control maps to its escape.
+func normalizeC1WithOffsets(content string) (string, *OffsetMapper) {
+	spans := c1Spans(content)
+	if len(spans) == 0 {
+		return content, identityMapper(len(content))
+	}
+
+	var result strings.Builder
+	result.Grow(len(content) + len(spans))
+	var regions []mappedRegion
+	lastEnd := 0
+	for _, span := range spans {
+		if span.start > lastEnd {
+			regions = append(regions, mappedRegion{
+				srcStart: lastEnd,
+				srcEnd:   span.start,
+				dstStart: result.Len(),
+			})
+		}
+		result.WriteString(content[lastEnd:span.start])
+		regions = append(regions, mappedRegion{
+			srcStart: span.start,
+			srcEnd:   span.end,
+			dstStart: result.Len(),
+		})
+		result.WriteString(span.repl)
+		lastEnd = span.end
+	}
+	if lastEnd < len(content) {
+		regions = append(regions, mappedRegion{
+			srcStart: lastEnd,
+			srcEnd:   len(content),
+			dstStart: result.Len(),
+		})
+	}
+	result.WriteString(content[lastEnd:])
+
+	return result.String(), &OffsetMapper{regions: regions, dstLen: result.Len()}
+}
+
+// c1Spans returns the C1 controls in content, in order.
+func c1Spans(content string) []c1Span {
+	if !hasC1Byte(content) {
+		return nil
+	}
+
+	var spans []c1Span
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if r == utf8.RuneError && size == 1 {
+			r = rune(content[i]) // a lone byte
+		}
+		if repl, ok := c1SevenBit[r]; ok {
+			spans = append(spans, c1Span{start: i, end: i + size, repl: repl})
+		}
+		i += size
+	}
+	return spans
+}
+
+// hasC1Byte reports whether content has any byte a C1 control is made of,
+// lone or as the second byte of its UTF-8 encoding.
+func hasC1Byte(content string) bool {
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case 0x9b, 0x9c, 0x9d:
+			return true
+		}
+	}
+	return false
+}
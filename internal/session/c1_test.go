@@ -0,0 +1,77 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeC1Controls(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no C1 controls", "hello ✓ world", "hello ✓ world"},
+		{"lone CSI byte", "a\x9b2Jb", "a\x1b[2Jb"},
+		{"UTF-8 encoded CSI", "a\u009b2Jb", "a\x1b[2Jb"},
+		{"OSC and ST", "\x9d0;title\x9cok", "\x1b]0;title\x1b\\ok"},
+		{"byte inside a multi-byte character", "✓ done", "✓ done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeC1Controls(tt.input); got != tt.want {
+				t.Errorf("NormalizeC1Controls(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeutralizeAllWithStats_C1Clear(t *testing.T) {
+	input := "before\r\n\x9bH\x9b2Jafter\r\n"
+
+	got, stats := NeutralizeAllWithStats(input)
+	if stats.ClearSeparators != 1 {
+		t.Errorf("ClearSeparators = %d, want 1", stats.ClearSeparators)
+	}
+	if !strings.Contains(got, ClearSeparator) {
+		t.Errorf("expected clear separator, got %q", got)
+	}
+	if strings.Contains(got, "\x9b") || strings.Contains(got, "\x1b[2J") {
+		t.Errorf("clear sequence not neutralized: %q", got)
+	}
+	if !strings.Contains(got, "before") || !strings.Contains(got, "after") {
+		t.Errorf("content around the clear lost: %q", got)
+	}
+}
+
+func TestNeutralizeAllWithStats_C1AltScreen(t *testing.T) {
+	input := "$ vim\r\n\x9b?1049hVIM SCREEN\x9b?1049l$ echo done\r\n"
+
+	got, stats := NeutralizeAllWithStats(input)
+	if stats.AltScreenRegions != 1 {
+		t.Errorf("AltScreenRegions = %d, want 1", stats.AltScreenRegions)
+	}
+	if strings.Contains(got, "VIM SCREEN") {
+		t.Errorf("alt screen content kept: %q", got)
+	}
+	if strings.Contains(got, "\x9b") || strings.Contains(got, "?1049") {
+		t.Errorf("alt screen sequence not neutralized: %q", got)
+	}
+	if !strings.Contains(got, "$ echo done") {
+		t.Errorf("content after alt screen lost: %q", got)
+	}
+}
+
+func TestNeutralizeAllWithOffsets_C1Controls(t *testing.T) {
+	input := "before\r\n\x9b2Jafter"
+
+	got, mapOffset := NeutralizeAllWithOffsets(input)
+	if !strings.Contains(got, ClearSeparator) {
+		t.Fatalf("expected clear separator, got %q", got)
+	}
+	src := strings.Index(input, "after")
+	dst := mapOffset(src)
+	if !strings.HasPrefix(got[dst:], "after") {
+		t.Errorf("offset %d mapped to %d (%q), want start of \"after\"", src, dst, got[dst:])
+	}
+}
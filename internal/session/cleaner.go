@@ -1,96 +1,260 @@
 package session
 
 import (
+	"regexp"
 	"strings"
 )
 
+// conptyPrefixPattern matches mode sequences Windows ConPTY emits ahead of the
+// first output (e.g. \x1b[?9001h win32-input-mode, \x1b[?1004h focus reporting,
+// \x1b[?25l hide cursor), which can end up glued to header/footer lines.
+var conptyPrefixPattern = regexp.MustCompile(`^(?:\x1b\[\??[0-9;]*[a-zA-Z])+`)
+
+// metadataLine normalizes a line for header/footer detection: drops the \r of
+// a CRLF line ending and any leading ConPTY escape sequences.
+func metadataLine(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	return conptyPrefixPattern.ReplaceAllString(line, "")
+}
+
+// The header and footer lines `script` writes differ between util-linux and
+// the BSD script of each macOS version:
+//
+//	Script started on Mon Oct  7 09:41:22 2019                     (Catalina)
+//	Script started on Wed Dec 31 12:10:34 2025                     (Ventura, then "Command: bash")
+//	Script started on Tue Oct  8 09:41:22 2024 on /dev/ttys003     (Sonoma, with the TTY)
+//	Script started, output file is typescript                      (BSD start message, when captured)
+//	Script started on 2026-01-12 06:41:43+00:00 [COMMAND="bash"]   (util-linux)
+//
+// with the matching "Script done on ..." / "Script done, output file is ..."
+// footer, after "Saving session..." and "Command exit status: 0" on newer
+// macOS versions.
+var (
+	scriptStartedPattern = regexp.MustCompile(`^Script started(?: on |, output (?:log )?file is )`)
+	scriptDonePattern    = regexp.MustCompile(`^Script done(?: on |, output (?:log )?file is )`)
+	commandLinePattern   = regexp.MustCompile(`^Command:`)
+	footerPattern        = regexp.MustCompile(`^(?:Saving session|Command exit status)`)
+)
+
+// IsHeaderLine returns true for lines added by the `script` command at the top.
+func IsHeaderLine(line string) bool {
+	line = metadataLine(line)
+	return scriptStartedPattern.MatchString(line) || commandLinePattern.MatchString(line)
+}
+
+// isFooterLine returns true for lines added by the `script` command at the bottom.
+func isFooterLine(line string) bool {
+	line = metadataLine(line)
+	return footerPattern.MatchString(line) || scriptDonePattern.MatchString(line)
+}
+
 // StripMetadata removes the session header and footer from raw session.log content.
 // Removes patterns like:
 // - Header: "Script started on ..." and "Command: ..."
 // - Footer: "Saving session", "Command exit status", "Script done on"
-func StripMetadata(content string) string {
-	lines := strings.Split(content, "\n")
+//
+// A log appended to by several `script` invocations (script -a), or holding
+// nested recordings, has more than one header/footer: every "Script started
+// on" line begins a session whose header is stripped, footer lines are
+// removed wherever they are, and the sessions' content is joined with a
+// line break.
+//
+// Works with both LF and CRLF line endings. Lines keep their original ending;
+// only a dangling \r left at the end of each session's content is dropped.
+// A leading byte order mark is dropped and UTF-16 logs are decoded first
+// (see DecodeText).
+func StripMetadata(content string, opts ...CleanOptions) string {
+	result, _ := StripMetadataWithStats(content, opts...)
+	return result
+}
 
-	startIndex := 0
-	endIndex := len(lines)
+// StripMetadataWithStats is like StripMetadata but also reports what
+// neutralization did (see NeutralizeAllWithStats).
+// CleanOptions.EnsureTrailingNewline is applied last, to the cleaned content.
+func StripMetadataWithStats(content string, opts ...CleanOptions) (string, CleaningStats) {
+	body := sessionsBody(DecodeText(content))
+	if body == "" {
+		return "", CleaningStats{}
+	}
+	result, stats := NeutralizeAllWithStats(body, opts...)
+	if len(opts) > 0 && opts[0].EnsureTrailingNewline {
+		result = EnsureTrailingNewline(result)
+	}
+	return result, stats
+}
 
-	// Find where actual content starts (skip header)
-	// The header consists of "Script started on..." followed by "Command: ..."
-	for i := 0; i < len(lines) && i < 5; i++ {
-		line := lines[i]
-		if strings.HasPrefix(line, "Script started on") || strings.HasPrefix(line, "Command:") {
-			startIndex = i + 1
+// sessionsBody returns the content of every `script` session in a
+// session.log without its header and footer, joined with \r\n (sessions
+// with no content are skipped), ready for neutralizing.
+func sessionsBody(content string) string {
+	return strings.TrimSuffix(joinSessions(content), "\r")
+}
+
+// joinSessions returns the content of every `script` session in content
+// without its header and footer, as StripMetadataOnly does.
+func joinSessions(content string) string {
+	var bodies []string
+	for _, lines := range scriptSessions(strings.Split(content, "\n")) {
+		if body := strings.Join(withoutFooterLines(sessionBody(lines)), "\n"); body != "" {
+			// A dangling \r is dropped where the next session's is joined
+			if len(bodies) > 0 {
+				bodies[len(bodies)-1] = strings.TrimSuffix(bodies[len(bodies)-1], "\r")
+			}
+			bodies = append(bodies, body)
 		}
 	}
+	return strings.Join(bodies, "\r\n")
+}
 
-	// Find where actual content ends (skip footer)
-	// Footer can contain "Saving session", "Command exit status", "Script done on" in any order
-	// Work backwards from end of file
-	footerStartIndex := len(lines)
-	hasFooterMarker := false
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := lines[i]
-		// Check if this line is a footer marker (must start with the marker text)
-		if strings.HasPrefix(line, "Saving session") ||
-			strings.HasPrefix(line, "Command exit status") ||
-			strings.HasPrefix(line, "Script done on") {
-			hasFooterMarker = true
-			footerStartIndex = i
-		} else if hasFooterMarker && strings.TrimSpace(line) == "" {
-			// Only treat empty lines as footer if we already found a footer marker
-			footerStartIndex = i
-		} else if footerStartIndex < len(lines) {
-			// We've found content before the footer, stop looking
-			break
+// isSessionStart returns true for the first header line of a `script` session.
+func isSessionStart(line string) bool {
+	return scriptStartedPattern.MatchString(metadataLine(line))
+}
+
+// scriptSessions splits session.log lines into one slice per `script`
+// session, each after the first starting at its "Script started on" line.
+func scriptSessions(lines []string) [][]string {
+	var sessions [][]string
+	start := 0
+	for i := 1; i < len(lines); i++ {
+		if isSessionStart(lines[i]) {
+			sessions = append(sessions, lines[start:i])
+			start = i
 		}
 	}
-	endIndex = footerStartIndex
+	return append(sessions, lines[start:])
+}
 
-	// Trim any trailing empty lines from the content
-	for endIndex > startIndex && strings.TrimSpace(lines[endIndex-1]) == "" {
-		endIndex--
+// withoutFooterLines drops footer lines left inside a session's content,
+// e.g. the "Script done on" of a recording nested in it.
+func withoutFooterLines(lines []string) []string {
+	var kept []string
+	for _, line := range lines {
+		if !isFooterLine(line) {
+			kept = append(kept, line)
+		}
 	}
+	return kept
+}
 
-	// Return the sliced content
-	if startIndex >= len(lines) || startIndex >= endIndex {
-		return ""
+// sessionBody returns the lines of one `script` session between its header
+// and footer, without trailing empty lines.
+func sessionBody(lines []string) []string {
+	startIndex := headerEnd(lines)
+	endIndex := contentEnd(lines, startIndex)
+	if startIndex >= endIndex {
+		return nil
 	}
-	content = strings.Join(lines[startIndex:endIndex], "\n")
+	return lines[startIndex:endIndex]
+}
+
+// CleanOptions configures optional cleaning behavior.
+type CleanOptions struct {
+	// KeepAltScreen replays each alternate screen region on an in-memory
+	// terminal grid and keeps its last visible frame between separators,
+	// instead of discarding it. More expensive; useful for short TUI
+	// interactions (e.g. a one-screen menu).
+	KeepAltScreen bool
+
+	// AltScreenLink, if set, gives where each discarded alternate screen
+	// region was stashed, by its 1-indexed position in AltScreenRegions: the
+	// region's separator links there (an OSC 8 hyperlink), and is written
+	// even at the start or end of the content. Ignored with KeepAltScreen.
+	AltScreenLink func(region int) string
+
+	// Redact replaces secrets (tokens, keys, password=... assignments) with
+	// RedactedText once the content is cleaned (see RedactSecrets).
+	Redact bool
+
+	// EnsureTrailingNewline ends the content StripMetadata returns with
+	// exactly one line break (see EnsureTrailingNewline), instead of none.
+	EnsureTrailingNewline bool
+}
+
+// CleaningStats summarizes what neutralization did to session content.
+type CleaningStats struct {
+	ClearSeparators  int // Clear sequences replaced with ClearSeparator
+	AltScreenRegions int // Alternate screen regions discarded (or rendered, with KeepAltScreen)
+	ScrollRegions    int // Scroll-region TUI redraws discarded
+	StatusReports    int // Cursor position / device attribute replies stripped
+	TransientSpans   int // Save/restore cursor status text (e.g. spinners) removed
+	Secrets          int // Secrets replaced with RedactedText (with Redact)
+}
 
-	// Neutralize alternate screen buffer sequences first (before clear handling)
+// NeutralizeAllWithStats applies NormalizeC1Controls, StripStatusReports,
+// NeutralizeTransientSequences, NeutralizeAltScreenSequences,
+// NeutralizeScrollRegionSequences and NeutralizeClearSequences to content
+// (in that order, as StripMetadata does), then RedactSecrets if
+// CleanOptions.Redact is set, and reports what each step did.
+func NeutralizeAllWithStats(content string, opts ...CleanOptions) (string, CleaningStats) {
+	var stats CleaningStats
+	var o CleanOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	// Rewrite 8-bit C1 controls so the steps below see the 7-bit forms
+	content = NormalizeC1Controls(content)
+
+	// Drop terminal replies that leaked into the output
+	content, stats.StatusReports = stripStatusReports(content)
+
+	// Drop save/restore cursor status text before clears and alt screens
+	// are neutralized around it
+	content, stats.TransientSpans = neutralizeTransientSequences(content)
+
+	// Neutralize alternate screen buffer sequences (before clear handling)
 	// so it can find clear sequences that precede alt screen transitions
-	content = NeutralizeAltScreenSequences(content)
+	content, stats.AltScreenRegions = neutralizeAltScreenSequences(content, o.KeepAltScreen, o.AltScreenLink)
+
+	// Neutralize scroll-region TUI redraws (also before clear handling)
+	content, stats.ScrollRegions = neutralizeScrollRegionSequences(content)
 
 	// Neutralize clear sequences so content before clears is preserved
-	content = NeutralizeClearSequences(content)
+	content, stats.ClearSeparators = neutralizeClearSequences(content)
+
+	// Redact last, so secrets split by sequences dropped above are found
+	if o.Redact {
+		content, stats.Secrets = redactSecrets(content, nil)
+	}
 
-	return content
+	return content, stats
 }
 
 // StripMetadataOnly removes only the session header and footer, without
 // neutralizing clear or alt-screen sequences. This preserves the raw terminal
 // output bytes, which is needed for byte-offset-based line number computation
-// (e.g., TOC generation from timing files).
+// (e.g., TOC generation from timing files). Line endings are left untouched,
+// including a trailing \r (in session.input it is the Enter keystroke).
+// Content is decoded first, as for StripMetadata (see DecodeText).
+//
+// An appended log's sessions are stripped and joined as StripMetadata does,
+// so its lines are numbered the same.
 func StripMetadataOnly(content string) string {
-	lines := strings.Split(content, "\n")
+	return joinSessions(DecodeText(content))
+}
 
+// headerEnd returns the index of the first line after the script header,
+// which is looked for in the first 5 lines.
+func headerEnd(lines []string) int {
 	startIndex := 0
-	endIndex := len(lines)
-
 	for i := 0; i < len(lines) && i < 5; i++ {
-		line := lines[i]
-		if strings.HasPrefix(line, "Script started on") || strings.HasPrefix(line, "Command:") {
+		if IsHeaderLine(lines[i]) {
 			startIndex = i + 1
 		}
 	}
+	return startIndex
+}
 
+// contentEnd returns the index after the last line of content: before the
+// script footer (and the blank lines around it) and any trailing blank
+// lines, but not before startIndex.
+func contentEnd(lines []string, startIndex int) int {
 	footerStartIndex := len(lines)
 	hasFooterMarker := false
 	for i := len(lines) - 1; i >= 0; i-- {
 		line := lines[i]
-		if strings.HasPrefix(line, "Saving session") ||
-			strings.HasPrefix(line, "Command exit status") ||
-			strings.HasPrefix(line, "Script done on") {
+		if isFooterLine(line) {
 			hasFooterMarker = true
 			footerStartIndex = i
 		} else if hasFooterMarker && strings.TrimSpace(line) == "" {
@@ -99,14 +263,10 @@ func StripMetadataOnly(content string) string {
 			break
 		}
 	}
-	endIndex = footerStartIndex
+	endIndex := footerStartIndex
 
 	for endIndex > startIndex && strings.TrimSpace(lines[endIndex-1]) == "" {
 		endIndex--
 	}
-
-	if startIndex >= len(lines) || startIndex >= endIndex {
-		return ""
-	}
-	return strings.Join(lines[startIndex:endIndex], "\n")
+	return endIndex
 }
@@ -1,8 +1,11 @@
 package session
 
 import (
+	"io"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestStripMetadata_SimpleCase(t *testing.T) {
@@ -32,6 +35,58 @@ Script done on Wed Dec 31 12:11:22 2025
 	}
 }
 
+func TestStripMetadata_MacOSHeaderVariants(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Catalina",
+			input: "Script started on Mon Oct  7 09:41:22 2019\n$ ls\r\nfile1\r\n\nScript done on Mon Oct  7 09:42:00 2019\n",
+		},
+		{
+			name: "Ventura",
+			input: "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n$ ls\r\nfile1\r\n\n" +
+				"Saving session...\nCommand exit status: 0\nScript done on Wed Dec 31 12:11:22 2025\n",
+		},
+		{
+			name: "Sonoma",
+			input: "Script started on Tue Oct  8 09:41:22 2024 on /dev/ttys003\nCommand: zsh\n$ ls\r\nfile1\r\n\n" +
+				"Saving session...\nCommand exit status: 0\nScript done on Tue Oct  8 09:42:02 2024 on /dev/ttys003\n",
+		},
+		{
+			name:  "start and done messages",
+			input: "Script started, output file is typescript\n$ ls\r\nfile1\r\n\nScript done, output file is typescript\n",
+		},
+		{
+			name:  "util-linux start and done messages",
+			input: "Script started, output log file is 'typescript'.\n$ ls\r\nfile1\r\n\nScript done, output log file is 'typescript'.\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := StripMetadata(tt.input); result != "$ ls\r\nfile1" {
+				t.Errorf("metadata should be stripped, got %q", result)
+			}
+			if result := StripMetadataOnly(tt.input); result != "$ ls\r\nfile1\r" {
+				t.Errorf("StripMetadataOnly should strip metadata too, got %q", result)
+			}
+		})
+	}
+}
+
+func TestStripMetadata_PreservesScriptLikeOutput(t *testing.T) {
+	input := "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n" +
+		"$ echo Script started later\r\nScript started later\r\n" +
+		"Script done soon\r\n\nScript done on Wed Dec 31 12:11:22 2025\n"
+
+	result := StripMetadata(input)
+
+	if !strings.Contains(result, "Script started later") || !strings.Contains(result, "Script done soon") {
+		t.Errorf("output that merely starts with \"Script started\"/\"Script done\" should be kept, got %q", result)
+	}
+}
+
 func TestStripMetadata_NoContent(t *testing.T) {
 	input := `Script started on Wed Dec 31 12:10:34 2025
 Command: bash
@@ -186,3 +241,249 @@ hello world
 		t.Errorf("Result should not contain 'Script started'")
 	}
 }
+
+func TestNeutralizeAllWithStats(t *testing.T) {
+	input := "a\x1b[?1049hTUI\x1b[?1049lb\x1b[2Jc\x1b[2Jd"
+
+	result, stats := NeutralizeAllWithStats(input)
+
+	if result != StripMetadata(input) {
+		t.Errorf("NeutralizeAllWithStats should match StripMetadata, got: %q", result)
+	}
+	if stats.ClearSeparators != 2 {
+		t.Errorf("ClearSeparators = %d, want 2", stats.ClearSeparators)
+	}
+	if stats.AltScreenRegions != 1 {
+		t.Errorf("AltScreenRegions = %d, want 1", stats.AltScreenRegions)
+	}
+	if stats.ScrollRegions != 0 {
+		t.Errorf("ScrollRegions = %d, want 0", stats.ScrollRegions)
+	}
+}
+
+func TestNeutralizeAllWithStats_NothingToDo(t *testing.T) {
+	result, stats := NeutralizeAllWithStats("plain output\n")
+
+	if result != "plain output\n" {
+		t.Errorf("Content should be unchanged, got: %q", result)
+	}
+	if stats != (CleaningStats{}) {
+		t.Errorf("Expected zero stats, got: %+v", stats)
+	}
+}
+
+func TestStripMetadata_CRLFLineEndings(t *testing.T) {
+	input := "Script started on Wed Dec 31 12:10:34 2025\r\nCommand: bash\r\nhello world\r\ntest content\r\n\r\nScript done on Wed Dec 31 12:11:00 2025\r\n"
+
+	result := StripMetadata(input)
+	expected := "hello world\r\ntest content"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestStripMetadata_LinuxFormat_CRLFLineEndings(t *testing.T) {
+	input := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\r\nhello world\r\n\r\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\r\n"
+
+	result := StripMetadata(input)
+
+	if result != "hello world" {
+		t.Errorf("Expected %q, got %q", "hello world", result)
+	}
+}
+
+func TestStripMetadataOnly_CRLFLineEndings(t *testing.T) {
+	input := "Script started on Wed Dec 31 12:10:34 2025\r\nCommand: bash\r\nbefore\x1b[2Jafter\r\nScript done on Wed Dec 31 12:11:00 2025\r\n"
+
+	result := StripMetadataOnly(input)
+	expected := "before\x1b[2Jafter\r"
+
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestStripMetadata_ConPTYSequencesBeforeHeader(t *testing.T) {
+	// ConPTY emits mode sequences before the first output, which can end up
+	// glued to the header line
+	input := "\x1b[?9001h\x1b[?1004hScript started on 2026-01-12 06:41:43+00:00 [COMMAND=\"pwsh\"]\r\nhello world\r\n\x1b[?25lScript done on 2026-01-12 06:45:00+00:00\r\n"
+
+	result := StripMetadata(input)
+
+	if result != "hello world" {
+		t.Errorf("Expected %q, got %q", "hello world", result)
+	}
+}
+
+func TestStripMetadata_AppendedSessions(t *testing.T) {
+	// Two `script -a` invocations writing to the same log
+	input := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\" <not executed on terminal>]\r\n" +
+		"$ echo part one\r\npart one\r\n" +
+		"\r\nScript done on 2026-01-12 06:42:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\r\n" +
+		"Script started on 2026-01-12 07:00:00+00:00 [COMMAND=\"bash\" <not executed on terminal>]\r\n" +
+		"$ echo part two\r\npart two\r\n" +
+		"\r\nScript done on 2026-01-12 07:01:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\r\n"
+
+	result := StripMetadata(input)
+
+	want := "$ echo part one\r\npart one\r\n$ echo part two\r\npart two"
+	if result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+func TestStripMetadataOnly_AppendedSessions(t *testing.T) {
+	input := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\r\n" +
+		"$ echo one\r\none\r\n" +
+		"\r\nScript done on 2026-01-12 06:42:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\r\n" +
+		"Script started on 2026-01-12 07:00:00+00:00 [COMMAND=\"bash\"]\r\n" +
+		"\x1b[2J$ echo two\r\ntwo\r\n" +
+		"\r\nScript done on 2026-01-12 07:01:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\r\n"
+
+	// Joined as StripMetadata joins them, sequences left alone
+	want := "$ echo one\r\none\r\n\x1b[2J$ echo two\r\ntwo\r"
+	if result := StripMetadataOnly(input); result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+func TestStripMetadata_AppendedSessionsMacOS(t *testing.T) {
+	input := `Script started on Wed Dec 31 12:10:34 2025
+Command: bash
+first
+Saving session...
+Script done on Wed Dec 31 12:11:22 2025
+Script started on Wed Dec 31 12:20:00 2025
+Command: bash
+Saving session...
+Script done on Wed Dec 31 12:20:05 2025
+Script started on Wed Dec 31 12:30:00 2025
+Command: bash
+third
+Saving session...
+Script done on Wed Dec 31 12:31:22 2025
+`
+
+	result := StripMetadata(input)
+
+	// The empty middle session is skipped
+	if want := "first\r\nthird"; result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+func TestStripMetadata_ConcatenatedScriptBlocks(t *testing.T) {
+	first := `Script started on Wed Dec 31 12:10:34 2025
+Command: bash
+first body
+Saving session...
+Command exit status: 0
+Script done on Wed Dec 31 12:11:22 2025
+`
+	second := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\" <not executed on terminal>]\n" +
+		"second body\n" +
+		"\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+
+	result := StripMetadata(first + second)
+
+	for _, marker := range []string{"Script started", "Command: bash", "Saving session", "Command exit status", "Script done"} {
+		if strings.Contains(result, marker) {
+			t.Errorf("Result should not contain %q, got %q", marker, result)
+		}
+	}
+	if !strings.Contains(result, "first body") || !strings.Contains(result, "second body") {
+		t.Errorf("Both bodies should remain, got %q", result)
+	}
+}
+
+func TestStripMetadata_NestedRecording(t *testing.T) {
+	// script run inside a recorded shell: the inner footer is followed by
+	// more of the outer session, so it isn't at the end of either
+	input := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ script inner.log\n" +
+		"Script started on 2026-01-12 06:42:00+00:00 [COMMAND=\"bash\"]\n" +
+		"inner work\n" +
+		"Script done on 2026-01-12 06:43:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\n" +
+		"$ history\n" +
+		"  1  script inner.log\n" +
+		"Command: kept, not a header here\n" +
+		"Script done on 2026-01-12 06:44:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+
+	result := StripMetadata(input)
+
+	want := "$ script inner.log\r\ninner work\n$ history\n  1  script inner.log\nCommand: kept, not a header here"
+	if result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+// TestStripMetadata_AdversarialInput feeds the cleaners large runs of near
+// matches for their patterns, the input that makes backtracking regexes or
+// per-match rescans blow up. The cleaners' patterns run over whole untrusted
+// logs, scanned in windows of at most regexWindow bytes (see
+// TestFindAllWindowed_BoundsEachScan); what could hang the cleaners besides
+// is a pass rescanning or copying content for each match.
+//
+// Cleaning 8x the input must allocate about 8x as much (a copy per match
+// would be 64x), which unlike timings doesn't vary from run to run, and
+// finish within a budget generous enough for a loaded machine but not for
+// quadratic work. StreamCleaner gets the input in 32 KB chunks, as
+// StripMetadataReader reads it.
+func TestStripMetadata_AdversarialInput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping timing test in short mode")
+	}
+	pieces := map[string]string{
+		"escape prefixes":       "\x1b[",
+		"unfinished clears":     "\x1b[1;1H\x1b[",
+		"clears":                "\x1b[2J",
+		"alt screen toggles":    "\x1b[?1049h",
+		"cursor addresses":      "\x1b[12;34",
+		"long parameters":       "\x1b[" + strings.Repeat("9", 1000),
+		"long SGR":              "\x1b[" + strings.Repeat("1;", 1000),
+		"scroll regions":        "\x1b[1;24r",
+		"scroll region redraws": "\x1b[1;24r" + strings.Repeat("\x1b[5;1Hx", 10),
+		"scroll region output":  "\x1b[1;24rhello\r\n",
+		"status reports":        "\x1b[?1;",
+		"echoed status reports": "^[[?1;",
+		"secret prefixes":       "password=",
+		"near tokens":           strings.Repeat("A", 31) + " ",
+	}
+	cleaners := map[string]func(string){
+		"StripMetadata": func(content string) {
+			StripMetadata(content, CleanOptions{Redact: true})
+		},
+		"StreamCleaner": func(content string) {
+			c := NewStreamCleaner(io.Discard)
+			for i := 0; i < len(content); i += 32 * 1024 {
+				c.Write([]byte(content[i:min(i+32*1024, len(content))]))
+			}
+			c.Close()
+		},
+	}
+	const small, budget = 32 * 1024, 10 * time.Second
+	for cleanerName, cleaner := range cleaners {
+		// clean returns the bytes cleaning content allocated
+		clean := func(content string) uint64 {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			cleaner(content)
+			runtime.ReadMemStats(&after)
+			return after.TotalAlloc - before.TotalAlloc
+		}
+		for name, piece := range pieces {
+			t.Run(cleanerName+"/"+name, func(t *testing.T) {
+				base := clean(strings.Repeat(piece, small/len(piece)+1))
+				start := time.Now()
+				allocated := clean(strings.Repeat(piece, 8*small/len(piece)+1))
+				if took := time.Since(start); took > budget {
+					t.Fatalf("cleaning took %v, over the %v budget", took, budget)
+				}
+				if allocated > 24*base {
+					t.Errorf("cleaning 8x the input allocated %d bytes vs %d: worse than linear", allocated, base)
+				}
+			})
+		}
+	}
+}
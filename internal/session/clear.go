@@ -2,14 +2,38 @@ package session
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/choonkeat/record-tui/internal/grid"
 )
 
+// The separators reset text attributes (SGR) before and after their text, so
+// colors a program left set (e.g. a red background) don't bleed into the
+// separator; the reset comes before the line breaks too, as a terminal
+// fills the lines it scrolls in with the current background.
+
 // ClearSeparator is the visual separator used to replace clear sequences
-const ClearSeparator = "\n\n──────── terminal cleared ────────\n\n"
+const ClearSeparator = "\x1b[0m\n\n──────── terminal cleared ────────\x1b[0m\n\n"
 
 // AltScreenSeparator is the visual separator used when exiting the alternate screen buffer
-const AltScreenSeparator = "\n\n──────── alternate screen ────────\n\n"
+const AltScreenSeparator = "\x1b[0m\n\n──────── alternate screen ────────\x1b[0m\n\n"
+
+// UnexitedAltScreenSeparator is the visual separator used when the content
+// ends inside the alternate screen buffer, e.g. a TUI killed before it
+// could restore the screen, so the discarded output doesn't look like the
+// recording just stopped
+const UnexitedAltScreenSeparator = "\x1b[0m\n\n──────── alternate screen (not exited) ────────\x1b[0m\n\n"
+
+// altScreenLinkSeparator is AltScreenSeparator with a hyperlink (OSC 8) to
+// where the discarded region was stashed (see CleanOptions.AltScreenLink).
+func altScreenLinkSeparator(url string) string {
+	return "\x1b[0m\n\n──────── alternate screen (\x1b]8;;" + url + "\x07view alternate screen content\x1b]8;;\x07) ────────\x1b[0m\n\n"
+}
+
+// ScrollRegionSeparator is the visual separator used in place of a discarded
+// scroll-region TUI redraw (see NeutralizeScrollRegionSequences)
+const ScrollRegionSeparator = "\x1b[0m\n\n──────── scroll region ────────\x1b[0m\n\n"
 
 // altScreenPattern matches alternate screen buffer sequences:
 // - \x1b[?1049h / \x1b[?1049l - xterm alternate screen (most common)
@@ -17,6 +41,20 @@ const AltScreenSeparator = "\n\n──────── alternate screen ──
 // - \x1b[?1047h / \x1b[?1047l - alternate screen variant
 var altScreenPattern = regexp.MustCompile(`\x1b\[\?(1049|47|1047)[hl]`)
 
+// scrollRegionSetPattern matches DECSTBM with explicit margins: \x1b[top;bottomr
+var scrollRegionSetPattern = regexp.MustCompile(`\x1b\[\d+;\d+r`)
+
+// scrollRegionResetPattern matches DECSTBM reset to full screen: \x1b[r or \x1b[;r
+var scrollRegionResetPattern = regexp.MustCompile(`\x1b\[;?r`)
+
+// cursorAddressPattern matches absolute cursor positioning (CUP/HVP): \x1b[row;colH
+var cursorAddressPattern = regexp.MustCompile(`\x1b\[(\d+);(\d+)[Hf]`)
+
+// scrollRegionMinCursorAddresses is how many absolute cursor moves a scroll
+// region needs before it is considered a full-screen redraw rather than,
+// say, a progress bar pinned to the last line.
+const scrollRegionMinCursorAddresses = 10
+
 // clearPatterns matches terminal clear sequences:
 // - \x1b[2J - Clear entire screen
 // - \x1b[3J - Clear entire screen including scrollback
@@ -30,12 +68,21 @@ var clearPattern = regexp.MustCompile(`\x1b\[(?:1;1)?H\x1b\[(?:0?J|[23]J)|\x1b\[
 // Clear sequences at the very start or end are simply stripped (no separator needed).
 // Other ANSI sequences (colors, cursor movement, etc.) are preserved.
 func NeutralizeClearSequences(content string) string {
+	result, _ := neutralizeClearSequences(content)
+	return result
+}
+
+// neutralizeClearSequences is NeutralizeClearSequences, also returning the
+// number of separators inserted.
+func neutralizeClearSequences(content string) (string, int) {
 	// Find all clear sequences
-	matches := clearPattern.FindAllStringIndex(content, -1)
+	matches := findAllIndex(clearPattern, content)
 	if len(matches) == 0 {
-		return content
+		return content, 0
 	}
 
+	separators := 0
+
 	var result strings.Builder
 	lastEnd := 0
 
@@ -53,6 +100,7 @@ func NeutralizeClearSequences(content string) string {
 			remaining := content[end:]
 			if strings.TrimSpace(remaining) != "" {
 				result.WriteString(ClearSeparator)
+				separators++
 			}
 		}
 
@@ -70,7 +118,7 @@ func NeutralizeClearSequences(content string) string {
 		}
 	}
 
-	return result.String()
+	return result.String(), separators
 }
 
 // NeutralizeAltScreenSequences removes alternate screen buffer regions and the
@@ -78,21 +126,62 @@ func NeutralizeClearSequences(content string) string {
 // (\x1b[?1049l) is discarded. Additionally, content from the last clear sequence
 // before the enter is also discarded, since it contains cursor-positioned TUI
 // redraws that would corrupt the rendering of content after the leave.
-// A separator is inserted at the transition point when there is content on both sides.
+// A separator is inserted at the transition point when there is content on both sides,
+// and UnexitedAltScreenSeparator after the content when it ends inside the alternate
+// screen.
 //
 // This function should be called BEFORE NeutralizeClearSequences so it can find
 // the clear sequences that precede alt screen transitions.
 func NeutralizeAltScreenSequences(content string) string {
-	altMatches := altScreenPattern.FindAllStringSubmatchIndex(content, -1)
+	result, _ := neutralizeAltScreenSequences(content, false, nil)
+	return result
+}
+
+// AltScreenRegions returns the alternate screen regions of session.log
+// content, each from its enter sequence through its leave sequence (or the
+// end of the content), in the order StripMetadata discards them. These are
+// the raw bytes of each full-screen TUI session, e.g. to keep them viewable
+// with cat while the cleaned content leaves them out (see
+// CleanOptions.AltScreenLink).
+func AltScreenRegions(content string) []string {
+	content = sessionsBody(content)
+	var regions []string
+	enter := -1
+	for _, match := range findAllIndex(altScreenPattern, content) {
+		isEnter := content[match[1]-1] == 'h'
+		if isEnter && enter < 0 {
+			enter = match[0]
+		} else if !isEnter && enter >= 0 {
+			regions = append(regions, content[enter:match[1]])
+			enter = -1
+		}
+	}
+	if enter >= 0 {
+		regions = append(regions, content[enter:])
+	}
+	return regions
+}
+
+// neutralizeAltScreenSequences is NeutralizeAltScreenSequences, also returning
+// the number of alternate screen regions discarded. If keep is true, each
+// region is replayed on an in-memory screen and its last visible frame is kept
+// between separators instead (see CleanOptions.KeepAltScreen). Otherwise, if
+// link is set, each region leaves a separator linking to link(n) for the
+// n-th region (1-indexed), whatever content is around it.
+func neutralizeAltScreenSequences(content string, keep bool, link func(int) string) (string, int) {
+	altMatches := findAllSubmatchIndex(altScreenPattern, content)
 	if len(altMatches) == 0 {
-		return content
+		return content, 0
 	}
 
+	regions := 0
+
 	// Find all clear sequences to identify TUI redraw boundaries
-	clearMatches := clearPattern.FindAllStringIndex(content, -1)
+	clears := clearCursor{matches: findAllIndex(clearPattern, content)}
 
 	var result strings.Builder
 	lastEnd := 0
+	enterEnd := 0
 	inAltScreen := false
 
 	for _, match := range altMatches {
@@ -100,24 +189,28 @@ func NeutralizeAltScreenSequences(content string) string {
 		isEnter := content[end-1] == 'h'
 
 		if isEnter && !inAltScreen {
+			enterEnd = end
+
 			// Find the first clear sequence before this enter (after lastEnd)
 			// Everything from that clear to the alt screen leave is TUI content
 			// (the TUI app clears the screen and redraws repeatedly)
-			stripFrom := start
-			for _, cm := range clearMatches {
-				if cm[0] >= lastEnd && cm[1] <= start {
-					stripFrom = cm[0]
-					break // Use the first clear, not the last
-				}
-			}
+			stripFrom := clears.first(lastEnd, start)
 
 			// Keep content before the strip point
 			before := content[lastEnd:stripFrom]
 			result.WriteString(before)
 			inAltScreen = true
+			regions++
 		} else if !isEnter && inAltScreen {
 			// Leaving alt screen — insert separator if content on both sides
 			inAltScreen = false
+			if keep {
+				writeAltScreenFrame(&result, content[enterEnd:start])
+			} else if link != nil {
+				result.WriteString(altScreenLinkSeparator(link(regions)))
+				lastEnd = end
+				continue
+			}
 			beforeContent := result.String()
 			remaining := content[end:]
 			if strings.TrimSpace(beforeContent) != "" && strings.TrimSpace(remaining) != "" {
@@ -132,7 +225,162 @@ func NeutralizeAltScreenSequences(content string) string {
 	if !inAltScreen {
 		remaining := content[lastEnd:]
 		result.WriteString(remaining)
+	} else if keep {
+		// Alt screen never left: the region runs to the end
+		writeAltScreenFrame(&result, content[enterEnd:])
+	} else if link != nil {
+		result.WriteString(altScreenLinkSeparator(link(regions)))
+	} else if strings.TrimSpace(result.String()) != "" {
+		result.WriteString(UnexitedAltScreenSeparator)
+	}
+
+	return result.String(), regions
+}
+
+// NeutralizeScrollRegionSequences removes scroll-region TUI redraws. Some
+// full-screen programs set a scroll region (\x1b[top;bottomr) and redraw with
+// absolute cursor addressing instead of switching to the alternate screen, so
+// NeutralizeAltScreenSequences misses them.
+//
+// A region runs from the DECSTBM set to the next reset (\x1b[r) or end of
+// content. It is treated as a TUI redraw only when it contains at least
+// scrollRegionMinCursorAddresses cursor moves and more cursor moves than
+// newlines; otherwise (e.g. a pinned progress bar over scrolling output) it is
+// kept as-is. Like the alt-screen case, the first clear before the region is
+// also discarded, and a separator is inserted when there is content on both sides.
+//
+// This function should be called BEFORE NeutralizeClearSequences.
+func NeutralizeScrollRegionSequences(content string) string {
+	result, _ := neutralizeScrollRegionSequences(content)
+	return result
+}
+
+// neutralizeScrollRegionSequences is NeutralizeScrollRegionSequences, also
+// returning the number of scroll regions discarded.
+func neutralizeScrollRegionSequences(content string) (string, int) {
+	spans := tuiScrollRegions(content)
+	if len(spans) == 0 {
+		return content, 0
+	}
+
+	var result strings.Builder
+	lastEnd := 0
+
+	for _, span := range spans {
+		result.WriteString(content[lastEnd:span[0]])
+		remaining := content[span[1]:]
+		if strings.TrimSpace(result.String()) != "" && strings.TrimSpace(remaining) != "" {
+			result.WriteString(ScrollRegionSeparator)
+		}
+		lastEnd = span[1]
+	}
+
+	result.WriteString(content[lastEnd:])
+	return result.String(), len(spans)
+}
+
+// tuiScrollRegions returns the [start, end) byte spans of scroll regions that
+// look like TUI redraws. start is moved back to the first clear sequence
+// between the previous region and the DECSTBM set, if any.
+func tuiScrollRegions(content string) [][2]int {
+	sets := findAllIndex(scrollRegionSetPattern, content)
+	if len(sets) == 0 {
+		return nil
+	}
+
+	clears := clearCursor{matches: findAllIndex(clearPattern, content)}
+
+	var spans [][2]int
+	lastEnd := 0
+	for _, set := range sets {
+		if set[0] < lastEnd {
+			// Margins changed again inside the current region
+			continue
+		}
+
+		end := len(content)
+		if reset := scrollRegionResetPattern.FindStringIndex(content[set[1]:]); reset != nil {
+			end = set[1] + reset[1]
+		}
+
+		if isTUIRedraw(content[set[0]:end]) {
+			stripFrom := clears.first(lastEnd, set[0])
+			spans = append(spans, [2]int{stripFrom, end})
+		}
+
+		lastEnd = end
+	}
+
+	return spans
+}
+
+// clearCursor finds clear sequences in content, from its clearPattern
+// matches (in order, as findAllIndex returns them), for a scan that
+// moves forward through content: each search resumes where the last one
+// stopped, so a scan costs O(len(matches)) however many searches it makes,
+// instead of rescanning the matches each time.
+type clearCursor struct {
+	matches [][]int
+	next    int // First match that may start at or after the last search's from
+}
+
+// first returns the start of the first clear sequence wholly within
+// content[from:to], or to if there is none. from must not be less than in
+// the previous call.
+func (c *clearCursor) first(from, to int) int {
+	for c.next < len(c.matches) && c.matches[c.next][0] < from {
+		c.next++
+	}
+	// Matches don't overlap, so if the first one starting at from or later
+	// ends past to, so do the rest
+	if c.next < len(c.matches) && c.matches[c.next][1] <= to {
+		return c.matches[c.next][0]
+	}
+	return to
+}
+
+// isTUIRedraw reports whether region is dominated by absolute cursor addressing.
+func isTUIRedraw(region string) bool {
+	moves := len(findAllIndex(cursorAddressPattern, region))
+	return moves >= scrollRegionMinCursorAddresses && moves > strings.Count(region, "\n")
+}
+
+// writeAltScreenFrame replays an alternate screen region on an in-memory
+// screen and writes its last visible frame to result, preceded by a
+// separator if result already has content. Blank frames are skipped.
+func writeAltScreenFrame(result *strings.Builder, region string) {
+	rows, cols := altScreenSize(region)
+	screen := grid.New(cols, rows)
+	screen.Write(region)
+	frame := screen.String()
+	if strings.TrimSpace(frame) == "" {
+		return
+	}
+	if strings.TrimSpace(result.String()) != "" {
+		result.WriteString(AltScreenSeparator)
 	}
+	result.WriteString(frame)
+}
 
-	return result.String()
+// Upper bounds for altScreenSize, so a bogus cursor address (e.g. \x1b[99999;99999H)
+// can't make the replay allocate an enormous grid.
+const (
+	maxAltScreenRows = 200
+	maxAltScreenCols = 500
+)
+
+// altScreenSize guesses the screen size a TUI was drawn for from the largest
+// cursor address it used, with a floor of 24x80 and a ceiling of
+// maxAltScreenRows x maxAltScreenCols.
+func altScreenSize(region string) (rows, cols int) {
+	rows, cols = 24, 80
+	for _, m := range findAllSubmatchIndex(cursorAddressPattern, region) {
+		if r, err := strconv.Atoi(region[m[2]:m[3]]); err == nil && r > rows {
+			rows = min(r, maxAltScreenRows)
+		}
+		if c, err := strconv.Atoi(region[m[4]:m[5]]); err == nil && c > cols {
+			cols = min(c, maxAltScreenCols)
+		}
+	}
+	return rows, cols
 }
@@ -1,12 +1,41 @@
 package session
 
 import (
+	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/choonkeat/record-tui/internal/grid"
 )
 
 // ClearSeparator is the visual separator used to replace clear sequences
-const testClearSeparator = "\n\n──────── terminal cleared ────────\n\n"
+const testClearSeparator = "\x1b[0m\n\n──────── terminal cleared ────────\x1b[0m\n\n"
+
+func TestNeutralizeClearSequences_SeparatorResetsColors(t *testing.T) {
+	// The first region leaves a red background set
+	input := "\x1b[41mfailing test\x1b[H\x1b[2Jnext screen"
+
+	result := NeutralizeClearSequences(input)
+
+	screen := grid.New(80, 10)
+	screen.Write(strings.ReplaceAll(result, "\n", "\r\n"))
+	for row, line := range screen.Lines() {
+		if !strings.Contains(line, "terminal cleared") {
+			continue
+		}
+		for col := 0; col < screen.Cols(); col++ {
+			if cell := screen.Cell(row, col); cell.Style != (grid.Style{}) {
+				t.Fatalf("separator cell %d has style %q, want the default", col, cell.Style.SGR())
+			}
+		}
+		// The blank line between the region and the separator isn't red either
+		if cell := screen.Cell(row-1, 0); cell.Style != (grid.Style{}) {
+			t.Errorf("line before the separator has style %q, want the default", cell.Style.SGR())
+		}
+		return
+	}
+	t.Fatalf("separator not found in %q", result)
+}
 
 func TestNeutralizeClearSequences_SimpleClear(t *testing.T) {
 	// \x1b[2J is "clear entire screen"
@@ -311,6 +340,30 @@ func TestNeutralizeAltScreenSequences_AtEnd(t *testing.T) {
 	}
 }
 
+func TestNeutralizeAltScreenSequences_NeverLeft(t *testing.T) {
+	// A TUI killed without restoring the screen: its output is discarded,
+	// and a separator says the recording ended inside it
+	result := NeutralizeAltScreenSequences("before\x1b[2J\x1b[?1049hTUI")
+	if want := "before" + UnexitedAltScreenSeparator; result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+
+	// Nothing before it: nothing to separate
+	if result := NeutralizeAltScreenSequences("\x1b[?1049hTUI"); result != "" {
+		t.Errorf("got %q, want empty", result)
+	}
+
+	cleaned := StripMetadata("Script started on Wed Dec 31 12:10:34 2025\n"+
+		"$ vim\r\nbefore\r\n\x1b[2J\x1b[?1049h\x1b[1;1HTUI\r\n"+
+		"Script done on Wed Dec 31 12:11:22 2025\n", CleanOptions{})
+	if !strings.Contains(cleaned, "before") || strings.Contains(cleaned, "TUI") {
+		t.Errorf("StripMetadata should keep the output before the TUI only, got %q", cleaned)
+	}
+	if !strings.Contains(cleaned, "alternate screen (not exited)") {
+		t.Errorf("StripMetadata should mark the unexited alternate screen, got %q", cleaned)
+	}
+}
+
 func TestNeutralizeAltScreenSequences_OlderVariants(t *testing.T) {
 	// Test \x1b[?47h/l variant — TUI content is discarded
 	input := "before\x1b[?47hTUI\x1b[?47lafter"
@@ -399,3 +452,246 @@ func TestNeutralizeClearSequences_PreservesOtherANSI(t *testing.T) {
 		t.Errorf("Result should not contain clear sequence")
 	}
 }
+
+// topStyleRedraw mimics a `top`-style program that sets a scroll region and
+// repaints every row with absolute cursor addressing (no alternate screen).
+func topStyleRedraw() string {
+	var b strings.Builder
+	b.WriteString("\x1b[1;24r")
+	for frame := 0; frame < 3; frame++ {
+		b.WriteString("\x1b[H\x1b[2J")
+		for row := 1; row <= 6; row++ {
+			b.WriteString("\x1b[" + strconv.Itoa(row) + ";1HPID " + strconv.Itoa(100+row) + " cpu " + strconv.Itoa(frame*10+row) + "%")
+		}
+	}
+	b.WriteString("\x1b[r")
+	return b.String()
+}
+
+func TestNeutralizeScrollRegionSequences_TopStyleRedraw(t *testing.T) {
+	input := "$ top\n" + topStyleRedraw() + "\n$ echo done\ndone\n"
+
+	result := NeutralizeScrollRegionSequences(input)
+
+	if !strings.Contains(result, "$ top") {
+		t.Errorf("Result should contain content before region, got: %q", result)
+	}
+	if strings.Contains(result, "PID") {
+		t.Errorf("Result should NOT contain redraw content (discarded), got: %q", result)
+	}
+	if !strings.Contains(result, "done") {
+		t.Errorf("Result should contain content after region, got: %q", result)
+	}
+	if !strings.Contains(result, "scroll region") {
+		t.Errorf("Result should contain separator 'scroll region', got: %q", result)
+	}
+	if strings.Contains(result, "\x1b[1;24r") || strings.Contains(result, "\x1b[r") {
+		t.Errorf("Result should not contain scroll region sequences")
+	}
+}
+
+func TestNeutralizeScrollRegionSequences_NoReset(t *testing.T) {
+	// Region never reset: runs to end of content, no separator needed
+	redraw := topStyleRedraw()
+	input := "before\n" + strings.TrimSuffix(redraw, "\x1b[r")
+
+	result := NeutralizeScrollRegionSequences(input)
+
+	if result != "before\n" {
+		t.Errorf("Expected only content before region, got: %q", result)
+	}
+}
+
+func TestNeutralizeScrollRegionSequences_PinnedProgressBarKept(t *testing.T) {
+	// A progress bar pinned below a scroll region: output still scrolls
+	// normally line by line, so the region is not a TUI redraw
+	input := "before\n\x1b[1;23rline 1\nline 2\nline 3\n\x1b[24;1Hprogress 50%\x1b[r\nafter"
+
+	result := NeutralizeScrollRegionSequences(input)
+
+	if result != input {
+		t.Errorf("Scroll region without heavy cursor addressing should be kept, got: %q", result)
+	}
+}
+
+func TestNeutralizeScrollRegionSequences_CombinedWithClear(t *testing.T) {
+	// As in cleaner.go: clear before the region is the strip point
+	input := "before\x1b[2Jmiddle" + topStyleRedraw() + "after"
+
+	result := NeutralizeScrollRegionSequences(input)
+	result = NeutralizeClearSequences(result)
+
+	expected := "before" + ScrollRegionSeparator + "after"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestNeutralizeAllWithOffsets_ScrollRegion(t *testing.T) {
+	input := "$ top\n" + topStyleRedraw() + "\n$ echo done\ndone\n"
+
+	result, mapFn := NeutralizeAllWithOffsets(input)
+
+	expected := NeutralizeClearSequences(NeutralizeScrollRegionSequences(NeutralizeAltScreenSequences(input)))
+	if result != expected {
+		t.Errorf("NeutralizeAllWithOffsets should match the StripMetadata pipeline\ngot:  %q\nwant: %q", result, expected)
+	}
+
+	// Offset of "$ echo" in the source maps to "$ echo" in the result
+	src := strings.Index(input, "$ echo")
+	dst := strings.Index(result, "$ echo")
+	if got := mapFn(src); got != dst {
+		t.Errorf("mapFn(%d) = %d, want %d", src, got, dst)
+	}
+}
+
+func TestNeutralizeAllWithOffsets_AltScreenNeverLeft(t *testing.T) {
+	input := "$ vim\nbefore\n\x1b[2J\x1b[?1049hTUI"
+
+	result, _ := NeutralizeAllWithOffsets(input)
+
+	expected := NeutralizeClearSequences(NeutralizeScrollRegionSequences(NeutralizeAltScreenSequences(input)))
+	if result != expected {
+		t.Errorf("NeutralizeAllWithOffsets should match the StripMetadata pipeline\ngot:  %q\nwant: %q", result, expected)
+	}
+	if !strings.Contains(result, "alternate screen (not exited)") {
+		t.Errorf("expected the unexited alternate screen separator, got %q", result)
+	}
+}
+
+// menuTUI mimics a one-screen menu drawn on the alternate screen with
+// cursor addressing, redrawing the selection marker as the user moves.
+const menuTUI = "\x1b[?1049h\x1b[H\x1b[2J" +
+	"\x1b[1;1HSelect a fruit:" +
+	"\x1b[3;3H  apples" +
+	"\x1b[4;3H  pears" +
+	"\x1b[3;3H>" +
+	"\x1b[3;3H \x1b[4;3H>" +
+	"\x1b[?1049l"
+
+func TestNeutralizeAllWithStats_KeepAltScreen(t *testing.T) {
+	input := "$ pick\n" + menuTUI + "$ echo done\ndone"
+
+	result, stats := NeutralizeAllWithStats(input, CleanOptions{KeepAltScreen: true})
+
+	expected := "$ pick\n" +
+		AltScreenSeparator +
+		"Select a fruit:\r\n\r\n    apples\r\n  > pears" +
+		AltScreenSeparator +
+		"$ echo done\ndone"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if stats.AltScreenRegions != 1 {
+		t.Errorf("AltScreenRegions = %d, want 1", stats.AltScreenRegions)
+	}
+}
+
+func TestNeutralizeAllWithStats_KeepAltScreenNeverLeft(t *testing.T) {
+	input := "$ pick\n" + strings.TrimSuffix(menuTUI, "\x1b[?1049l")
+
+	result, _ := NeutralizeAllWithStats(input, CleanOptions{KeepAltScreen: true})
+
+	expected := "$ pick\n" + AltScreenSeparator + "Select a fruit:\r\n\r\n    apples\r\n  > pears"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestNeutralizeAllWithStats_KeepAltScreenBlankFrame(t *testing.T) {
+	// A TUI that clears its screen before leaving has nothing to keep
+	input := "before\x1b[?1049hTUI\x1b[2J\x1b[?1049lafter"
+
+	result, _ := NeutralizeAllWithStats(input, CleanOptions{KeepAltScreen: true})
+
+	expected := "before" + AltScreenSeparator + "after"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestNeutralizeAllWithStats_AltScreenLink(t *testing.T) {
+	// The second region runs to the end: its separator is still written
+	input := "$ pick\n" + menuTUI + "$ vim\n\x1b[?1049hediting"
+	link := func(n int) string { return "alt-screen-" + strconv.Itoa(n) + ".log" }
+
+	result, stats := NeutralizeAllWithStats(input, CleanOptions{AltScreenLink: link})
+
+	expected := "$ pick\n" +
+		altScreenLinkSeparator("alt-screen-1.log") +
+		"$ vim\n" +
+		altScreenLinkSeparator("alt-screen-2.log")
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+	if stats.AltScreenRegions != 2 {
+		t.Errorf("AltScreenRegions = %d, want 2", stats.AltScreenRegions)
+	}
+	if !strings.Contains(result, "\x1b]8;;alt-screen-1.log\x07view alternate screen content\x1b]8;;\x07") {
+		t.Error("separator should hyperlink to the stashed region")
+	}
+}
+
+func TestAltScreenRegions(t *testing.T) {
+	input := "Script started on 2026-01-12 06:41:43+00:00\n$ pick\n" + menuTUI +
+		"$ vim\n\x1b[?1049hediting\nScript done on 2026-01-12 06:45:00+00:00\n"
+
+	regions := AltScreenRegions(input)
+
+	if len(regions) != 2 {
+		t.Fatalf("got %d regions, want 2: %q", len(regions), regions)
+	}
+	if regions[0] != menuTUI {
+		t.Errorf("region 1 = %q, want the whole TUI session %q", regions[0], menuTUI)
+	}
+	if regions[1] != "\x1b[?1049hediting" {
+		t.Errorf("region 2 = %q, want it to run to the end without the footer", regions[1])
+	}
+}
+
+func TestStripMetadata_DiscardsAltScreenByDefault(t *testing.T) {
+	input := "$ pick\n" + menuTUI + "$ echo done\ndone"
+
+	result := StripMetadata(input)
+
+	if strings.Contains(result, "Select a fruit") {
+		t.Errorf("Alt screen content should be discarded without KeepAltScreen, got: %q", result)
+	}
+}
+
+func TestClearCursor(t *testing.T) {
+	// Clears at [2,6), [10,14) and [20,24)
+	c := clearCursor{matches: [][]int{{2, 6}, {10, 14}, {20, 24}}}
+	searches := []struct {
+		from, to, want int
+	}{
+		{0, 1, 1},    // none before to
+		{0, 8, 2},    // the first one, not the last
+		{0, 30, 2},   // same search again
+		{6, 12, 12},  // the next one ends past to
+		{6, 16, 10},  // skipping to the next one
+		{14, 30, 20}, // the last one
+		{24, 30, 30}, // none left
+	}
+	for _, s := range searches {
+		if got := c.first(s.from, s.to); got != s.want {
+			t.Errorf("first(%d, %d) = %d, want %d", s.from, s.to, got, s.want)
+		}
+	}
+}
+
+// manyClearsContent is a recording that clears the screen n times, then
+// opens and closes a full-screen TUI n times.
+func manyClearsContent(n int) string {
+	return strings.Repeat("frame\r\n\x1b[H\x1b[2J", n) +
+		strings.Repeat("$ vim\r\n\x1b[?1049hVIM\x1b[?1049l$ \r\n", n)
+}
+
+func BenchmarkNeutralizeAllWithOffsets_ManyClears(b *testing.B) {
+	content := manyClearsContent(10000)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NeutralizeAllWithOffsets(content)
+	}
+}
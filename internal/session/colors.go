@@ -0,0 +1,198 @@
+package session
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ColorMode16 is the playback ColorMode that downsamples 256-color and
+// true-color SGR sequences to the 16 standard colors (see DownsampleColors).
+const ColorMode16 = "16"
+
+// sgrPattern matches an SGR (Select Graphic Rendition) sequence, e.g.
+// \x1b[1;38;5;196m. Parameters may use ':' sub-parameters (38:2::R:G:B).
+var sgrPattern = regexp.MustCompile(`\x1b\[([0-9;:]*)m`)
+
+// ansi16Palette is xterm's default palette for the 16 standard colors
+// (0-7 normal, 8-15 bright), used to find the nearest one.
+var ansi16Palette = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 color cube (indices 16-231).
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+// DownsampleColors rewrites 256-color (\x1b[38;5;Nm) and true-color
+// (\x1b[38;2;R;G;Bm) SGR foreground and background colors to the nearest of
+// the 16 standard colors (\x1b[3Nm, \x1b[9Nm and their background forms),
+// for viewers and printers that render extended colors poorly. Underline
+// colors (58) have no 16-color form and are dropped. Other attributes in
+// the same sequence are kept.
+func DownsampleColors(content string) string {
+	return sgrPattern.ReplaceAllStringFunc(content, func(seq string) string {
+		params := seq[2 : len(seq)-1]
+		if !strings.Contains(params, "8") {
+			return seq // no 38/48/58 parameter
+		}
+		out := downsampleParams(strings.Split(params, ";"))
+		if len(out) == 0 {
+			return "" // only an underline color; \x1b[m would reset everything
+		}
+		return "\x1b[" + strings.Join(out, ";") + "m"
+	})
+}
+
+// downsampleParams rewrites the extended colors in a list of SGR parameters.
+func downsampleParams(params []string) []string {
+	out := make([]string, 0, len(params))
+	for i := 0; i < len(params); i++ {
+		p := params[i]
+
+		// Colon form keeps a color in one parameter: 38:5:N, 38:2::R:G:B
+		if sub := strings.Split(p, ":"); len(sub) > 1 {
+			if base := extendedColorBase(sub[0]); base >= 0 {
+				if c, ok := subParamColor(sub[1:]); ok {
+					if base != 58 {
+						out = append(out, sgr16(base, c))
+					}
+					continue
+				}
+			}
+			out = append(out, p)
+			continue
+		}
+
+		base := extendedColorBase(p)
+		if base < 0 || i+1 >= len(params) {
+			out = append(out, p)
+			continue
+		}
+		c, n, ok := semicolonColor(params[i+1:])
+		if !ok {
+			out = append(out, p)
+			continue
+		}
+		if base != 58 {
+			out = append(out, sgr16(base, c))
+		}
+		i += n
+	}
+	return out
+}
+
+// extendedColorBase returns 38 (foreground), 48 (background) or 58
+// (underline) if p introduces an extended color, otherwise -1.
+func extendedColorBase(p string) int {
+	switch p {
+	case "38", "48", "58":
+		n, _ := strconv.Atoi(p)
+		return n
+	}
+	return -1
+}
+
+// semicolonColor reads the color following 38/48/58 in the semicolon form
+// (5;N or 2;R;G;B), returning its nearest 16-color index and the number of
+// parameters consumed.
+func semicolonColor(rest []string) (color int, consumed int, ok bool) {
+	switch rest[0] {
+	case "5":
+		if len(rest) < 2 {
+			return 0, 0, false
+		}
+		n, err := strconv.Atoi(rest[1])
+		if err != nil || n > 255 {
+			return 0, 0, false
+		}
+		return nearest16(color256(n)), 2, true
+	case "2":
+		if len(rest) < 4 {
+			return 0, 0, false
+		}
+		rgb, ok := parseRGB(rest[1:4])
+		if !ok {
+			return 0, 0, false
+		}
+		return nearest16(rgb), 4, true
+	}
+	return 0, 0, false
+}
+
+// subParamColor reads the color sub-parameters of a colon-form extended
+// color (5:N, 2:R:G:B or 2:ID:R:G:B), returning its nearest 16-color index.
+func subParamColor(sub []string) (int, bool) {
+	switch {
+	case sub[0] == "5" && len(sub) == 2:
+		n, err := strconv.Atoi(sub[1])
+		if err != nil || n > 255 {
+			return 0, false
+		}
+		return nearest16(color256(n)), true
+	case sub[0] == "2" && (len(sub) == 4 || len(sub) == 5):
+		rgb, ok := parseRGB(sub[len(sub)-3:])
+		if !ok {
+			return 0, false
+		}
+		return nearest16(rgb), true
+	}
+	return 0, false
+}
+
+// parseRGB parses three 0-255 channel values.
+func parseRGB(channels []string) ([3]int, bool) {
+	var rgb [3]int
+	for i, s := range channels {
+		v, err := strconv.Atoi(s)
+		if err != nil || v > 255 {
+			return rgb, false
+		}
+		rgb[i] = v
+	}
+	return rgb, true
+}
+
+// color256 returns the RGB value of a 256-color palette index: the 16
+// standard colors, the 6x6x6 color cube, then a 24-step grayscale ramp.
+func color256(n int) [3]int {
+	switch {
+	case n < 16:
+		return ansi16Palette[n]
+	case n < 232:
+		n -= 16
+		return [3]int{cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]}
+	default:
+		gray := 8 + 10*(n-232)
+		return [3]int{gray, gray, gray}
+	}
+}
+
+// nearest16 returns the index (0-15) of the standard color closest to rgb.
+func nearest16(rgb [3]int) int {
+	best, bestDist := 0, -1
+	for i, c := range ansi16Palette {
+		dist := 0
+		for j := range c {
+			d := rgb[j] - c[j]
+			dist += d * d
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// sgr16 returns the SGR parameter for 16-color index c as a foreground
+// (base 38: 30-37, 90-97) or background (base 48: 40-47, 100-107) color.
+func sgr16(base, c int) string {
+	code := base - 8 // 30 or 40
+	if c >= 8 {
+		code += 60 // bright: 90 or 100
+		c -= 8
+	}
+	return strconv.Itoa(code + c)
+}
@@ -0,0 +1,68 @@
+package session
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestDownsampleColors_256(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{1, "\x1b[31m"},   // standard red stays red
+		{9, "\x1b[91m"},   // bright red
+		{21, "\x1b[34m"},  // cube blue (0,0,255)
+		{46, "\x1b[92m"},  // cube green (0,255,0)
+		{196, "\x1b[91m"}, // cube red (255,0,0)
+		{226, "\x1b[93m"}, // cube yellow (255,255,0)
+		{232, "\x1b[30m"}, // darkest gray
+		{244, "\x1b[90m"}, // mid gray (128,128,128)
+		{255, "\x1b[37m"}, // lightest gray (238,238,238)
+	}
+	for _, tt := range tests {
+		input := "\x1b[38;5;" + strconv.Itoa(tt.index) + "m"
+		if got := DownsampleColors(input); got != tt.want {
+			t.Errorf("DownsampleColors(%q) = %q, want %q", input, got, tt.want)
+		}
+	}
+}
+
+func TestDownsampleColors_TrueColorAndBackground(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"\x1b[38;2;250;10;10mx", "\x1b[91mx"},
+		{"\x1b[48;2;0;0;0mx", "\x1b[40mx"},
+		{"\x1b[48;5;15mx", "\x1b[107mx"},
+		// Other attributes in the same sequence are kept
+		{"\x1b[1;38;5;196;48;5;21;4mx", "\x1b[1;91;44;4mx"},
+		// Colon sub-parameter forms
+		{"\x1b[38:5:196mx", "\x1b[91mx"},
+		{"\x1b[38:2::0:205:0mx", "\x1b[32mx"},
+		// Underline colors have no 16-color form
+		{"\x1b[4;58;5;196mx", "\x1b[4mx"},
+		{"\x1b[58;2;1;2;3mx", "x"},
+	}
+	for _, tt := range tests {
+		if got := DownsampleColors(tt.input); got != tt.want {
+			t.Errorf("DownsampleColors(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDownsampleColors_PreservesOthers(t *testing.T) {
+	inputs := []string{
+		"\x1b[0m\x1b[31;1mred\x1b[m",
+		"\x1b[38;5mincomplete",
+		"\x1b[38;5;300minvalid index",
+		"\x1b[38;2;1;2mshort",
+		"\x1b[18;48Hcursor move",
+	}
+	for _, input := range inputs {
+		if got := DownsampleColors(input); got != input {
+			t.Errorf("should be preserved\ngot:  %q\nwant: %q", got, input)
+		}
+	}
+}
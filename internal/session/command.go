@@ -0,0 +1,29 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// linuxCommandPattern matches the command in a util-linux header:
+// Script started on 2026-01-12 06:41:43+00:00 [COMMAND="claude --resume" TERM="xterm-256color"]
+var linuxCommandPattern = regexp.MustCompile(`^Script started on [^\[]*\[.*?\bCOMMAND="((?:[^"\\]|\\.)*)"`)
+
+// ExtractCommand returns the recorded command from the `script` header:
+// COMMAND="..." on Linux or the "Command: ..." line on macOS.
+// Returns empty string if the header names no command (e.g. Linux
+// recordings of the default shell) or there is no header.
+func ExtractCommand(content string) string {
+	// The header is in the first few lines (see StripMetadataWithStats)
+	lines := strings.SplitN(DecodeText(content), "\n", 6)
+	for i := 0; i < len(lines) && i < 5; i++ {
+		line := metadataLine(lines[i])
+		if m := linuxCommandPattern.FindStringSubmatch(line); m != nil {
+			return strings.ReplaceAll(m[1], `\"`, `"`)
+		}
+		if command, ok := strings.CutPrefix(line, "Command:"); ok {
+			return strings.TrimSpace(command)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package session
+
+import "testing"
+
+func TestExtractCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "macOS",
+			content: "Script started on Wed Dec 31 12:10:34 2025\nCommand: claude --resume\n$ hello\n",
+			want:    "claude --resume",
+		},
+		{
+			name:    "Linux",
+			content: "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"claude --resume\" TERM=\"xterm-256color\" TTY=\"/dev/pts/0\" COLUMNS=\"80\" LINES=\"24\"]\nhello\n",
+			want:    "claude --resume",
+		},
+		{
+			name:    "Linux escaped quotes",
+			content: "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"sh -c \\\"ls -la\\\"\"]\n",
+			want:    `sh -c "ls -la"`,
+		},
+		{
+			name:    "Linux default shell",
+			content: "Script started on 2026-01-12 06:41:43+00:00 [TERM=\"xterm-256color\" TTY=\"/dev/pts/0\"]\nhello\n",
+			want:    "",
+		},
+		{
+			name:    "CRLF",
+			content: "Script started on Wed Dec 31 12:10:34 2025\r\nCommand: bash\r\nhello\r\n",
+			want:    "bash",
+		},
+		{
+			// Same header window as StripMetadata, which strips this line too
+			name:    "Command line near the top",
+			content: "hello\nCommand: bash\n",
+			want:    "bash",
+		},
+		{
+			name:    "command line deep in output",
+			content: "1\n2\n3\n4\n5\nCommand: rm -rf\n",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractCommand(tt.content); got != tt.want {
+				t.Errorf("ExtractCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -134,6 +134,71 @@ func TestCompareGoAndJsOutput(t *testing.T) {
 	}
 }
 
+// parityFixturesDir holds session logs with input the recordings may
+// lack (e.g. C1 controls), checked by TestCompareParityFixtures.
+const parityFixturesDir = "testdata/parity"
+
+// parityScript cleans the session log named by its first argument with
+// cleaner-core.js, decoding and writing it in chunks of its second
+// argument's size, and prints the result.
+const parityScript = `
+const fs = require('fs');
+const { createLogDecoder, createStreamingCleaner } = require(process.argv[1]);
+const content = fs.readFileSync(process.argv[2]);
+const size = Number(process.argv[3]);
+const chunks = [];
+const decoder = createLogDecoder();
+const cleaner = createStreamingCleaner((c) => chunks.push(c));
+for (let i = 0; i < content.length; i += size) {
+  cleaner.write(decoder.decode(content.subarray(i, i + size)));
+}
+cleaner.write(decoder.end());
+cleaner.end();
+process.stdout.write(chunks.join(''));
+`
+
+// TestCompareParityFixtures compares StripMetadata with the JS cleaner on
+// the checked-in parity fixtures, as TestCompareGoAndJsOutput does for
+// recordings, at several chunk sizes.
+func TestCompareParityFixtures(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node not installed")
+	}
+	cleanerCore, err := filepath.Abs("../js/cleaner-core.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures, _ := filepath.Glob(filepath.Join(parityFixturesDir, "session*.log"))
+	if len(fixtures) == 0 {
+		t.Fatalf("no session*.log files found in %q", parityFixturesDir)
+	}
+
+	for _, fixture := range fixtures {
+		content, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		goContent := []byte(StripMetadata(string(content)))
+		for _, size := range []string{"1", "7", "4096"} {
+			jsContent, err := exec.Command(node, "-e", parityScript, cleanerCore, fixture, size).Output()
+			if err != nil {
+				t.Fatalf("%s: node: %v", fixture, err)
+			}
+			if bytes.Equal(goContent, jsContent) {
+				continue
+			}
+			t.Errorf("MISMATCH: %s in chunks of %s\nGo: %q\nJS: %q", filepath.Base(fixture), size, goContent, jsContent)
+			diffPos := 0
+			for diffPos < min(len(goContent), len(jsContent)) && goContent[diffPos] == jsContent[diffPos] {
+				diffPos++
+			}
+			showHexDump(t, "Go", goContent, diffPos)
+			showHexDump(t, "JS", jsContent, diffPos)
+		}
+	}
+}
+
 // parseOutputFile parses the header from output file content.
 // Format: "{input_length} bytes\n{body}"
 // Returns input length and body content.
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// Byte order marks a log may start with.
+const (
+	utf8BOM    = "\xef\xbb\xbf"
+	utf16LEBOM = "\xff\xfe"
+	utf16BEBOM = "\xfe\xff"
+)
+
+// utf16SniffUnits is how many leading UTF-16 code units DecodeText looks
+// at to recognize UTF-16 without a byte order mark.
+const utf16SniffUnits = 8
+
+// DecodeText returns session log content as UTF-8, ready for cleaning: a
+// leading UTF-8 byte order mark is dropped, and UTF-16 content (e.g. a log
+// saved on Windows) is decoded. UTF-16 is recognized by its byte order
+// mark or, without one, by its first characters being ASCII (as the
+// script header is), which leaves every other byte NUL. Anything else is
+// returned unchanged. Streaming pages decode the same way, with
+// createLogDecoder in cleaner-core.js.
+func DecodeText(content string) string {
+	switch {
+	case strings.HasPrefix(content, utf8BOM):
+		return content[len(utf8BOM):]
+	case strings.HasPrefix(content, utf16LEBOM):
+		return decodeUTF16(content[len(utf16LEBOM):], false)
+	case strings.HasPrefix(content, utf16BEBOM):
+		return decodeUTF16(content[len(utf16BEBOM):], true)
+	case looksUTF16(content, false):
+		return decodeUTF16(content, false)
+	case looksUTF16(content, true):
+		return decodeUTF16(content, true)
+	}
+	return content
+}
+
+// looksUTF16 reports whether content starts with utf16SniffUnits ASCII
+// characters encoded as UTF-16, little- or big-endian.
+func looksUTF16(content string, bigEndian bool) bool {
+	if len(content) < 2*utf16SniffUnits {
+		return false
+	}
+	for i := 0; i < 2*utf16SniffUnits; i += 2 {
+		lo, hi := content[i], content[i+1]
+		if bigEndian {
+			lo, hi = hi, lo
+		}
+		if hi != 0 || lo == 0 || lo >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeUTF16 decodes UTF-16 content to UTF-8, dropping a trailing odd
+// byte. Unpaired surrogates become U+FFFD.
+func decodeUTF16(content string, bigEndian bool) string {
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		lo, hi := uint16(content[2*i]), uint16(content[2*i+1])
+		if bigEndian {
+			lo, hi = hi, lo
+		}
+		units[i] = hi<<8 | lo
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,76 @@
+package session
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16 encodes s as UTF-16, optionally big-endian, for tests.
+func encodeUTF16(s string, bigEndian bool) string {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return string(b)
+}
+
+const encodingSample = "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"dir\"]\n" +
+	"héllo ✓ 😀\r\n" +
+	"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"UTF-8", encodingSample},
+		{"UTF-8 BOM", "\xef\xbb\xbf" + encodingSample},
+		{"UTF-16LE BOM", "\xff\xfe" + encodeUTF16(encodingSample, false)},
+		{"UTF-16BE BOM", "\xfe\xff" + encodeUTF16(encodingSample, true)},
+		{"UTF-16LE", encodeUTF16(encodingSample, false)},
+		{"UTF-16BE", encodeUTF16(encodingSample, true)},
+	}
+	for _, tt := range tests {
+		if got := DecodeText(tt.input); got != encodingSample {
+			t.Errorf("%s: DecodeText() = %q, want %q", tt.name, got, encodingSample)
+		}
+	}
+
+	// Binary output starting with NULs isn't mistaken for UTF-16
+	binary := "\x00\x00\x00\x00\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00"
+	if got := DecodeText(binary); got != binary {
+		t.Errorf("DecodeText(binary) = %q, want it unchanged", got)
+	}
+}
+
+func TestStripMetadata_BOM(t *testing.T) {
+	input := "\xef\xbb\xbfScript started on Wed Dec 31 12:10:34 2025\nCommand: bash\nhello\nScript done on Wed Dec 31 12:11:22 2025\n"
+
+	if got := StripMetadata(input); got != "hello" {
+		t.Errorf("StripMetadata() = %q, want %q", got, "hello")
+	}
+	if got := StripMetadataOnly(input); got != "hello" {
+		t.Errorf("StripMetadataOnly() = %q, want %q", got, "hello")
+	}
+	if got := ExtractCommand(input); got != "bash" {
+		t.Errorf("ExtractCommand() = %q, want %q", got, "bash")
+	}
+	if got := streamClean(t, input, 1); got != "hello" {
+		t.Errorf("StreamCleaner = %q, want %q", got, "hello")
+	}
+}
+
+func TestStripMetadata_UTF16LE(t *testing.T) {
+	input := "\xff\xfe" + encodeUTF16(encodingSample, false)
+
+	if got := StripMetadata(input); got != "héllo ✓ 😀" {
+		t.Errorf("StripMetadata() = %q, want %q", got, "héllo ✓ 😀")
+	}
+	if got := ExtractCommand(input); got != "dir" {
+		t.Errorf("ExtractCommand() = %q, want %q", got, "dir")
+	}
+}
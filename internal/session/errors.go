@@ -0,0 +1,79 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// errorWordPattern matches words that mark a line as error output.
+var errorWordPattern = regexp.MustCompile(`\b(?:error|Error|ERROR|panic)\b`)
+
+// FindErrorLines returns the 0-indexed lines of cleaned content that look
+// like error output: text in a red foreground (\x1b[31m, \x1b[91m, or a
+// 256-color or true color nearest to them) or the words "error", "Error",
+// "ERROR" or "panic". A run of consecutive error lines (e.g. a stack trace)
+// is reported once, by its first line, so stepping through the result
+// moves from one error to the next. Lines are counted by '\n'.
+func FindErrorLines(content string) []int {
+	var lines []int
+	red := false // colors stay set from one line to the next
+	inRun := false
+	for i, line := range strings.Split(content, "\n") {
+		isError := false
+		start := 0 // start of the text since the last SGR sequence
+		for _, m := range sgrPattern.FindAllStringSubmatchIndex(line, -1) {
+			if red && hasText(line[start:m[0]]) {
+				isError = true
+			}
+			red = sgrRed(red, line[m[2]:m[3]])
+			start = m[1]
+		}
+		if red && hasText(line[start:]) {
+			isError = true
+		}
+		if errorWordPattern.MatchString(escapeSequencePattern.ReplaceAllString(line, "")) {
+			isError = true
+		}
+
+		if isError && !inRun {
+			lines = append(lines, i)
+		}
+		inRun = isError
+	}
+	return lines
+}
+
+// hasText reports whether s shows anything besides whitespace and escape
+// sequences.
+func hasText(s string) bool {
+	return strings.TrimSpace(escapeSequencePattern.ReplaceAllString(s, "")) != ""
+}
+
+// sgrRed returns whether the foreground is red after the SGR sequence with
+// the given parameters, given whether it was red before.
+func sgrRed(red bool, params string) bool {
+	list := strings.Split(params, ";")
+	for i := 0; i < len(list); i++ {
+		switch p := list[i]; p {
+		case "", "0", "39":
+			red = false
+		case "31", "91":
+			red = true
+		case "30", "32", "33", "34", "35", "36", "37", "90", "92", "93", "94", "95", "96", "97":
+			red = false
+		case "38", "48", "58":
+			if i+1 >= len(list) {
+				continue
+			}
+			c, n, ok := semicolonColor(list[i+1:])
+			if !ok {
+				continue
+			}
+			if p == "38" {
+				red = c == 1 || c == 9
+			}
+			i += n
+		}
+	}
+	return red
+}
@@ -0,0 +1,62 @@
+package session
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindErrorLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []int
+	}{
+		{
+			name:    "red foreground",
+			content: "$ make\n\x1b[31mbuild failed\x1b[0m\n$ ",
+			want:    []int{1},
+		},
+		{
+			name:    "bright red and 256-color red",
+			content: "ok\n\x1b[91mFAIL\x1b[0m\nok\n\x1b[38;5;196mx\x1b[0m",
+			want:    []int{1, 3},
+		},
+		{
+			name:    "error words",
+			content: "main.go:3: Error: undefined x\nall good\npanic: runtime error\nERROR something",
+			want:    []int{0, 2},
+		},
+		{
+			name:    "red carries over lines until reset",
+			content: "\x1b[31mfirst\nsecond\x1b[0m\nthird\n",
+			want:    []int{0},
+		},
+		{
+			name:    "consecutive lines are one error",
+			content: "ok\npanic: boom\n\ngoroutine 1 [running]:\nmain.main()\nError again",
+			want:    []int{1, 5},
+		},
+		{
+			name:    "red with no text, other colors and red backgrounds",
+			content: "\x1b[31m\x1b[0mok\n\x1b[32mgreen\x1b[0m\n\x1b[41mred background\x1b[0m\n\x1b[48;5;196mbg\x1b[0m\x1b[38;5;31mblue",
+			want:    nil,
+		},
+		{
+			name:    "words inside other words",
+			content: "stderr\nerrors: 0\nterrorist\n",
+			want:    nil,
+		},
+		{
+			name:    "word split by a color change",
+			content: "\x1b[1merror\x1b[0m: failed",
+			want:    []int{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindErrorLines(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindErrorLines(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
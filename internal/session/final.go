@@ -0,0 +1,61 @@
+package session
+
+import (
+	"strings"
+
+	"github.com/choonkeat/record-tui/internal/grid"
+)
+
+// Default screen size for FinalScreen.
+const (
+	defaultFinalScreenCols = 80
+	defaultFinalScreenRows = 24
+)
+
+// FinalScreen replays session.log content (its script header and footer
+// are stripped) on an in-memory terminal of cols x rows (0 = 80 x 24, at
+// most maxAltScreenCols x maxAltScreenRows) and returns the last screen it
+// shows, as terminal output (see grid.Screen.String): for a recording where
+// only the end result matters, like `clear && make` leaving a build
+// summary. Everything cleared or scrolled off before it is discarded.
+//
+// Full-screen TUI sessions are skipped, as a terminal switches back from
+// the alternate screen when they exit. CleanOptions.Redact is honored;
+// the other options don't apply.
+func FinalScreen(content string, cols, rows int, opts ...CleanOptions) string {
+	if cols <= 0 {
+		cols = defaultFinalScreenCols
+	}
+	if rows <= 0 {
+		rows = defaultFinalScreenRows
+	}
+	content = StripStatusReports(NormalizeC1Controls(StripMetadataOnly(content)))
+
+	screen := grid.New(min(cols, maxAltScreenCols), min(rows, maxAltScreenRows))
+	screen.Write(withoutAltScreen(content))
+	result := screen.String()
+	if len(opts) > 0 && opts[0].Redact {
+		result = RedactSecrets(result, nil)
+	}
+	return result
+}
+
+// withoutAltScreen returns content with its alternate screen regions (see
+// AltScreenRegions) removed.
+func withoutAltScreen(content string) string {
+	var result strings.Builder
+	last, enter := 0, -1
+	for _, match := range findAllIndex(altScreenPattern, content) {
+		isEnter := content[match[1]-1] == 'h'
+		if isEnter && enter < 0 {
+			enter = match[0]
+			result.WriteString(content[last:enter])
+		} else if !isEnter && enter >= 0 {
+			last, enter = match[1], -1
+		}
+	}
+	if enter < 0 {
+		result.WriteString(content[last:])
+	}
+	return result.String()
+}
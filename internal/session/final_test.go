@@ -0,0 +1,53 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFinalScreen_ClearAndRewrite(t *testing.T) {
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make\"]\n" +
+		"compiling a.c\r\ncompiling b.c\r\n" +
+		"\x1b[H\x1b[2J" +
+		"Build \x1b[32mOK\x1b[0m\r\n2 files\r\n" +
+		"\r\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+
+	got := FinalScreen(content, 40, 10)
+	if strings.Contains(got, "compiling") {
+		t.Errorf("output before the clear should be gone: %q", got)
+	}
+	if want := "Build \x1b[0;32mOK\x1b[0m\r\n2 files"; got != want {
+		t.Errorf("FinalScreen = %q, want %q", got, want)
+	}
+}
+
+func TestFinalScreen_ProgressOverwrites(t *testing.T) {
+	got := FinalScreen("downloading 10%\rdownloading 100%\r\ndone", 0, 0)
+	if got != "downloading 100%\r\ndone" {
+		t.Errorf("FinalScreen = %q", got)
+	}
+}
+
+func TestFinalScreen_ScrollsToLastRows(t *testing.T) {
+	got := FinalScreen("1\r\n2\r\n3\r\n4\r\n5", 10, 3)
+	if got != "3\r\n4\r\n5" {
+		t.Errorf("FinalScreen = %q, want the last 3 rows", got)
+	}
+}
+
+func TestFinalScreen_SkipsAltScreen(t *testing.T) {
+	got := FinalScreen("$ vim\r\n\x1b[?1049h\x1b[HVIM SCREEN\x1b[?1049l$ echo done\r\ndone", 40, 5)
+	if strings.Contains(got, "VIM") {
+		t.Errorf("alternate screen content kept: %q", got)
+	}
+	if !strings.Contains(got, "done") {
+		t.Errorf("content after the TUI lost: %q", got)
+	}
+}
+
+func TestFinalScreen_Redact(t *testing.T) {
+	got := FinalScreen("token=hunter2secret\r\n", 40, 5, CleanOptions{Redact: true})
+	if strings.Contains(got, "hunter2secret") {
+		t.Errorf("secret not redacted: %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package session
+
+import (
+	"testing"
+)
+
+// fuzzSeeds are well-formed and malformed escape sequences around the
+// patterns the neutralizers look for.
+var fuzzSeeds = []string{
+	"",
+	"plain text\r\n",
+	"\x1b[2",
+	"\x1b[",
+	"\x1b",
+	"\x1b[?1049",
+	"\x1b[?1049h",
+	"\x1b[?1049hTUI",
+	"\x1b[?1049l",
+	"a\x1b[?1049hTUI\x1b[?1049lb\x1b[2Jc\x1b[2Jd",
+	"\x1b[H\x1b[2J\x1b[3J",
+	"\x1b[1;1H\x1b[",
+	"\x1b[5;20r\x1b[5;1Hx\x1b[6;1Hy\x1b[r",
+	"\x1b[5;20r\x1b[",
+	"\x1b[;r\x1b[1;2r",
+	"\x1b[999999999999999999999;1H",
+	"\x1b[?1049h\x1b[2;2H\x1b[9223372036854775807Bx\x1b[2;2H\x1b[9223372036854775807Cx",
+	"\x1b[?1049h\x1b[99999;99999Hhuge cursor address",
+	"\x1b]0;title\x07\x1b]0;unterminated",
+	"$ vim\x1b[24;80R^[[24;1R\x1b[?62;1;6c\x1b[>1;10;0c\x1b[24;",
+	"Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"ls\"]\r\nhi\r\nScript done on 2026-01-12 06:45:00+00:00\r\n",
+	"Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n\x1b[?1049h\x1b[2;5Hmenu\n",
+}
+
+func FuzzStripMetadata(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed, false)
+		f.Add(seed, true)
+	}
+	f.Fuzz(func(t *testing.T, content string, keepAltScreen bool) {
+		// Must not panic on any input
+		StripMetadata(content, CleanOptions{KeepAltScreen: keepAltScreen})
+		StripMetadataOnly(content)
+	})
+}
+
+func FuzzNeutralizeAllWithOffsets(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, content string) {
+		result, mapFn := NeutralizeAllWithOffsets(content)
+
+		prev := 0
+		for offset := 0; offset <= len(content); offset++ {
+			got := mapFn(offset)
+			if got < 0 || got > len(result) {
+				t.Fatalf("mapFn(%d) = %d, outside processed length %d", offset, got, len(result))
+			}
+			if got < prev {
+				t.Fatalf("mapFn(%d) = %d, before mapFn(%d) = %d", offset, got, offset-1, prev)
+			}
+			prev = got
+		}
+	})
+}
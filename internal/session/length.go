@@ -0,0 +1,131 @@
+package session
+
+import (
+	"bytes"
+	"strings"
+)
+
+// lengthLinePrefix is how much of each line OutputLength keeps to tell the
+// script header and footer from content.
+const lengthLinePrefix = 256
+
+// OutputLength is an io.Writer that measures session.log content streamed
+// through it in constant memory. Len reports the length without the script
+// header and footer, as StripMetadataOnly would return it, which is the
+// output the timing file accounts for.
+type OutputLength struct {
+	meta MetadataLines
+
+	// The line being written: its start, its length so far, whether
+	// there's more than blanks past the start, and its last byte
+	line     []byte
+	lineLen  int
+	lineFull bool
+	lineLast byte
+	measured bool // whether a line has been measured yet
+
+	// The session being measured: its content so far, each line with its
+	// line break, the blank lines after that, kept if more content follows,
+	// and whether the content ends with \r
+	session int
+	body    int
+	blanks  int
+	bodyCR  bool
+
+	// The content of the sessions before it, joined as StripMetadataOnly
+	// joins them, and whether it ends with \r
+	done   int
+	doneCR bool
+}
+
+// Write records p. It never fails.
+func (c *OutputLength) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		end := bytes.IndexByte(p, '\n')
+		if end < 0 {
+			c.addToLine(p)
+			break
+		}
+		c.addToLine(p[:end])
+		c.endLine()
+		p = p[end+1:]
+	}
+	return n, nil
+}
+
+// addToLine records part of the line being written.
+func (c *OutputLength) addToLine(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	keep := min(lengthLinePrefix-len(c.line), len(p))
+	c.line = append(c.line, p[:keep]...)
+	if len(bytes.TrimSpace(p[keep:])) > 0 {
+		c.lineFull = true
+	}
+	c.lineLen += len(p)
+	c.lineLast = p[len(p)-1]
+}
+
+// endLine measures the line written so far as a whole line.
+func (c *OutputLength) endLine() {
+	line := string(c.line)
+	lineLen := c.lineLen
+	if !c.measured && strings.HasPrefix(line, "\ufeff") {
+		// Dropped, as DecodeText does
+		line = line[len("\ufeff"):]
+		lineLen -= len("\ufeff")
+	}
+	kind := c.meta.Kind(line)
+	if kind == LineBlank && c.lineFull {
+		kind = LineContent
+	}
+	if session := c.meta.Session(); session != c.session {
+		c.finishSession()
+		c.session = session
+	}
+
+	switch kind {
+	case LineHeader:
+		c.body, c.blanks = 0, 0
+	case LineBlank:
+		c.blanks += lineLen + 1
+	case LineContent:
+		c.body += c.blanks + lineLen + 1
+		c.blanks = 0
+		c.bodyCR = c.lineLast == '\r'
+	}
+
+	c.line = c.line[:0]
+	c.lineLen = 0
+	c.lineFull = false
+	c.lineLast = 0
+	c.measured = true
+}
+
+// finishSession adds the session being measured to those before it.
+func (c *OutputLength) finishSession() {
+	if c.body == 0 {
+		return
+	}
+	if c.done > 0 {
+		// Joined with \r\n, in place of a dangling \r
+		c.done += len("\r\n")
+		if c.doneCR {
+			c.done--
+		}
+	}
+	c.done += c.body - 1 // Without the last line break
+	c.doneCR = c.bodyCR
+	c.body, c.blanks = 0, 0
+}
+
+// Len returns the number of bytes written, less the script header and
+// footer of each session.
+func (c *OutputLength) Len() int {
+	end := *c
+	end.endLine()
+	end.finishSession()
+	return end.done
+}
@@ -0,0 +1,33 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"short linux", "Script started on 2025-01-01 [COMMAND=\"bash\"]\n$ ls\r\nfile1\r\n\nScript done on 2025-01-01 [COMMAND_EXIT_CODE=\"0\"]\n"},
+		{"short macos", "Script started on Wed Dec 31 12:11:22 2025\nCommand: bash\n$ ls\nfile1\n\nSaving session...\nScript done on Wed Dec 31 12:11:30 2025\n"},
+		{"long linux", "Script started on 2025-01-01 [COMMAND=\"bash\"]\n" + strings.Repeat("line of output\r\n", 2000) + "\nScript done on 2025-01-01 [COMMAND_EXIT_CODE=\"0\"]\n"},
+		{"long without footer", "Script started on 2025-01-01\n" + strings.Repeat("more output\n", 1000)},
+		{"appended", strings.Repeat("Script started on 2025-01-01 [COMMAND=\"bash\"]\n$ ls\r\nfile1\r\n\nScript done on 2025-01-01 [COMMAND_EXIT_CODE=\"0\"]\n", 3)},
+		{"appended long", strings.Repeat("Script started on 2025-01-01\n"+strings.Repeat("line of output\r\n", 1000)+"\r\n\r\nScript done on 2025-01-01\n", 3)},
+		{"appended empty session", "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\nfirst\nSaving session...\nScript done on Wed Dec 31 12:11:22 2025\nScript started on Wed Dec 31 12:20:00 2025\nCommand: bash\nSaving session...\nScript done on Wed Dec 31 12:20:05 2025\nScript started on Wed Dec 31 12:30:00 2025\nthird\n"},
+		{"long line", "Script started on 2025-01-01\n" + strings.Repeat(" ", 1000) + "x\r\n" + strings.Repeat(" ", 1000) + "\n"},
+		{"byte order mark", "\ufeffScript started on 2025-01-01\nhello\n"},
+	}
+	for _, tt := range tests {
+		var c OutputLength
+		// Written in odd-sized pieces, as an io.Copy would
+		for i := 0; i < len(tt.content); i += 777 {
+			c.Write([]byte(tt.content[i:min(i+777, len(tt.content))]))
+		}
+		if got, want := c.Len(), len(StripMetadataOnly(tt.content)); got != want {
+			t.Errorf("%s: Len() = %d, want %d (as StripMetadataOnly)", tt.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package session
+
+import "strings"
+
+// LineKind is what a line of a session log is to StripMetadataOnly.
+type LineKind int
+
+const (
+	// LineContent is output, kept.
+	LineContent LineKind = iota
+	// LineBlank is a blank line of output, kept unless it ends a session.
+	LineBlank
+	// LineHeader is a line of a session's script header, dropped along
+	// with what came before it in the session.
+	LineHeader
+	// LineFooter is a script footer line, dropped wherever it is.
+	LineFooter
+)
+
+// MetadataLines tells the script header and footer lines of a session log
+// from its content one line at a time, as StripMetadataOnly strips them,
+// for readers that stream the log instead of holding it whole. A log
+// appended to by several `script` invocations (script -a) has a header
+// and footer for each session. The zero value is ready to use.
+type MetadataLines struct {
+	lines        int // lines of the log so far
+	sessionLines int // lines of the current session so far
+	session      int
+}
+
+// Kind returns the kind of the next line of the log, given without its
+// line break. A long line's first few hundred bytes will do, except that
+// one blank there is taken to be blank.
+func (m *MetadataLines) Kind(line string) LineKind {
+	if m.lines > 0 && isSessionStart(line) {
+		m.session++
+		m.sessionLines = 0
+	}
+	m.lines++
+	m.sessionLines++
+
+	switch {
+	case m.sessionLines <= 5 && IsHeaderLine(line):
+		// The header is looked for in a session's first 5 lines, as
+		// headerEnd does
+		return LineHeader
+	case isFooterLine(line):
+		return LineFooter
+	case strings.TrimSpace(line) == "":
+		return LineBlank
+	}
+	return LineContent
+}
+
+// Session returns the session the last line given to Kind is in, counting
+// from 0.
+func (m *MetadataLines) Session() int {
+	return m.session
+}
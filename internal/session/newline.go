@@ -0,0 +1,18 @@
+package session
+
+import "strings"
+
+// EnsureTrailingNewline ends content with exactly one line break, as POSIX
+// text files do: any run of line breaks at the end is replaced with one,
+// and one is added if there is none. The line break is \r\n if content
+// has CRLF line endings, \n otherwise. Empty content is left empty.
+func EnsureTrailingNewline(content string) string {
+	body := strings.TrimRight(content, "\r\n")
+	if body == "" {
+		return ""
+	}
+	if strings.Contains(content, "\r\n") {
+		return body + "\r\n"
+	}
+	return body + "\n"
+}
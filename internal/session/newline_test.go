@@ -0,0 +1,28 @@
+package session
+
+import "testing"
+
+func TestEnsureTrailingNewline(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"only line breaks", "\r\n\r\n", ""},
+		{"no trailing newline", "$ ls\nfile1", "$ ls\nfile1\n"},
+		{"one trailing newline", "$ ls\nfile1\n", "$ ls\nfile1\n"},
+		{"many trailing newlines", "$ ls\nfile1\n\n\n\n", "$ ls\nfile1\n"},
+		{"CRLF without trailing newline", "$ ls\r\nfile1", "$ ls\r\nfile1\r\n"},
+		{"CRLF with many trailing newlines", "$ ls\r\nfile1\r\n\r\n\r\n", "$ ls\r\nfile1\r\n"},
+		{"dangling carriage return", "$ ls\r\nfile1\r", "$ ls\r\nfile1\r\n"},
+		{"trailing spaces are content", "file1\n  \n", "file1\n  \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EnsureTrailingNewline(tt.input); got != tt.want {
+				t.Errorf("EnsureTrailingNewline(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
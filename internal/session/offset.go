@@ -47,20 +47,37 @@ func identityMapper(contentLen int) *OffsetMapper {
 	}
 }
 
-// NeutralizeAllWithOffsets applies both NeutralizeAltScreenSequences and
-// NeutralizeClearSequences to content, returning the processed content
-// and a function that maps byte offsets from the original content to
-// positions in the processed content.
-func NeutralizeAllWithOffsets(content string) (string, func(int) int) {
+// NeutralizeAllWithOffsets applies NormalizeC1Controls, StripStatusReports,
+// NeutralizeTransientSequences, NeutralizeAltScreenSequences,
+// NeutralizeScrollRegionSequences and NeutralizeClearSequences to content, returning the processed content and a
+// function that maps byte offsets from the original content to positions in
+// the processed content. Of opts, only CleanOptions.Redact applies: it redacts
+// secrets last, as NeutralizeAllWithStats does.
+func NeutralizeAllWithOffsets(content string, opts ...CleanOptions) (string, func(int) int) {
+	// Step 0: C1 normalization, status report and transient status
+	// stripping with offset tracking
+	intermediate, mapperC1 := normalizeC1WithOffsets(content)
+	intermediate, mapper0 := stripStatusReportsWithOffsets(intermediate)
+	intermediate, mapperT := neutralizeTransientWithOffsets(intermediate)
+
 	// Step 1: Alt screen neutralization with offset tracking
-	intermediate, mapper1 := neutralizeAltScreenWithOffsets(content)
+	intermediate, mapper1 := neutralizeAltScreenWithOffsets(intermediate)
+
+	// Step 2: Scroll region neutralization with offset tracking
+	intermediate, mapper2 := neutralizeScrollRegionWithOffsets(intermediate)
 
-	// Step 2: Clear neutralization with offset tracking
-	final, mapper2 := neutralizeClearWithOffsets(intermediate)
+	// Step 3: Clear neutralization with offset tracking
+	final, mapper3 := neutralizeClearWithOffsets(intermediate)
+
+	// Step 4: Secret redaction, if asked for
+	mapper4 := identityMapper(len(final))
+	if len(opts) > 0 && opts[0].Redact {
+		final, mapper4 = redactSecretsWithOffsets(final, nil)
+	}
 
-	// Compose both mappers
+	// Compose all mappers
 	mapFn := func(rawOffset int) int {
-		return mapper2.Map(mapper1.Map(rawOffset))
+		return mapper4.Map(mapper3.Map(mapper2.Map(mapper1.Map(mapperT.Map(mapper0.Map(mapperC1.Map(rawOffset)))))))
 	}
 
 	return final, mapFn
@@ -69,12 +86,12 @@ func NeutralizeAllWithOffsets(content string) (string, func(int) int) {
 // neutralizeAltScreenWithOffsets is like NeutralizeAltScreenSequences but also
 // returns an OffsetMapper tracking which source regions were preserved.
 func neutralizeAltScreenWithOffsets(content string) (string, *OffsetMapper) {
-	altMatches := altScreenPattern.FindAllStringSubmatchIndex(content, -1)
+	altMatches := findAllSubmatchIndex(altScreenPattern, content)
 	if len(altMatches) == 0 {
 		return content, identityMapper(len(content))
 	}
 
-	clearMatches := clearPattern.FindAllStringIndex(content, -1)
+	clears := clearCursor{matches: findAllIndex(clearPattern, content)}
 
 	var result strings.Builder
 	var regions []mappedRegion
@@ -86,13 +103,7 @@ func neutralizeAltScreenWithOffsets(content string) (string, *OffsetMapper) {
 		isEnter := content[end-1] == 'h'
 
 		if isEnter && !inAltScreen {
-			stripFrom := start
-			for _, cm := range clearMatches {
-				if cm[0] >= lastEnd && cm[1] <= start {
-					stripFrom = cm[0]
-					break
-				}
-			}
+			stripFrom := clears.first(lastEnd, start)
 
 			before := content[lastEnd:stripFrom]
 			if len(before) > 0 {
@@ -126,7 +137,49 @@ func neutralizeAltScreenWithOffsets(content string) (string, *OffsetMapper) {
 			})
 		}
 		result.WriteString(remaining)
+	} else if strings.TrimSpace(result.String()) != "" {
+		result.WriteString(UnexitedAltScreenSeparator)
+	}
+
+	return result.String(), &OffsetMapper{regions: regions, dstLen: result.Len()}
+}
+
+// neutralizeScrollRegionWithOffsets is like NeutralizeScrollRegionSequences but
+// also returns an OffsetMapper tracking which source regions were preserved.
+func neutralizeScrollRegionWithOffsets(content string) (string, *OffsetMapper) {
+	spans := tuiScrollRegions(content)
+	if len(spans) == 0 {
+		return content, identityMapper(len(content))
+	}
+
+	var result strings.Builder
+	var regions []mappedRegion
+	lastEnd := 0
+
+	for _, span := range spans {
+		if span[0] > lastEnd {
+			regions = append(regions, mappedRegion{
+				srcStart: lastEnd,
+				srcEnd:   span[0],
+				dstStart: result.Len(),
+			})
+		}
+		result.WriteString(content[lastEnd:span[0]])
+		remaining := content[span[1]:]
+		if strings.TrimSpace(result.String()) != "" && strings.TrimSpace(remaining) != "" {
+			result.WriteString(ScrollRegionSeparator)
+		}
+		lastEnd = span[1]
+	}
+
+	if lastEnd < len(content) {
+		regions = append(regions, mappedRegion{
+			srcStart: lastEnd,
+			srcEnd:   len(content),
+			dstStart: result.Len(),
+		})
 	}
+	result.WriteString(content[lastEnd:])
 
 	return result.String(), &OffsetMapper{regions: regions, dstLen: result.Len()}
 }
@@ -134,7 +187,7 @@ func neutralizeAltScreenWithOffsets(content string) (string, *OffsetMapper) {
 // neutralizeClearWithOffsets is like NeutralizeClearSequences but also
 // returns an OffsetMapper tracking which source regions were preserved.
 func neutralizeClearWithOffsets(content string) (string, *OffsetMapper) {
-	matches := clearPattern.FindAllStringIndex(content, -1)
+	matches := findAllIndex(clearPattern, content)
 	if len(matches) == 0 {
 		return content, identityMapper(len(content))
 	}
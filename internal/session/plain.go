@@ -0,0 +1,41 @@
+package session
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// PlainText returns cleaned content (see StripMetadata) as plain text, for
+// grepping or pasting into an issue: escape sequences (colors, cursor
+// movement, titles) are removed, line endings become \n, and a line
+// rewritten with carriage returns or backspaces (progress bars, spinners)
+// keeps only what was written last. Other control characters except tab are
+// dropped.
+func PlainText(content string) string {
+	lines := strings.Split(escapeSequencePattern.ReplaceAllString(content, ""), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if cr := strings.LastIndexByte(line, '\r'); cr >= 0 {
+			line = line[cr+1:]
+		}
+		lines[i] = plainLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainLine applies backspaces in line and drops its control characters.
+func plainLine(line string) string {
+	var b []byte
+	for _, r := range line {
+		switch {
+		case r == '\b':
+			if len(b) > 0 {
+				_, size := utf8.DecodeLastRune(b)
+				b = b[:len(b)-size]
+			}
+		case r == '\t' || (r >= 0x20 && r != 0x7f):
+			b = utf8.AppendRune(b, r)
+		}
+	}
+	return string(b)
+}
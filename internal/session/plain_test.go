@@ -0,0 +1,28 @@
+package session
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "hello\nworld", "hello\nworld"},
+		{"colors removed", "\x1b[31merror\x1b[0m: bad", "error: bad"},
+		{"CRLF", "one\r\ntwo\r\n", "one\ntwo\n"},
+		{"title removed", "\x1b]0;title\x07$ ls", "$ ls"},
+		{"progress bar keeps last write", "10%\r50%\r100%\r\ndone", "100%\ndone"},
+		{"backspace", "lz\bs -la", "ls -la"},
+		{"backspace over multi-byte", "caf✓\bé", "café"},
+		{"control characters dropped", "a\x07b\x00c\td", "abc\td"},
+		{"separator kept as text", "a" + ClearSeparator + "b", "a\n\n──────── terminal cleared ────────\n\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.input); got != tt.want {
+				t.Errorf("PlainText(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
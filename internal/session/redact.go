@@ -0,0 +1,166 @@
+package session
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// RedactedText replaces each secret found by RedactSecrets.
+const RedactedText = "[REDACTED]"
+
+// highEntropyPattern matches long base64-like blobs. Only matches that
+// also look random (see looksHighEntropy) are redacted, so hex hashes and
+// ordinary long words are left alone.
+var highEntropyPattern = regexp.MustCompile(`[A-Za-z0-9+/_-]{32,}={0,2}`)
+
+// DefaultSecretPatterns are the secrets RedactSecrets looks for when given
+// no patterns. Where a pattern has a capture group, only the group is
+// redacted, so the name of the secret stays readable (password=[REDACTED]).
+// None match across lines, so redaction never changes line numbers.
+var DefaultSecretPatterns = []*regexp.Regexp{
+	// AWS access key IDs
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// GitHub tokens
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	// Authorization: Bearer <token>
+	regexp.MustCompile(`(?i)\bbearer[ \t]+([A-Za-z0-9\-._~+/]{8,}=*)`),
+	// password=..., api_key: ..., AWS_SECRET_ACCESS_KEY=... and the like
+	regexp.MustCompile(`(?i)(?:password|passwd|secret|token|api[_-]?key|access[_-]?key)["']?[ \t]*[=:][ \t]*["']?([^\s"'\x1b]+)`),
+	highEntropyPattern,
+}
+
+// RedactSecrets replaces secrets in content with RedactedText: matches of
+// patterns, or of DefaultSecretPatterns if patterns is nil (AWS access
+// keys, GitHub and bearer tokens, password=... assignments and random-
+// looking base64 blobs). Recordings easily capture a token that was echoed
+// or pasted by mistake.
+func RedactSecrets(content string, patterns []*regexp.Regexp) string {
+	result, _ := redactSecrets(content, patterns)
+	return result
+}
+
+// redactSecrets is RedactSecrets, also returning the number of secrets
+// replaced.
+func redactSecrets(content string, patterns []*regexp.Regexp) (string, int) {
+	spans := secretSpans(content, patterns)
+	if len(spans) == 0 {
+		return content, 0
+	}
+
+	var result strings.Builder
+	result.Grow(len(content))
+	lastEnd := 0
+	for _, span := range spans {
+		result.WriteString(content[lastEnd:span[0]])
+		result.WriteString(RedactedText)
+		lastEnd = span[1]
+	}
+	result.WriteString(content[lastEnd:])
+	return result.String(), len(spans)
+}
+
+// redactSecretsWithOffsets is like RedactSecrets but also returns an
+// OffsetMapper tracking which source regions were preserved. An offset
+// inside a secret maps to just after its RedactedText.
+func redactSecretsWithOffsets(content string, patterns []*regexp.Regexp) (string, *OffsetMapper) {
+	spans := secretSpans(content, patterns)
+	if len(spans) == 0 {
+		return content, identityMapper(len(content))
+	}
+
+	var result strings.Builder
+	result.Grow(len(content))
+	var regions []mappedRegion
+	lastEnd := 0
+	for _, span := range spans {
+		if span[0] > lastEnd {
+			regions = append(regions, mappedRegion{
+				srcStart: lastEnd,
+				srcEnd:   span[0],
+				dstStart: result.Len(),
+			})
+		}
+		result.WriteString(content[lastEnd:span[0]])
+		result.WriteString(RedactedText)
+		lastEnd = span[1]
+	}
+	if lastEnd < len(content) {
+		regions = append(regions, mappedRegion{
+			srcStart: lastEnd,
+			srcEnd:   len(content),
+			dstStart: result.Len(),
+		})
+	}
+	result.WriteString(content[lastEnd:])
+
+	return result.String(), &OffsetMapper{regions: regions, dstLen: result.Len()}
+}
+
+// secretSpans returns the sorted, non-overlapping [start, end) byte spans
+// of the secrets in content: each match of patterns (nil = the defaults),
+// or its first capture group if it has one.
+func secretSpans(content string, patterns []*regexp.Regexp) [][2]int {
+	if patterns == nil {
+		patterns = DefaultSecretPatterns
+	}
+	var spans [][2]int
+	for _, re := range patterns {
+		for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if start == end {
+				continue
+			}
+			if re == highEntropyPattern && !looksHighEntropy(content[start:end]) {
+				continue
+			}
+			spans = append(spans, [2]int{start, end})
+		}
+	}
+
+	// Merge overlapping matches of different patterns
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var merged [][2]int
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], span[1])
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// looksHighEntropy reports whether s looks like a random token rather than
+// a word, path or hex hash: it mixes upper case, lower case and digits, and
+// has at least 4 bits of Shannon entropy per character.
+func looksHighEntropy(s string) bool {
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+		counts[r]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+
+	var entropy float64
+	for _, n := range counts {
+		p := float64(n) / float64(len(s))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 4
+}
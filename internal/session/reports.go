@@ -0,0 +1,77 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// statusReportPattern matches replies a terminal sends back when a program
+// queries it, which end up in session.log either raw or echoed by the tty in
+// caret notation (^[ for ESC):
+// - \x1b[24;80R - cursor position report (reply to \x1b[6n), also \x1b[?24;80R
+// - \x1b[?62;1;6c - primary device attributes (reply to \x1b[c)
+// - \x1b[>1;10;0c - secondary device attributes (reply to \x1b[>c)
+// Queries themselves (\x1b[6n, \x1b[c, \x1b[>c) are not matched.
+var statusReportPattern = regexp.MustCompile(`(?:\x1b|\^\[)\[(?:\??\d+;\d+R|\?\d+(?:;\d+)*c|>\d+;\d+;\d+c)`)
+
+// StripStatusReports removes cursor position reports and device attribute
+// responses from content. They are input that leaked into the output (e.g.
+// shown as ^[[24;1R) and carry nothing worth rendering.
+func StripStatusReports(content string) string {
+	result, _ := stripStatusReports(content)
+	return result
+}
+
+// stripStatusReports is StripStatusReports, also returning the number of
+// replies removed.
+func stripStatusReports(content string) (string, int) {
+	matches := findAllIndex(statusReportPattern, content)
+	if len(matches) == 0 {
+		return content, 0
+	}
+
+	var result strings.Builder
+	result.Grow(len(content))
+	lastEnd := 0
+	for _, match := range matches {
+		result.WriteString(content[lastEnd:match[0]])
+		lastEnd = match[1]
+	}
+	result.WriteString(content[lastEnd:])
+	return result.String(), len(matches)
+}
+
+// stripStatusReportsWithOffsets is like StripStatusReports but also returns
+// an OffsetMapper tracking which source regions were preserved.
+func stripStatusReportsWithOffsets(content string) (string, *OffsetMapper) {
+	matches := findAllIndex(statusReportPattern, content)
+	if len(matches) == 0 {
+		return content, identityMapper(len(content))
+	}
+
+	var result strings.Builder
+	result.Grow(len(content))
+	var regions []mappedRegion
+	lastEnd := 0
+	for _, match := range matches {
+		if match[0] > lastEnd {
+			regions = append(regions, mappedRegion{
+				srcStart: lastEnd,
+				srcEnd:   match[0],
+				dstStart: result.Len(),
+			})
+		}
+		result.WriteString(content[lastEnd:match[0]])
+		lastEnd = match[1]
+	}
+	if lastEnd < len(content) {
+		regions = append(regions, mappedRegion{
+			srcStart: lastEnd,
+			srcEnd:   len(content),
+			dstStart: result.Len(),
+		})
+	}
+	result.WriteString(content[lastEnd:])
+
+	return result.String(), &OffsetMapper{regions: regions, dstLen: result.Len()}
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripStatusReports_CursorPosition(t *testing.T) {
+	input := "$ vim notes.txt\r\n\x1b[24;80Rhello\r\n"
+
+	result := StripStatusReports(input)
+
+	if result != "$ vim notes.txt\r\nhello\r\n" {
+		t.Errorf("cursor position report should be stripped, got %q", result)
+	}
+}
+
+func TestStripStatusReports_CaretEchoed(t *testing.T) {
+	input := "$ ^[[24;1R^[[?1;2cls\r\n"
+
+	result := StripStatusReports(input)
+
+	if result != "$ ls\r\n" {
+		t.Errorf("caret-echoed replies should be stripped, got %q", result)
+	}
+}
+
+func TestStripStatusReports_DeviceAttributes(t *testing.T) {
+	input := "a\x1b[?62;1;6cb\x1b[?6cc\x1b[>1;10;0cd"
+
+	result := StripStatusReports(input)
+
+	if result != "abcd" {
+		t.Errorf("device attribute replies should be stripped, got %q", result)
+	}
+}
+
+func TestStripStatusReports_PreservesQueriesAndOtherANSI(t *testing.T) {
+	// Queries, cursor addressing, colors and scroll regions are not replies
+	input := "\x1b[6n\x1b[c\x1b[>c\x1b[0c\x1b[24;80H\x1b[31mred\x1b[0m\x1b[1;24r\x1b[2K"
+
+	if result := StripStatusReports(input); result != input {
+		t.Errorf("non-reply sequences should be preserved\ngot:  %q\nwant: %q", result, input)
+	}
+}
+
+func TestNeutralizeAllWithStats_StatusReports(t *testing.T) {
+	input := "one\x1b[24;80R\r\ntwo\x1b[12;1R\r\n"
+
+	result, stats := NeutralizeAllWithStats(input)
+
+	if strings.Contains(result, "R") {
+		t.Errorf("replies should be stripped, got %q", result)
+	}
+	if stats.StatusReports != 2 {
+		t.Errorf("StatusReports = %d, want 2", stats.StatusReports)
+	}
+}
+
+func TestNeutralizeAllWithOffsets_StatusReports(t *testing.T) {
+	input := "$ htop\r\n\x1b[24;80R\x1b[?62;1;6c$ echo done\r\ndone\x1b[24;80R\r\n"
+
+	result, mapFn := NeutralizeAllWithOffsets(input)
+
+	if want := "$ htop\r\n$ echo done\r\ndone\r\n"; result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+
+	// Offsets after a stripped reply shift back by its length
+	for _, text := range []string{"$ htop", "$ echo", "done\r\n"} {
+		src := strings.Index(input, text)
+		dst := strings.Index(result, text)
+		if got := mapFn(src); got != dst {
+			t.Errorf("mapFn(offset of %q) = %d, want %d", text, got, dst)
+		}
+	}
+
+	// An offset inside a stripped reply maps to where it was removed
+	src := strings.Index(input, "\x1b[24;80R") + 3
+	if got, want := mapFn(src), strings.Index(result, "$ echo"); got != want {
+		t.Errorf("mapFn(inside reply) = %d, want %d", got, want)
+	}
+}
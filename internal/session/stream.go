@@ -0,0 +1,536 @@
+package session
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// streamHeaderLines is how many lines StreamCleaner waits for before
+	// deciding where the header ends, as headerEnd looks in the first 5.
+	streamHeaderLines = 5
+
+	// streamTrailingSize is how much StreamCleaner holds back (from a line
+	// start) until it knows whether it is the footer.
+	streamTrailingSize = 500
+
+	// streamEscapeSize is how close to the end of a chunk an escape
+	// sequence must start for StreamCleaner to hold it back, in case the
+	// rest of it is in the next chunk.
+	streamEscapeSize = 10
+
+	// streamScrollRegionSize is how much of a scroll region StreamCleaner
+	// holds back to tell whether it is a TUI redraw. A region still open
+	// past it is decided on what came so far.
+	streamScrollRegionSize = 1 << 20
+
+	// scrollRegionResetOverlap is how much of the held back region is
+	// searched again with the next text, for a reset split between them
+	// (one byte short of the longest, \x1b[;r).
+	scrollRegionResetOverlap = 3
+)
+
+// StreamCleaner is StripMetadata for content arriving in chunks, e.g. a
+// session.log too large to hold in memory: content written to it is
+// cleaned and written on to the underlying writer as soon as it can be,
+// holding back only what the footer or a sequence split between chunks
+// might need. It is the Go counterpart of the browser's streaming cleaner
+// (createStreamingCleaner in internal/js/cleaner-core.js) and cleans the
+// same way:
+//   - The header is stripped once the first 5 lines are in (or Close is
+//     called), and each appended session's header as it starts.
+//   - Footer lines are dropped wherever they are; the last ~500 bytes are
+//     held back until Close, which strips the footer and trailing blank
+//     lines from them.
+//   - Clear and alternate screen sequences are neutralized as they arrive,
+//     with separators only between content. Scroll regions are held back
+//     until they end, to tell whether they are a TUI redraw, or until they
+//     pass 1 MB, when what came so far decides.
+//
+// The output matches StripMetadata's except around full-screen TUIs:
+// output between a clear and an alternate screen entered after it is kept
+// (StripMetadata drops it as the TUI's first screen), and alternate screen
+// regions are discarded before clears are handled, not after. A leading
+// UTF-8 byte order mark is dropped, but UTF-16 isn't decoded (see
+// DecodeText). CleanOptions don't apply.
+//
+// Call Close once the content is all written, to write what is held back.
+type StreamCleaner struct {
+	w   io.Writer
+	err error // First error writing to w
+
+	// Header: buffered until there are enough lines to find its end
+	headerBuffer   string
+	headerStripped bool
+
+	// Clears: whether a separator is due before the next content, and the
+	// whitespace that followed the clear, written after it
+	hasEmittedContent bool
+	pendingSeparator  bool
+	pendingWhitespace string
+
+	// Alternate screen: inside one, everything is discarded
+	inAltScreen               bool
+	altScreenHadContentBefore bool
+
+	// Scroll regions: buffered from set to reset, to decide whether they
+	// are a TUI redraw. One decided as a redraw before its end (see
+	// streamScrollRegionSize) is discarded to its reset, buffering only
+	// the last few bytes, where the reset may start.
+	inScrollRegion               bool
+	scrollRegionBuffer           []byte
+	discardingScrollRegion       bool
+	scrollRegionHadContentBefore bool
+	pendingScrollRegionSeparator bool
+
+	// An escape sequence or character that may continue in the next chunk
+	escapeBuffer string
+
+	// The end of what was written, held back for footer detection
+	trailingBuffer string
+
+	// Appended sessions (script -a) are joined with \r\n, skipping sessions
+	// with no content
+	hadSessionContent bool
+	joinPending       bool
+	pendingJoinText   string
+}
+
+// NewStreamCleaner returns a StreamCleaner writing cleaned content to w.
+func NewStreamCleaner(w io.Writer) *StreamCleaner {
+	return &StreamCleaner{w: w}
+}
+
+// Write cleans p, following what was already written, and writes what it
+// can of the result to the underlying writer. It returns the first error
+// that writer returned, if any.
+func (c *StreamCleaner) Write(p []byte) (int, error) {
+	text := c.escapeBuffer + string(p)
+	c.escapeBuffer = ""
+
+	// Hold back an incomplete UTF-8 character, and an escape sequence (or
+	// 8-bit C1 introducer) that may be cut short
+	cut := len(text)
+	if i := incompleteRuneStart(text); i >= 0 {
+		cut = i
+	}
+	if i := escapeStart(text[:cut], len(text)-streamEscapeSize+1); i >= 0 {
+		cut = i
+	}
+	c.escapeBuffer = text[cut:]
+	c.feed(text[:cut], false)
+	return len(p), c.err
+}
+
+// Close writes what is held back, without the footer. It returns the
+// first error the underlying writer returned, if any.
+func (c *StreamCleaner) Close() error {
+	text := c.escapeBuffer
+	c.escapeBuffer = ""
+	c.feed(text, true)
+
+	// A scroll region still open at the end runs to the end
+	if c.inScrollRegion {
+		region := string(c.scrollRegionBuffer)
+		c.inScrollRegion = false
+		c.scrollRegionBuffer = c.scrollRegionBuffer[:0]
+		if !c.discardingScrollRegion {
+			c.emit(c.finishScrollRegion(region))
+		}
+		c.discardingScrollRegion = false
+	}
+
+	// Ended inside the alternate screen: say so after what came before
+	if c.inAltScreen && c.altScreenHadContentBefore {
+		c.emit(UnexitedAltScreenSeparator)
+	}
+	return c.err
+}
+
+// incompleteRuneStart returns where a UTF-8 character cut short at the end
+// of text starts, or -1 if there is none.
+func incompleteRuneStart(text string) int {
+	for i := len(text) - 1; i >= 0 && i >= len(text)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(text[i]) {
+			if !utf8.FullRuneInString(text[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return -1
+}
+
+// escapeStart returns where the last escape sequence in text starts, if it
+// is at from or later: an ESC, or an 8-bit CSI or OSC introducer (raw or
+// UTF-8 encoded, see NormalizeC1Controls). Returns -1 if there is none.
+func escapeStart(text string, from int) int {
+	for i := len(text) - 1; i >= max(from, 0); i-- {
+		switch text[i] {
+		case '\x1b':
+			return i
+		case 0x9b, 0x9d:
+			if i > 0 && text[i-1] == 0xc2 {
+				return i - 1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// emit writes cleaned text to the underlying writer, keeping its first error.
+func (c *StreamCleaner) emit(text string) {
+	if text == "" || c.err != nil {
+		return
+	}
+	_, c.err = io.WriteString(c.w, text)
+}
+
+// feed processes text following what was already fed. It strips each
+// session's header (buffering until there are enough lines to find it) and
+// keeps a trailing buffer, cut at a line start, for footer detection. With
+// final set, everything buffered is flushed.
+func (c *StreamCleaner) feed(text string, final bool) {
+	nextSession := ""
+	if !c.headerStripped {
+		c.headerBuffer += text
+
+		// Wait for enough lines to find the header, unless the next
+		// session already starts (its header isn't this one's)
+		next := sessionStartIndex(c.headerBuffer, true)
+		if !final && next < 0 && strings.Count(c.headerBuffer, "\n") < streamHeaderLines {
+			return
+		}
+		if next > 0 {
+			nextSession = c.headerBuffer[next:]
+			c.headerBuffer = c.headerBuffer[:next]
+		}
+		lines := strings.Split(strings.TrimPrefix(c.headerBuffer, utf8BOM), "\n")
+		text = strings.Join(lines[headerEnd(lines):], "\n")
+		c.headerStripped = true
+		c.headerBuffer = ""
+	}
+
+	text = c.trailingBuffer + text + nextSession
+	c.trailingBuffer = ""
+
+	// Another script session (appended log): finish this one, start the next
+	if start := sessionStartIndex(text, false); start >= 0 {
+		c.finishSession(text[:start])
+		c.headerStripped = false
+		c.feed(text[start:], final)
+		return
+	}
+
+	if final {
+		c.finishSession(text)
+		return
+	}
+
+	// Keep the end for footer detection, from a line start so that a
+	// session start line is always seen whole
+	if len(text) <= streamTrailingSize {
+		c.trailingBuffer = text
+		return
+	}
+	cut := strings.LastIndexByte(text[:len(text)-streamTrailingSize], '\n') + 1
+	if cut == 0 {
+		// A long line: cut mid-line, but before any cursor save (or one the
+		// cut would split) so transient status text is seen whole, not
+		// inside a run of escape sequences (a clear can be two in a row),
+		// and not inside a UTF-8 character
+		cut = len(text) - streamTrailingSize
+		if save := cursorSaveIndex(text[:min(cut+2, len(text))]); save >= 0 {
+			cut = save
+		}
+		for cut > 0 {
+			esc := strings.LastIndexByte(text[:cut], '\x1b')
+			if esc < 0 || esc <= cut-streamEscapeSize {
+				break
+			}
+			cut = esc
+		}
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	c.trailingBuffer = text[cut:]
+	c.emitBody(withoutFooterText(text[:cut]))
+}
+
+// sessionStartIndex returns where the line beginning a new script session
+// starts in text, looking from the second line if skipFirst is set, or -1
+// if there is none.
+func sessionStartIndex(text string, skipFirst bool) int {
+	lineStart := 0
+	if skipFirst {
+		lineStart = strings.IndexByte(text, '\n') + 1
+		if lineStart == 0 {
+			return -1
+		}
+	}
+	for lineStart < len(text) {
+		line := text[lineStart:]
+		lineEnd := strings.IndexByte(line, '\n')
+		if lineEnd >= 0 {
+			line = line[:lineEnd]
+		}
+		if isSessionStart(line) {
+			return lineStart
+		}
+		if lineEnd < 0 {
+			break
+		}
+		lineStart += lineEnd + 1
+	}
+	return -1
+}
+
+// cursorSaveIndex returns where the first cursor save sequence in text
+// starts, or -1 if there is none.
+func cursorSaveIndex(text string) int {
+	for i := range len(text) {
+		if seqAt(text, i, cursorSaveSeqs) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// withoutFooterText drops footer lines from text (see withoutFooterLines).
+func withoutFooterText(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := withoutFooterLines(lines)
+	if len(kept) == len(lines) {
+		return text
+	}
+	return strings.Join(kept, "\n")
+}
+
+// finishSession emits the rest of a session, minus its footer, trailing
+// blank lines and a dangling \r (see sessionBody).
+func (c *StreamCleaner) finishSession(text string) {
+	lines := strings.Split(text, "\n")
+	text = strings.Join(lines[:contentEnd(lines, 0)], "\n")
+	c.emitBody(withoutFooterText(strings.TrimSuffix(text, "\r")))
+
+	// A session with no content is skipped
+	c.pendingJoinText = ""
+	c.joinPending = c.hadSessionContent
+}
+
+// emitBody cleans and emits session content (header and footer already
+// stripped), joined to the previous session's content if needed.
+func (c *StreamCleaner) emitBody(text string) {
+	if c.joinPending {
+		// Only join once this session turns out to have content
+		c.pendingJoinText += text
+		if strings.TrimSpace(c.pendingJoinText) == "" {
+			return
+		}
+		text = "\r\n" + c.pendingJoinText
+		c.pendingJoinText = ""
+		c.joinPending = false
+	}
+	if strings.TrimSpace(text) != "" {
+		c.hadSessionContent = true
+	}
+
+	text, _ = stripStatusReports(NormalizeC1Controls(text))
+	text, _ = neutralizeTransientSequences(text)
+	c.emit(c.processScrollRegion(c.processAltScreen(c.processClears(text))))
+}
+
+// processClears replaces clear sequences in text with ClearSeparator
+// between content, as neutralizeClearSequences does, carrying whether a
+// separator is due over to the next text.
+func (c *StreamCleaner) processClears(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	matches := findAllIndex(clearPattern, text)
+	var result strings.Builder
+	lastEnd := 0
+	for _, match := range matches {
+		before := text[lastEnd:match[0]]
+		if strings.TrimSpace(before) != "" {
+			c.writeContent(&result, before)
+		} else if c.pendingSeparator {
+			c.pendingWhitespace += before
+		}
+
+		// A separator is due if content came before; whitespace before
+		// this clear is dropped
+		if c.hasEmittedContent {
+			c.pendingSeparator = true
+			c.pendingWhitespace = ""
+		}
+		lastEnd = match[1]
+	}
+
+	remaining := text[lastEnd:]
+	if strings.TrimSpace(remaining) != "" {
+		c.writeContent(&result, remaining)
+	} else if c.pendingSeparator {
+		// Whitespace only: written after the separator, if content follows
+		c.pendingWhitespace += remaining
+	} else if len(matches) == 0 {
+		// Whitespace after a clear is dropped, but not without one
+		result.WriteString(remaining)
+	}
+	return result.String()
+}
+
+// writeContent writes non-blank content to result, after the separator
+// and whitespace due before it.
+func (c *StreamCleaner) writeContent(result *strings.Builder, content string) {
+	if c.pendingSeparator {
+		result.WriteString(ClearSeparator)
+		result.WriteString(c.pendingWhitespace)
+		c.pendingSeparator = false
+		c.pendingWhitespace = ""
+	}
+	result.WriteString(content)
+	c.hasEmittedContent = true
+}
+
+// processAltScreen discards alternate screen regions in text, carrying
+// whether one is open over to the next text. AltScreenSeparator is written
+// where one ends, if there is content on both sides.
+func (c *StreamCleaner) processAltScreen(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	if c.inAltScreen {
+		for _, m := range findAllIndex(altScreenPattern, text) {
+			if text[m[1]-1] != 'l' {
+				continue
+			}
+			c.inAltScreen = false
+			processed := c.processAltScreen(text[m[1]:])
+			if c.altScreenHadContentBefore && strings.TrimSpace(processed) != "" {
+				return AltScreenSeparator + processed
+			}
+			return processed
+		}
+		return "" // Still inside: discard everything
+	}
+
+	for _, m := range findAllIndex(altScreenPattern, text) {
+		if text[m[1]-1] != 'h' {
+			continue
+		}
+		before := text[:m[0]]
+		if strings.TrimSpace(before) != "" {
+			c.altScreenHadContentBefore = true
+		}
+		c.inAltScreen = true
+		return before + c.processAltScreen(text[m[1]:])
+	}
+
+	if strings.TrimSpace(text) != "" {
+		c.altScreenHadContentBefore = true
+	}
+	return text
+}
+
+// processScrollRegion discards scroll regions in text that are TUI
+// redraws (see isTUIRedraw), buffering a region until it is reset.
+// ScrollRegionSeparator is written where one was, if there is content on
+// both sides.
+func (c *StreamCleaner) processScrollRegion(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	if c.inScrollRegion {
+		// Only the new text, and the end of what's held back in case the
+		// reset straddles them, can hold the reset
+		from := max(0, len(c.scrollRegionBuffer)-scrollRegionResetOverlap)
+		c.scrollRegionBuffer = append(c.scrollRegionBuffer, text...)
+		reset := scrollRegionResetPattern.FindIndex(c.scrollRegionBuffer[from:])
+		if reset == nil {
+			switch {
+			case c.discardingScrollRegion:
+				c.keepScrollRegionTail()
+			case len(c.scrollRegionBuffer) > streamScrollRegionSize:
+				return c.decideScrollRegion()
+			}
+			return "" // Still inside: keep buffering
+		}
+		end := from + reset[1]
+		region := string(c.scrollRegionBuffer[:end])
+		rest := string(c.scrollRegionBuffer[end:])
+		c.inScrollRegion = false
+		c.scrollRegionBuffer = c.scrollRegionBuffer[:0]
+		if c.discardingScrollRegion {
+			c.discardingScrollRegion = false
+			return c.processScrollRegion(rest)
+		}
+		return c.finishScrollRegion(region) + c.processScrollRegion(rest)
+	}
+
+	set := scrollRegionSetPattern.FindStringIndex(text)
+	if set == nil {
+		return c.emitOutsideScrollRegion(text)
+	}
+	before := c.emitOutsideScrollRegion(text[:set[0]])
+	c.inScrollRegion = true
+	return before + c.processScrollRegion(text[set[0]:])
+}
+
+// decideScrollRegion decides the fate of a scroll region grown past
+// streamScrollRegionSize on what came so far, so it isn't held back
+// without limit: a TUI redraw is discarded to its reset, leaving a
+// separator due; anything else is written and no longer held back.
+func (c *StreamCleaner) decideScrollRegion() string {
+	region := string(c.scrollRegionBuffer)
+	if isTUIRedraw(region) {
+		if c.scrollRegionHadContentBefore {
+			c.pendingScrollRegionSeparator = true
+		}
+		c.discardingScrollRegion = true
+		c.keepScrollRegionTail()
+		return ""
+	}
+	c.inScrollRegion = false
+	c.scrollRegionBuffer = c.scrollRegionBuffer[:0]
+	return c.emitOutsideScrollRegion(region)
+}
+
+// keepScrollRegionTail drops all but the last few bytes of the scroll
+// region buffer, where a reset may start.
+func (c *StreamCleaner) keepScrollRegionTail() {
+	tail := c.scrollRegionBuffer[len(c.scrollRegionBuffer)-min(len(c.scrollRegionBuffer), scrollRegionResetOverlap):]
+	c.scrollRegionBuffer = c.scrollRegionBuffer[:copy(c.scrollRegionBuffer, tail)]
+}
+
+// finishScrollRegion decides the fate of a whole scroll region: a TUI
+// redraw is discarded, leaving a separator due; anything else is kept.
+func (c *StreamCleaner) finishScrollRegion(region string) string {
+	if isTUIRedraw(region) {
+		if c.scrollRegionHadContentBefore {
+			c.pendingScrollRegionSeparator = true
+		}
+		return ""
+	}
+	return c.emitOutsideScrollRegion(region)
+}
+
+// emitOutsideScrollRegion returns text outside a discarded scroll region,
+// after the separator due before it, if any.
+func (c *StreamCleaner) emitOutsideScrollRegion(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return text
+	}
+	c.scrollRegionHadContentBefore = true
+	if c.pendingScrollRegionSeparator {
+		c.pendingScrollRegionSeparator = false
+		return ScrollRegionSeparator + text
+	}
+	return text
+}
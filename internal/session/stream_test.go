@@ -0,0 +1,158 @@
+package session
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// streamClean writes content to a StreamCleaner in chunks of size bytes
+// and returns what it wrote.
+func streamClean(t *testing.T, content string, size int) string {
+	t.Helper()
+	var out strings.Builder
+	c := NewStreamCleaner(&out)
+	for i := 0; i < len(content); i += size {
+		end := min(i+size, len(content))
+		if _, err := c.Write([]byte(content[i:end])); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return out.String()
+}
+
+func TestStreamCleaner_MatchesStripMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			"linux header and footer",
+			"Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+				"$ ls\r\nfile1  file2\r\n\x1b[1;32m✓ done\x1b[0m\r\n" +
+				"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n",
+		},
+		{
+			"macOS header and footer",
+			"Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n" +
+				"$ echo héllo\r\nhéllo\r\n\nScript done on Wed Dec 31 12:11:22 2025\n",
+		},
+		{
+			"clear between output",
+			"Script started on Wed Dec 31 12:10:34 2025\n" +
+				"before\r\n\x1b[H\x1b[2Jafter\r\n" +
+				"Script done on Wed Dec 31 12:11:22 2025\n",
+		},
+		{
+			"appended sessions",
+			"Script started on Wed Dec 31 12:10:34 2025\nfirst\r\n" +
+				"Script done on Wed Dec 31 12:11:22 2025\n" +
+				"Script started on Wed Dec 31 12:12:00 2025\nsecond\r\n" +
+				"Script done on Wed Dec 31 12:13:00 2025\n",
+		},
+		{
+			"alternate screen never left",
+			"Script started on Wed Dec 31 12:10:34 2025\n" +
+				"$ vim\r\nbefore\r\n\x1b[?1049h\x1b[1;1HTUI\r\n" +
+				"Script done on Wed Dec 31 12:11:22 2025\n",
+		},
+		{
+			"long output",
+			"Script started on Wed Dec 31 12:10:34 2025\n" +
+				strings.Repeat("line of output ✓\r\n", 200) +
+				"Script done on Wed Dec 31 12:11:22 2025\n",
+		},
+	}
+	for _, tt := range tests {
+		want := StripMetadata(tt.content, CleanOptions{})
+		for _, size := range []int{1, 3, 7, 64, len(tt.content)} {
+			if got := streamClean(t, tt.content, size); got != want {
+				t.Errorf("%s, %d-byte chunks:\n got %q\nwant %q", tt.name, size, got, want)
+			}
+		}
+	}
+}
+
+func TestStreamCleaner_SplitSequences(t *testing.T) {
+	// The alternate screen enter, the clear and the check mark are each
+	// split between writes
+	chunks := []string{
+		"Script started on Wed Dec 31 12:10:34 2025\n$ vim\r\n\x1b[?10",
+		"49hVIM SCREEN\x1b[?1049l$ ok \xe2\x9c",
+		"\x93\r\n\x1b[",
+		"2Jdone\r\nScript done on Wed Dec 31 12:11:22 2025\n",
+	}
+
+	var out strings.Builder
+	c := NewStreamCleaner(&out)
+	for _, chunk := range chunks {
+		c.Write([]byte(chunk))
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got := out.String()
+	for _, s := range []string{"VIM SCREEN", "?1049", "\x1b[2J", "Script "} {
+		if strings.Contains(got, s) {
+			t.Errorf("output contains %q: %q", s, got)
+		}
+	}
+	for _, s := range []string{"$ vim", "$ ok ✓", ClearSeparator, "done"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("output lacks %q: %q", s, got)
+		}
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{ err error }
+
+func (w failingWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestStreamCleaner_WriterError(t *testing.T) {
+	errWrite := errors.New("disk full")
+	c := NewStreamCleaner(failingWriter{errWrite})
+
+	c.Write([]byte("Script started on Wed Dec 31 12:10:34 2025\n"))
+	c.Write([]byte(strings.Repeat("output\r\n", 200)))
+	if err := c.Close(); !errors.Is(err, errWrite) {
+		t.Errorf("Close() = %v, want %v", err, errWrite)
+	}
+}
+
+func TestStreamCleaner_UnendedScrollRegion(t *testing.T) {
+	// Plain output in a scroll region never reset is written once it
+	// passes the limit, not held back to Close
+	var out strings.Builder
+	c := NewStreamCleaner(&out)
+	c.Write([]byte("Script started on Wed Dec 31 12:10:34 2025\n$ tail -f log\r\n\x1b[1;20r"))
+	line := strings.Repeat("x", 63) + "\n"
+	for i := 0; i < 2*streamScrollRegionSize/len(line); i++ {
+		c.Write([]byte(line))
+	}
+	if out.Len() < streamScrollRegionSize {
+		t.Errorf("wrote %d bytes before Close, want at least %d", out.Len(), streamScrollRegionSize)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := strings.Count(out.String(), line[:63]); got != 2*streamScrollRegionSize/len(line) {
+		t.Errorf("output has %d lines, want %d", got, 2*streamScrollRegionSize/len(line))
+	}
+
+	// A redraw past the limit is discarded to its reset
+	redraw := "\x1b[1;20r" + strings.Repeat("\x1b[5;1HREDRAW", 2*streamScrollRegionSize/13) + "\x1b[r$ after\r\n"
+	got := streamClean(t, "$ top\r\n"+redraw, 32*1024)
+	if strings.Contains(got, "REDRAW") {
+		t.Errorf("output keeps the redraw: %q", got[:min(len(got), 200)])
+	}
+	for _, s := range []string{"$ top", ScrollRegionSeparator, "$ after"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("output lacks %q: %q", s, got)
+		}
+	}
+}
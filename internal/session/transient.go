@@ -0,0 +1,127 @@
+package session
+
+import "strings"
+
+// Cursor save/restore and erase sequences recognized by transientSpans.
+var (
+	cursorSaveSeqs    = []string{"\x1b[s", "\x1b7"}                                   // SCOSC, DECSC
+	cursorRestoreSeqs = []string{"\x1b[u", "\x1b8"}                                   // SCORC, DECRC
+	eraseSeqs         = []string{"\x1b[K", "\x1b[0K", "\x1b[2K", "\x1b[J", "\x1b[0J"} // erase rightwards of the cursor
+)
+
+// seqAt returns the length of whichever of seqs starts content[i:], or 0.
+func seqAt(content string, i int, seqs []string) int {
+	for _, seq := range seqs {
+		if strings.HasPrefix(content[i:], seq) {
+			return len(seq)
+		}
+	}
+	return 0
+}
+
+// transientSpans returns the [start, end) byte spans of transient status
+// text drawn with cursor save/restore, e.g. a spinner:
+//
+//	\x1b[s⠋ building\x1b[u\x1b[s⠙ building\x1b[u\x1b[Kdone
+//
+// A span starts at a save and runs through frames that each return to the
+// saved position (a restore, possibly followed straight away by another
+// save), up to a restore immediately followed by an erase, which wipes the
+// last frame. The erase itself is not part of the span. Spans stay on one
+// line: a newline, or a save somewhere else, ends the chain without a span.
+func transientSpans(content string) [][2]int {
+	var spans [][2]int
+	start := -1 // save starting the current chain, or -1
+	for i := 0; i < len(content); {
+		if n := seqAt(content, i, cursorSaveSeqs); n > 0 {
+			start = i // a save elsewhere on the line restarts the chain
+			i += n
+			continue
+		}
+		if start < 0 {
+			i++
+			continue
+		}
+		switch {
+		case content[i] == '\n':
+			start = -1
+			i++
+		case seqAt(content, i, cursorRestoreSeqs) > 0:
+			i += seqAt(content, i, cursorRestoreSeqs)
+			if seqAt(content, i, eraseSeqs) > 0 {
+				spans = append(spans, [2]int{start, i})
+				start = -1
+			} else if n := seqAt(content, i, cursorSaveSeqs); n > 0 {
+				// Saving at the restored position continues the chain
+				i += n
+			}
+		default:
+			i++
+		}
+	}
+	return spans
+}
+
+// NeutralizeTransientSequences removes transient status text drawn with
+// cursor save/restore (see transientSpans), such as spinners, keeping the
+// erase that wiped it. Unlike \r-based spinners, these frames can survive
+// as stale text once clear and alternate screen sequences around them are
+// neutralized.
+func NeutralizeTransientSequences(content string) string {
+	result, _ := neutralizeTransientSequences(content)
+	return result
+}
+
+// neutralizeTransientSequences is NeutralizeTransientSequences, also
+// returning the number of spans removed.
+func neutralizeTransientSequences(content string) (string, int) {
+	spans := transientSpans(content)
+	if len(spans) == 0 {
+		return content, 0
+	}
+
+	var result strings.Builder
+	result.Grow(len(content))
+	lastEnd := 0
+	for _, span := range spans {
+		result.WriteString(content[lastEnd:span[0]])
+		lastEnd = span[1]
+	}
+	result.WriteString(content[lastEnd:])
+	return result.String(), len(spans)
+}
+
+// neutralizeTransientWithOffsets is like NeutralizeTransientSequences but
+// also returns an OffsetMapper tracking which source regions were preserved.
+func neutralizeTransientWithOffsets(content string) (string, *OffsetMapper) {
+	spans := transientSpans(content)
+	if len(spans) == 0 {
+		return content, identityMapper(len(content))
+	}
+
+	var result strings.Builder
+	result.Grow(len(content))
+	var regions []mappedRegion
+	lastEnd := 0
+	for _, span := range spans {
+		if span[0] > lastEnd {
+			regions = append(regions, mappedRegion{
+				srcStart: lastEnd,
+				srcEnd:   span[0],
+				dstStart: result.Len(),
+			})
+		}
+		result.WriteString(content[lastEnd:span[0]])
+		lastEnd = span[1]
+	}
+	if lastEnd < len(content) {
+		regions = append(regions, mappedRegion{
+			srcStart: lastEnd,
+			srcEnd:   len(content),
+			dstStart: result.Len(),
+		})
+	}
+	result.WriteString(content[lastEnd:])
+
+	return result.String(), &OffsetMapper{regions: regions, dstLen: result.Len()}
+}
@@ -0,0 +1,98 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNeutralizeTransientSequences_SaveRestoreSpinner(t *testing.T) {
+	// Each frame saves, draws and restores; the last restore is erased
+	input := "$ make\r\n" +
+		"\x1b[s⠋ building module-with-a-long-name\x1b[u" +
+		"\x1b[s⠙ linking\x1b[u" +
+		"\x1b[s⠹ linking\x1b[u\x1b[Kdone\r\n"
+
+	result := NeutralizeTransientSequences(input)
+
+	if want := "$ make\r\n\x1b[Kdone\r\n"; result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+func TestNeutralizeTransientSequences_SaveOnce(t *testing.T) {
+	// DECSC once, then each frame returns to the saved position
+	input := "Loading \x1b7|\x1b8/\x1b8-\x1b8\x1b[0Kok\r\n"
+
+	result := NeutralizeTransientSequences(input)
+
+	if want := "Loading \x1b[0Kok\r\n"; result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+func TestNeutralizeTransientSequences_PreservesNonTransient(t *testing.T) {
+	inputs := []string{
+		// Restore not followed by an erase: the text stays on screen
+		"\x1b[sstatus\x1b[u more\r\n",
+		// Save and restore on different lines
+		"\x1b[sline one\r\nline two\x1b[u\x1b[K\r\n",
+		// No restore at all
+		"\x1b[s⠋ building\r\n",
+	}
+	for _, input := range inputs {
+		if result := NeutralizeTransientSequences(input); result != input {
+			t.Errorf("should be preserved\ngot:  %q\nwant: %q", result, input)
+		}
+	}
+}
+
+func TestNeutralizeTransientSequences_ResaveElsewhere(t *testing.T) {
+	// A save after other output moves the saved position: only the frames
+	// drawn from the latest save are transient
+	input := "\x1b[sA\x1b[uB\x1b[sC\x1b[u\x1b[K\r\n"
+
+	result := NeutralizeTransientSequences(input)
+
+	if want := "\x1b[sA\x1b[uB\x1b[K\r\n"; result != want {
+		t.Errorf("got %q, want %q", result, want)
+	}
+}
+
+func TestNeutralizeAllWithStats_TransientSpans(t *testing.T) {
+	input := "\x1b[s⠋ 1/2\x1b[u\x1b[Kone\r\n\x1b[s⠋ 2/2\x1b[u\x1b[Ktwo\r\n"
+
+	result, stats := NeutralizeAllWithStats(input)
+
+	if strings.Contains(result, "⠋") {
+		t.Errorf("spinner frames should be removed, got %q", result)
+	}
+	if stats.TransientSpans != 2 {
+		t.Errorf("TransientSpans = %d, want 2", stats.TransientSpans)
+	}
+}
+
+func TestNeutralizeAllWithOffsets_SaveRestoreSpinner(t *testing.T) {
+	// A clear inside a spinner line must not throw off offsets after it
+	input := "$ npm install\r\n" +
+		"\x1b[s⠋ fetching\x1b[u\x1b[s⠙ fetching\x1b[u\x1b[K" +
+		"added 12 packages\r\n\x1b[H\x1b[2J$ npm test\r\nok\r\n"
+
+	result, mapFn := NeutralizeAllWithOffsets(input)
+
+	if strings.Contains(result, "fetching") {
+		t.Errorf("spinner frames should be removed, got %q", result)
+	}
+	for _, text := range []string{"$ npm install", "added 12", "$ npm test", "ok\r\n"} {
+		src := strings.Index(input, text)
+		dst := strings.Index(result, text)
+		if got := mapFn(src); got != dst {
+			t.Errorf("mapFn(offset of %q) = %d, want %d", text, got, dst)
+		}
+	}
+
+	// An offset inside the spinner maps to where it was removed
+	src := strings.Index(input, "⠙")
+	if got, want := mapFn(src), strings.Index(result, "\x1b[Kadded"); got != want {
+		t.Errorf("mapFn(inside spinner) = %d, want %d", got, want)
+	}
+}
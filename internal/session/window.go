@@ -0,0 +1,75 @@
+package session
+
+import (
+	"regexp"
+	"strings"
+)
+
+// regexWindow caps the bytes a pattern scans per call when the cleaners look
+// for sequences in whole untrusted logs. Go's regexp package matches in time
+// linear in its input, but the cap keeps that true whatever the pattern: a
+// pattern that did backtrack could take at most quadratic time in a window,
+// not in the whole log.
+const regexWindow = 64 * 1024
+
+// regexWindowOverlap is how far back from the cut a window with no newline
+// in it is rescanned, so a sequence straddling the cut is found whole in the
+// next window. It must be longer than any sequence the windowed patterns
+// match; a longer one (e.g. a status report with hundreds of digits) across
+// such a cut can be missed.
+const regexWindowOverlap = 256
+
+// findAllIndex is re.FindAllStringIndex(content, -1), scanning content in
+// windows of at most regexWindow bytes (see findAllWindowed).
+func findAllIndex(re *regexp.Regexp, content string) [][]int {
+	return findAllWindowed(content, func(window string) [][]int {
+		return re.FindAllStringIndex(window, -1)
+	})
+}
+
+// findAllSubmatchIndex is re.FindAllStringSubmatchIndex(content, -1),
+// scanning content in windows of at most regexWindow bytes (see
+// findAllWindowed).
+func findAllSubmatchIndex(re *regexp.Regexp, content string) [][]int {
+	return findAllWindowed(content, func(window string) [][]int {
+		return re.FindAllStringSubmatchIndex(window, -1)
+	})
+}
+
+// findAllWindowed calls find on successive windows of content, each at most
+// regexWindow bytes, and returns the matches it finds with their indexes
+// into content. Windows end after their last newline, which the patterns
+// never match across, so a match is never cut short. A window without a
+// newline ends at regexWindow bytes instead, and only the matches starting
+// regexWindowOverlap bytes before its end are kept; the next window starts
+// at the earliest byte not yet covered.
+func findAllWindowed(content string, find func(string) [][]int) [][]int {
+	var matches [][]int
+	for from := 0; from < len(content); {
+		end, keep := len(content), len(content)
+		if end-from > regexWindow {
+			end = from + regexWindow
+			if nl := strings.LastIndexByte(content[from:end], '\n'); nl >= 0 {
+				end = from + nl + 1
+				keep = end
+			} else {
+				keep = end - regexWindowOverlap
+			}
+		}
+		next := keep
+		for _, m := range find(content[from:end]) {
+			if from+m[0] >= keep {
+				break
+			}
+			for i := range m {
+				if m[i] >= 0 {
+					m[i] += from
+				}
+			}
+			matches = append(matches, m)
+			next = max(next, m[1])
+		}
+		from = next
+	}
+	return matches
+}
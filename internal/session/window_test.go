@@ -0,0 +1,56 @@
+package session
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFindAllIndex_MatchesWholeScan(t *testing.T) {
+	patterns := []*regexp.Regexp{clearPattern, altScreenPattern, statusReportPattern, scrollRegionSetPattern, cursorAddressPattern}
+	// Sequences at every offset around the window cuts, with and without
+	// newlines to cut after
+	var lines, line strings.Builder
+	for i := 0; lines.Len() < 3*regexWindow; i++ {
+		seq := []string{"\x1b[H\x1b[2J", "\x1b[?1049h", "\x1b[12;34R", "\x1b[1;24r", "\x1b[5;1H", "\x1b[2J\x1b[H"}[i%6]
+		pad := strings.Repeat("x", i%17)
+		lines.WriteString(pad + seq + "\n")
+		line.WriteString(pad + seq)
+	}
+	for _, content := range []string{lines.String(), line.String(), strings.Repeat("\x1b[2J", regexWindow)} {
+		for _, re := range patterns {
+			if got, want := findAllIndex(re, content), re.FindAllStringIndex(content, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: findAllIndex found %d matches, FindAllStringIndex %d", re, len(got), len(want))
+			}
+			if got, want := findAllSubmatchIndex(re, content), re.FindAllStringSubmatchIndex(content, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: findAllSubmatchIndex found %d matches, FindAllStringSubmatchIndex %d", re, len(got), len(want))
+			}
+		}
+	}
+	if got := findAllIndex(clearPattern, ""); got != nil {
+		t.Errorf("empty content: got %v", got)
+	}
+}
+
+func TestFindAllWindowed_BoundsEachScan(t *testing.T) {
+	for name, content := range map[string]string{
+		"lines":        strings.Repeat("\x1b[2Jhello\r\n", 4*regexWindow/11),
+		"one line":     strings.Repeat("\x1b[", 4*regexWindow/2),
+		"long lines":   strings.Repeat(strings.Repeat("x", 3*regexWindow/2)+"\n", 4),
+		"early breaks": strings.Repeat("\n"+strings.Repeat("x", regexWindow), 4),
+	} {
+		scanned := 0
+		findAllWindowed(content, func(window string) [][]int {
+			if len(window) > regexWindow {
+				t.Fatalf("%s: scanned a %d byte window, over the %d byte cap", name, len(window), regexWindow)
+			}
+			scanned += len(window)
+			return clearPattern.FindAllStringIndex(window, -1)
+		})
+		// Only the overlaps of windows without a newline are scanned twice
+		if limit := len(content) + (len(content)/(regexWindow-regexWindowOverlap)+1)*regexWindowOverlap; scanned > limit {
+			t.Errorf("%s: scanned %d bytes of %d, over %d", name, scanned, len(content), limit)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package svg renders terminal output as a static SVG snapshot.
+// Output is replayed on an internal/grid screen, so carriage-return
+// overwrites and SGR colors are honored, and each row is drawn as <text>
+// runs at fixed cell metrics on a dark background.
+package svg
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/choonkeat/record-tui/internal/grid"
+	"github.com/choonkeat/record-tui/internal/session"
+)
+
+// Defaults match the HTML viewer's xterm.js theme and font size.
+const (
+	DefaultCols     = 80
+	DefaultFontSize = 15
+
+	background = "#1e1e1e"
+	foreground = "#d4d4d4"
+	fontFamily = "'SF Mono', Menlo, Consolas, Monaco, 'Courier New', monospace"
+	padding    = 10
+
+	// maxScreenRows bounds the screen output is replayed on, so a long log
+	// doesn't need a grid as tall as it (see grid.Screen.SetScrollback)
+	maxScreenRows = 1000
+
+	// maxNoWrapCols bounds how wide grid.NoWrap makes the snapshot; lines
+	// wider still are truncated
+	maxNoWrapCols = 500
+)
+
+// Options configures SVG rendering.
+type Options struct {
+	Cols     int           // Terminal width in cells (0 = DefaultCols)
+	FontSize float64       // Font size in pixels (0 = DefaultFontSize)
+	Overflow grid.Overflow // Lines longer than Cols: wrap (the default), truncate, or none to widen the snapshot (up to 500 cells, then truncated)
+}
+
+// Render returns content as a standalone SVG document.
+func Render(content string, opts Options) (string, error) {
+	cols := opts.Cols
+	if cols == 0 {
+		cols = DefaultCols
+	}
+	fontSize := opts.FontSize
+	if fontSize == 0 {
+		fontSize = DefaultFontSize
+	}
+	if cols < 0 || fontSize < 0 {
+		return "", fmt.Errorf("invalid SVG options: cols=%d fontSize=%g", cols, opts.FontSize)
+	}
+	overflow, err := grid.ParseOverflow(string(opts.Overflow))
+	if err != nil {
+		return "", fmt.Errorf("invalid SVG options: %w", err)
+	}
+
+	// Enough rows that nothing is lost: one per line plus one per possible
+	// wrap (a wrap needs at least cols bytes of text). The screen is at
+	// most maxScreenRows of them, the rest kept as runs as they scroll off.
+	rows := strings.Count(content, "\n") + 1
+	switch overflow {
+	case grid.Wrap:
+		rows += len(content) / cols
+	case grid.NoWrap:
+		widest := 0
+		for _, line := range strings.Split(session.PlainText(content), "\n") {
+			widest = max(widest, grid.Width(line))
+		}
+		cols = max(cols, min(widest, maxNoWrapCols))
+		if widest > cols {
+			// Too wide to keep whole: cut like Truncate at the widest allowed
+			overflow = grid.Truncate
+		}
+	}
+	screen := grid.New(cols, min(rows, maxScreenRows))
+	screen.SetOverflow(overflow)
+	var lines [][]run
+	screen.SetScrollback(func(row []grid.Cell) {
+		lines = append(lines, rowRuns(row))
+	})
+	screen.Write(content)
+	for r := range screen.Lines() {
+		lines = append(lines, rowRuns(screenRow(screen, r)))
+	}
+	for len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	used := len(lines)
+
+	cellWidth := fontSize * 0.6
+	lineHeight := fontSize * 1.2
+	width := float64(cols)*cellWidth + 2*padding
+	height := float64(max(used, 1))*lineHeight + 2*padding
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		num(width), num(height), num(width), num(height))
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" rx="6" fill="%s"/>`+"\n", background)
+	fmt.Fprintf(&b, `<g font-family="%s" font-size="%s" fill="%s" xml:space="preserve">`+"\n",
+		html.EscapeString(fontFamily), num(fontSize), foreground)
+
+	for r, runs := range lines {
+		y := padding + float64(r)*lineHeight
+
+		// Backgrounds first so text is drawn on top
+		for _, run := range runs {
+			if _, bg := Colors(run.style); bg != "" {
+				fmt.Fprintf(&b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n",
+					num(padding+float64(run.col)*cellWidth), num(y),
+					num(float64(len(run.text))*cellWidth), num(lineHeight), bg)
+			}
+		}
+
+		var text strings.Builder
+		for _, run := range runs {
+			if strings.TrimSpace(string(run.text)) == "" {
+				continue
+			}
+			fmt.Fprintf(&text, `<tspan x="%s"%s>%s</tspan>`,
+				num(padding+float64(run.col)*cellWidth), attrs(run.style), html.EscapeString(string(run.text)))
+		}
+		if text.Len() > 0 {
+			// Baseline sits at roughly 80% of the line box
+			fmt.Fprintf(&b, `<text y="%s">%s</text>`+"\n", num(y+lineHeight*0.8), text.String())
+		}
+	}
+
+	b.WriteString("</g>\n</svg>\n")
+	return b.String(), nil
+}
+
+// run is a horizontal span of cells sharing one style.
+type run struct {
+	col   int
+	style grid.Style
+	text  []rune
+}
+
+// rowRuns groups the cells of a row into runs, up to the last written cell.
+func rowRuns(cells []grid.Cell) []run {
+	end := 0
+	for c, cell := range cells {
+		if (cell.Char != 0 && cell.Char != ' ') || cell.Style.BG != "" || cell.Style.Attrs&grid.Inverse != 0 {
+			end = c + 1
+		}
+	}
+
+	var runs []run
+	for c, cell := range cells[:end] {
+		ch := cell.Char
+		if ch == 0 {
+			ch = ' '
+		}
+		if n := len(runs); n > 0 && runs[n-1].style == cell.Style {
+			runs[n-1].text = append(runs[n-1].text, ch)
+			continue
+		}
+		runs = append(runs, run{col: c, style: cell.Style, text: []rune{ch}})
+	}
+	return runs
+}
+
+// screenRow returns a row of the screen's cells.
+func screenRow(screen *grid.Screen, row int) []grid.Cell {
+	cells := make([]grid.Cell, screen.Cols())
+	for c := range cells {
+		cells[c] = screen.Cell(row, c)
+	}
+	return cells
+}
+
+// Colors returns the hex colors for a style's text and background, as
+// drawn (inverse swaps them), in xterm.js's default theme. An empty result
+// means the default (inherited foreground, no background).
+func Colors(st grid.Style) (fg, bg string) {
+	fg, bg = sgrColor(st.FG), sgrColor(st.BG)
+	if st.Attrs&grid.Inverse != 0 {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = background
+		}
+		if bg == "" {
+			bg = foreground
+		}
+	}
+	return fg, bg
+}
+
+// attrs returns the SVG presentation attributes for a style.
+func attrs(st grid.Style) string {
+	var a string
+	if fg, _ := Colors(st); fg != "" {
+		a += ` fill="` + fg + `"`
+	}
+	if st.Attrs&grid.Bold != 0 {
+		a += ` font-weight="bold"`
+	}
+	if st.Attrs&grid.Italic != 0 {
+		a += ` font-style="italic"`
+	}
+	if st.Attrs&grid.Dim != 0 {
+		a += ` fill-opacity="0.6"`
+	}
+	switch {
+	case st.Attrs&grid.Underline != 0 && st.Attrs&grid.Strike != 0:
+		a += ` text-decoration="underline line-through"`
+	case st.Attrs&grid.Underline != 0:
+		a += ` text-decoration="underline"`
+	case st.Attrs&grid.Strike != 0:
+		a += ` text-decoration="line-through"`
+	}
+	return a
+}
+
+// palette is xterm.js's default 16-color theme, so snapshots match the HTML viewer.
+var palette = [16]string{
+	"#2e3436", "#cc0000", "#4e9a06", "#c4a000", "#3465a4", "#75507b", "#06989a", "#d3d7cf",
+	"#555753", "#ef2929", "#8ae234", "#fce94f", "#729fcf", "#ad7fa8", "#34e2e2", "#eeeeec",
+}
+
+// sgrColor converts grid.Style color parameters ("31", "94", "38;5;208",
+// "48;2;1;2;3") to a hex color. Returns "" for the default color.
+func sgrColor(param string) string {
+	if param == "" {
+		return ""
+	}
+	parts := strings.Split(param, ";")
+	n := make([]int, len(parts))
+	for i, p := range parts {
+		n[i], _ = strconv.Atoi(p)
+	}
+	switch {
+	case len(n) == 3 && n[1] == 5:
+		return color256(n[2])
+	case len(n) == 5 && n[1] == 2:
+		return fmt.Sprintf("#%02x%02x%02x", n[2]&0xff, n[3]&0xff, n[4]&0xff)
+	case n[0] >= 30 && n[0] <= 37:
+		return palette[n[0]-30]
+	case n[0] >= 40 && n[0] <= 47:
+		return palette[n[0]-40]
+	case n[0] >= 90 && n[0] <= 97:
+		return palette[n[0]-90+8]
+	case n[0] >= 100 && n[0] <= 107:
+		return palette[n[0]-100+8]
+	}
+	return ""
+}
+
+// color256 converts an xterm 256-color index to hex.
+func color256(i int) string {
+	switch {
+	case i < 0 || i > 255:
+		return ""
+	case i < 16:
+		return palette[i]
+	case i < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		i -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[i/36], levels[i/6%6], levels[i%6])
+	default:
+		v := 8 + (i-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", v, v, v)
+	}
+}
+
+// num formats a coordinate compactly ("12", "12.5"), to two decimals.
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,161 @@
+package svg
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/choonkeat/record-tui/internal/grid"
+)
+
+func TestRender_ColorSpans(t *testing.T) {
+	out, err := Render("plain \x1b[31mred\x1b[0m \x1b[1;38;5;208mbold orange\x1b[0m", Options{})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	if !strings.Contains(out, `fill="#cc0000">red</tspan>`) {
+		t.Errorf("SVG should contain a red span, got:\n%s", out)
+	}
+	if !strings.Contains(out, `fill="#ff8700" font-weight="bold">bold orange</tspan>`) {
+		t.Errorf("SVG should contain a bold 256-color span, got:\n%s", out)
+	}
+	if !strings.Contains(out, `>plain </tspan>`) {
+		t.Errorf("SVG should contain default-colored text, got:\n%s", out)
+	}
+}
+
+func TestRender_BackgroundAndInverse(t *testing.T) {
+	out, err := Render("\x1b[44m  \x1b[0m\x1b[7mrev\x1b[0m", Options{})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	if !strings.Contains(out, `fill="#3465a4"/>`) {
+		t.Error("SVG should draw a blue background rect")
+	}
+	// Inverse swaps default colors: dark text on a light background
+	if !strings.Contains(out, `fill="#d4d4d4"/>`) || !strings.Contains(out, `fill="#1e1e1e">rev</tspan>`) {
+		t.Errorf("SVG should render inverse video, got:\n%s", out)
+	}
+}
+
+func TestRender_EscapesAndOverwrites(t *testing.T) {
+	out, err := Render("progress 10%\rprogress 99%\r\n<a & b>\r\n", Options{})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	if strings.Contains(out, "10%") || !strings.Contains(out, "progress 99%") {
+		t.Error("carriage return should overwrite the line")
+	}
+	if !strings.Contains(out, "&lt;a &amp; b&gt;") {
+		t.Error("text should be XML-escaped")
+	}
+	// Two rows of text, trailing newline adds none
+	if got := strings.Count(out, "<text "); got != 2 {
+		t.Errorf("expected 2 text rows, got %d", got)
+	}
+}
+
+func TestRender_Dimensions(t *testing.T) {
+	out, err := Render("a\nb\nc", Options{Cols: 10, FontSize: 10})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	// 10 cols * 6px + 2*10 padding, 3 rows * 12px + 2*10 padding
+	if !strings.Contains(out, `width="80" height="56"`) {
+		t.Errorf("unexpected dimensions in:\n%s", out[:strings.Index(out, "\n")])
+	}
+
+	if _, err := Render("x", Options{Cols: -1}); err == nil {
+		t.Error("negative cols should be an error")
+	}
+}
+
+func TestRender_LongLog(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&b, "line %d\r\n", i)
+	}
+	content := b.String()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	out, err := Render(content, Options{FontSize: 10})
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	// Every line is drawn, in order, though the screen is much shorter
+	if n := strings.Count(out, "<text "); n != 100000 {
+		t.Errorf("drew %d lines, want 100000", n)
+	}
+	if !strings.Contains(out, `<text y="19.6"><tspan x="10">line 0</tspan></text>`) ||
+		!strings.Contains(out, ">line 99999<") {
+		t.Error("SVG should start at line 0 and end at line 99999")
+	}
+	// A grid as tall as the log would be 100000 x 80 cells of 48 bytes
+	if alloc := after.TotalAlloc - before.TotalAlloc; alloc > 150<<20 {
+		t.Errorf("allocated %d MB, want the screen bounded", alloc>>20)
+	}
+}
+
+func TestRender_Overflow(t *testing.T) {
+	long := strings.Repeat("x", 15)
+	for _, tc := range []struct {
+		overflow grid.Overflow
+		size     string // 10px font: 6px cells, 12px rows, 10px padding
+		text     []string
+	}{
+		{grid.Wrap, `width="80" height="44"`, []string{">xxxxxxxxxx<", ">xxxxx<"}},
+		{grid.Truncate, `width="80" height="32"`, []string{">xxxxxxxxx…<"}},
+		{grid.NoWrap, `width="110" height="32"`, []string{">" + long + "<"}},
+	} {
+		out, err := Render(long, Options{Cols: 10, FontSize: 10, Overflow: tc.overflow})
+		if err != nil {
+			t.Fatalf("%s: Render failed: %v", tc.overflow, err)
+		}
+		if !strings.Contains(out, tc.size) {
+			t.Errorf("%s: unexpected dimensions in:\n%s", tc.overflow, out[:strings.Index(out, "\n")])
+		}
+		for _, text := range tc.text {
+			if !strings.Contains(out, text) {
+				t.Errorf("%s: SVG should contain %q, got:\n%s", tc.overflow, text, out)
+			}
+		}
+	}
+
+	// A huge line widens the snapshot only so far, then is truncated
+	out, err := Render(strings.Repeat("y", 5000)+"\nshort", Options{Cols: 10, FontSize: 10, Overflow: grid.NoWrap})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(out, `width="3020"`) || !strings.Contains(out, ">"+strings.Repeat("y", 499)+"…<") {
+		t.Errorf("unexpected huge line: %.200s", out)
+	}
+
+	if _, err := Render(long, Options{Overflow: "clip"}); err == nil {
+		t.Error("unknown overflow should be an error")
+	}
+}
+
+func TestSGRColor(t *testing.T) {
+	tests := map[string]string{
+		"":             "",
+		"31":           "#cc0000",
+		"97":           "#eeeeec",
+		"38;5;16":      "#000000",
+		"38;5;231":     "#ffffff",
+		"48;5;244":     "#808080",
+		"38;2;1;2;255": "#0102ff",
+		"38;5;9":       "#ef2929",
+	}
+	for param, want := range tests {
+		if got := sgrColor(param); got != want {
+			t.Errorf("sgrColor(%q) = %q, want %q", param, got, want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package timing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// AsciicastSource is a Source reading an asciinema cast (asciicast v2 or
+// v3): a JSON header line, then one [time, code, data] event per line.
+// Output ("o") and input ("i") events become Output and Input entries of
+// len(data) bytes; other events (resize, marker, exit) are skipped, their
+// time counted in the next entry's Delay.
+//
+// A cast holds the recorded bytes itself, so the source also collects them
+// as it reads: Output and Input return what a session.log and session.input
+// would hold, for ExtractCommands and toc.FromCommands.
+type AsciicastSource struct {
+	r       *bufio.Reader
+	version int
+	lineNum int
+	last    float64 // Time of the last entry (v2) or time since it (v3)
+	output  []byte
+	input   []byte
+}
+
+// asciicastHeader is the part of a cast's header line that matters here.
+type asciicastHeader struct {
+	Version int `json:"version"`
+}
+
+// NewAsciicastSource returns a Source reading the asciinema cast in r. The
+// header line is read on the first call to Next.
+func NewAsciicastSource(r io.Reader) *AsciicastSource {
+	return &AsciicastSource{r: bufio.NewReader(r)}
+}
+
+// Next returns the entry for the next output or input event.
+func (s *AsciicastSource) Next() (Entry, error) {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return Entry{}, err
+		}
+		if line == "" {
+			continue
+		}
+
+		if s.version == 0 {
+			var header asciicastHeader
+			if err := json.Unmarshal([]byte(line), &header); err != nil {
+				return Entry{}, fmt.Errorf("line %d: invalid asciicast header: %w", s.lineNum, err)
+			}
+			if header.Version != 2 && header.Version != 3 {
+				return Entry{}, fmt.Errorf("line %d: unsupported asciicast version %d", s.lineNum, header.Version)
+			}
+			s.version = header.Version
+			continue
+		}
+
+		var event []any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return Entry{}, fmt.Errorf("line %d: invalid asciicast event: %w", s.lineNum, err)
+		}
+		if len(event) < 3 {
+			return Entry{}, fmt.Errorf("line %d: expected [time, code, data], got %d fields", s.lineNum, len(event))
+		}
+		t, ok1 := event[0].(float64)
+		code, ok2 := event[1].(string)
+		data, ok3 := event[2].(string)
+		if !ok1 || !ok2 || !ok3 {
+			return Entry{}, fmt.Errorf("line %d: expected [time, code, data]: %s", s.lineNum, line)
+		}
+
+		// v2 times are since the start, v3 times since the previous event
+		var delay float64
+		if s.version == 2 {
+			delay = max(0, t-s.last)
+		} else {
+			delay = s.last + t
+		}
+
+		var typ EntryType
+		switch code {
+		case "o":
+			typ = Output
+			s.output = append(s.output, data...)
+		case "i":
+			typ = Input
+			s.input = append(s.input, data...)
+		default:
+			if s.version == 3 {
+				s.last = delay
+			}
+			continue
+		}
+		if s.version == 2 {
+			s.last = t
+		} else {
+			s.last = 0
+		}
+		return Entry{Type: typ, Delay: delay, ByteCount: len(data)}, nil
+	}
+}
+
+// readLine returns the next line without its line break, or io.EOF.
+func (s *AsciicastSource) readLine() (string, error) {
+	line, err := s.r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("reading asciicast: %w", err)
+	}
+	if line == "" && err == io.EOF {
+		return "", io.EOF
+	}
+	s.lineNum++
+	return strings.TrimSpace(line), nil
+}
+
+// Output returns the output bytes of the events read so far.
+func (s *AsciicastSource) Output() []byte {
+	return s.output
+}
+
+// Input returns the input bytes of the events read so far.
+func (s *AsciicastSource) Input() []byte {
+	return s.input
+}
+
+// AsciicastHeader describes the recording in the header line WriteAsciicast
+// writes.
+type AsciicastHeader struct {
+	Width     int    `json:"width"`               // Terminal columns (0 = 80)
+	Height    int    `json:"height"`              // Terminal rows (0 = 24)
+	Timestamp int64  `json:"timestamp,omitempty"` // Unix time the recording started (0 = omitted)
+	Title     string `json:"title,omitempty"`
+}
+
+// WriteAsciicast writes output as an asciicast v2 cast to w, for `asciinema
+// play` and other cast players: the header line, then one "o" event per
+// Output entry at the time the entries' delays add up to. output is the
+// session output the entries account for (session.log without its script
+// header and footer); output they don't account for is written with the
+// last event, and with no Output entries all of it is written at time 0.
+//
+// A UTF-8 character split between two entries is written whole with the
+// second, since event data must be valid UTF-8.
+func WriteAsciicast(w io.Writer, header AsciicastHeader, entries []Entry, output []byte) error {
+	if header.Width <= 0 {
+		header.Width = 80
+	}
+	if header.Height <= 0 {
+		header.Height = 24
+	}
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(struct {
+		Version int `json:"version"`
+		AsciicastHeader
+	}{2, header}); err != nil {
+		return err
+	}
+
+	var elapsed, last float64
+	start, offset := 0, 0
+	for _, e := range entries {
+		elapsed += e.Delay
+		if e.Type != Output {
+			continue
+		}
+		// The timing file may count more output than the log holds (truncated)
+		offset = min(offset+e.ByteCount, len(output))
+		end := completeUTF8(output[:offset], start)
+		if end > start {
+			if err := enc.Encode([]any{elapsed, "o", string(output[start:end])}); err != nil {
+				return err
+			}
+			start = end
+		}
+		last = elapsed
+	}
+	if start < len(output) {
+		return enc.Encode([]any{last, "o", string(output[start:])})
+	}
+	return nil
+}
+
+// completeUTF8 returns len(b), less a UTF-8 character cut off at its end
+// that started at or after from.
+func completeUTF8(b []byte, from int) int {
+	for i := len(b) - 1; i >= from && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,136 @@
+package timing
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAsciicastSource_ExtractCommands(t *testing.T) {
+	cast := `{"version": 2, "width": 80, "height": 24}
+[0.1, "o", "$ "]
+[0.5, "i", "l"]
+[0.51, "o", "l"]
+[0.6, "i", "s"]
+[0.61, "o", "s"]
+[0.7, "i", "\r"]
+[0.71, "o", "\r\nfile1\r\n$ "]
+[1.0, "r", "100x30"]
+[1.5, "i", "pwd\r"]
+[1.6, "o", "pwd\r\n/tmp\r\n$ "]
+`
+	src := NewAsciicastSource(strings.NewReader(cast))
+	entries, err := ReadAll(src)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(entries) != 9 {
+		t.Fatalf("expected 9 entries (resize skipped), got %d: %+v", len(entries), entries)
+	}
+	if entries[0].Type != Output || entries[0].ByteCount != 2 || math.Abs(entries[0].Delay-0.1) > 1e-9 {
+		t.Errorf("entry 0 = %+v, want Output of 2 bytes after 0.1s", entries[0])
+	}
+	// The resize's time counts toward the next entry
+	if math.Abs(entries[7].Delay-0.79) > 1e-9 {
+		t.Errorf("entry 7 delay = %v, want 0.79", entries[7].Delay)
+	}
+
+	commands := ExtractCommands(entries, src.Input())
+	if len(commands) != 2 {
+		t.Fatalf("expected 2 commands, got %+v", commands)
+	}
+	if commands[0].Text != "ls" || commands[0].OutputByteOffset != 2 {
+		t.Errorf("command 0 = %+v, want ls at offset 2", commands[0])
+	}
+	if commands[1].Text != "pwd" || commands[1].OutputByteOffset != 15 {
+		t.Errorf("command 1 = %+v, want pwd at offset 15", commands[1])
+	}
+	if got := string(src.Output()); got != "$ ls\r\nfile1\r\n$ pwd\r\n/tmp\r\n$ " {
+		t.Errorf("Output() = %q", got)
+	}
+}
+
+func TestAsciicastSource_V3RelativeTimes(t *testing.T) {
+	cast := `{"version": 3, "term": {"cols": 80, "rows": 24}}
+[0.25, "o", "hello"]
+[0.5, "m", "chapter"]
+[0.25, "o", " world"]
+[0.1, "x", "0"]
+`
+	entries, err := ReadAll(NewAsciicastSource(strings.NewReader(cast)))
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Delay != 0.25 || entries[1].Delay != 0.75 {
+		t.Errorf("delays = %v, %v; want 0.25, 0.75", entries[0].Delay, entries[1].Delay)
+	}
+}
+
+func TestAsciicastSource_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cast string
+	}{
+		{"unsupported version", `{"version": 1}` + "\n"},
+		{"invalid header", "not json\n"},
+		{"invalid event", `{"version": 2}` + "\n[0.1, \"o\"]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ReadAll(NewAsciicastSource(strings.NewReader(tt.cast))); err == nil || err == io.EOF {
+				t.Errorf("expected an error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWriteAsciicast(t *testing.T) {
+	entries := []Entry{
+		{Type: Output, Delay: 0.5, ByteCount: 2},
+		{Type: Input, Delay: 0.25, ByteCount: 1},
+		{Type: Output, Delay: 0.25, ByteCount: 4}, // ends partway through "✓"
+		{Type: Output, Delay: 1, ByteCount: 3},
+	}
+	output := []byte("$ ls✓\r\n")
+
+	var buf bytes.Buffer
+	if err := WriteAsciicast(&buf, AsciicastHeader{Width: 100, Timestamp: 1700000000}, entries, output); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":2,"width":100,"height":24,"timestamp":1700000000}
+[0.5,"o","$ "]
+[1,"o","ls"]
+[2,"o","✓\r\n"]
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// It reads back as the same output and timing
+	src := NewAsciicastSource(&buf)
+	read, err := ReadAll(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src.Output()) != string(output) || len(read) != 3 || read[2].Delay != 1 {
+		t.Errorf("round trip: entries %+v, output %q", read, src.Output())
+	}
+}
+
+func TestWriteAsciicast_NoTiming(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteAsciicast(&buf, AsciicastHeader{}, nil, []byte("hello\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":2,"width":80,"height":24}
+[0,"o","hello\r\n"]
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,30 @@
+package timing
+
+import "strings"
+
+// DefaultChapterPrefix marks a typed line as a chapter bookmark rather than
+// a command. Starting with # makes it a comment, so the shell ignores it:
+//
+//	$ #chapter: Setup
+const DefaultChapterPrefix = "#chapter:"
+
+// MarkChapters replaces the text of commands typed as a chapter bookmark
+// (prefix followed by a name, e.g. "#chapter: Build") with the chapter name,
+// so navigation shows "Build" instead of the literal line. A bookmark
+// without a name is kept as typed. prefix "" uses DefaultChapterPrefix.
+// The commands slice is updated in place and returned.
+func MarkChapters(commands []Command, prefix string) []Command {
+	if prefix == "" {
+		prefix = DefaultChapterPrefix
+	}
+	for i, cmd := range commands {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(cmd.Text), prefix)
+		if !ok {
+			continue
+		}
+		if name := strings.TrimSpace(rest); name != "" {
+			commands[i].Text = name
+		}
+	}
+	return commands
+}
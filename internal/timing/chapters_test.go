@@ -0,0 +1,49 @@
+package timing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkChapters(t *testing.T) {
+	timingData := `I 0.1 16
+O 0.001 16
+I 0.5 9
+O 0.2 40
+`
+	entries, err := Parse(strings.NewReader(timingData))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	commands := MarkChapters(ExtractCommands(entries, []byte("#chapter: Build\rmake all\r")), "")
+
+	if len(commands) != 2 {
+		t.Fatalf("expected 2 commands, got %d: %+v", len(commands), commands)
+	}
+	if commands[0].Text != "Build" {
+		t.Errorf("chapter label = %q, want %q", commands[0].Text, "Build")
+	}
+	if commands[0].OutputByteOffset != 0 {
+		t.Errorf("chapter offset = %d, want 0", commands[0].OutputByteOffset)
+	}
+	if commands[1].Text != "make all" {
+		t.Errorf("ordinary command should be unchanged, got %q", commands[1].Text)
+	}
+}
+
+func TestMarkChapters_CustomPrefix(t *testing.T) {
+	commands := []Command{
+		{Text: "## Deploy to staging"},
+		{Text: "#chapter: not with this prefix"},
+		{Text: "##"},
+	}
+
+	MarkChapters(commands, "##")
+
+	want := []string{"Deploy to staging", "#chapter: not with this prefix", "##"}
+	for i, cmd := range commands {
+		if cmd.Text != want[i] {
+			t.Errorf("commands[%d] = %q, want %q", i, cmd.Text, want[i])
+		}
+	}
+}
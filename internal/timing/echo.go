@@ -0,0 +1,54 @@
+package timing
+
+import "bytes"
+
+// OutputOnly returns output without the echo of what the user typed, and
+// entries with their Output byte counts adjusted to match it, so the result
+// can still be sliced by time or have commands extracted from it. output is
+// the session output the entries account for (session.log without its
+// script header and footer).
+//
+// The echo is taken to be the first Output entry after each run of Input
+// entries, up to its first newline: the terminal echoes a keystroke before
+// anything else is written. The newline itself is kept (as "\r\n"), so the
+// output keeps its line count and line numbers from the original still
+// apply. This is a heuristic: output a program happens to write right after
+// a keystroke it didn't read (typing ahead during a long build) is dropped
+// as if it were echo.
+func OutputOnly(entries []Entry, output []byte) ([]byte, []Entry) {
+	result := make([]byte, 0, len(output))
+	adjusted := make([]Entry, len(entries))
+	offset := 0
+	afterInput := false
+	for i, e := range entries {
+		adjusted[i] = e
+		switch e.Type {
+		case Input:
+			afterInput = true
+			continue
+		case Output:
+		default:
+			continue
+		}
+
+		// The timing file may count more output than the log holds (truncated)
+		start, end := min(offset, len(output)), min(offset+e.ByteCount, len(output))
+		offset += e.ByteCount
+		chunk := output[start:end]
+		if afterInput {
+			afterInput = false
+			if nl := bytes.IndexByte(chunk, '\n'); nl >= 0 {
+				chunk = append([]byte("\r\n"), chunk[nl+1:]...)
+			} else {
+				chunk = nil
+			}
+		}
+		result = append(result, chunk...)
+		adjusted[i].ByteCount = len(chunk)
+	}
+	// Output the timing file doesn't account for is kept
+	if offset < len(output) {
+		result = append(result, output[offset:]...)
+	}
+	return result, adjusted
+}
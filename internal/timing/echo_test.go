@@ -0,0 +1,53 @@
+package timing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputOnly(t *testing.T) {
+	// Prompt, "ls" typed a key at a time and echoed, Enter echoed with
+	// the command's output in the same chunk, then the next prompt
+	timingData := "O 0.1 2\nI 0.5 1\nO 0.0 1\nI 0.2 1\nO 0.0 1\nI 0.3 1\nO 0.0 13\nO 0.1 2\n"
+	entries, err := Parse(strings.NewReader(timingData))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	output := []byte("$ ls\r\na.txt b.txt\r\n$ ")
+
+	result, adjusted := OutputOnly(entries, output)
+
+	if got, want := string(result), "$ \r\na.txt b.txt\r\n$ "; got != want {
+		t.Errorf("OutputOnly = %q, want %q", got, want)
+	}
+	if strings.Count(string(result), "\n") != strings.Count(string(output), "\n") {
+		t.Errorf("line count should be kept, got %q", result)
+	}
+	if err := Validate(adjusted, len(result)); err != nil {
+		t.Errorf("adjusted entries should account for the result: %v", err)
+	}
+	if len(adjusted) != len(entries) || adjusted[1] != entries[1] {
+		t.Errorf("Input entries should be unchanged, got %+v", adjusted)
+	}
+}
+
+func TestOutputOnly_NoInput(t *testing.T) {
+	entries := []Entry{{Type: Output, ByteCount: 6}, {Type: Output, ByteCount: 5}}
+	output := []byte("hello\nworld")
+
+	result, _ := OutputOnly(entries, output)
+
+	if string(result) != string(output) {
+		t.Errorf("output without input should be unchanged, got %q", result)
+	}
+}
+
+func TestOutputOnly_TruncatedLog(t *testing.T) {
+	entries := []Entry{{Type: Output, ByteCount: 4}, {Type: Input, ByteCount: 1}, {Type: Output, ByteCount: 10}}
+
+	result, _ := OutputOnly(entries, []byte("$ x\n"))
+
+	if string(result) != "$ x\n" {
+		t.Errorf("OutputOnly = %q, want %q", result, "$ x\n")
+	}
+}
@@ -0,0 +1,68 @@
+package timing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// String returns the event type name used in exports.
+func (t EntryType) String() string {
+	switch t {
+	case Output:
+		return "output"
+	case Input:
+		return "input"
+	case Header:
+		return "header"
+	case Signal:
+		return "signal"
+	}
+	return fmt.Sprintf("EntryType(%q)", byte(t))
+}
+
+// event is one line of a JSON lines export.
+type event struct {
+	Type      string  `json:"type"`
+	Delay     float64 `json:"delay"`
+	ByteCount int     `json:"byteCount"`
+	Command   string  `json:"command,omitempty"`
+}
+
+// WriteEventsJSONL writes entries to w as JSON lines, one object per entry:
+//
+//	{"type":"input","delay":0.5,"byteCount":1}
+//
+// Each command (see ExtractCommands) is written as a "command" event, with
+// zero delay and byte count, just before the input entry that starts it.
+// Lines are written as they are encoded, so w may be a file or network
+// connection without the export being held in memory.
+func WriteEventsJSONL(w io.Writer, entries []Entry, commands []Command) error {
+	enc := json.NewEncoder(w)
+	outputOffset := 0
+	next := 0 // next command to write
+
+	for _, e := range entries {
+		// A command starts with the first input once output reaches its offset
+		for e.Type == Input && next < len(commands) && commands[next].OutputByteOffset <= outputOffset {
+			if err := enc.Encode(event{Type: "command", Command: commands[next].Text}); err != nil {
+				return err
+			}
+			next++
+		}
+		if err := enc.Encode(event{Type: e.Type.String(), Delay: e.Delay, ByteCount: e.ByteCount}); err != nil {
+			return err
+		}
+		if e.Type == Output {
+			outputOffset += e.ByteCount
+		}
+	}
+
+	// Commands past the last entry (inconsistent inputs) still get written
+	for ; next < len(commands); next++ {
+		if err := enc.Encode(event{Type: "command", Command: commands[next].Text}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
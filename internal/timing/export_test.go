@@ -0,0 +1,71 @@
+package timing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteEventsJSONL(t *testing.T) {
+	timingData := `O 0.010000 2
+I 0.500000 1
+O 0.001000 1
+I 0.200000 1
+O 0.001000 2
+O 0.300000 6
+I 1.000000 4
+O 0.001000 2
+`
+	entries, err := Parse(strings.NewReader(timingData))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	input := []byte("l\rpwd\r")
+	commands := ExtractCommands(entries, input)
+
+	var out strings.Builder
+	if err := WriteEventsJSONL(&out, entries, commands); err != nil {
+		t.Fatalf("WriteEventsJSONL failed: %v", err)
+	}
+
+	want := `{"type":"output","delay":0.01,"byteCount":2}
+{"type":"command","delay":0,"byteCount":0,"command":"l"}
+{"type":"input","delay":0.5,"byteCount":1}
+{"type":"output","delay":0.001,"byteCount":1}
+{"type":"input","delay":0.2,"byteCount":1}
+{"type":"output","delay":0.001,"byteCount":2}
+{"type":"output","delay":0.3,"byteCount":6}
+{"type":"command","delay":0,"byteCount":0,"command":"pwd"}
+{"type":"input","delay":1,"byteCount":4}
+{"type":"output","delay":0.001,"byteCount":2}
+`
+	if got := out.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteEventsJSONL_NoCommands(t *testing.T) {
+	entries := []Entry{{Type: Header, Delay: 0, ByteCount: 0}, {Type: Signal, Delay: 0.25, ByteCount: 0}}
+
+	var out strings.Builder
+	if err := WriteEventsJSONL(&out, entries, nil); err != nil {
+		t.Fatalf("WriteEventsJSONL failed: %v", err)
+	}
+
+	want := "{\"type\":\"header\",\"delay\":0,\"byteCount\":0}\n{\"type\":\"signal\",\"delay\":0.25,\"byteCount\":0}\n"
+	if got := out.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteEventsJSONL_WriteError(t *testing.T) {
+	entries := []Entry{{Type: Output, Delay: 0.1, ByteCount: 1}}
+	if err := WriteEventsJSONL(failingWriter{}, entries, nil); err == nil {
+		t.Error("expected the write error to be returned")
+	}
+}
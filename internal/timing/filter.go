@@ -0,0 +1,37 @@
+package timing
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultNoiseCommands is the commands ExcludeCommands is usually given:
+// typed bare, they only move around or tidy up the terminal, and clutter
+// navigation.
+var DefaultNoiseCommands = []string{"ls", "cd", "clear"}
+
+// FilterCommands returns the commands keep returns true for, in order.
+// The commands slice is not modified.
+func FilterCommands(commands []Command, keep func(Command) bool) []Command {
+	var kept []Command
+	for _, cmd := range commands {
+		if keep(cmd) {
+			kept = append(kept, cmd)
+		}
+	}
+	return kept
+}
+
+// ExcludeCommands returns a FilterCommands predicate that drops commands
+// typed exactly as one of names (ignoring surrounding whitespace, so "ls"
+// drops a bare ls but not "ls -la") or matching pattern, if it's not nil.
+func ExcludeCommands(names []string, pattern *regexp.Regexp) func(Command) bool {
+	return func(cmd Command) bool {
+		text := strings.TrimSpace(cmd.Text)
+		if slices.Contains(names, text) {
+			return false
+		}
+		return pattern == nil || !pattern.MatchString(text)
+	}
+}
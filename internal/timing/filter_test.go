@@ -0,0 +1,52 @@
+package timing
+
+import (
+	"regexp"
+	"testing"
+)
+
+func commandTexts(commands []Command) []string {
+	var texts []string
+	for _, cmd := range commands {
+		texts = append(texts, cmd.Text)
+	}
+	return texts
+}
+
+func TestFilterCommands_DefaultNoise(t *testing.T) {
+	commands := []Command{
+		{Text: "cd", OutputByteOffset: 0},
+		{Text: "ls -la", OutputByteOffset: 10},
+		{Text: " ls ", OutputByteOffset: 20},
+		{Text: "make test", OutputByteOffset: 30},
+		{Text: "clear", OutputByteOffset: 40},
+	}
+
+	got := FilterCommands(commands, ExcludeCommands(DefaultNoiseCommands, nil))
+
+	want := []string{"ls -la", "make test"}
+	if texts := commandTexts(got); len(texts) != len(want) || texts[0] != want[0] || texts[1] != want[1] {
+		t.Fatalf("filtered commands = %q, want %q", texts, want)
+	}
+	if got[1].OutputByteOffset != 30 {
+		t.Errorf("offset = %d, want 30", got[1].OutputByteOffset)
+	}
+	if commands[0].Text != "cd" {
+		t.Errorf("input slice modified: %+v", commands)
+	}
+}
+
+func TestFilterCommands_Pattern(t *testing.T) {
+	commands := []Command{
+		{Text: "git status"},
+		{Text: "git commit -m wip"},
+		{Text: "ls"},
+	}
+
+	got := FilterCommands(commands, ExcludeCommands(nil, regexp.MustCompile(`^git status\b`)))
+
+	want := []string{"git commit -m wip", "ls"}
+	if texts := commandTexts(got); len(texts) != len(want) || texts[0] != want[0] || texts[1] != want[1] {
+		t.Errorf("filtered commands = %q, want %q", texts, want)
+	}
+}
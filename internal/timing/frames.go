@@ -0,0 +1,50 @@
+package timing
+
+import "bytes"
+
+// FramePoint is a point an animated playback steps to: the output written
+// up to OutputByteOffset, shown Time seconds into the recording.
+type FramePoint struct {
+	Time             float64 // Seconds from the start of the recording
+	OutputByteOffset int     // Cumulative output bytes shown at this point
+}
+
+// FramePoints returns the points an animated playback of a recording steps
+// through, from its Input and Output entries and inputContent (the
+// session.input bytes, with metadata stripped). There is one when each
+// command is entered (input with \r or \n, as ExtractCommands splits
+// commands), showing the command whole and the previous command's output,
+// and a last one at the end of the recording.
+//
+// With keystrokes, there is also one after the echo of each keystroke (the
+// first Output entry after each run of Input entries, see OutputOnly), so
+// typed commands appear a character at a time with the recorded delays
+// between keystrokes instead of all at once.
+func FramePoints(entries []Entry, inputContent []byte, keystrokes bool) []FramePoint {
+	var points []FramePoint
+	var elapsed float64
+	inputOffset, outputOffset := 0, 0
+	afterInput := false
+	for _, e := range entries {
+		elapsed += e.Delay
+		switch e.Type {
+		case Output:
+			outputOffset += e.ByteCount
+			if afterInput && keystrokes {
+				points = append(points, FramePoint{Time: elapsed, OutputByteOffset: outputOffset})
+			}
+			afterInput = false
+
+		case Input:
+			end := min(inputOffset+e.ByteCount, len(inputContent))
+			if inputOffset < end && bytes.ContainsAny(inputContent[inputOffset:end], "\r\n") {
+				points = append(points, FramePoint{Time: elapsed, OutputByteOffset: outputOffset})
+				afterInput = false
+			} else {
+				afterInput = true
+			}
+			inputOffset = max(inputOffset, end)
+		}
+	}
+	return append(points, FramePoint{Time: elapsed, OutputByteOffset: outputOffset})
+}
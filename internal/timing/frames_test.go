@@ -0,0 +1,73 @@
+package timing
+
+import "testing"
+
+// typedSession types "ls" and "pwd", each key echoed by its own Output
+// entry, with output after each Enter
+var typedSession = struct {
+	entries []Entry
+	input   []byte
+}{
+	entries: []Entry{
+		{Type: Output, Delay: 0.0, ByteCount: 2}, // "$ "
+		{Type: Input, Delay: 0.5, ByteCount: 1},  // l
+		{Type: Output, Delay: 0.01, ByteCount: 1},
+		{Type: Input, Delay: 0.2, ByteCount: 1}, // s
+		{Type: Output, Delay: 0.01, ByteCount: 1},
+		{Type: Input, Delay: 0.3, ByteCount: 1},    // \r
+		{Type: Output, Delay: 0.01, ByteCount: 14}, // "\r\nfile.txt\r\n$ "
+		{Type: Input, Delay: 1.0, ByteCount: 1},    // p
+		{Type: Output, Delay: 0.01, ByteCount: 1},
+		{Type: Input, Delay: 0.1, ByteCount: 1}, // w
+		{Type: Output, Delay: 0.01, ByteCount: 1},
+		{Type: Input, Delay: 0.1, ByteCount: 1}, // d
+		{Type: Output, Delay: 0.01, ByteCount: 1},
+		{Type: Input, Delay: 0.4, ByteCount: 1},   // \r
+		{Type: Output, Delay: 0.01, ByteCount: 8}, // "\r\n/tmp\r\n"
+	},
+	input: []byte("ls\rpwd\r"),
+}
+
+func TestFramePoints_Commands(t *testing.T) {
+	points := FramePoints(typedSession.entries, typedSession.input, false)
+
+	// One per Enter, plus the end
+	want := []int{4, 21, 29}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for i, p := range points {
+		if p.OutputByteOffset != want[i] {
+			t.Errorf("point %d: OutputByteOffset = %d, want %d", i, p.OutputByteOffset, want[i])
+		}
+	}
+}
+
+func TestFramePoints_Keystrokes(t *testing.T) {
+	points := FramePoints(typedSession.entries, typedSession.input, true)
+
+	// One per echoed keystroke (l, s, p, w, d), one per Enter, plus the end
+	if len(points) != 5+2+1 {
+		t.Fatalf("got %d points, want 8: %+v", len(points), points)
+	}
+	want := []int{3, 4, 4, 19, 20, 21, 21, 29}
+	for i, p := range points {
+		if p.OutputByteOffset != want[i] {
+			t.Errorf("point %d: OutputByteOffset = %d, want %d", i, p.OutputByteOffset, want[i])
+		}
+		if i > 0 && p.Time < points[i-1].Time {
+			t.Errorf("point %d: Time %v before previous %v", i, p.Time, points[i-1].Time)
+		}
+	}
+	// The second keystroke's echo keeps its recorded delay after the first
+	if d := points[1].Time - points[0].Time; d < 0.2 || d > 0.22 {
+		t.Errorf("delay between keystroke frames = %v, want about 0.21", d)
+	}
+}
+
+func TestFramePoints_Empty(t *testing.T) {
+	points := FramePoints(nil, nil, true)
+	if len(points) != 1 || points[0] != (FramePoint{}) {
+		t.Errorf("FramePoints(nil) = %+v, want a single point at the start", points)
+	}
+}
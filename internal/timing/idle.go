@@ -0,0 +1,27 @@
+package timing
+
+import "bytes"
+
+// TrimLeadingIdle returns output from the line the first keystroke was
+// typed on, dropping what was written while the recording sat waiting for
+// it (a shell's startup banner, an idle screen), and the number of bytes
+// dropped, by which offsets into output (e.g. Command.OutputByteOffset)
+// move back. The line the first command is typed at is kept, so the page
+// starts at its prompt. output is the session output the entries account
+// for (session.log without its script header and footer).
+//
+// Returns output whole (and 0) if nothing was typed.
+func TrimLeadingIdle(entries []Entry, output []byte) ([]byte, int) {
+	offset := 0
+	for _, e := range entries {
+		switch e.Type {
+		case Input:
+			// The timing file may count more output than the log holds (truncated)
+			cut := bytes.LastIndexByte(output[:min(offset, len(output))], '\n') + 1
+			return output[cut:], cut
+		case Output:
+			offset += e.ByteCount
+		}
+	}
+	return output, 0
+}
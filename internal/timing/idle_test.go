@@ -0,0 +1,59 @@
+package timing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTrimLeadingIdle(t *testing.T) {
+	// A login banner and a minute of output-only spinner frames before
+	// "ls" is typed at the prompt
+	banner := "Last login: Mon Jan 12 06:41:43\r\n" + strings.Repeat("waiting...\r\n", 60)
+	output := []byte(banner + "$ ls\r\na.txt\r\n$ ")
+	timingData := "O 0.1 33\n"
+	for i := 0; i < 60; i++ {
+		timingData += "O 1.0 12\n"
+	}
+	timingData += "O 0.1 2\nI 5.0 3\nO 0.0 11\nO 0.1 2\n"
+	entries, err := Parse(strings.NewReader(timingData))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	result, offset := TrimLeadingIdle(entries, output)
+
+	if got, want := string(result), "$ ls\r\na.txt\r\n$ "; got != want {
+		t.Errorf("TrimLeadingIdle = %q, want %q", got, want)
+	}
+	if offset != len(banner) {
+		t.Errorf("offset = %d, want %d", offset, len(banner))
+	}
+
+	// Command offsets move back by the bytes dropped
+	commands := ExtractCommands(entries, []byte("ls\r"))
+	if len(commands) != 1 || commands[0].OutputByteOffset-offset != len("$ ") {
+		t.Errorf("command offset in the trimmed output = %+v, want %d", commands, len("$ "))
+	}
+}
+
+func TestTrimLeadingIdle_NoInput(t *testing.T) {
+	entries := []Entry{{Type: Output, ByteCount: 6}, {Type: Output, ByteCount: 5}}
+	output := []byte("hello\nworld")
+
+	result, offset := TrimLeadingIdle(entries, output)
+
+	if string(result) != string(output) || offset != 0 {
+		t.Errorf("got %q, %d; want the output whole", result, offset)
+	}
+}
+
+func TestTrimLeadingIdle_TypedFirst(t *testing.T) {
+	entries := []Entry{{Type: Input, ByteCount: 3}, {Type: Output, ByteCount: 4}}
+	output := []byte("ls\r\n")
+
+	result, offset := TrimLeadingIdle(entries, output)
+
+	if string(result) != "ls\r\n" || offset != 0 {
+		t.Errorf("got %q, %d; want nothing dropped", result, offset)
+	}
+}
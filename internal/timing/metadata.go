@@ -0,0 +1,78 @@
+package timing
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Metadata is the recording's information from the H entries of an
+// advanced format timing file, by name, as util-linux script(1) writes it:
+//
+//	H 0.000000 START_TIME 2026-02-03 08:32:06+00:00
+//	H 0.000000 TERM xterm-256color
+//	H 0.000000 COLUMNS 120
+//	H 0.000000 LINES 40
+type Metadata map[string]string
+
+// HeaderMetadata collects the metadata of the H entries in entries. A
+// name given more than once keeps its last value.
+func HeaderMetadata(entries []Entry) Metadata {
+	m := Metadata{}
+	for _, e := range entries {
+		if e.Type == Header && e.Name != "" {
+			m[e.Name] = e.Value
+		}
+	}
+	return m
+}
+
+// Int returns the named value as an integer, e.g. m.Int("COLUMNS").
+func (m Metadata) Int(name string) (int, bool) {
+	n, err := strconv.Atoi(m[name])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// StartTime returns START_TIME, when the recording started.
+func (m Metadata) StartTime() (time.Time, bool) {
+	t, err := time.Parse("2006-01-02 15:04:05-07:00", m["START_TIME"])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseMetadataLine parses an H or S line: TYPE DELAY, then either a byte
+// count (as in "H 0.000000 0") or NAME and the rest of the line as its
+// value. Runs of spaces or tabs between fields are allowed, and the value's
+// own spacing is kept. An unparsable delay is 0.
+func parseMetadataLine(typ EntryType, line string) Entry {
+	_, rest := cutField(line) // The type
+	delayField, rest := cutField(rest)
+	delay, _ := strconv.ParseFloat(delayField, 64)
+	entry := Entry{Type: typ, Delay: delay}
+
+	name, value := cutField(rest)
+	if n, err := strconv.Atoi(name); err == nil && value == "" {
+		entry.ByteCount = n
+		return entry
+	}
+	entry.Name = name
+	entry.Value = strings.TrimRightFunc(value, unicode.IsSpace)
+	return entry
+}
+
+// cutField returns s's first whitespace-separated field and what follows
+// it, with leading whitespace removed from both.
+func cutField(s string) (field, rest string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	i := strings.IndexFunc(s, unicode.IsSpace)
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeftFunc(s[i:], unicode.IsSpace)
+}
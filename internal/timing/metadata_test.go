@@ -0,0 +1,84 @@
+package timing
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_HeaderMetadata(t *testing.T) {
+	input := "H 0.000000 START_TIME 2026-02-03 08:32:06+00:00\n" +
+		"H 0.000000 TERM xterm-256color\n" +
+		"H 0.000000 TTY /dev/pts/3\n" +
+		"H\t0.000000   COLUMNS\t120\n" +
+		"H 0.000000  LINES  40  \n" +
+		"H 0.000000 SHELL /bin/bash\n" +
+		"H 0.000000 TIMING_LOG /tmp/rec/session.timing\n" +
+		"O 0.009404 16\n" +
+		"S 0.500000 SIGWINCH ROWS=24 COLS=80\n" +
+		"H 0.000000 0\n"
+	entries, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 10 {
+		t.Fatalf("expected 10 entries, got %d", len(entries))
+	}
+
+	m := HeaderMetadata(entries)
+	want := Metadata{
+		"START_TIME": "2026-02-03 08:32:06+00:00",
+		"TERM":       "xterm-256color",
+		"TTY":        "/dev/pts/3",
+		"COLUMNS":    "120",
+		"LINES":      "40",
+		"SHELL":      "/bin/bash",
+		"TIMING_LOG": "/tmp/rec/session.timing",
+	}
+	if len(m) != len(want) {
+		t.Errorf("got %v, want %v", m, want)
+	}
+	for name, value := range want {
+		if m[name] != value {
+			t.Errorf("%s = %q, want %q", name, m[name], value)
+		}
+	}
+
+	if cols, ok := m.Int("COLUMNS"); !ok || cols != 120 {
+		t.Errorf("COLUMNS = %d, %t; want 120", cols, ok)
+	}
+	if rows, ok := m.Int("LINES"); !ok || rows != 40 {
+		t.Errorf("LINES = %d, %t; want 40", rows, ok)
+	}
+	if _, ok := m.Int("TERM"); ok {
+		t.Error("TERM should not be an integer")
+	}
+	started, ok := m.StartTime()
+	if want := time.Date(2026, 2, 3, 8, 32, 6, 0, time.UTC); !ok || !started.Equal(want) {
+		t.Errorf("StartTime = %v, %t; want %v", started, ok, want)
+	}
+
+	if sig := entries[8]; sig.Type != Signal || sig.Delay != 0.5 || sig.Name != "SIGWINCH" || sig.Value != "ROWS=24 COLS=80" {
+		t.Errorf("signal entry = %+v", sig)
+	}
+	if h := entries[9]; h.Name != "" || h.ByteCount != 0 {
+		t.Errorf("H with a byte count = %+v, want no metadata", h)
+	}
+}
+
+func TestHeaderMetadata_None(t *testing.T) {
+	entries, err := Parse(strings.NewReader("0.009404 16\n0.440731 35\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	m := HeaderMetadata(entries)
+	if len(m) != 0 {
+		t.Errorf("classic format should have no metadata, got %v", m)
+	}
+	if _, ok := m.StartTime(); ok {
+		t.Error("StartTime should be missing")
+	}
+	if _, ok := m.Int("COLUMNS"); ok {
+		t.Error("COLUMNS should be missing")
+	}
+}
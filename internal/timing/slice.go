@@ -0,0 +1,51 @@
+package timing
+
+import (
+	"bytes"
+	"time"
+)
+
+// SliceByTime returns the part of output written between from and to,
+// measured from the start of the recording by adding up the entries'
+// delays. output is the session output the entries account for (session.log
+// without its script header and footer); to 0 means the end of the recording.
+//
+// The slice is widened to whole lines, so a window that starts or ends
+// partway through a command's output doesn't cut a line (or an escape
+// sequence or multi-byte character in it) in half. Returns nil if nothing
+// was output in the window.
+func SliceByTime(entries []Entry, output []byte, from, to time.Duration) []byte {
+	var elapsed float64
+	offset := 0
+	start, end := -1, -1
+	for _, e := range entries {
+		elapsed += e.Delay
+		if e.Type != Output {
+			continue
+		}
+		at := time.Duration(elapsed * float64(time.Second))
+		if at >= from && (to == 0 || at < to) {
+			if start < 0 {
+				start = offset
+			}
+			end = offset + e.ByteCount
+		}
+		offset += e.ByteCount
+	}
+
+	// The timing file may count more output than the log holds (truncated)
+	start, end = min(start, len(output)), min(end, len(output))
+	if start < 0 || start >= end {
+		return nil
+	}
+
+	start = bytes.LastIndexByte(output[:start], '\n') + 1
+	if output[end-1] != '\n' {
+		if i := bytes.IndexByte(output[end:], '\n'); i >= 0 {
+			end += i + 1
+		} else {
+			end = len(output)
+		}
+	}
+	return output[start:end]
+}
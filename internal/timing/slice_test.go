@@ -0,0 +1,77 @@
+package timing
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// sliceFixture is output written one line per second: "one" at 1s,
+// "two" at 2s, and so on.
+func sliceFixture(t *testing.T) ([]Entry, []byte) {
+	t.Helper()
+	timingData := "O 1.0 5\nI 0.5 3\nO 0.5 5\nO 1.0 7\nO 1.0 6\n"
+	entries, err := Parse(strings.NewReader(timingData))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return entries, []byte("one\r\ntwo\r\nthree\r\nfour\r\n")
+}
+
+func TestSliceByTime(t *testing.T) {
+	entries, output := sliceFixture(t)
+
+	tests := []struct {
+		name     string
+		from, to time.Duration
+		want     string
+	}{
+		{"whole recording", 0, 0, "one\r\ntwo\r\nthree\r\nfour\r\n"},
+		{"middle", 2 * time.Second, 4 * time.Second, "two\r\nthree\r\n"},
+		{"open ended", 3 * time.Second, 0, "three\r\nfour\r\n"},
+		{"up to", 0, 1500 * time.Millisecond, "one\r\n"},
+		{"window with no output", 1100 * time.Millisecond, 1900 * time.Millisecond, ""},
+		{"past the end", time.Minute, 0, ""},
+	}
+	for _, tt := range tests {
+		if got := string(SliceByTime(entries, output, tt.from, tt.to)); got != tt.want {
+			t.Errorf("%s: SliceByTime(%v, %v) = %q, want %q", tt.name, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestSliceByTime_WidensToWholeLines(t *testing.T) {
+	// "progress" is written in two chunks a minute apart; a window holding
+	// only the second chunk still gets the whole line
+	entries := []Entry{
+		{Type: Output, Delay: 0, ByteCount: 6},   // "$ make"
+		{Type: Output, Delay: 1, ByteCount: 15},  // "\r\n\x1b[32mbuilding"
+		{Type: Output, Delay: 60, ByteCount: 11}, // " 100%\x1b[0m\r\n"
+		{Type: Output, Delay: 1, ByteCount: 4},   // "$ ls"
+	}
+	output := []byte("$ make\r\n\x1b[32mbuilding 100%\x1b[0m\r\n$ ls")
+
+	got := string(SliceByTime(entries, output, 30*time.Second, 62*time.Second))
+	if want := "\x1b[32mbuilding 100%\x1b[0m\r\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A window ending partway through a line runs to the end of it
+	got = string(SliceByTime(entries, output, 0, 500*time.Millisecond))
+	if want := "$ make\r\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSliceByTime_TruncatedOutput(t *testing.T) {
+	entries, output := sliceFixture(t)
+
+	// Timing counts more output than the (truncated) log holds
+	got := string(SliceByTime(entries, output[:8], 2*time.Second, 0))
+	if want := "two"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := SliceByTime(entries, output[:3], 2*time.Second, 0); got != nil {
+		t.Errorf("expected nil past the truncated output, got %q", got)
+	}
+}
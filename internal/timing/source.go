@@ -0,0 +1,65 @@
+package timing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Source produces the timing entries of a recording one at a time, so
+// recordings from recorders other than script(1) (see AsciicastSource and
+// TtyrecSource) can be used wherever Parse's entries are. Next returns
+// io.EOF after the last entry.
+type Source interface {
+	Next() (Entry, error)
+}
+
+// ReadAll returns every entry src produces, in order.
+func ReadAll(src Source) ([]Entry, error) {
+	var entries []Entry
+	for {
+		entry, err := src.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// scriptSource is the Source for a script(1) timing file (see Parse).
+type scriptSource struct {
+	scanner *bufio.Scanner
+	lineNum int
+}
+
+// NewScriptSource returns a Source reading a script(1) timing file, in the
+// advanced or classic format.
+func NewScriptSource(r io.Reader) Source {
+	return &scriptSource{scanner: bufio.NewScanner(r)}
+}
+
+// Next returns the entry on the next non-blank line.
+func (s *scriptSource) Next() (Entry, error) {
+	for s.scanner.Scan() {
+		s.lineNum++
+		line := strings.TrimSpace(s.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		entry, err := parseLine(line)
+		if err != nil {
+			return Entry{}, fmt.Errorf("line %d: %w", s.lineNum, err)
+		}
+		return entry, nil
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return Entry{}, fmt.Errorf("reading timing file: %w", err)
+	}
+	return Entry{}, io.EOF
+}
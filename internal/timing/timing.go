@@ -12,10 +12,13 @@
 //
 //	0.009404 16
 //	0.440731 35
+//
+// Recordings made with other recorders produce the same entries through a
+// Source: AsciicastSource for asciinema casts, TtyrecSource for ttyrec files.
 package timing
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"strconv"
@@ -37,6 +40,11 @@ type Entry struct {
 	Type      EntryType
 	Delay     float64 // Seconds since previous entry
 	ByteCount int     // Number of bytes in this chunk
+
+	// Name and Value are an H or S entry's metadata, e.g. "COLUMNS" and
+	// "120", or "SIGWINCH" and "ROWS=24 COLS=80" (see HeaderMetadata)
+	Name  string
+	Value string
 }
 
 // Command represents a user command extracted from grouped Input entries.
@@ -48,29 +56,7 @@ type Command struct {
 // Parse reads a timing file and returns structured entries.
 // Supports both advanced format (with I/O/H/S prefix) and classic format (no prefix, treated as Output).
 func Parse(r io.Reader) ([]Entry, error) {
-	var entries []Entry
-	scanner := bufio.NewScanner(r)
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-
-		entry, err := parseLine(line)
-		if err != nil {
-			return nil, fmt.Errorf("line %d: %w", lineNum, err)
-		}
-		entries = append(entries, entry)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("reading timing file: %w", err)
-	}
-
-	return entries, nil
+	return ReadAll(NewScriptSource(r))
 }
 
 func parseLine(line string) (Entry, error) {
@@ -83,15 +69,11 @@ func parseLine(line string) (Entry, error) {
 	if len(fields[0]) == 1 && !isDigit(fields[0][0]) {
 		typ := EntryType(fields[0][0])
 
-		// H (Header) and S (Signal) entries may have extra metadata fields
-		// e.g., "H 0.000000 START_TIME 2026-02-03 08:32:06+00:00"
-		// We only care about I and O for command extraction, so parse H/S leniently
+		// H (Header) and S (Signal) entries may carry metadata instead of a
+		// byte count, e.g. "H 0.000000 START_TIME 2026-02-03 08:32:06+00:00".
+		// They don't affect command extraction, so parse them leniently
 		if typ == Header || typ == Signal {
-			delay := 0.0
-			if len(fields) >= 2 {
-				delay, _ = strconv.ParseFloat(fields[1], 64)
-			}
-			return Entry{Type: typ, Delay: delay, ByteCount: 0}, nil
+			return parseMetadataLine(typ, line), nil
 		}
 
 		// I and O entries: TYPE DELAY BYTECOUNT
@@ -102,9 +84,9 @@ func parseLine(line string) (Entry, error) {
 		if err != nil {
 			return Entry{}, fmt.Errorf("invalid delay %q: %w", fields[1], err)
 		}
-		byteCount, err := strconv.Atoi(fields[2])
+		byteCount, err := parseByteCount(fields[2])
 		if err != nil {
-			return Entry{}, fmt.Errorf("invalid byte count %q: %w", fields[2], err)
+			return Entry{}, err
 		}
 		return Entry{Type: typ, Delay: delay, ByteCount: byteCount}, nil
 	}
@@ -114,13 +96,26 @@ func parseLine(line string) (Entry, error) {
 	if err != nil {
 		return Entry{}, fmt.Errorf("invalid delay %q: %w", fields[0], err)
 	}
-	byteCount, err := strconv.Atoi(fields[1])
+	byteCount, err := parseByteCount(fields[1])
 	if err != nil {
-		return Entry{}, fmt.Errorf("invalid byte count %q: %w", fields[1], err)
+		return Entry{}, err
 	}
 	return Entry{Type: Output, Delay: delay, ByteCount: byteCount}, nil
 }
 
+// parseByteCount parses the byte count of an I or O entry, which can't be
+// negative.
+func parseByteCount(field string) (int, error) {
+	byteCount, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte count %q: %w", field, err)
+	}
+	if byteCount < 0 {
+		return 0, fmt.Errorf("invalid byte count %q: negative", field)
+	}
+	return byteCount, nil
+}
+
 func isDigit(b byte) bool {
 	return b >= '0' && b <= '9'
 }
@@ -135,11 +130,14 @@ func isDigit(b byte) bool {
 // across I/O pairs, splitting commands at \r or \n boundaries in the input stream.
 func ExtractCommands(entries []Entry, inputContent []byte) []Command {
 	var commands []Command
-	var inputOffset int    // position in inputContent
-	var outputOffset int   // cumulative output bytes
-	var currentInput []byte // accumulating current command's input
+	var inputOffset int  // position in inputContent
+	var outputOffset int // cumulative output bytes
 	var commandOutputOffset int
 
+	// Accumulates the current command's input. Reused across commands:
+	// finalizeCommand copies what it keeps.
+	currentInput := make([]byte, 0, 256)
+
 	for _, e := range entries {
 		switch e.Type {
 		case Output:
@@ -155,16 +153,20 @@ func ExtractCommands(entries []Entry, inputContent []byte) []Command {
 			}
 			if inputOffset < len(inputContent) {
 				chunk := inputContent[inputOffset:end]
-				for _, b := range chunk {
-					currentInput = append(currentInput, b)
+				for len(chunk) > 0 {
 					// Split on \r or \n — this ends a command
-					if b == '\r' || b == '\n' {
-						cmd := finalizeCommand(currentInput, commandOutputOffset)
-						if cmd != nil {
-							commands = append(commands, *cmd)
-						}
-						currentInput = nil
+					i := bytes.IndexAny(chunk, "\r\n")
+					if i < 0 {
+						currentInput = append(currentInput, chunk...)
+						break
+					}
+					currentInput = append(currentInput, chunk[:i+1]...)
+					cmd := finalizeCommand(currentInput, commandOutputOffset)
+					if cmd != nil {
+						commands = append(commands, *cmd)
 					}
+					currentInput = currentInput[:0]
+					chunk = chunk[i+1:]
 				}
 			}
 			inputOffset = end
@@ -237,7 +239,7 @@ func finalizeCommand(input []byte, outputOffset int) *Command {
 // cursor movement (arrow keys), backspace (0x7F/DEL), readline word
 // movement (ESC b/f), and readline editing commands (Ctrl+K/W/U/A/E).
 func stripEscapeSequences(s string) string {
-	var buf []byte
+	buf := make([]byte, 0, len(s))
 	cursor := 0
 	i := 0
 	for i < len(s) {
@@ -389,6 +391,15 @@ func stripEscapeSequences(s string) string {
 		} else if s[i] < 0x20 {
 			// Skip other control characters
 			i++
+		} else if cursor == len(buf) {
+			// Printable run at end of line (the common case): append it whole
+			j := i + 1
+			for j < len(s) && s[j] >= 0x20 && s[j] != 0x7f {
+				j++
+			}
+			buf = append(buf, s[i:j]...)
+			cursor = len(buf)
+			i = j
 		} else {
 			// Printable character: overwrite at cursor position
 			if cursor < len(buf) {
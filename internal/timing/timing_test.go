@@ -1,6 +1,9 @@
 package timing
 
 import (
+	"math/rand"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -92,6 +95,14 @@ garbage
 	}
 }
 
+func TestParse_NegativeByteCount(t *testing.T) {
+	for _, input := range []string{"O 0.009404 -16\n", "I 0.5 -1\n", "0.009404 -16\n"} {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("Parse(%q) should fail", input)
+		}
+	}
+}
+
 func TestExtractCommands_SimpleCommand(t *testing.T) {
 	// Simulate: some output, then user types "ls\r", then output
 	entries := []Entry{
@@ -235,3 +246,107 @@ func TestExtractCommands_EmptyEnter(t *testing.T) {
 		t.Fatalf("expected 0 commands (empty enter), got %d", len(commands))
 	}
 }
+
+// extractCommandsByteByByte is the original byte-at-a-time ExtractCommands,
+// kept as a reference for the optimized implementation.
+func extractCommandsByteByByte(entries []Entry, inputContent []byte) []Command {
+	var commands []Command
+	var inputOffset, outputOffset, commandOutputOffset int
+	var currentInput []byte
+	for _, e := range entries {
+		switch e.Type {
+		case Output:
+			outputOffset += e.ByteCount
+		case Input:
+			if len(currentInput) == 0 {
+				commandOutputOffset = outputOffset
+			}
+			end := inputOffset + e.ByteCount
+			if end > len(inputContent) {
+				end = len(inputContent)
+			}
+			if inputOffset < len(inputContent) {
+				for _, b := range inputContent[inputOffset:end] {
+					currentInput = append(currentInput, b)
+					if b == '\r' || b == '\n' {
+						if cmd := finalizeCommand(currentInput, commandOutputOffset); cmd != nil {
+							commands = append(commands, *cmd)
+						}
+						currentInput = nil
+					}
+				}
+			}
+			inputOffset = end
+		}
+	}
+	if len(currentInput) > 0 {
+		if cmd := finalizeCommand(currentInput, commandOutputOffset); cmd != nil {
+			commands = append(commands, *cmd)
+		}
+	}
+	return commands
+}
+
+func TestExtractCommands_MatchesByteByByte(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []byte("ls -la\r\n\x1b[A\x7f\x03\tgit status\r\r\n")
+	for iter := 0; iter < 500; iter++ {
+		input := make([]byte, rng.Intn(200))
+		for i := range input {
+			input[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		var entries []Entry
+		for n := rng.Intn(40); n > 0; n-- {
+			typ := Output
+			if rng.Intn(2) == 0 {
+				typ = Input
+			}
+			entries = append(entries, Entry{Type: typ, ByteCount: rng.Intn(30)})
+		}
+
+		got := ExtractCommands(entries, input)
+		want := extractCommandsByteByByte(entries, input)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("input %q entries %v:\ngot  %+v\nwant %+v", input, entries, got, want)
+		}
+	}
+}
+
+// benchmarkInput returns timing entries and input for a session where each
+// command is typed in chunks, plus one large pasted blob.
+func benchmarkInput(commands int, pasteSize int) ([]Entry, []byte) {
+	var entries []Entry
+	var input []byte
+	for i := 0; i < commands; i++ {
+		cmd := []byte("git commit -m 'change number " + strconv.Itoa(i) + "'\r")
+		for len(cmd) > 0 {
+			n := min(len(cmd), 4)
+			entries = append(entries, Entry{Type: Input, ByteCount: n})
+			input = append(input, cmd[:n]...)
+			cmd = cmd[n:]
+		}
+		entries = append(entries, Entry{Type: Output, ByteCount: 200})
+	}
+	paste := []byte(strings.Repeat("pasted text without a newline ", pasteSize/30) + "\r")
+	entries = append(entries, Entry{Type: Input, ByteCount: len(paste)})
+	input = append(input, paste...)
+	return entries, input
+}
+
+func BenchmarkExtractCommands(b *testing.B) {
+	entries, input := benchmarkInput(10000, 4<<20)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ExtractCommands(entries, input)
+	}
+}
+
+func BenchmarkExtractCommands_ByteByByte(b *testing.B) {
+	entries, input := benchmarkInput(10000, 4<<20)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		extractCommandsByteByByte(entries, input)
+	}
+}
@@ -0,0 +1,70 @@
+package timing
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// ttyrecHeaderSize is the size of a ttyrec record header: seconds,
+// microseconds and data length, each a little-endian uint32.
+const ttyrecHeaderSize = 12
+
+// TtyrecSource is a Source reading a ttyrec recording: records of a
+// 12-byte header (timestamp and length) followed by that many bytes of
+// output. Each record becomes an Output entry, its Delay the time since
+// the previous record. ttyrec doesn't record input, so there are no Input
+// entries and no commands to extract.
+//
+// Like AsciicastSource, it collects the recorded bytes as it reads: Output
+// returns what a session.log would hold.
+type TtyrecSource struct {
+	r       io.Reader
+	started bool
+	last    float64 // Timestamp of the previous record, in seconds
+	records int
+	output  []byte
+}
+
+// NewTtyrecSource returns a Source reading the ttyrec recording in r.
+func NewTtyrecSource(r io.Reader) *TtyrecSource {
+	return &TtyrecSource{r: r}
+}
+
+// Next returns the entry for the next record.
+func (s *TtyrecSource) Next() (Entry, error) {
+	var header [ttyrecHeaderSize]byte
+	if _, err := io.ReadFull(s.r, header[:]); err != nil {
+		if err == io.EOF {
+			return Entry{}, io.EOF
+		}
+		return Entry{}, fmt.Errorf("record %d: reading ttyrec header: %w", s.records+1, err)
+	}
+	sec := binary.LittleEndian.Uint32(header[0:4])
+	usec := binary.LittleEndian.Uint32(header[4:8])
+	length := binary.LittleEndian.Uint32(header[8:12])
+
+	// Read through a limit, so a corrupt length can't allocate gigabytes
+	data, err := io.ReadAll(io.LimitReader(s.r, int64(length)))
+	if err == nil && len(data) < int(length) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("record %d: reading %d bytes of ttyrec data: %w", s.records+1, length, err)
+	}
+	s.records++
+	s.output = append(s.output, data...)
+
+	t := float64(sec) + float64(usec)/1e6
+	delay := 0.0
+	if s.started {
+		delay = max(0, t-s.last)
+	}
+	s.started, s.last = true, t
+	return Entry{Type: Output, Delay: delay, ByteCount: len(data)}, nil
+}
+
+// Output returns the output bytes of the records read so far.
+func (s *TtyrecSource) Output() []byte {
+	return s.output
+}
@@ -0,0 +1,58 @@
+package timing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// ttyrecRecord encodes one ttyrec record.
+func ttyrecRecord(sec, usec uint32, data string) []byte {
+	var header [ttyrecHeaderSize]byte
+	binary.LittleEndian.PutUint32(header[0:4], sec)
+	binary.LittleEndian.PutUint32(header[4:8], usec)
+	binary.LittleEndian.PutUint32(header[8:12], uint32(len(data)))
+	return append(header[:], data...)
+}
+
+func TestTtyrecSource(t *testing.T) {
+	var rec bytes.Buffer
+	rec.Write(ttyrecRecord(1700000000, 0, "$ ls\r\n"))
+	rec.Write(ttyrecRecord(1700000000, 500000, "file1\r\n"))
+	rec.Write(ttyrecRecord(1700000002, 0, "$ "))
+
+	src := NewTtyrecSource(&rec)
+	entries, err := ReadAll(src)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", entries)
+	}
+	wantDelays := []float64{0, 0.5, 1.5}
+	for i, e := range entries {
+		if e.Type != Output {
+			t.Errorf("entry %d type = %v, want Output", i, e.Type)
+		}
+		if math.Abs(e.Delay-wantDelays[i]) > 1e-6 {
+			t.Errorf("entry %d delay = %v, want %v", i, e.Delay, wantDelays[i])
+		}
+	}
+	if entries[1].ByteCount != 7 {
+		t.Errorf("entry 1 byte count = %d, want 7", entries[1].ByteCount)
+	}
+	if got := string(src.Output()); got != "$ ls\r\nfile1\r\n$ " {
+		t.Errorf("Output() = %q", got)
+	}
+}
+
+func TestTtyrecSource_Truncated(t *testing.T) {
+	record := ttyrecRecord(1, 0, "hello")
+	if _, err := ReadAll(NewTtyrecSource(bytes.NewReader(record[:len(record)-2]))); err == nil {
+		t.Error("expected an error for a truncated record")
+	}
+	if _, err := ReadAll(NewTtyrecSource(bytes.NewReader(record[:5]))); err == nil {
+		t.Error("expected an error for a truncated header")
+	}
+}
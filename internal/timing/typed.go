@@ -0,0 +1,102 @@
+package timing
+
+import "strings"
+
+// keyNames names the keys whose escape sequences ReconstructInput
+// recognizes, by what follows ESC [ (CSI) or ESC O (SS3). Bracketed paste
+// markers are named "" and left out.
+var keyNames = map[string]string{
+	"A": "<Up>", "B": "<Down>", "C": "<Right>", "D": "<Left>",
+	"H": "<Home>", "F": "<End>",
+	"1~": "<Home>", "7~": "<Home>", "4~": "<End>", "8~": "<End>",
+	"2~": "<Insert>", "3~": "<Delete>", "5~": "<PageUp>", "6~": "<PageDown>",
+	"P": "<F1>", "Q": "<F2>", "R": "<F3>", "S": "<F4>",
+	"200~": "", "201~": "",
+}
+
+// ReconstructInput returns what was typed during a recording, from the
+// Input entries and inputContent (the session.input bytes, with metadata
+// stripped), with control keys annotated so they can be read: Enter as
+// "<Enter>" and a line break, Ctrl+C as "^C", Backspace as "<Backspace>",
+// Tab as "<Tab>", arrow and editing keys as "<Up>", "<Delete>" etc. and
+// Alt+b as "<Alt-b>". Printable text is kept as typed.
+//
+// Each Input entry is one read of the terminal, so a key's escape sequence
+// is looked for within it: an ESC ending an entry is the Esc key.
+func ReconstructInput(entries []Entry, inputContent []byte) string {
+	var result strings.Builder
+	inputOffset := 0
+	for _, e := range entries {
+		if e.Type != Input || inputOffset >= len(inputContent) {
+			continue
+		}
+		end := min(inputOffset+e.ByteCount, len(inputContent))
+		writeKeys(&result, string(inputContent[inputOffset:end]))
+		inputOffset = end
+	}
+	return result.String()
+}
+
+// writeKeys writes the keys in chunk, one read of terminal input, to result
+// as ReconstructInput annotates them.
+func writeKeys(result *strings.Builder, chunk string) {
+	for i := 0; i < len(chunk); {
+		c := chunk[i]
+		switch {
+		case c == 0x1b:
+			n, name := escapeKey(chunk[i:])
+			result.WriteString(name)
+			i += n
+			continue
+		case c == '\r' || c == '\n':
+			result.WriteString("<Enter>\n")
+		case c == '\t':
+			result.WriteString("<Tab>")
+		case c == 0x7f || c == 0x08:
+			result.WriteString("<Backspace>")
+		case c < 0x20:
+			result.WriteByte('^')
+			result.WriteByte(c + '@')
+		default:
+			result.WriteByte(c)
+		}
+		i++
+	}
+}
+
+// escapeKey returns the length of the key sequence starting with ESC at
+// the start of s, and its annotation.
+func escapeKey(s string) (int, string) {
+	if len(s) == 1 || s[1] == 0x1b {
+		return 1, "<Esc>"
+	}
+	switch s[1] {
+	case '[':
+		// CSI: parameter and intermediate bytes, then a final byte
+		j := 2
+		for j < len(s) && s[j] >= 0x20 && s[j] <= 0x3f {
+			j++
+		}
+		if j == len(s) {
+			return len(s), "<Esc>" + s[1:]
+		}
+		if name, ok := keyNames[s[2:j+1]]; ok {
+			return j + 1, name
+		}
+		return j + 1, "<Esc>" + s[1:j+1]
+	case 'O':
+		if len(s) > 2 {
+			if name, ok := keyNames[s[2:3]]; ok {
+				return 3, name
+			}
+			return 3, "<Esc>" + s[1:3]
+		}
+		return 2, "<Alt-O>"
+	case 0x7f:
+		return 2, "<Alt-Backspace>"
+	}
+	if s[1] < 0x20 {
+		return 1, "<Esc>"
+	}
+	return 2, "<Alt-" + s[1:2] + ">"
+}
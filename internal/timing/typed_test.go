@@ -0,0 +1,46 @@
+package timing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReconstructInput(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string // One Input entry each
+		want   string
+	}{
+		{"printable", []string{"l", "s", "\r"}, "ls<Enter>\n"},
+		{"ctrl-c", []string{"sleep 10\r", "\x03"}, "sleep 10<Enter>\n^C"},
+		{"backspace and tab", []string{"gti", "\x7f\x7f", "it", "\t", "\r"}, "gti<Backspace><Backspace>it<Tab><Enter>\n"},
+		{"arrow keys", []string{"\x1b[A", "\x1b[D", "\x1bOB", "\r"}, "<Up><Left><Down><Enter>\n"},
+		{"delete and alt", []string{"\x1b[3~", "\x1bb", "\x1b\x7f"}, "<Delete><Alt-b><Alt-Backspace>"},
+		{"lone escape", []string{"\x1b", ":wq\r"}, "<Esc>:wq<Enter>\n"},
+		{"bracketed paste", []string{"\x1b[200~echo hi\x1b[201~", "\r"}, "echo hi<Enter>\n"},
+		{"unknown sequence", []string{"\x1b[15~"}, "<Esc>[15~"},
+		{"utf-8", []string{"echo héllo\r"}, "echo héllo<Enter>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []Entry
+			for _, chunk := range tt.chunks {
+				entries = append(entries,
+					Entry{Type: Input, ByteCount: len(chunk)},
+					Entry{Type: Output, ByteCount: 3})
+			}
+			input := []byte(strings.Join(tt.chunks, ""))
+			if got := ReconstructInput(entries, input); got != tt.want {
+				t.Errorf("ReconstructInput() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconstructInput_ShortInput(t *testing.T) {
+	// Entries counting more input than the file holds
+	entries := []Entry{{Type: Input, ByteCount: 10}, {Type: Input, ByteCount: 5}}
+	if got := ReconstructInput(entries, []byte("ls\r")); got != "ls<Enter>\n" {
+		t.Errorf("ReconstructInput() = %q", got)
+	}
+}
@@ -0,0 +1,35 @@
+package timing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOutputMismatch is returned by Validate when the timing file doesn't
+// account for the session output, e.g. the files come from different runs
+// or one of them was truncated.
+var ErrOutputMismatch = errors.New("timing output does not match session output")
+
+// minTolerance is the smallest difference in bytes Validate tolerates, for
+// the line endings and blank lines around the script header and footer.
+const minTolerance = 256
+
+// Validate checks that the Output entries add up to outputLen, the length of
+// the session output with its script header and footer stripped. A
+// difference of up to 1% (at least 256 bytes) is tolerated; beyond that the
+// byte offsets in the timing file can't be trusted to point at the right
+// lines, and an error wrapping ErrOutputMismatch is returned.
+func Validate(entries []Entry, outputLen int) error {
+	recorded := 0
+	for _, e := range entries {
+		if e.Type == Output {
+			recorded += e.ByteCount
+		}
+	}
+
+	tolerance := max(minTolerance, outputLen/100)
+	if diff := recorded - outputLen; diff > tolerance || -diff > tolerance {
+		return fmt.Errorf("%w: timing records %d output bytes, session has %d", ErrOutputMismatch, recorded, outputLen)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package timing
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	entries := []Entry{
+		{Type: Output, ByteCount: 4000},
+		{Type: Input, ByteCount: 500},
+		{Type: Output, ByteCount: 6000},
+		{Type: Header, ByteCount: 0},
+	}
+
+	tests := []struct {
+		name      string
+		outputLen int
+		wantErr   bool
+	}{
+		{"exact", 10000, false},
+		{"within tolerance", 10100, false},
+		{"small recording within minimum tolerance", 9800, false},
+		{"truncated log", 5000, true},
+		{"log from a longer run", 20000, true},
+	}
+	for _, tt := range tests {
+		err := Validate(entries, tt.outputLen)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate(%d) = %v, wantErr %v", tt.name, tt.outputLen, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrOutputMismatch) {
+			t.Errorf("%s: error should wrap ErrOutputMismatch, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidate_ErrorDescribesMismatch(t *testing.T) {
+	err := Validate([]Entry{{Type: Output, ByteCount: 5000}}, 100)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"5000", "100"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should mention %s", err, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package toc
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/choonkeat/record-tui/internal/session"
+)
+
+// DefaultPromptPattern matches the default prompts of common shells at the
+// start of a line: bash's "$ " (or "# " as root) and zsh's "% ", optionally
+// after a word such as "user@host:~", and fish's "user@host ~> ". It's a
+// guess, so a line like "50% done" also matches.
+var DefaultPromptPattern = regexp.MustCompile(`^(?:\S*[$%]|\S+#|\S+@\S+ \S*>) `)
+
+// FromPrompts computes coarse TOC entries from session output alone, for
+// recordings without the timing and input files FromCommands needs: each
+// line where promptPattern (nil uses DefaultPromptPattern) matches the
+// line's plain text (see session.PlainText), so without colors or other
+// escape sequences, is an entry, labeled with what follows the match. Bare prompts, with nothing typed after them, are
+// skipped. Lines are numbered as for FromCommands, skipping the script
+// header and footer of each session. The output is read a line at a time.
+func FromPrompts(r io.Reader, promptPattern *regexp.Regexp) []Entry {
+	if promptPattern == nil {
+		promptPattern = DefaultPromptPattern
+	}
+
+	var entries []Entry
+	lineCount := 0
+	blanks := 0
+	var meta session.MetadataLines
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && line == "" {
+			break
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch meta.Kind(line) {
+		case session.LineHeader:
+			blanks = 0
+			continue
+		case session.LineFooter:
+			continue
+		case session.LineBlank:
+			blanks++
+			continue
+		}
+		lineCount += blanks
+		blanks = 0
+
+		plain := session.PlainText(line)
+		if loc := promptPattern.FindStringIndex(plain); loc != nil {
+			if label := strings.TrimSpace(plain[loc[1]:]); label != "" {
+				entries = append(entries, Entry{Label: label, Line: lineCount})
+			}
+		}
+		lineCount++
+	}
+	return entries
+}
+
+// TrimTrailingPrompt removes the prompt a recording usually ends waiting
+// at: if the last non-blank line is a bare prompt, one promptPattern (nil
+// uses DefaultPromptPattern) matches as for FromPrompts with nothing typed
+// after it, it is cut along with the blank lines after it. Anything else,
+// such as a half-typed "$ exit", is left alone, as is content that would
+// be left blank.
+func TrimTrailingPrompt(content string, promptPattern *regexp.Regexp) string {
+	if promptPattern == nil {
+		promptPattern = DefaultPromptPattern
+	}
+
+	end := len(content)
+	for {
+		start := strings.LastIndexByte(content[:end], '\n') + 1
+		plain := session.PlainText(content[start:end])
+		if strings.TrimSpace(plain) == "" {
+			if start == 0 {
+				return content
+			}
+			end = start - 1
+			continue
+		}
+		loc := promptPattern.FindStringIndex(plain)
+		if loc == nil || strings.TrimSpace(plain[loc[1]:]) != "" {
+			return content
+		}
+		if strings.TrimSpace(session.PlainText(content[:start])) == "" {
+			return content
+		}
+		return content[:start]
+	}
+}
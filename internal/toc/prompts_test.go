@@ -0,0 +1,149 @@
+package toc
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/choonkeat/record-tui/internal/session"
+)
+
+func TestFromPrompts(t *testing.T) {
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ ls\r\nfile1\r\nfile2\r\n" +
+		"\x1b[32muser@host:~/app$\x1b[0m npm test\r\nPASS\r\n" +
+		"% git stash\r\n" +
+		"$ \r\n"
+
+	got := FromPrompts(strings.NewReader(content), nil)
+	want := []Entry{
+		{Label: "ls", Line: 0},
+		{Label: "npm test", Line: 3},
+		{Label: "git stash", Line: 5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromPrompts = %+v, want %+v", got, want)
+	}
+}
+
+func TestFromPrompts_AppendedSessions(t *testing.T) {
+	// Two `script -a` invocations writing to the same log: lines are
+	// numbered as in session.StripMetadata's output
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ echo one\r\none\r\n" +
+		"\r\nScript done on 2026-01-12 06:42:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\n" +
+		"Script started on 2026-01-12 07:00:00+00:00 [COMMAND=\"bash\"]\n" +
+		"$ echo two\r\ntwo\r\n" +
+		"\r\nScript done on 2026-01-12 07:01:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+
+	got := FromPrompts(strings.NewReader(content), nil)
+	want := []Entry{
+		{Label: "echo one", Line: 0},
+		{Label: "echo two", Line: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FromPrompts = %+v, want %+v", got, want)
+	}
+	lines := strings.Split(session.StripMetadata(content), "\n")
+	for _, e := range got {
+		if !strings.Contains(lines[e.Line], e.Label) {
+			t.Errorf("%q at line %d, cleaned line is %q", e.Label, e.Line, lines[e.Line])
+		}
+	}
+}
+
+func TestFromPrompts_DefaultShells(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"bash", "user@host:~/app$ make", "make"},
+		{"bash as root", "root@host:~# apt update", "apt update"},
+		{"zsh", "host% ls -la", "ls -la"},
+		{"fish", "user@host ~/app> go test", "go test"},
+		{"colored", "\x1b[1;32muser@host\x1b[0m:\x1b[34m~\x1b[0m$ \x1b[1mmake\x1b[0m", "make"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromPrompts(strings.NewReader(tt.line+"\r\n"), nil)
+			if len(got) != 1 || got[0].Label != tt.want {
+				t.Errorf("FromPrompts(%q) = %+v, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromPrompts_EditedLine(t *testing.T) {
+	got := FromPrompts(strings.NewReader("$ mkae\b\b\bake\r\n"), nil)
+	want := []Entry{{Label: "make", Line: 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromPrompts = %+v, want %+v", got, want)
+	}
+}
+
+func TestFromPrompts_Pattern(t *testing.T) {
+	content := "$ not a prompt here\r\n>>> print(1)\r\n1\r\n>>> exit()\r\n"
+
+	got := FromPrompts(strings.NewReader(content), regexp.MustCompile(`^>>> `))
+	want := []Entry{
+		{Label: "print(1)", Line: 1},
+		{Label: "exit()", Line: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromPrompts = %+v, want %+v", got, want)
+	}
+}
+
+func TestFromPrompts_CustomPrompt(t *testing.T) {
+	content := "\x1b[1;32m➜  \x1b[36m~\x1b[0m git status\r\nclean\r\n" +
+		"$ not my prompt\r\n" +
+		"➜  ~/app npm test\r\n"
+
+	got := FromPrompts(strings.NewReader(content), regexp.MustCompile(`^➜ +\S+ `))
+	want := []Entry{
+		{Label: "git status", Line: 0},
+		{Label: "npm test", Line: 3},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromPrompts = %+v, want %+v", got, want)
+	}
+}
+
+func TestFromPrompts_NoPrompts(t *testing.T) {
+	if got := FromPrompts(strings.NewReader("hello\nworld\n"), nil); got != nil {
+		t.Errorf("FromPrompts = %+v, want nil", got)
+	}
+}
+
+func TestTrimTrailingPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"bare prompt", "$ make\r\nok\r\n$ ", "$ make\r\nok\r\n"},
+		{"blank lines after", "$ make\r\nok\r\nuser@host:~$ \r\n\r\n", "$ make\r\nok\r\n"},
+		{"colored", "ok\r\n\x1b[32muser@host:~$\x1b[0m \x1b[0m", "ok\r\n"},
+		{"half-typed command", "ok\r\n$ exi", "ok\r\n$ exi"},
+		{"output last", "$ make\r\nok\r\n", "$ make\r\nok\r\n"},
+		{"only a prompt", "$ \r\n", "$ \r\n"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrimTrailingPrompt(tt.content, nil); got != tt.want {
+				t.Errorf("TrimTrailingPrompt(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+
+	custom := regexp.MustCompile(`^➜ +\S+ `)
+	if got := TrimTrailingPrompt("ok\r\n➜  ~ ", custom); got != "ok\r\n" {
+		t.Errorf("custom prompt: got %q", got)
+	}
+	if got := TrimTrailingPrompt("ok\r\n$ ", custom); got != "ok\r\n$ " {
+		t.Errorf("custom prompt shouldn't trim $: got %q", got)
+	}
+}
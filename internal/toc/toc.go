@@ -5,10 +5,13 @@ package toc
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"io"
 	"sort"
 	"strings"
 
+	"github.com/choonkeat/record-tui/internal/session"
 	"github.com/choonkeat/record-tui/internal/timing"
 )
 
@@ -18,13 +21,14 @@ type Entry struct {
 	Line  int    // Line number in the output (0-indexed, for xterm.js scrolling)
 }
 
-// isScriptHeader returns true for lines added by the `script` command at the top.
-func isScriptHeader(line string) bool {
-	return strings.HasPrefix(line, "Script started on") || strings.HasPrefix(line, "Command:")
-}
-
 // FromCommands computes TOC entries by streaming through an io.Reader.
-// Skips script header lines. Uses constant memory regardless of recording size.
+// Skips script header and footer lines, and the blank lines ending a
+// session, of each session of an appended log, numbering lines as in
+// session.StripMetadata's output. Uses constant memory regardless of
+// recording size.
+// Lines are counted by newline, and by the index (ESC D) and reverse index
+// (ESC M) sequences some programs scroll with instead: ESC D moves down a
+// line as a newline does, ESC M up one (never above line 0).
 //
 // Performance: O(max_offset) in streaming I/O, constant memory.
 func FromCommands(commands []timing.Command, r io.Reader) []Entry {
@@ -52,35 +56,50 @@ func FromCommands(commands []timing.Command, r io.Reader) []Entry {
 	entries := make([]Entry, len(commands))
 	bytePos := 0
 	lineCount := 0
+	blanks := 0 // Blank lines not counted yet: those ending a session aren't
 	cmdIdx := 0
-	inHeader := true
+	var meta session.MetadataLines
 
 	scanner := bufio.NewScanner(r)
 	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(scanLinesKeepCR)
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		// Skip script header lines at the start
-		if inHeader && isScriptHeader(line) {
+		// Skip script header and footer lines, of every session in an
+		// appended log
+		kind := meta.Kind(line)
+		if kind == session.LineHeader || kind == session.LineFooter {
+			if kind == session.LineHeader {
+				blanks = 0
+			}
 			// Account for the bytes consumed (line + newline)
 			bytePos += len(line) + 1
 			continue
 		}
-		inHeader = false
+		if kind == session.LineContent {
+			lineCount += blanks
+			blanks = 0
+		}
 
 		// Process bytes in this line + the newline
 		lineEnd := bytePos + len(line) + 1
 
 		// Assign entries for any commands whose offset falls within this line
 		for cmdIdx < len(sorted) && sorted[cmdIdx].cmd.OutputByteOffset < lineEnd {
+			before := min(max(sorted[cmdIdx].cmd.OutputByteOffset-bytePos, 0), len(line))
 			entries[sorted[cmdIdx].origIndex] = Entry{
 				Label: sorted[cmdIdx].cmd.Text,
-				Line:  lineCount,
+				Line:  indexLines(lineCount+blanks, line[:before]),
 			}
 			cmdIdx++
 		}
 
-		lineCount++
+		if kind == session.LineBlank {
+			blanks++
+		} else {
+			lineCount = indexLines(lineCount, line) + 1
+		}
 		bytePos = lineEnd
 
 		// Stop early if we've passed all command offsets
@@ -99,3 +118,91 @@ func FromCommands(commands []timing.Command, r io.Reader) []Entry {
 
 	return entries
 }
+
+// indexLines returns the line the cursor is on after text, from line, where
+// text has no newlines but may move it with index (ESC D) or reverse index
+// (ESC M) sequences.
+func indexLines(line int, text string) int {
+	for {
+		i := strings.IndexByte(text, '\x1b')
+		if i < 0 || i+1 >= len(text) {
+			return line
+		}
+		switch text[i+1] {
+		case 'D':
+			line++
+		case 'M':
+			line = max(line-1, 0)
+		}
+		text = text[i+1:]
+	}
+}
+
+// scanLinesKeepCR is bufio.ScanLines without dropping the \r of a \r\n
+// line ending, so line lengths add up to the byte offsets a timing file
+// counts.
+func scanLinesKeepCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// CollapseRepeats merges runs of consecutive entries with the same label
+// (e.g. `npm test` run ten times while debugging) into the run's first
+// entry, labeled with how many there were: "npm test (×10)". Entries with
+// the same label separated by other entries are kept apart.
+func CollapseRepeats(entries []Entry) []Entry {
+	var result []Entry
+	for i := 0; i < len(entries); {
+		j := i + 1
+		for j < len(entries) && entries[j].Label == entries[i].Label {
+			j++
+		}
+		entry := entries[i]
+		if n := j - i; n > 1 {
+			entry.Label = fmt.Sprintf("%s (×%d)", entry.Label, n)
+		}
+		result = append(result, entry)
+		i = j
+	}
+	return result
+}
+
+// LineTimes returns when each output line first appeared, in seconds from
+// the start of the recording: element i is the time of line i (0-indexed,
+// as Entry.Line), taken from the Output entry that first wrote to it. r is
+// the session output the entries account for, as for FromCommands, which
+// locates each entry's last byte.
+//
+// Returns nil if the entries have no output.
+func LineTimes(entries []timing.Entry, r io.Reader) []float64 {
+	var ends []timing.Command
+	var times []float64
+	var elapsed float64
+	offset := 0
+	for _, e := range entries {
+		elapsed += e.Delay
+		if e.Type != timing.Output || e.ByteCount == 0 {
+			continue
+		}
+		offset += e.ByteCount
+		ends = append(ends, timing.Command{OutputByteOffset: offset - 1})
+		times = append(times, elapsed)
+	}
+	if len(ends) == 0 {
+		return nil
+	}
+
+	// Lines up to where each entry's output ends appeared by its time
+	var result []float64
+	for i, e := range FromCommands(ends, r) {
+		for len(result) <= e.Line {
+			result = append(result, times[i])
+		}
+	}
+	return result
+}
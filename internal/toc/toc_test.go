@@ -63,6 +63,16 @@ func TestFromCommands_ZeroOffset(t *testing.T) {
 	}
 }
 
+func TestFromCommands_CRLF(t *testing.T) {
+	// Offsets count the \r of each line ending
+	content := "$ ls\r\nfile1\r\nfile2\r\nfile3\r\n$ pwd\r\n/tmp\r\n"
+
+	entries := FromCommands([]timing.Command{{Text: "pwd", OutputByteOffset: 29}}, strings.NewReader(content))
+	if len(entries) != 1 || entries[0].Line != 4 {
+		t.Errorf("got %+v, want pwd on line 4", entries)
+	}
+}
+
 func TestFromCommands_SkipsScriptHeader(t *testing.T) {
 	content := "Script started on 2026-01-12 06:41:43+00:00\n$ \nls output\n"
 
@@ -79,6 +89,25 @@ func TestFromCommands_SkipsScriptHeader(t *testing.T) {
 	}
 }
 
+func TestFromCommands_AppendedSessions(t *testing.T) {
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		"$ echo one\r\none\r\n" +
+		"\r\nScript done on 2026-01-12 06:42:00+00:00 [COMMAND_EXIT_CODE=\"0\"]\n" +
+		"Script started on 2026-01-12 07:00:00+00:00 [COMMAND=\"bash\"]\n" +
+		"$ echo two\r\ntwo\r\n"
+
+	commands := []timing.Command{
+		{Text: "echo two", OutputByteOffset: strings.Index(content, "$ echo two")},
+	}
+
+	// As in session.StripMetadata's output, without the header and footer
+	// between the sessions, nor the blank line before the footer
+	entries := FromCommands(commands, strings.NewReader(content))
+	if len(entries) != 1 || entries[0].Line != 2 {
+		t.Errorf("got %+v, want line 2", entries)
+	}
+}
+
 func TestFromCommands_LargeContent(t *testing.T) {
 	var b strings.Builder
 	b.WriteString("Script started on 2026-01-12\n")
@@ -97,3 +126,106 @@ func TestFromCommands_LargeContent(t *testing.T) {
 		t.Errorf("got line %d, want ~5000", entries[0].Line)
 	}
 }
+
+func TestCollapseRepeats_Consecutive(t *testing.T) {
+	entries := []Entry{
+		{Label: "make", Line: 0},
+		{Label: "npm test", Line: 3},
+		{Label: "npm test", Line: 9},
+		{Label: "npm test", Line: 15},
+		{Label: "git push", Line: 20},
+	}
+
+	got := CollapseRepeats(entries)
+	want := []Entry{
+		{Label: "make", Line: 0},
+		{Label: "npm test (×3)", Line: 3},
+		{Label: "git push", Line: 20},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCollapseRepeats_Separated(t *testing.T) {
+	entries := []Entry{
+		{Label: "npm test", Line: 0},
+		{Label: "vim main.go", Line: 5},
+		{Label: "npm test", Line: 6},
+	}
+
+	got := CollapseRepeats(entries)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", got)
+	}
+	for i := range entries {
+		if got[i] != entries[i] {
+			t.Errorf("entry %d: got %+v, want %+v", i, got[i], entries[i])
+		}
+	}
+}
+
+func TestLineTimes(t *testing.T) {
+	content := "$ ls\nfile1\nfile2\n$ npm test\nPASS\n"
+	entries := []timing.Entry{
+		{Type: timing.Output, Delay: 0.5, ByteCount: 5}, // "$ ls\n"
+		{Type: timing.Input, Delay: 1, ByteCount: 1},
+		{Type: timing.Output, Delay: 0.5, ByteCount: 3},  // "fil"
+		{Type: timing.Output, Delay: 0.25, ByteCount: 9}, // "e1\nfile2\n"
+		{Type: timing.Output, Delay: 2, ByteCount: 16},   // "$ npm test\nPASS\n"
+	}
+
+	got := LineTimes(entries, strings.NewReader(content))
+	want := []float64{0.5, 2, 2.25, 4.25, 4.25}
+	if len(got) != len(want) {
+		t.Fatalf("LineTimes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: time %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLineTimes_NoOutput(t *testing.T) {
+	entries := []timing.Entry{{Type: timing.Input, Delay: 1, ByteCount: 1}}
+	if got := LineTimes(entries, strings.NewReader("x\n")); got != nil {
+		t.Errorf("LineTimes = %v, want nil", got)
+	}
+}
+
+func TestFromCommands_IndexSequences(t *testing.T) {
+	// A pager scrolling with ESC D (index) instead of newlines
+	content := "$ less\r\n\x1bDone\x1bDtwo\x1bDthree\r\n$ ls\r\n"
+
+	offset := strings.Index(content, "ls")
+	entries := FromCommands([]timing.Command{
+		{Text: "two", OutputByteOffset: strings.Index(content, "two")},
+		{Text: "ls", OutputByteOffset: offset},
+	}, strings.NewReader(content))
+	if len(entries) != 2 || entries[0].Line != 3 || entries[1].Line != 5 {
+		t.Errorf("got %+v, want two on line 3 and ls on line 5", entries)
+	}
+}
+
+func TestFromCommands_ReverseIndex(t *testing.T) {
+	// ESC M moves up a line, but never above the first
+	content := "a\r\nb\r\n\x1bM\x1bMtop\r\n\x1bM\x1bM\x1bMx\r\n$ ls\r\n"
+
+	entries := FromCommands([]timing.Command{
+		{Text: "top", OutputByteOffset: strings.Index(content, "top")},
+		{Text: "x", OutputByteOffset: strings.Index(content, "x")},
+		{Text: "ls", OutputByteOffset: strings.Index(content, "ls")},
+	}, strings.NewReader(content))
+	want := []int{0, 0, 1}
+	for i, e := range entries {
+		if e.Line != want[i] {
+			t.Errorf("%s: got line %d, want %d", e.Label, e.Line, want[i])
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Package websocket implements the server side of the WebSocket protocol
+// (RFC 6455), as far as streaming a live recording to a browser needs: the
+// opening handshake, sending binary messages, and answering the client's
+// ping and close frames. Messages from the client are read and discarded.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// acceptGUID is appended to the client's key to compute Sec-WebSocket-Accept.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// maxControlPayload is the largest payload a control frame may carry.
+const maxControlPayload = 125
+
+// Conn is a server-side WebSocket connection.
+type Conn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+	mu   sync.Mutex // serializes frame writes
+
+	closeSent bool // a close frame was sent: nothing may follow it
+}
+
+// Options configures Upgrade.
+type Options struct {
+	// AllowedOrigins are the hosts ("example.com", "example.com:8443"),
+	// besides the request's own, whose pages may connect. "*" allows any.
+	AllowedOrigins []string
+}
+
+// Upgrade completes the opening handshake for a WebSocket request and
+// takes over its connection. If r isn't a valid WebSocket request, it
+// replies with 400 Bad Request and returns an error. A request from a
+// browser page on another host than r's own, by its Origin header, gets
+// 403 Forbidden unless Options.AllowedOrigins has that host.
+func Upgrade(w http.ResponseWriter, r *http.Request, opts ...Options) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	switch {
+	case r.Method != http.MethodGet:
+		return nil, badRequest(w, "websocket: method must be GET")
+	case !headerHasToken(r.Header, "Connection", "upgrade"):
+		return nil, badRequest(w, "websocket: missing Connection: Upgrade")
+	case !headerHasToken(r.Header, "Upgrade", "websocket"):
+		return nil, badRequest(w, "websocket: missing Upgrade: websocket")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		return nil, badRequest(w, "websocket: unsupported version")
+	case key == "":
+		return nil, badRequest(w, "websocket: missing Sec-WebSocket-Key")
+	}
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if !originAllowed(r, o.AllowedOrigins) {
+		http.Error(w, "websocket: origin not allowed", http.StatusForbidden)
+		return nil, fmt.Errorf("websocket: origin %q not allowed", r.Header.Get("Origin"))
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket: connection can't be hijacked", http.StatusInternalServerError)
+		return nil, errors.New("websocket: response writer doesn't support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	return &Conn{conn: conn, rw: rw}, nil
+}
+
+// badRequest replies with 400 Bad Request and returns msg as an error.
+func badRequest(w http.ResponseWriter, msg string) error {
+	http.Error(w, msg, http.StatusBadRequest)
+	return errors.New(msg)
+}
+
+// originAllowed reports whether r may connect by its Origin header: a
+// request without one doesn't come from a browser page, and one from a
+// page on r's own host or an allowed host may.
+func originAllowed(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false // e.g. "null", from a sandboxed or file:// page
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, host := range allowed {
+		if host == "*" || strings.EqualFold(host, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerHasToken reports whether the comma-separated header name contains
+// token, ignoring case (e.g. "Connection: keep-alive, Upgrade").
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acceptKey returns the Sec-WebSocket-Accept value for a client's
+// Sec-WebSocket-Key.
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WriteBinary sends p as a single binary message.
+func (c *Conn) WriteBinary(p []byte) error {
+	return c.writeFrame(opBinary, p)
+}
+
+// writeFrame sends one unfragmented, unmasked frame (servers never mask).
+func (c *Conn) writeFrame(opcode byte, p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeSent {
+		return errors.New("websocket: connection closed")
+	}
+	c.closeSent = opcode == opClose
+
+	header := []byte{0x80 | opcode, 0}
+	switch n := len(p); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(p); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// Discard reads frames from the client until the connection ends,
+// answering pings and closes and dropping data messages. It returns nil
+// once the client closes the connection, or the error that ended it.
+// Run it alongside writes to notice a client going away.
+func (c *Conn) Discard() error {
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opClose:
+			// Echo the status code, as the closing handshake expects
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			c.writeFrame(opClose, payload)
+			return nil
+		}
+	}
+}
+
+// readFrame reads one frame from the client. Data frame payloads are
+// skipped (returned as nil); control frame payloads are unmasked.
+func (c *Conn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if !masked {
+		return 0, nil, errors.New("websocket: client frame is not masked")
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	switch opcode {
+	case opContinuation, opText, opBinary:
+		if _, err := io.CopyN(io.Discard, c.rw, int64(length)); err != nil {
+			return 0, nil, err
+		}
+		return opcode, nil, nil
+	case opClose, opPing, opPong:
+		if length > maxControlPayload {
+			return 0, nil, errors.New("websocket: control frame too long")
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.rw, payload); err != nil {
+			return 0, nil, err
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+		return opcode, payload, nil
+	default:
+		return 0, nil, fmt.Errorf("websocket: unknown opcode %#x", opcode)
+	}
+}
+
+// Close sends a close frame and closes the connection.
+func (c *Conn) Close() error {
+	c.writeFrame(opClose, nil)
+	return c.conn.Close()
+}
@@ -0,0 +1,156 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455 section 1.3
+	got := acceptKey("dGhlIHNhbXBsZSBub25jZQ==")
+	if want := "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="; got != want {
+		t.Errorf("acceptKey = %q, want %q", got, want)
+	}
+}
+
+func TestUpgrade_RejectsPlainRequest(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := Upgrade(w, r); err == nil {
+			t.Error("Upgrade should fail for a plain HTTP request")
+		}
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestUpgrade_Origin(t *testing.T) {
+	tests := []struct {
+		origin  string
+		allowed []string
+		want    int
+	}{
+		{"http://evil.example", nil, http.StatusForbidden},
+		{"null", nil, http.StatusForbidden},
+		{"http://evil.example", []string{"other.example"}, http.StatusForbidden},
+		{"http://example.com", nil, http.StatusInternalServerError}, // Allowed: the recorder can't be hijacked
+		{"https://EXAMPLE.com", nil, http.StatusInternalServerError},
+		{"", nil, http.StatusInternalServerError},
+		{"http://viewer.example", []string{"viewer.example"}, http.StatusInternalServerError},
+		{"http://evil.example", []string{"*"}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://example.com/live", nil)
+		r.Header.Set("Connection", "Upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		Upgrade(rec, r, Options{AllowedOrigins: tt.allowed})
+		if rec.Code != tt.want {
+			t.Errorf("Origin %q, allowed %v: status = %d, want %d", tt.origin, tt.allowed, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestConn_WriteBinary(t *testing.T) {
+	large := bytes.Repeat([]byte("x"), 70000) // needs the 64-bit length
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+		for _, msg := range [][]byte{[]byte("hello"), bytes.Repeat([]byte("y"), 300), large} {
+			if err := conn.WriteBinary(msg); err != nil {
+				t.Errorf("WriteBinary failed: %v", err)
+				return
+			}
+		}
+		if err := conn.Discard(); err != nil {
+			t.Errorf("Discard should end cleanly on close, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	nc, r := dial(t, server.URL)
+	defer nc.Close()
+
+	for _, want := range []int{5, 300, len(large)} {
+		opcode, payload := readFrame(t, r)
+		if opcode != opBinary {
+			t.Fatalf("opcode = %#x, want binary", opcode)
+		}
+		if len(payload) != want {
+			t.Errorf("payload length = %d, want %d", len(payload), want)
+		}
+	}
+
+	// Closing gets the status code echoed back
+	nc.Write([]byte{0x80 | opClose, 0x80 | 2, 0, 0, 0, 0, 0x03, 0xE8})
+	opcode, payload := readFrame(t, r)
+	if opcode != opClose || !bytes.Equal(payload, []byte{0x03, 0xE8}) {
+		t.Errorf("got opcode %#x payload %v, want close 1000", opcode, payload)
+	}
+}
+
+// dial opens a WebSocket connection to an httptest server URL.
+func dial(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	nc, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	r := bufio.NewReader(nc)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept = %q", got)
+	}
+	return nc, r
+}
+
+// readFrame reads one unmasked server frame.
+func readFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0F, payload
+}
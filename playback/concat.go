@@ -0,0 +1,56 @@
+package playback
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// recordingSeparator returns the line that starts recording n (1-indexed)
+// in a Concat result.
+func recordingSeparator(n int) string {
+	return "──────── recording " + strconv.Itoa(n) + " ────────"
+}
+
+// Concat stitches recordings into one, e.g. many short snippets into a
+// single page for RenderHTML. Each recording's content starts with a
+// "──────── recording N ────────" separator line, and its TOC entries are
+// moved down by the lines before it, so the merged TOC navigates every
+// recording in order.
+//
+// The result is LayoutMultiFile if any of the recordings is, as its TOC
+// may have entries. Returns an error if recordings is empty or holds nil.
+func Concat(recordings []*Recording) (*Recording, error) {
+	if len(recordings) == 0 {
+		return nil, fmt.Errorf("no recordings to concatenate")
+	}
+
+	var content strings.Builder
+	lines := 0 // lines written to content so far
+	merged := &Recording{Layout: LayoutSingleFile}
+	for i, rec := range recordings {
+		if rec == nil {
+			return nil, fmt.Errorf("recording %d is nil", i+1)
+		}
+		separator := recordingSeparator(i+1) + "\n\n"
+		if i > 0 {
+			separator = "\n\n" + separator
+		}
+		content.WriteString(separator)
+		lines += strings.Count(separator, "\n")
+
+		// TOC lines are 0-indexed, so the lines before the recording are
+		// its first line's number
+		for _, e := range rec.TOC {
+			merged.TOC = append(merged.TOC, TOCEntry{Label: e.Label, Line: e.Line + lines})
+		}
+		content.WriteString(rec.Content)
+		lines += strings.Count(rec.Content, "\n")
+
+		if rec.Layout == LayoutMultiFile {
+			merged.Layout = LayoutMultiFile
+		}
+	}
+	merged.Content = content.String()
+	return merged, nil
+}
@@ -0,0 +1,67 @@
+package playback
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestConcat(t *testing.T) {
+	first := &Recording{
+		Layout:  LayoutMultiFile,
+		Content: "$ ls\nfile1\nfile2\n$ npm test\nPASS",
+		TOC:     []TOCEntry{{Label: "ls", Line: 0}, {Label: "npm test", Line: 3}},
+	}
+	second := &Recording{Layout: LayoutSingleFile, Content: "hello\nworld"}
+	third := &Recording{
+		Layout:  LayoutMultiFile,
+		Content: "$ pwd\n/tmp\n$ make\nok",
+		TOC:     []TOCEntry{{Label: "pwd", Line: 0}, {Label: "make", Line: 2}},
+	}
+
+	merged, err := Concat([]*Recording{first, second, third})
+	if err != nil {
+		t.Fatalf("Concat failed: %v", err)
+	}
+
+	for n := 1; n <= 3; n++ {
+		if !strings.Contains(merged.Content, "──────── recording "+strconv.Itoa(n)+" ────────") {
+			t.Errorf("content should have a separator for recording %d, got %q", n, merged.Content)
+		}
+	}
+	if merged.Layout != LayoutMultiFile {
+		t.Errorf("Layout = %v, want multi-file", merged.Layout)
+	}
+
+	// Every merged entry points at the line holding its command
+	lines := strings.Split(merged.Content, "\n")
+	want := []string{"ls", "npm test", "pwd", "make"}
+	if len(merged.TOC) != len(want) {
+		t.Fatalf("TOC = %+v, want %d entries", merged.TOC, len(want))
+	}
+	for i, e := range merged.TOC {
+		if e.Label != want[i] {
+			t.Errorf("TOC[%d].Label = %q, want %q", i, e.Label, want[i])
+		}
+		if e.Line >= len(lines) || lines[e.Line] != "$ "+want[i] {
+			t.Errorf("TOC[%d].Line = %d, want the line of %q", i, e.Line, "$ "+want[i])
+		}
+	}
+	if merged.TOC[0].Line != 2 || merged.TOC[2].Line != 15 {
+		t.Errorf("TOC lines = %+v, want ls at 2 and pwd at 15", merged.TOC)
+	}
+
+	// The recordings themselves are unchanged
+	if first.TOC[1].Line != 3 {
+		t.Errorf("input TOC should not be modified, got %+v", first.TOC)
+	}
+}
+
+func TestConcat_Errors(t *testing.T) {
+	if _, err := Concat(nil); err == nil {
+		t.Error("expected an error for no recordings")
+	}
+	if _, err := Concat([]*Recording{{Content: "a"}, nil}); err == nil {
+		t.Error("expected an error for a nil recording")
+	}
+}
@@ -0,0 +1,43 @@
+package playback
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUnsafeDataURL is returned by RenderStreamingHTML for a DataURL the page
+// shouldn't fetch: one with a scheme other than http or https (e.g.
+// javascript:), or, with StreamingOptions.SameOriginDataURL, one that
+// isn't relative to the page's origin.
+var ErrUnsafeDataURL = errors.New("unsafe DataURL")
+
+// checkDataURL returns an error wrapping ErrUnsafeDataURL if dataURL is
+// unsafe (see ErrUnsafeDataURL). It is checked the way a browser reads
+// it: tabs and line breaks removed, leading and trailing spaces and
+// control characters trimmed, and backslashes read as slashes.
+func checkDataURL(dataURL string, sameOrigin bool) error {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		if r == '\\' {
+			return '/'
+		}
+		return r
+	}, dataURL)
+	normalized = strings.TrimFunc(normalized, func(r rune) bool { return r <= ' ' })
+
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %w", ErrUnsafeDataURL, dataURL, err)
+	}
+	if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: %q: scheme %q is not http or https", ErrUnsafeDataURL, dataURL, u.Scheme)
+	}
+	if sameOrigin && (u.Scheme != "" || strings.HasPrefix(normalized, "//")) {
+		return fmt.Errorf("%w: %q: not relative to the page's origin", ErrUnsafeDataURL, dataURL)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package playback
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRenderStreamingHTML_RejectsUnsafeDataURL(t *testing.T) {
+	for _, dataURL := range []string{
+		"javascript:alert(1)",
+		"JavaScript:alert(1)",
+		" java\tscript:alert(1)",
+		"data:text/plain,hi",
+		"file:///etc/passwd",
+	} {
+		_, err := RenderStreamingHTML(StreamingOptions{DataURL: dataURL})
+		if !errors.Is(err, ErrUnsafeDataURL) {
+			t.Errorf("DataURL %q: got %v, want ErrUnsafeDataURL", dataURL, err)
+		}
+	}
+}
+
+func TestRenderStreamingHTML_SameOriginDataURL(t *testing.T) {
+	tests := []struct {
+		dataURL string
+		allowed bool
+	}{
+		{"./session.log", true},
+		{"/api/recording/123", true},
+		{"session.log?part=2", true},
+		{"", true},
+		{"//evil.com/x", false},
+		{`/\evil.com/x`, false},
+		{"https://evil.com/x", false},
+		{"javascript:alert(1)", false},
+	}
+	for _, tt := range tests {
+		_, err := RenderStreamingHTML(StreamingOptions{DataURL: tt.dataURL, SameOriginDataURL: true})
+		if tt.allowed && err != nil {
+			t.Errorf("DataURL %q: unexpected error %v", tt.dataURL, err)
+		}
+		if !tt.allowed && !errors.Is(err, ErrUnsafeDataURL) {
+			t.Errorf("DataURL %q: got %v, want ErrUnsafeDataURL", tt.dataURL, err)
+		}
+	}
+
+	// Without SameOriginDataURL, other origins are allowed
+	for _, dataURL := range []string{"//cdn.example.com/x", "https://cdn.example.com/session.log"} {
+		if _, err := RenderStreamingHTML(StreamingOptions{DataURL: dataURL}); err != nil {
+			t.Errorf("DataURL %q: unexpected error %v", dataURL, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package playback
+
+import "fmt"
+
+// DiffKind says whether a command appears in one or both recordings.
+type DiffKind int
+
+const (
+	DiffSame    DiffKind = iota // Command in both recordings
+	DiffRemoved                 // Command only in the first recording
+	DiffAdded                   // Command only in the second recording
+)
+
+// String returns the kind name.
+func (k DiffKind) String() string {
+	switch k {
+	case DiffSame:
+		return "same"
+	case DiffRemoved:
+		return "removed"
+	case DiffAdded:
+		return "added"
+	}
+	return fmt.Sprintf("DiffKind(%d)", int(k))
+}
+
+// DiffEntry is one line of a command diff (see DiffTOC).
+type DiffEntry struct {
+	Kind  DiffKind
+	Label string // Command as typed
+	A     int    // Index in the first recording's TOC (-1 for DiffAdded)
+	B     int    // Index in the second recording's TOC (-1 for DiffRemoved)
+}
+
+// DiffTOC aligns the commands of two recordings, e.g. the same workflow run
+// on two branches, by longest common subsequence of their labels. Entries
+// follow the order of both recordings, like a unified diff: where they
+// differ, removed commands come before added ones. A reordered command shows
+// as removed in one place and added in another.
+func DiffTOC(a, b []TOCEntry) []DiffEntry {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i].Label == b[j].Label {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffEntry
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].Label == b[j].Label:
+			diff = append(diff, DiffEntry{Kind: DiffSame, Label: a[i].Label, A: i, B: j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffEntry{Kind: DiffRemoved, Label: a[i].Label, A: i, B: -1})
+			i++
+		default:
+			diff = append(diff, DiffEntry{Kind: DiffAdded, Label: b[j].Label, A: -1, B: j})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffEntry{Kind: DiffRemoved, Label: a[i].Label, A: i, B: -1})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffEntry{Kind: DiffAdded, Label: b[j].Label, A: -1, B: j})
+	}
+	return diff
+}
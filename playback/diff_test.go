@@ -0,0 +1,84 @@
+package playback
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// tocOf builds TOC entries from labels.
+func tocOf(labels ...string) []TOCEntry {
+	toc := make([]TOCEntry, len(labels))
+	for i, label := range labels {
+		toc[i] = TOCEntry{Label: label, Line: i * 10}
+	}
+	return toc
+}
+
+// formatDiff renders a diff as unified-diff style lines for comparison.
+func formatDiff(diff []DiffEntry) string {
+	var lines []string
+	for _, d := range diff {
+		prefix := map[DiffKind]string{DiffSame: " ", DiffRemoved: "-", DiffAdded: "+"}[d.Kind]
+		lines = append(lines, fmt.Sprintf("%s%s %d/%d", prefix, d.Label, d.A, d.B))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDiffTOC_Same(t *testing.T) {
+	diff := DiffTOC(tocOf("make", "make test"), tocOf("make", "make test"))
+
+	want := " make 0/0\n make test 1/1"
+	if got := formatDiff(diff); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffTOC_Insertion(t *testing.T) {
+	diff := DiffTOC(tocOf("git pull", "make test"), tocOf("git pull", "npm ci", "make test"))
+
+	want := " git pull 0/0\n+npm ci -1/1\n make test 1/2"
+	if got := formatDiff(diff); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffTOC_Deletion(t *testing.T) {
+	diff := DiffTOC(tocOf("git pull", "npm ci", "make test", "git push"), tocOf("git pull", "make test"))
+
+	want := " git pull 0/0\n-npm ci 1/-1\n make test 2/1\n-git push 3/-1"
+	if got := formatDiff(diff); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffTOC_Reordering(t *testing.T) {
+	diff := DiffTOC(tocOf("build", "lint", "test"), tocOf("lint", "build", "test"))
+
+	// One of the swapped commands stays aligned, the other moves
+	want := "-build 0/-1\n lint 1/0\n+build -1/1\n test 2/2"
+	if got := formatDiff(diff); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffTOC_Empty(t *testing.T) {
+	if diff := DiffTOC(nil, nil); len(diff) != 0 {
+		t.Errorf("expected no entries, got %v", diff)
+	}
+
+	want := "+ls -1/0"
+	if got := formatDiff(DiffTOC(nil, tocOf("ls"))); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	want = "-ls 0/-1"
+	if got := formatDiff(DiffTOC(tocOf("ls"), nil)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDiffKind_String(t *testing.T) {
+	if DiffAdded.String() != "added" || DiffRemoved.String() != "removed" || DiffSame.String() != "same" {
+		t.Error("unexpected DiffKind names")
+	}
+}
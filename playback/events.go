@@ -0,0 +1,27 @@
+package playback
+
+import (
+	"strings"
+
+	"github.com/choonkeat/record-tui/internal/timing"
+)
+
+// ExportEventsJSONL exports a recording's timing entries and commands as
+// JSON lines for analytics (e.g. how long commands take, which run most),
+// one object per line:
+//
+//	{"type":"output","delay":0.009404,"byteCount":16}
+//	{"type":"command","delay":0,"byteCount":0,"command":"npm test"}
+//	{"type":"input","delay":0.5,"byteCount":9}
+//
+// type is output, input, header or signal for timing entries, with delay in
+// seconds since the previous entry. A command event precedes the input that
+// starts the command. For large timing files, timing.WriteEventsJSONL writes
+// to an io.Writer instead of building the string.
+func ExportEventsJSONL(entries []timing.Entry, commands []timing.Command) (string, error) {
+	var sb strings.Builder
+	if err := timing.WriteEventsJSONL(&sb, entries, commands); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,42 @@
+package playback
+
+import (
+	"github.com/choonkeat/record-tui/internal/session"
+	"github.com/choonkeat/record-tui/internal/timing"
+)
+
+// FrameOptions configures BuildFrames.
+type FrameOptions struct {
+	// TypingAnimation adds a frame for each echoed keystroke, so typed
+	// commands appear a character at a time with their recorded delays
+	// between keystrokes, instead of the whole command appearing when
+	// Enter is pressed.
+	TypingAnimation bool
+}
+
+// BuildFrames returns frames for animated playback of a recording with
+// RenderHTML, from its timing entries (see timing.Parse), its
+// session.input inputContent and its session.log content, whose script
+// headers and footers are stripped. There is a frame as each command is
+// entered and one at the end of the recording, each holding the cleaned
+// output so far (see CleanWithOffsets) at its recorded time.
+//
+// Every frame holds the whole output up to it, so a long recording with
+// FrameOptions.TypingAnimation makes a large page.
+func BuildFrames(entries []timing.Entry, inputContent []byte, content string, opts ...FrameOptions) []Frame {
+	var o FrameOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	cleaned, mapOffset := CleanWithOffsets(content)
+	input := []byte(session.StripMetadataOnly(string(inputContent)))
+
+	points := timing.FramePoints(entries, input, o.TypingAnimation)
+	frames := make([]Frame, len(points))
+	for i, p := range points {
+		frames[i] = Frame{Timestamp: p.Time, Content: cleaned[:mapOffset(p.OutputByteOffset)]}
+	}
+	// The end shows everything, including output the timing doesn't account for
+	frames[len(frames)-1].Content = cleaned
+	return frames
+}
@@ -0,0 +1,66 @@
+package playback
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/choonkeat/record-tui/internal/timing"
+)
+
+// typedEntries type "ls" with each keystroke echoed, then list a file
+var typedEntries = []timing.Entry{
+	{Type: timing.Output, Delay: 0, ByteCount: 2}, // "$ "
+	{Type: timing.Input, Delay: 0.5, ByteCount: 1},
+	{Type: timing.Output, Delay: 0.01, ByteCount: 1}, // "l"
+	{Type: timing.Input, Delay: 0.2, ByteCount: 1},
+	{Type: timing.Output, Delay: 0.01, ByteCount: 1}, // "s"
+	{Type: timing.Input, Delay: 0.3, ByteCount: 1},
+	{Type: timing.Output, Delay: 0.01, ByteCount: 12}, // "\r\nfile.txt\r\n"
+}
+
+const typedContent = "$ ls\r\nfile.txt\r\n"
+
+func TestBuildFrames(t *testing.T) {
+	frames := BuildFrames(typedEntries, []byte("ls\r"), typedContent)
+
+	// The command appears whole as Enter is pressed, then the end
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2: %+v", len(frames), frames)
+	}
+	if frames[0].Content != "$ ls" {
+		t.Errorf("frame 0 = %q, want %q", frames[0].Content, "$ ls")
+	}
+	if !strings.Contains(frames[1].Content, "file.txt") {
+		t.Errorf("last frame missing output: %q", frames[1].Content)
+	}
+}
+
+func TestBuildFrames_TypingAnimation(t *testing.T) {
+	frames := BuildFrames(typedEntries, []byte("ls\r"), typedContent, FrameOptions{TypingAnimation: true})
+
+	// A frame per echoed keystroke, then Enter and the end
+	want := []string{"$ l", "$ ls", "$ ls"}
+	if len(frames) != len(want)+1 {
+		t.Fatalf("got %d frames, want %d: %+v", len(frames), len(want)+1, frames)
+	}
+	for i, w := range want {
+		if frames[i].Content != w {
+			t.Errorf("frame %d = %q, want %q", i, frames[i].Content, w)
+		}
+	}
+	if d := frames[1].Timestamp - frames[0].Timestamp; d < 0.2 || d > 0.22 {
+		t.Errorf("keystroke delay = %v, want about 0.21", d)
+	}
+}
+
+func TestBuildFrames_Metadata(t *testing.T) {
+	content := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" + typedContent +
+		"\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+	input := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\nls\r" +
+		"\nScript done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+
+	frames := BuildFrames(typedEntries, []byte(input), content)
+	if len(frames) != 2 || frames[0].Content != "$ ls" {
+		t.Errorf("expected script metadata stripped, got %+v", frames)
+	}
+}
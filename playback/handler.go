@@ -0,0 +1,130 @@
+package playback
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// handlerCommandBytes is how much of the start of the log Handler reads
+// for the command in its script header.
+const handlerCommandBytes = 4096
+
+// Handler returns an http.Handler serving the recording in recordingDir
+// (a directory holding session.log, or session.log.gz, as record-tui
+// writes), for exposing recordings from an existing Go web server:
+//
+//	/             the streaming page (see RenderStreamingHTML)
+//	/session.log  the raw log the page fetches, unbuffered
+//
+// Everything else is 404, as are both paths if there's no session log.
+// The page is rendered on each request, with command navigation from the
+// timing and input files if the recording has them (see DetectLayout),
+// otherwise from shell prompts, so reloading shows a recording still
+// being made as it is now. The log is streamed, not read whole, and a
+// session.log.gz is served decompressed.
+//
+// The page fetches ./session.log, so mount the handler at a path ending
+// in a slash:
+//
+//	http.Handle("/recordings/demo/", http.StripPrefix("/recordings/demo", playback.Handler(dir)))
+func Handler(recordingDir string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		logPath, ok := handlerLogPath(recordingDir)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		f, err := OpenLogFile(logPath)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		// The command is in the header, at the start
+		head := make([]byte, handlerCommandBytes)
+		n, err := io.ReadFull(f, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			http.Error(w, "failed to read recording", http.StatusInternalServerError)
+			return
+		}
+		head = head[:n]
+
+		page, err := RenderStreamingHTML(StreamingOptions{
+			Title:   filepath.Base(recordingDir),
+			Command: ExtractCommand(string(head)),
+			DataURL: "./session.log",
+			TOC:     handlerTOC(logPath, io.MultiReader(bytes.NewReader(head), f)),
+		})
+		if err != nil {
+			http.Error(w, "failed to render recording", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	})
+
+	mux.HandleFunc("/session.log", func(w http.ResponseWriter, r *http.Request) {
+		logPath, ok := handlerLogPath(recordingDir)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		// Don't let proxies buffer it, nor browsers keep an old copy of a
+		// log that's still growing
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.Header().Set("Cache-Control", "no-cache")
+		if filepath.Ext(logPath) != ".gz" {
+			http.ServeFile(w, r, logPath)
+			return
+		}
+		f, err := OpenLogFile(logPath)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		io.Copy(w, f)
+	})
+
+	return mux
+}
+
+// handlerLogPath returns the session log in recordingDir: session.log, or
+// session.log.gz if that's all there is.
+func handlerLogPath(recordingDir string) (string, bool) {
+	for _, name := range []string{"session.log", "session.log.gz"} {
+		path := filepath.Join(recordingDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// handlerTOC returns Handler's command navigation for the session log at
+// logPath, read from r: from its timing and input files if it has them,
+// otherwise guessed from shell prompts.
+func handlerTOC(logPath string, r io.Reader) []TOCEntry {
+	if DetectLayout(logPath) == LayoutMultiFile {
+		timingFile, err := OpenLogFile(LogCompanionPath(logPath, ".timing"))
+		if err == nil {
+			defer timingFile.Close()
+			input, err := ReadLogFile(LogCompanionPath(logPath, ".input"))
+			if err == nil {
+				return BuildTOC(timingFile, input, r)
+			}
+		}
+	}
+	return buildTOCFromPrompts(r)
+}
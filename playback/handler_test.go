@@ -0,0 +1,111 @@
+package playback
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func getHandler(t *testing.T, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	log := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make\"]\n$ make test\r\nPASS\r\n"
+	if err := os.WriteFile(filepath.Join(dir, "session.log"), []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(Handler(dir))
+	defer server.Close()
+
+	resp, page := getHandler(t, server.URL+"/")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/: status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("/: Content-Type = %q", ct)
+	}
+	for _, want := range []string{"./session.log", "make test"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("/: page should contain %q", want)
+		}
+	}
+
+	resp, body := getHandler(t, server.URL+"/session.log")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/session.log: status = %d, want 200", resp.StatusCode)
+	}
+	if body != log {
+		t.Errorf("/session.log = %q, want the raw log", body)
+	}
+	if got := resp.Header.Get("X-Accel-Buffering"); got != "no" {
+		t.Errorf("/session.log: X-Accel-Buffering = %q, want no", got)
+	}
+
+	for _, path := range []string{"/session.timing", "/index.html", "/session.log/x"} {
+		if resp, _ := getHandler(t, server.URL+path); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandler_Gzip(t *testing.T) {
+	dir := t.TempDir()
+	log := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"make\"]\n$ make test\r\nPASS\r\n"
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(log))
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "session.log.gz"), gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(Handler(dir))
+	defer server.Close()
+
+	resp, page := getHandler(t, server.URL+"/")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/: status = %d, want 200", resp.StatusCode)
+	}
+	for _, want := range []string{"Recording of: <code>make</code>", "make test"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("/: page should contain %q", want)
+		}
+	}
+
+	// Served decompressed, as the page cleans it as text
+	resp, body := getHandler(t, server.URL+"/session.log")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/session.log: status = %d, want 200", resp.StatusCode)
+	}
+	if body != log {
+		t.Errorf("/session.log = %q, want the decompressed log", body)
+	}
+}
+
+func TestHandler_MissingLog(t *testing.T) {
+	server := httptest.NewServer(Handler(t.TempDir()))
+	defer server.Close()
+
+	for _, path := range []string{"/", "/session.log"} {
+		if resp, _ := getHandler(t, server.URL+path); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package playback
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/choonkeat/record-tui/internal/websocket"
+)
+
+// tailPollInterval is how often TailHandler checks the log for new output.
+const tailPollInterval = 250 * time.Millisecond
+
+// TailHandler returns an http.Handler that streams the session.log at path
+// over a WebSocket as it grows, like tail -f, for pages rendered by
+// RenderStreamingHTML with StreamingOptions.WebSocketURL pointing at it.
+// Each connection gets the file from the start (or from the byte given by
+// an "offset" query parameter, which the page sends when it reconnects)
+// as binary messages, then new output as it's written, until the client
+// disconnects.
+//
+// The raw log is sent, metadata and all: the page cleans it as it would a
+// fetched one. Only pages on the handler's own host may connect, unless
+// TailOptions.AllowedOrigins lets others; anyone who can reach it without
+// a browser can still read the log, so only serve it where the recording
+// may be watched.
+//
+// Example:
+//
+//	http.Handle("/live", playback.TailHandler("session.log"))
+func TailHandler(path string, opts ...TailOptions) http.Handler {
+	var o TailOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var offset int64
+		if s := r.URL.Query().Get("offset"); s != "" {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid offset", http.StatusBadRequest)
+				return
+			}
+			offset = n
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			http.Error(w, "session log not found", http.StatusNotFound)
+			return
+		}
+		defer f.Close()
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+
+		conn, err := websocket.Upgrade(w, r, websocket.Options{AllowedOrigins: o.AllowedOrigins})
+		if err != nil {
+			return // Upgrade has replied
+		}
+		defer conn.Close()
+
+		// The client only ever closes: reading notices when it does
+		gone := make(chan struct{})
+		go func() {
+			conn.Discard()
+			close(gone)
+		}()
+
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				if conn.WriteBinary(buf[:n]) != nil {
+					return
+				}
+				continue
+			}
+			if err != nil && err != io.EOF {
+				return
+			}
+			select {
+			case <-gone:
+				return
+			case <-time.After(tailPollInterval):
+			}
+		}
+	})
+}
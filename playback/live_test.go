@@ -0,0 +1,150 @@
+package playback
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTailHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	if err := os.WriteFile(path, []byte("Script started on 2026-01-01\nhello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(TailHandler(path))
+	defer server.Close()
+
+	nc, r := dialTail(t, server.URL+"/")
+	defer nc.Close()
+	if got := readTail(t, r, len("Script started on 2026-01-01\nhello\n")); got != "Script started on 2026-01-01\nhello\n" {
+		t.Errorf("first message = %q", got)
+	}
+
+	// Output written later is sent as it's noticed
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("world\n")
+	f.Close()
+	if got := readTail(t, r, len("world\n")); got != "world\n" {
+		t.Errorf("appended message = %q", got)
+	}
+
+	// Reconnecting resumes from the offset
+	nc2, r2 := dialTail(t, server.URL+"/?offset=29")
+	defer nc2.Close()
+	if got := readTail(t, r2, len("hello\nworld\n")); got != "hello\nworld\n" {
+		t.Errorf("resumed message = %q", got)
+	}
+}
+
+func TestTailHandler_Errors(t *testing.T) {
+	missing := httptest.NewServer(TailHandler(filepath.Join(t.TempDir(), "missing.log")))
+	defer missing.Close()
+	resp, err := http.Get(missing.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("missing log: status = %d, want 404", resp.StatusCode)
+	}
+
+	path := filepath.Join(t.TempDir(), "session.log")
+	os.WriteFile(path, []byte("hello\n"), 0644)
+	server := httptest.NewServer(TailHandler(path))
+	defer server.Close()
+	for _, url := range []string{server.URL + "/?offset=-1", server.URL + "/"} {
+		// Not a WebSocket request (or a bad offset)
+		resp, err := http.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", url, resp.StatusCode)
+		}
+	}
+}
+
+func TestTailHandler_Origin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	os.WriteFile(path, []byte("hello\n"), 0644)
+
+	tail := func(handler http.Handler, origin string) int {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Another site's page can't read the log through a viewer's browser
+	if got := tail(TailHandler(path), "http://evil.example"); got != http.StatusForbidden {
+		t.Errorf("foreign origin: status = %d, want 403", got)
+	}
+	if got := tail(TailHandler(path, TailOptions{AllowedOrigins: []string{"viewer.example"}}), "https://viewer.example"); got != http.StatusSwitchingProtocols {
+		t.Errorf("allowed origin: status = %d, want 101", got)
+	}
+}
+
+// dialTail opens a WebSocket connection to a TailHandler URL.
+func dialTail(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	hostPath := strings.TrimPrefix(url, "http://")
+	host, path, _ := strings.Cut(hostPath, "/")
+	nc, err := net.Dial("tcp", host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nc.SetDeadline(time.Now().Add(5 * time.Second))
+	nc.Write([]byte("GET /" + path + " HTTP/1.1\r\nHost: " + host + "\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	r := bufio.NewReader(nc)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want 101", resp.StatusCode)
+	}
+	return nc, r
+}
+
+// readTail reads small binary messages until n bytes of payload arrived.
+func readTail(t *testing.T, r *bufio.Reader, n int) string {
+	t.Helper()
+	var got []byte
+	for len(got) < n {
+		var head [2]byte
+		if _, err := io.ReadFull(r, head[:]); err != nil {
+			t.Fatal(err)
+		}
+		if head[0] != 0x82 || head[1] > 125 {
+			t.Fatalf("unexpected frame header %x", head)
+		}
+		payload := make([]byte, head[1])
+		if _, err := io.ReadFull(r, payload); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, payload...)
+	}
+	return string(got)
+}
@@ -2,10 +2,14 @@ package playback
 
 import (
 	"io"
+	"regexp"
+	"strings"
 
+	"github.com/choonkeat/record-tui/internal/grid"
 	"github.com/choonkeat/record-tui/internal/html"
 	"github.com/choonkeat/record-tui/internal/logfile"
 	"github.com/choonkeat/record-tui/internal/session"
+	"github.com/choonkeat/record-tui/internal/svg"
 	"github.com/choonkeat/record-tui/internal/timing"
 	"github.com/choonkeat/record-tui/internal/toc"
 )
@@ -20,13 +24,88 @@ import (
 //	[content]
 //	Script done on Wed Dec 31 12:11:22 2025
 //
+// Older macOS versions write no "Command:" line, newer ones may append the
+// TTY ("... 2024 on /dev/ttys003"), and "Script started, output file is
+// typescript" messages captured in the log are stripped too.
+//
 // Linux format:
 //
 //	Script started on 2026-01-12 06:41:43+00:00 [COMMAND="bash" TERM="xterm-256color" ...]
 //	[content]
-//	Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS="0"]
-func StripMetadata(content string) string {
-	return session.StripMetadata(content)
+//	Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_CODE="0"]
+//
+// Both LF and CRLF line endings are supported (e.g. logs produced on Windows
+// or passed through Windows tooling), as are a leading byte order mark and
+// UTF-16 logs, which are decoded to UTF-8 first.
+//
+// Full-screen TUI (alternate screen) output is discarded unless
+// StripOptions.KeepAltScreen is set. Secrets are kept unless
+// StripOptions.Redact is set.
+func StripMetadata(content string, opts ...StripOptions) string {
+	return session.StripMetadata(content, cleanOptions(opts))
+}
+
+// cleanOptions converts optional StripOptions to session.CleanOptions.
+func cleanOptions(opts []StripOptions) session.CleanOptions {
+	var cleanOpts session.CleanOptions
+	if len(opts) > 0 {
+		cleanOpts.KeepAltScreen = opts[0].KeepAltScreen
+		cleanOpts.AltScreenLink = opts[0].AltScreenLink
+		cleanOpts.Redact = opts[0].Redact
+		cleanOpts.EnsureTrailingNewline = opts[0].EnsureTrailingNewline
+	}
+	return cleanOpts
+}
+
+// AltScreenRegions returns the raw output of each full-screen TUI
+// (alternate screen) session in session log content, in the order
+// StripMetadata discards them, from the sequence entering the alternate
+// screen through the one leaving it. Saved to files, they can be linked
+// from the page with StripOptions.AltScreenLink and replayed with cat.
+func AltScreenRegions(content string) []string {
+	return session.AltScreenRegions(content)
+}
+
+// CleanWithOffsets cleans session log content like StripMetadata and also
+// returns mapOffset, which maps a byte offset in the recorded output to its
+// position in cleaned, for tools that overlay their own markers (e.g. from
+// timing file offsets) on the cleaned content.
+//
+// Offsets are into the content after its script header and footer are
+// removed, the bytes a timing file counts, not into the raw session.log.
+// The sessions of an appended log (script -a) are joined with a line
+// break, as StripMetadata joins them, which offsets past the first
+// session don't account for.
+// An offset inside a region the cleaning dropped maps to where that region
+// was. Full-screen TUI output is always discarded (StripOptions.KeepAltScreen
+// and AltScreenLink are ignored), and trailing line breaks are left as they
+// are (EnsureTrailingNewline is ignored). With StripOptions.Redact, an offset inside
+// a redacted secret maps to just after its "[REDACTED]".
+func CleanWithOffsets(content string, opts ...StripOptions) (cleaned string, mapOffset func(int) int) {
+	return session.NeutralizeAllWithOffsets(session.StripMetadataOnly(content), cleanOptions(opts))
+}
+
+// FindErrorLines returns the lines of cleaned content (see StripMetadata)
+// that look like error output, red text or the words "error", "Error",
+// "ERROR" or "panic", for Options.ErrorLines. A run of consecutive error
+// lines is reported by its first line.
+func FindErrorLines(content string) []int {
+	return session.FindErrorLines(content)
+}
+
+// ReconstructInput returns what was typed during a recording, with control
+// keys annotated ("<Enter>", "^C", "<Up>"), for Options.TypedInput. entries
+// are the recording's timing entries (see timing.Parse) and inputContent
+// its session.input, whose script header and footer are stripped.
+func ReconstructInput(entries []timing.Entry, inputContent []byte) string {
+	return timing.ReconstructInput(entries, []byte(session.StripMetadataOnly(string(inputContent))))
+}
+
+// ExtractCommand returns the command recorded in a session log's `script`
+// header (COMMAND="..." on Linux, "Command: ..." on macOS), for use as
+// Options.Command. Returns empty string if there is none.
+func ExtractCommand(content string) string {
+	return session.ExtractCommand(content)
 }
 
 // RenderHTML generates a standalone HTML page with terminal playback using xterm.js.
@@ -38,36 +117,103 @@ func StripMetadata(content string) string {
 //
 // Options can be used to customize the output (e.g., page title).
 func RenderHTML(frames []Frame, opts ...Options) (string, error) {
+	internalFrames, internalOpts := toInternalPlayback(frames, opts)
+	return html.RenderPlaybackHTMLWithOptions(internalFrames, internalOpts)
+}
+
+// RenderHTMLTo is like RenderHTML but writes the page to w as it is
+// generated, e.g. straight to a file, rather than returning it: the page
+// of a large recording isn't held in memory whole. Returns w's error if a
+// write fails.
+func RenderHTMLTo(w io.Writer, frames []Frame, opts ...Options) error {
+	internalFrames, internalOpts := toInternalPlayback(frames, opts)
+	return html.WritePlaybackHTML(w, internalFrames, internalOpts)
+}
+
+// toInternalPlayback converts RenderHTML's frames and options to the
+// internal types.
+func toInternalPlayback(frames []Frame, opts []Options) ([]html.PlaybackFrame, html.PlaybackOptions) {
 	// Convert public Frame to internal PlaybackFrame
+	downsample := len(opts) > 0 && opts[0].ColorMode == session.ColorMode16
 	internalFrames := make([]html.PlaybackFrame, len(frames))
 	for i, f := range frames {
+		// Recordings may hold raw or latin-1 bytes; make them valid UTF-8
+		// so the frames encode to JSON the page can decode
+		content := strings.ToValidUTF8(f.Content, "\uFFFD")
+		if downsample {
+			content = session.DownsampleColors(content)
+		}
 		internalFrames[i] = html.PlaybackFrame{
 			Timestamp: f.Timestamp,
-			Content:   f.Content,
+			Content:   content,
+			Label:     strings.ToValidUTF8(f.Label, "\uFFFD"),
 		}
 	}
 
 	// Extract options
-	title := "Terminal"
-	var footerLink html.FooterLink
-	var tocEntries []html.TOCEntry
+	internalOpts := html.PlaybackOptions{Title: "Terminal"}
 	if len(opts) > 0 {
 		if opts[0].Title != "" {
-			title = opts[0].Title
+			internalOpts.Title = opts[0].Title
 		}
-		footerLink = html.FooterLink{
+		internalOpts.Command = opts[0].Command
+		internalOpts.Cwd = opts[0].Cwd
+		internalOpts.Shell = opts[0].Shell
+		internalOpts.FooterLink = html.FooterLink{
 			Text: opts[0].FooterLink.Text,
 			URL:  opts[0].FooterLink.URL,
 		}
+		internalOpts.FooterLinks = toInternalFooterLinks(opts[0].FooterLinks)
 		for _, e := range opts[0].TOC {
-			tocEntries = append(tocEntries, html.TOCEntry{
+			internalOpts.TOC = append(internalOpts.TOC, html.TOCEntry{
 				Label: e.Label,
 				Line:  e.Line,
 			})
 		}
+		internalOpts.Cols = opts[0].Cols
+		internalOpts.StrictCSP = opts[0].StrictCSP
+		internalOpts.HideAttribution = opts[0].HideAttribution
+		internalOpts.SourceHash = opts[0].SourceHash
+		internalOpts.Version = opts[0].Version
+		internalOpts.Embedded = opts[0].Embedded
+		internalOpts.ExtraCSS = opts[0].ExtraCSS
+		internalOpts.Responsive = opts[0].Responsive
+		internalOpts.PageLinks = toInternalFooterLinks(opts[0].PageLinks)
+		internalOpts.Minimap = opts[0].Minimap
+		internalOpts.MaxRows = opts[0].MaxRows
+		internalOpts.ErrorLines = opts[0].ErrorLines
+		internalOpts.TypedInput = strings.ToValidUTF8(opts[0].TypedInput, "\uFFFD")
+		internalOpts.LineTimes = opts[0].LineTimes
+		internalOpts.Collapsed = opts[0].Collapsed
+		internalOpts.OpenGraph = opts[0].OpenGraph
+		internalOpts.OpenGraphDescription = opts[0].OpenGraphDescription
+		internalOpts.OpenGraphImage = opts[0].OpenGraphImage
+		for _, e := range opts[0].Timing {
+			internalOpts.Timing = append(internalOpts.Timing, html.TimingEntry{
+				Type:      byte(e.Type),
+				Delay:     e.Delay,
+				ByteCount: e.ByteCount,
+			})
+		}
 	}
+	return internalFrames, internalOpts
+}
 
-	return html.RenderPlaybackHTML(internalFrames, title, footerLink, tocEntries)
+// RenderIndexHTML generates the index page of a recording split into
+// several pages rendered with RenderHTML: a list of the pages, each with
+// its commands linking to them on that page.
+func RenderIndexHTML(title string, pages []IndexPage) (string, error) {
+	internalPages := make([]html.IndexPage, len(pages))
+	for i, p := range pages {
+		internalPages[i].URL = p.URL
+		for _, e := range p.TOC {
+			internalPages[i].TOC = append(internalPages[i].TOC, html.TOCEntry{
+				Label: e.Label,
+				Line:  e.Line,
+			})
+		}
+	}
+	return html.RenderIndexHTML(title, internalPages)
 }
 
 // RenderStreamingHTML generates an HTML page that streams terminal data from a URL.
@@ -89,7 +235,9 @@ func RenderHTML(frames []Frame, opts ...Options) (string, error) {
 //	    DataURL: "./session.log",
 //	})
 // BuildTOC generates table-of-contents entries from a timing file, input file,
-// and session log content. Returns nil if parsing fails or no commands are found.
+// and session log content. Returns nil if parsing fails, no commands are found,
+// or the timing file doesn't account for the session output (see
+// timing.Validate), e.g. when the files come from different runs.
 //
 // Parameters:
 //   - timingReader: reader for the timing file (advanced format with I/O/H/S markers)
@@ -97,7 +245,10 @@ func RenderHTML(frames []Frame, opts ...Options) (string, error) {
 //   - sessionContent: raw bytes from the session.log file (may include script metadata)
 //
 // Both inputContent and sessionContent have their script header/footer stripped
-// automatically before processing.
+// automatically before processing. Lines typed as chapter bookmarks (see
+// TOCOptions) are labeled with the chapter name, commands matching
+// TOCOptions.Exclude or ExcludePattern are left out, and repeated commands
+// are merged with TOCOptions.CollapseRepeats.
 // BuildTOC generates a table of contents by streaming session content.
 // Accepts io.Reader for session content to avoid loading entire recordings
 // into memory. Uses constant memory regardless of recording size.
@@ -108,19 +259,44 @@ func RenderHTML(frames []Frame, opts ...Options) (string, error) {
 //	inputBytes, _ := os.ReadFile("session.input")
 //	sessionFile, _ := os.Open("session.log")
 //	tocEntries := playback.BuildTOC(timingFile, inputBytes, sessionFile)
-func BuildTOC(timingReader io.Reader, inputContent []byte, sessionReader io.Reader) []TOCEntry {
+func BuildTOC(timingReader io.Reader, inputContent []byte, sessionReader io.Reader, opts ...TOCOptions) []TOCEntry {
 	entries, err := timing.Parse(timingReader)
 	if err != nil {
 		return nil
 	}
+	return BuildTOCFromEntries(entries, inputContent, sessionReader, opts...)
+}
 
+// BuildTOCFromEntries is like BuildTOC but takes timing entries already
+// read, e.g. with timing.ReadAll from a timing.Source, so recordings from
+// recorders other than script(1) can be navigated too. For an asciinema
+// cast, read a timing.AsciicastSource and pass its Input and Output.
+func BuildTOCFromEntries(entries []timing.Entry, inputContent []byte, sessionReader io.Reader, opts ...TOCOptions) []TOCEntry {
 	strippedInput := []byte(session.StripMetadataOnly(string(inputContent)))
-	commands := timing.ExtractCommands(entries, strippedInput)
+	var o TOCOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	commands := filterTOCCommands(timing.ExtractCommands(entries, strippedInput), o)
 	if len(commands) == 0 {
 		return nil
 	}
+	commands = timing.MarkChapters(commands, o.ChapterPrefix)
+
+	// Measure the whole session output while scanning it, so offsets from a
+	// timing file that doesn't match it aren't turned into misleading entries
+	var outputLen session.OutputLength
+	tocRaw := toc.FromCommands(commands, io.TeeReader(sessionReader, &outputLen))
+	if _, err := io.Copy(&outputLen, sessionReader); err != nil {
+		return nil
+	}
+	if timing.Validate(entries, outputLen.Len()) != nil {
+		return nil
+	}
 
-	tocRaw := toc.FromCommands(commands, sessionReader)
+	if o.CollapseRepeats {
+		tocRaw = toc.CollapseRepeats(tocRaw)
+	}
 
 	result := make([]TOCEntry, len(tocRaw))
 	for i, e := range tocRaw {
@@ -129,7 +305,89 @@ func BuildTOC(timingReader io.Reader, inputContent []byte, sessionReader io.Read
 	return result
 }
 
+// DefaultPromptPattern is the shell prompt BuildTOCFromPrompts looks for
+// by default: bash's "$ " (or "# " as root) and zsh's "% " at the start of
+// a line, optionally after a word such as "user@host:~", and fish's
+// "user@host ~> ".
+var DefaultPromptPattern = toc.DefaultPromptPattern
+
+// BuildTOCFromPrompts is a coarse fallback for BuildTOC when a recording
+// has no timing or input file: commands are found by scanning the session
+// content for lines starting with a shell prompt matching
+// TOCOptions.PromptPattern, each labeled with the rest of its line. Output
+// that looks like a prompt is listed too. The other TOCOptions apply as for
+// BuildTOC, matched against the labels.
+func BuildTOCFromPrompts(content string, opts ...TOCOptions) []TOCEntry {
+	return buildTOCFromPrompts(strings.NewReader(content), opts...)
+}
+
+// buildTOCFromPrompts is BuildTOCFromPrompts reading the session content
+// from r a line at a time.
+func buildTOCFromPrompts(r io.Reader, opts ...TOCOptions) []TOCEntry {
+	var o TOCOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	keep := timing.ExcludeCommands(o.Exclude, o.ExcludePattern)
+	var tocRaw []toc.Entry
+	for _, e := range toc.FromPrompts(r, o.PromptPattern) {
+		cmd := timing.Command{Text: e.Label}
+		if !keep(cmd) {
+			continue
+		}
+		e.Label = timing.MarkChapters([]timing.Command{cmd}, o.ChapterPrefix)[0].Text
+		tocRaw = append(tocRaw, e)
+	}
+	if len(tocRaw) == 0 {
+		return nil
+	}
+
+	if o.CollapseRepeats {
+		tocRaw = toc.CollapseRepeats(tocRaw)
+	}
+
+	result := make([]TOCEntry, len(tocRaw))
+	for i, e := range tocRaw {
+		result[i] = TOCEntry{Label: e.Label, Line: e.Line}
+	}
+	return result
+}
+
+// TrimTrailingPrompt removes the bare shell prompt a recording usually
+// ends waiting at from cleaned content (see StripMetadata), so the page
+// doesn't end looking unfinished. Only a last line matching promptPattern
+// (nil uses DefaultPromptPattern), as for BuildTOCFromPrompts, with nothing
+// typed after it is removed; a half-typed "exit" is kept.
+func TrimTrailingPrompt(content string, promptPattern *regexp.Regexp) string {
+	return toc.TrimTrailingPrompt(content, promptPattern)
+}
+
+// LineTimes returns when each line of a recording's output first appeared,
+// in seconds from its start, for Options.LineTimes: element i is the time
+// of line i, numbered as TOCEntry.Line is. entries are the recording's
+// timing entries (see timing.Parse) and sessionReader its session.log.
+// Times are approximate, to the Output entry that wrote the line.
+func LineTimes(entries []timing.Entry, sessionReader io.Reader) []float64 {
+	return toc.LineTimes(entries, sessionReader)
+}
+
+// filterTOCCommands leaves out the commands TOCOptions excludes.
+func filterTOCCommands(commands []timing.Command, o TOCOptions) []timing.Command {
+	if len(o.Exclude) == 0 && o.ExcludePattern == nil {
+		return commands
+	}
+	return timing.FilterCommands(commands, timing.ExcludeCommands(o.Exclude, o.ExcludePattern))
+}
+
+// RenderStreamingHTML renders a page that fetches the session log from
+// opts.DataURL (or tails opts.WebSocketURL) instead of embedding it.
+// Returns an error wrapping ErrUnsafeDataURL if DataURL isn't safe to
+// fetch.
 func RenderStreamingHTML(opts StreamingOptions) (string, error) {
+	if err := checkDataURL(opts.DataURL, opts.SameOriginDataURL); err != nil {
+		return "", err
+	}
+
 	var tocEntries []html.TOCEntry
 	for _, e := range opts.TOC {
 		tocEntries = append(tocEntries, html.TOCEntry{
@@ -140,18 +398,54 @@ func RenderStreamingHTML(opts StreamingOptions) (string, error) {
 
 	internalOpts := html.StreamingOptions{
 		Title:   opts.Title,
+		Command: opts.Command,
 		DataURL: opts.DataURL,
 		FooterLink: html.FooterLink{
 			Text: opts.FooterLink.Text,
 			URL:  opts.FooterLink.URL,
 		},
-		Cols:    opts.Cols,
-		MaxRows: opts.MaxRows,
-		TOC:     tocEntries,
+		FooterLinks:     toInternalFooterLinks(opts.FooterLinks),
+		Cols:            opts.Cols,
+		MaxRows:         opts.MaxRows,
+		Scrollback:      opts.Scrollback,
+		TOC:             tocEntries,
+		StrictCSP:       opts.StrictCSP,
+		HideAttribution: opts.HideAttribution,
+		ExtraCSS:        opts.ExtraCSS,
+		Follow:          opts.Follow,
+		Minimap:         opts.Minimap,
+		Collapsed:       opts.Collapsed,
+		WebSocketURL:    opts.WebSocketURL,
 	}
 	return html.RenderStreamingPlaybackHTML(internalOpts)
 }
 
+// RenderSVG renders terminal content as a standalone SVG snapshot, for
+// embedding where JavaScript isn't allowed (READMEs, static docs).
+// Content should already be cleaned (see StripMetadata). ANSI colors and
+// text attributes are preserved and carriage returns overwrite like a
+// terminal; full-screen cursor addressing is not reproduced faithfully.
+//
+// Example:
+//
+//	svg, err := playback.RenderSVG(playback.StripMetadata(raw), playback.SVGOptions{Cols: 100})
+func RenderSVG(content string, opts SVGOptions) (string, error) {
+	return svg.Render(content, svg.Options{
+		Cols:     opts.Cols,
+		FontSize: opts.FontSize,
+		Overflow: grid.Overflow(opts.Overflow),
+	})
+}
+
+// toInternalFooterLinks converts public footer links to the internal type.
+func toInternalFooterLinks(links []FooterLink) []html.FooterLink {
+	var result []html.FooterLink
+	for _, l := range links {
+		result = append(result, html.FooterLink{Text: l.Text, URL: l.URL})
+	}
+	return result
+}
+
 // OpenLogFile opens a session log file for reading, transparently decompressing
 // gzip-compressed files. It detects gzip format by checking for magic bytes
 // (0x1f 0x8b) at the start of the file, so it works regardless of file extension.
@@ -0,0 +1,108 @@
+package playback
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/choonkeat/record-tui/internal/logfile"
+)
+
+// Layout identifies how a recording's files are laid out on disk.
+type Layout int
+
+const (
+	// LayoutSingleFile is a plain `script session.log` recording: session.log
+	// holds the output between its "Script started/done" header and footer,
+	// with no input log. There is no command navigation.
+	LayoutSingleFile Layout = iota
+
+	// LayoutMultiFile is util-linux script(1) writing separate files:
+	//
+	//	script --log-out session.log --log-in session.input --log-timing session.timing
+	//
+	// session.log holds only the output, session.input the keystrokes and
+	// session.timing (advanced format) interleaves the two, so commands can
+	// be located in the output for navigation.
+	LayoutMultiFile
+)
+
+// String returns the layout name.
+func (l Layout) String() string {
+	switch l {
+	case LayoutSingleFile:
+		return "single-file"
+	case LayoutMultiFile:
+		return "multi-file"
+	}
+	return fmt.Sprintf("Layout(%d)", int(l))
+}
+
+// DetectLayout reports the layout of the recording whose output is at
+// sessionLogPath: LayoutMultiFile if the companion .input and .timing files
+// (see LogCompanionPath) both exist, otherwise LayoutSingleFile.
+func DetectLayout(sessionLogPath string) Layout {
+	for _, ext := range []string{".input", ".timing"} {
+		if _, err := os.Stat(LogCompanionPath(sessionLogPath, ext)); err != nil {
+			return LayoutSingleFile
+		}
+	}
+	return LayoutMultiFile
+}
+
+// Recording is a recording loaded from disk, ready for RenderHTML.
+type Recording struct {
+	Layout  Layout     // Layout the recording was loaded from
+	Content string     // Output with script metadata stripped and cleaned (see StripMetadata)
+	TOC     []TOCEntry // Command navigation (nil for LayoutSingleFile or if none was found)
+}
+
+// LoadRecording loads the recording whose output is at sessionLogPath,
+// detecting its layout (see DetectLayout). Companion files are found with
+// LogCompanionPath, so session.log.gz works too.
+func LoadRecording(sessionLogPath string, opts ...StripOptions) (*Recording, error) {
+	if DetectLayout(sessionLogPath) == LayoutMultiFile {
+		return LoadRecordingMultiFile(sessionLogPath,
+			LogCompanionPath(sessionLogPath, ".input"),
+			LogCompanionPath(sessionLogPath, ".timing"),
+			opts...)
+	}
+	content, err := logfile.ReadFile(sessionLogPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read output log: %w", err)
+	}
+	return &Recording{
+		Layout:  LayoutSingleFile,
+		Content: StripMetadata(string(content), opts...),
+	}, nil
+}
+
+// LoadRecordingMultiFile loads a LayoutMultiFile recording from the files
+// written by script's --log-out, --log-in and --log-timing options, which
+// may have any names (e.g. session-<uuid>.input). Any of them may be gzip
+// compressed.
+//
+// Navigation is built with BuildTOC; if the input or timing log can't be
+// used (e.g. a classic, output-only timing file), the recording still loads
+// without it.
+func LoadRecordingMultiFile(outPath, inPath, timingPath string, opts ...StripOptions) (*Recording, error) {
+	content, err := logfile.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read output log: %w", err)
+	}
+	input, err := logfile.ReadFile(inPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read input log: %w", err)
+	}
+	timingFile, err := logfile.Open(timingPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read timing log: %w", err)
+	}
+	defer timingFile.Close()
+
+	return &Recording{
+		Layout:  LayoutMultiFile,
+		Content: StripMetadata(string(content), opts...),
+		TOC:     BuildTOC(timingFile, input, bytes.NewReader(content)),
+	}, nil
+}
@@ -0,0 +1,92 @@
+package playback
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles writes name → content files into dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// multiFileRecording is what script --log-out/--log-in/--log-timing writes
+var multiFileRecording = map[string]string{
+	"out.log":    "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n$ ls\nfile1\nfile2\n$ npm test\nPASS\n",
+	"in.log":     "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\nls\rnpm test\r",
+	"timing.log": "H 0.000000 START_TIME 2026-01-12 06:41:43+00:00\nO 0.010 4\nI 0.500 3\nO 0.010 18\nI 1.000 9\nO 0.010 5\n",
+}
+
+func TestLoadRecordingMultiFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, multiFileRecording)
+
+	rec, err := LoadRecordingMultiFile(
+		filepath.Join(dir, "out.log"),
+		filepath.Join(dir, "in.log"),
+		filepath.Join(dir, "timing.log"),
+	)
+	if err != nil {
+		t.Fatalf("LoadRecordingMultiFile failed: %v", err)
+	}
+
+	if rec.Layout != LayoutMultiFile {
+		t.Errorf("Layout = %v, want multi-file", rec.Layout)
+	}
+	if rec.Content != "$ ls\nfile1\nfile2\n$ npm test\nPASS" {
+		t.Errorf("Content = %q, want output without the header", rec.Content)
+	}
+	if len(rec.TOC) != 2 || rec.TOC[0].Label != "ls" || rec.TOC[1].Label != "npm test" {
+		t.Errorf("TOC = %+v, want ls and npm test", rec.TOC)
+	}
+}
+
+func TestLoadRecordingMultiFile_MissingInput(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, multiFileRecording)
+
+	_, err := LoadRecordingMultiFile(filepath.Join(dir, "out.log"), filepath.Join(dir, "nope"), filepath.Join(dir, "timing.log"))
+	if err == nil {
+		t.Error("expected an error for a missing input log")
+	}
+}
+
+func TestLoadRecording_DetectsLayout(t *testing.T) {
+	// session.log with .input and .timing companions: multi-file
+	multi := t.TempDir()
+	writeFiles(t, multi, map[string]string{
+		"session.log":    multiFileRecording["out.log"],
+		"session.input":  multiFileRecording["in.log"],
+		"session.timing": multiFileRecording["timing.log"],
+	})
+	rec, err := LoadRecording(filepath.Join(multi, "session.log"))
+	if err != nil {
+		t.Fatalf("LoadRecording failed: %v", err)
+	}
+	if rec.Layout != LayoutMultiFile || len(rec.TOC) != 2 {
+		t.Errorf("got layout %v with %d TOC entries, want multi-file with 2", rec.Layout, len(rec.TOC))
+	}
+
+	// session.log alone (or with only a classic timing file): single file
+	single := t.TempDir()
+	writeFiles(t, single, map[string]string{
+		"session.log":    "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\nhello\nScript done on Wed Dec 31 12:11:00 2025\n",
+		"session.timing": "0.010 6\n",
+	})
+	rec, err = LoadRecording(filepath.Join(single, "session.log"))
+	if err != nil {
+		t.Fatalf("LoadRecording failed: %v", err)
+	}
+	if rec.Layout != LayoutSingleFile || rec.TOC != nil {
+		t.Errorf("got layout %v with TOC %+v, want single-file without TOC", rec.Layout, rec.TOC)
+	}
+	if rec.Content != "hello" {
+		t.Errorf("Content = %q, want %q", rec.Content, "hello")
+	}
+}
@@ -0,0 +1,84 @@
+package playback
+
+import (
+	"github.com/choonkeat/record-tui/internal/grid"
+	"github.com/choonkeat/record-tui/internal/session"
+	"github.com/choonkeat/record-tui/internal/svg"
+)
+
+// Default screen size for RenderLastScreenText.
+const (
+	defaultScreenCols = 80
+	defaultScreenRows = 24
+)
+
+// Cell is one character position of a screen rendered by
+// RenderLastScreenText.
+type Cell struct {
+	Char rune   // ' ' where nothing was written
+	FG   string // Text color as "#rrggbb" (xterm.js default theme), "" = default
+	BG   string // Background color as "#rrggbb", "" = default (none)
+
+	Bold      bool
+	Dim       bool
+	Italic    bool
+	Underline bool
+	Strike    bool
+}
+
+// FinalScreen returns the last screen a recording shows, for a page of
+// just its end result (e.g. a build summary after `clear && make`):
+// session log content is replayed on an in-memory terminal of cols x rows
+// (0 = 80 x 24) and what it shows at the end is returned as terminal
+// output, ready for RenderHTML. Everything cleared or scrolled off before
+// it is discarded, and full-screen TUI sessions are skipped.
+// StripOptions.Redact is honored; the other options don't apply.
+func FinalScreen(content string, cols, rows int, opts ...StripOptions) string {
+	return session.FinalScreen(content, cols, rows, cleanOptions(opts))
+}
+
+// RenderLastScreenText replays terminal output on an in-memory screen of
+// cols x rows (0 = 80 x 24) and returns what it shows at the end, row by
+// row, e.g. for a thumbnail of a recording that a caller rasterizes or
+// draws as SVG. Cursor movement, erases, scrolling and SGR colors are
+// honored, so a TUI's last redraw comes out as it looked; output that
+// scrolled off the top is gone, as on a real terminal.
+//
+// Colors are resolved like RenderSVG's (inverse video swaps FG and BG).
+// content is terminal output, e.g. StripMetadata's result or the tail of
+// a session log.
+func RenderLastScreenText(content string, cols, rows int) [][]Cell {
+	if cols <= 0 {
+		cols = defaultScreenCols
+	}
+	if rows <= 0 {
+		rows = defaultScreenRows
+	}
+	screen := grid.New(cols, rows)
+	screen.Write(content)
+
+	cells := make([][]Cell, rows)
+	for r := range cells {
+		cells[r] = make([]Cell, cols)
+		for c := range cells[r] {
+			cell := screen.Cell(r, c)
+			ch := cell.Char
+			if ch == 0 || cell.Style.Attrs&grid.Hidden != 0 {
+				ch = ' '
+			}
+			fg, bg := svg.Colors(cell.Style)
+			attrs := cell.Style.Attrs
+			cells[r][c] = Cell{
+				Char:      ch,
+				FG:        fg,
+				BG:        bg,
+				Bold:      attrs&grid.Bold != 0,
+				Dim:       attrs&grid.Dim != 0,
+				Italic:    attrs&grid.Italic != 0,
+				Underline: attrs&grid.Underline != 0,
+				Strike:    attrs&grid.Strike != 0,
+			}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,79 @@
+package playback
+
+import "testing"
+
+// rowText returns the characters of a rendered row.
+func rowText(row []Cell) string {
+	var s []rune
+	for _, c := range row {
+		s = append(s, c.Char)
+	}
+	return string(s)
+}
+
+func TestRenderLastScreenText_CursorAddressing(t *testing.T) {
+	// Drawn out of order, then the title overwritten in place
+	content := "\x1b[2J\x1b[3;5Hstatus\x1b[1;1Htitle\x1b[2;10HX\x1b[1;1HT"
+
+	cells := RenderLastScreenText(content, 20, 4)
+
+	if len(cells) != 4 || len(cells[0]) != 20 {
+		t.Fatalf("got %d x %d cells, want 4 rows of 20", len(cells), len(cells[0]))
+	}
+	want := []string{
+		"Title               ",
+		"         X          ",
+		"    status          ",
+		"                    ",
+	}
+	for r, line := range want {
+		if got := rowText(cells[r]); got != line {
+			t.Errorf("row %d = %q, want %q", r, got, line)
+		}
+	}
+	if cells[1][9].Char != 'X' {
+		t.Errorf("cell (1, 9) = %q, want X", cells[1][9].Char)
+	}
+}
+
+func TestRenderLastScreenText_EraseChars(t *testing.T) {
+	cells := RenderLastScreenText("ABCDEFGH\r\x1b[3X", 10, 1)
+	if got := rowText(cells[0]); got != "   DEFGH  " {
+		t.Errorf("row 0 = %q, want %q", got, "   DEFGH  ")
+	}
+}
+
+func TestRenderLastScreenText_Colors(t *testing.T) {
+	cells := RenderLastScreenText("\x1b[1;31;44mE\x1b[0m \x1b[7mI\x1b[0m", 10, 1)
+
+	e := cells[0][0]
+	if e.Char != 'E' || e.FG != "#cc0000" || e.BG != "#3465a4" || !e.Bold {
+		t.Errorf("bold red on blue cell = %+v", e)
+	}
+	if plain := cells[0][1]; plain.FG != "" || plain.BG != "" || plain.Bold {
+		t.Errorf("reset cell should have default style, got %+v", plain)
+	}
+	// Inverse video swaps the default colors
+	if inv := cells[0][2]; inv.FG != "#1e1e1e" || inv.BG != "#d4d4d4" {
+		t.Errorf("inverse cell = %+v", inv)
+	}
+}
+
+func TestRenderLastScreenText_ScrollsAndDefaults(t *testing.T) {
+	cells := RenderLastScreenText("one\r\ntwo\r\nthree", 5, 2)
+	if got := rowText(cells[0]) + "|" + rowText(cells[1]); got != "two  |three" {
+		t.Errorf("last screen = %q, want the bottom two lines", got)
+	}
+
+	cells = RenderLastScreenText("", 0, 0)
+	if len(cells) != 24 || len(cells[0]) != 80 {
+		t.Errorf("default size = %d x %d, want 80 x 24", len(cells[0]), len(cells))
+	}
+}
+
+func TestFinalScreen(t *testing.T) {
+	got := FinalScreen("old output\r\n\x1b[2J\x1b[Hnew output\r\n", 20, 3)
+	if got != "new output" {
+		t.Errorf("FinalScreen = %q, want %q", got, "new output")
+	}
+}
@@ -0,0 +1,71 @@
+package playback
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/choonkeat/record-tui/internal/session"
+)
+
+// streamChunkSize is how much StripMetadataReader reads from its source at
+// a time.
+const streamChunkSize = 32 * 1024
+
+// StripMetadataReader is StripMetadata for a session.log too large to hold
+// in memory: the returned reader yields the cleaned content of r, reading
+// and cleaning r a chunk at a time as it is read. Only what cleaning a chunk
+// depends on is held back (the header until its first lines are in, the
+// last ~500 bytes until r ends, for the footer, and a scroll region until
+// it ends), so memory stays bounded for any length of log.
+//
+// The first chunk of r is read before StripMetadataReader returns, so an
+// unreadable source fails straight away; later read errors are returned
+// by the reader's Read. The cleaned content is the same as StripMetadata's
+// with no options, except around full-screen TUIs (see
+// session.StreamCleaner): output between a clear and an alternate screen
+// entered after it is kept, as a stream can't look back to drop it.
+func StripMetadataReader(r io.Reader) (io.Reader, error) {
+	sr := &stripReader{src: r, chunk: make([]byte, streamChunkSize)}
+	sr.cleaner = session.NewStreamCleaner(&sr.out)
+	sr.fill()
+	if sr.err != nil && sr.err != io.EOF {
+		return nil, sr.err
+	}
+	return sr, nil
+}
+
+// stripReader is the reader StripMetadataReader returns: it cleans its
+// source into out a chunk at a time, whenever out runs dry.
+type stripReader struct {
+	src     io.Reader
+	cleaner *session.StreamCleaner
+	chunk   []byte
+	out     bytes.Buffer
+	err     error // io.EOF once src is done and the cleaner closed
+}
+
+// Read implements io.Reader.
+func (sr *stripReader) Read(p []byte) (int, error) {
+	if sr.out.Len() == 0 {
+		sr.fill()
+	}
+	if sr.out.Len() > 0 {
+		return sr.out.Read(p)
+	}
+	return 0, sr.err
+}
+
+// fill reads and cleans chunks of the source until there is cleaned
+// content to read or the source ends (or fails).
+func (sr *stripReader) fill() {
+	for sr.out.Len() == 0 && sr.err == nil {
+		n, err := sr.src.Read(sr.chunk)
+		if n > 0 {
+			sr.cleaner.Write(sr.chunk[:n])
+		}
+		if err == io.EOF {
+			sr.cleaner.Close()
+		}
+		sr.err = err
+	}
+}
@@ -0,0 +1,79 @@
+package playback
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStripMetadataReader_MultiChunk(t *testing.T) {
+	input := "Script started on 2026-01-12 06:41:43+00:00 [COMMAND=\"bash\"]\n" +
+		strings.Repeat("$ make\r\n\x1b[1;32m✓ built\x1b[0m\r\n", 1000) +
+		"\x1b[H\x1b[2J$ echo done\r\ndone\r\n" +
+		"Script done on 2026-01-12 06:45:00+00:00 [COMMAND_EXIT_STATUS=\"0\"]\n"
+
+	// One byte per read, so every sequence and character is split
+	r, err := StripMetadataReader(iotest.OneByteReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("StripMetadataReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if want := StripMetadata(input); string(got) != want {
+		t.Errorf("got %d bytes, want StripMetadata's %d bytes", len(got), len(want))
+	}
+}
+
+func TestStripMetadataReader_Reads(t *testing.T) {
+	input := "Script started on Wed Dec 31 12:10:34 2025\nCommand: bash\n" +
+		strings.Repeat("hello world\r\n", 10000) +
+		"Script done on Wed Dec 31 12:11:22 2025\n"
+
+	r, err := StripMetadataReader(iotest.HalfReader(strings.NewReader(input)))
+	if err != nil {
+		t.Fatalf("StripMetadataReader: %v", err)
+	}
+	if err := iotest.TestReader(r, []byte(StripMetadata(input))); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStripMetadataReader_Errors(t *testing.T) {
+	errRead := errors.New("read failed")
+
+	// Failing straight away: returned by StripMetadataReader
+	if _, err := StripMetadataReader(iotest.ErrReader(errRead)); !errors.Is(err, errRead) {
+		t.Errorf("StripMetadataReader() error = %v, want %v", err, errRead)
+	}
+
+	// Failing later: returned by Read, after the content cleaned so far
+	input := "Script started on Wed Dec 31 12:10:34 2025\n" + strings.Repeat("hello world\r\n", 5000)
+	src := io.MultiReader(strings.NewReader(input), iotest.ErrReader(errRead))
+	r, err := StripMetadataReader(src)
+	if err != nil {
+		t.Fatalf("StripMetadataReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, errRead) {
+		t.Errorf("ReadAll() error = %v, want %v", err, errRead)
+	}
+	if !strings.HasPrefix(string(got), "hello world\r\n") {
+		t.Errorf("content before the error lost: %q...", got[:min(len(got), 40)])
+	}
+}
+
+func TestStripMetadataReader_Empty(t *testing.T) {
+	r, err := StripMetadataReader(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("StripMetadataReader: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || len(got) != 0 {
+		t.Errorf("ReadAll() = %q, %v, want empty", got, err)
+	}
+}
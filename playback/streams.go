@@ -0,0 +1,121 @@
+package playback
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StreamRange is a span of a session.log that one output stream wrote, as
+// listed in the session.stderr file of a recording made with stderr kept
+// separate (record-tui -separate-stderr).
+type StreamRange struct {
+	Offset int // Byte offset into session.log
+	Length int // Bytes
+}
+
+// StderrColor is the SGR sequence MarkStderr colors stderr output with
+// (red), and stderrColorEnd the one that ends it (default foreground).
+const (
+	StderrColor    = "\x1b[31m"
+	stderrColorEnd = "\x1b[39m"
+)
+
+// ParseStreamRanges parses a session.stderr file: one "<offset> <length>"
+// line per span of session.log the command wrote to stderr. Ranges are
+// returned sorted by offset.
+func ParseStreamRanges(r io.Reader) ([]StreamRange, error) {
+	var ranges []StreamRange
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: want \"<offset> <length>\", got %q", n, line)
+		}
+		offset, err := strconv.Atoi(fields[0])
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("line %d: invalid offset %q", n, fields[0])
+		}
+		length, err := strconv.Atoi(fields[1])
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("line %d: invalid length %q", n, fields[1])
+		}
+		ranges = append(ranges, StreamRange{Offset: offset, Length: length})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Offset < ranges[j].Offset })
+	return ranges, nil
+}
+
+// MarkStderr colors the stderr ranges of raw session.log content with
+// StderrColor, so stderr output stands out once rendered. Colors the
+// output sets itself still apply; a reset (ESC[0m) inside a range is
+// followed by StderrColor again. Ranges outside content are ignored.
+func MarkStderr(content string, stderr []StreamRange) string {
+	if len(stderr) == 0 {
+		return content
+	}
+	var sb strings.Builder
+	sb.Grow(len(content) + len(stderr)*(len(StderrColor)+len(stderrColorEnd)))
+	pos := 0
+	for _, r := range clampRanges(stderr, len(content)) {
+		sb.WriteString(content[pos:r.Offset])
+		text := content[r.Offset : r.Offset+r.Length]
+		text = strings.ReplaceAll(text, "\x1b[0m", "\x1b[0m"+StderrColor)
+		text = strings.ReplaceAll(text, "\x1b[m", "\x1b[m"+StderrColor)
+		sb.WriteString(StderrColor + text + stderrColorEnd)
+		pos = r.Offset + r.Length
+	}
+	sb.WriteString(content[pos:])
+	return sb.String()
+}
+
+// StreamFrames returns the stdout and stderr output of raw session.log
+// content as two frames, labeled "stdout" and "stderr", each cleaned as
+// StripMetadata cleans it. stderr is the content's stderr ranges (see
+// ParseStreamRanges); the rest is stdout, script header and footer
+// included, and stripped.
+func StreamFrames(content string, stderr []StreamRange, opts ...StripOptions) []Frame {
+	var stdoutText, stderrText strings.Builder
+	pos := 0
+	for _, r := range clampRanges(stderr, len(content)) {
+		stdoutText.WriteString(content[pos:r.Offset])
+		stderrText.WriteString(content[r.Offset : r.Offset+r.Length])
+		pos = r.Offset + r.Length
+	}
+	stdoutText.WriteString(content[pos:])
+
+	return []Frame{
+		{Timestamp: 0, Content: StripMetadata(stdoutText.String(), opts...), Label: "stdout"},
+		{Timestamp: 0, Content: StripMetadata(stderrText.String(), opts...), Label: "stderr"},
+	}
+}
+
+// clampRanges returns sorted ranges cut to fit within size bytes, without
+// overlaps or empty ranges.
+func clampRanges(ranges []StreamRange, size int) []StreamRange {
+	sorted := append([]StreamRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	var clamped []StreamRange
+	end := 0
+	for _, r := range sorted {
+		start := max(r.Offset, end)
+		stop := min(r.Offset+r.Length, size)
+		if start >= stop {
+			continue
+		}
+		clamped = append(clamped, StreamRange{Offset: start, Length: stop - start})
+		end = stop
+	}
+	return clamped
+}
@@ -0,0 +1,57 @@
+package playback
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseStreamRanges(t *testing.T) {
+	got, err := ParseStreamRanges(strings.NewReader("40 5\n\n10 3\n"))
+	if err != nil {
+		t.Fatalf("ParseStreamRanges: %v", err)
+	}
+	want := []StreamRange{{Offset: 10, Length: 3}, {Offset: 40, Length: 5}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"10\n", "x 3\n", "10 -1\n", "1 2 3\n"} {
+		if _, err := ParseStreamRanges(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseStreamRanges(%q) should fail", bad)
+		}
+	}
+}
+
+func TestMarkStderr(t *testing.T) {
+	content := "ok\r\nboom\x1b[0m!\r\ndone\r\n"
+	start := strings.Index(content, "boom")
+	end := strings.Index(content, "done")
+
+	got := MarkStderr(content, []StreamRange{{Offset: start, Length: end - start}, {Offset: 1000, Length: 5}})
+	want := "ok\r\n" + StderrColor + "boom\x1b[0m" + StderrColor + "!\r\n\x1b[39mdone\r\n"
+	if got != want {
+		t.Errorf("MarkStderr() = %q, want %q", got, want)
+	}
+	if got := MarkStderr(content, nil); got != content {
+		t.Errorf("MarkStderr(nil ranges) = %q, want content unchanged", got)
+	}
+}
+
+func TestStreamFrames(t *testing.T) {
+	content := "Script started on Wed Dec 31 12:10:34 2025\n" +
+		"$ make\r\nwarning: unused\r\nbuilt\r\n" +
+		"Script done on Wed Dec 31 12:11:22 2025\n"
+	start := strings.Index(content, "warning")
+	end := strings.Index(content, "built")
+
+	frames := StreamFrames(content, []StreamRange{{Offset: start, Length: end - start}})
+	if len(frames) != 2 {
+		t.Fatalf("got %d frames, want 2", len(frames))
+	}
+	if frames[0].Label != "stdout" || frames[0].Content != "$ make\r\nbuilt" {
+		t.Errorf("stdout frame = %+v", frames[0])
+	}
+	if frames[1].Label != "stderr" || frames[1].Content != "warning: unused" {
+		t.Errorf("stderr frame = %+v", frames[1])
+	}
+}
@@ -0,0 +1,41 @@
+package playback
+
+import (
+	"fmt"
+
+	"github.com/choonkeat/record-tui/internal/html"
+)
+
+// NamedRecording is a recording shown as one tab by RenderTabbedHTML.
+type NamedRecording struct {
+	Name    string     // Tab label, e.g. the test that was recorded ("Recording N" if empty)
+	Content string     // Cleaned output (see StripMetadata, Recording.Content)
+	TOC     []TOCEntry // Command navigation (optional)
+	Cols    uint16     // Terminal columns (0 = estimate from content, see Options.Cols)
+}
+
+// RenderTabbedHTML generates one HTML page showing several recordings, e.g.
+// those of a test suite run, with a tab per recording. Each is rendered by
+// RenderHTML, with its name as the title and its TOC for navigation, and
+// its terminal is only started when its tab is first shown. #tab-N in the
+// URL (0-indexed) opens tab N.
+//
+// Returns an error if recordings is empty.
+func RenderTabbedHTML(recordings []NamedRecording) (string, error) {
+	if len(recordings) == 0 {
+		return "", fmt.Errorf("no recordings to render")
+	}
+	tabs := make([]html.Tab, len(recordings))
+	for i, rec := range recordings {
+		page, err := RenderHTML([]Frame{{Timestamp: 0, Content: rec.Content}}, Options{
+			Title: rec.Name,
+			TOC:   rec.TOC,
+			Cols:  rec.Cols,
+		})
+		if err != nil {
+			return "", fmt.Errorf("recording %d: %w", i+1, err)
+		}
+		tabs[i] = html.Tab{Label: rec.Name, Page: page}
+	}
+	return html.RenderTabbedHTML("Terminal recordings", tabs)
+}
@@ -0,0 +1,82 @@
+package playback
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// tabPages returns the recording pages embedded in a RenderTabbedHTML page.
+func tabPages(t *testing.T, page string) []string {
+	t.Helper()
+	start := strings.Index(page, `<script type="application/json" id="tab-pages">`)
+	if start < 0 {
+		t.Fatal("page should embed the tabs' pages")
+	}
+	rest := page[start+len(`<script type="application/json" id="tab-pages">`):]
+	var pages []string
+	if err := json.Unmarshal([]byte(rest[:strings.Index(rest, "</script>")]), &pages); err != nil {
+		t.Fatalf("cannot parse the tabs' pages: %v", err)
+	}
+	return pages
+}
+
+// pageFrames returns the frames embedded in a RenderHTML page.
+func pageFrames(t *testing.T, page string) []Frame {
+	t.Helper()
+	const marker = "const framesBase64 = '"
+	start := strings.Index(page, marker)
+	if start < 0 {
+		t.Fatal("page should embed its frames")
+	}
+	encoded := page[start+len(marker):]
+	decoded, err := base64.StdEncoding.DecodeString(encoded[:strings.Index(encoded, "'")])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frames []Frame
+	if err := json.Unmarshal(decoded, &frames); err != nil {
+		t.Fatal(err)
+	}
+	return frames
+}
+
+func TestRenderTabbedHTML(t *testing.T) {
+	page, err := RenderTabbedHTML([]NamedRecording{
+		{Name: "TestLogin", Content: "$ go test -run TestLogin\r\nok\r\n", TOC: []TOCEntry{{Label: "go test -run TestLogin", Line: 0}}},
+		{Name: "TestLogout <slow>", Content: "$ go test -run TestLogout\r\nFAIL</script>\r\n"},
+	})
+	if err != nil {
+		t.Fatalf("RenderTabbedHTML failed: %v", err)
+	}
+
+	for _, want := range []string{`>TestLogin</button>`, `>TestLogout &lt;slow&gt;</button>`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page should contain tab %s", want)
+		}
+	}
+	if strings.Count(page, "</script>") != 2 {
+		t.Error("recording content should not end a <script> element early")
+	}
+
+	pages := tabPages(t, page)
+	if len(pages) != 2 {
+		t.Fatalf("got %d tab pages, want 2", len(pages))
+	}
+	for i, want := range []string{"ok\r\n", "FAIL</script>\r\n"} {
+		if frames := pageFrames(t, pages[i]); len(frames) != 1 || !strings.HasSuffix(frames[0].Content, want) {
+			t.Errorf("tab %d frames = %+v, want content ending in %q", i, frames, want)
+		}
+	}
+	if !strings.Contains(pages[0], `"go test -run TestLogin"`) {
+		t.Error("first tab should have its TOC")
+	}
+	if !strings.Contains(pages[1], "<title>TestLogout &lt;slow&gt;</title>") {
+		t.Error("each tab's page should be titled with its name")
+	}
+
+	if _, err := RenderTabbedHTML(nil); err == nil {
+		t.Error("RenderTabbedHTML(nil) should fail")
+	}
+}
@@ -2,12 +2,19 @@
 // It supports both macOS and Linux script command output formats.
 package playback
 
+import (
+	"regexp"
+
+	"github.com/choonkeat/record-tui/internal/timing"
+)
+
 // Frame represents a single frame of terminal content at a specific timestamp.
 // For static playback, use a single frame with Timestamp 0.
 // For animated playback, use multiple frames with increasing timestamps.
 type Frame struct {
-	Timestamp float64 `json:"timestamp"` // Time in seconds (cumulative from start)
-	Content   string  `json:"content"`   // Terminal content (with ANSI codes preserved)
+	Timestamp float64 `json:"timestamp"`       // Time in seconds (cumulative from start)
+	Content   string  `json:"content"`         // Terminal content (with ANSI codes preserved)
+	Label     string  `json:"label,omitempty"` // Optional chapter title, shown as a caption while the frame plays
 }
 
 // FooterLink represents a link to display in the footer alongside record-tui attribution.
@@ -16,23 +23,266 @@ type FooterLink struct {
 	URL  string // Link URL (e.g., "https://github.com/choonkeat/swe-swe")
 }
 
+// StripOptions configures optional StripMetadata behavior.
+type StripOptions struct {
+	// KeepAltScreen keeps the last visible frame of each full-screen TUI
+	// (alternate screen) session instead of discarding it. The frame is
+	// reconstructed by replaying the TUI output on an in-memory terminal,
+	// which is more expensive than discarding.
+	KeepAltScreen bool
+
+	// AltScreenLink returns where the n-th full-screen TUI session
+	// (1-indexed, as listed by AltScreenRegions) was saved, e.g.
+	// "alt-screen-1.log". Each discarded session is then marked by a
+	// separator linking there ("view alternate screen content"), so the
+	// page stays clean while the TUI output stays reachable. Ignored with
+	// KeepAltScreen.
+	AltScreenLink func(n int) string
+
+	// Redact replaces secrets in the cleaned content (AWS keys, GitHub and
+	// bearer tokens, password=... assignments, random-looking base64 blobs)
+	// with "[REDACTED]", so a recording can be shared after a token was
+	// echoed or pasted by mistake.
+	Redact bool
+
+	// EnsureTrailingNewline ends the cleaned content with exactly one line
+	// break (\r\n for CRLF content), as POSIX text tools expect, instead
+	// of the trailing empty lines being trimmed to none.
+	EnsureTrailingNewline bool
+}
+
 // Options configures HTML rendering behavior.
 type Options struct {
-	Title      string     // Page title (defaults to "Terminal" if empty)
-	FooterLink FooterLink // Optional co-branding link in footer (e.g., "generated by record-tui x swe-swe")
-	TOC        []TOCEntry // Optional table-of-contents entries for navigation
+	Title       string       // Page title (defaults to "Terminal" if empty)
+	Command     string       // Recorded command, shown as "Recording of: <command>" above the terminal (see ExtractCommand)
+	Cwd         string       // Working directory it was recorded in, added to that heading (optional)
+	Shell       string       // Shell it was recorded with, added to that heading (optional)
+	FooterLink  FooterLink   // Optional co-branding link in footer (e.g., "generated by record-tui x swe-swe")
+	FooterLinks []FooterLink // Additional footer links, joined by " x " after FooterLink
+	TOC         []TOCEntry   // Optional table-of-contents entries for navigation
+	Cols        uint16       // Terminal columns (0 = estimate from content, 80-240)
+
+	// StrictCSP emits a Content-Security-Policy <meta> tag and tags every
+	// <script>/<style> with a per-render random nonce, so the page works
+	// without 'unsafe-inline'.
+	StrictCSP bool
+
+	// HideAttribution removes the "generated by record-tui" footer link.
+	// A FooterLink, if set, is still shown on its own.
+	HideAttribution bool
+
+	// SourceHash is the hex-encoded SHA-256 of the session.log the page was
+	// rendered from. When set, the page carries provenance <meta> tags
+	// (record-tui:source-sha256, record-tui:version, record-tui:generated-at)
+	// so a viewer can check it against a specific recording.
+	SourceHash string
+
+	// Version is the record-tui version recorded in the page's
+	// record-tui:version <meta> tag, e.g. the CLI's -ldflags version. Empty
+	// uses the module version from the build info (only with SourceHash).
+	Version string
+
+	// Embedded renders a minimal page for showing inside an <iframe> (e.g.
+	// in a blog post): no footer, TOC navigation or viewer controls, a
+	// transparent background, and the terminal width fitted to the iframe
+	// instead of the recording. The page posts its height to the host as a
+	// {type: "record-tui:height", height: <px>} message so the iframe can be
+	// sized to fit.
+	Embedded bool
+
+	// ExtraCSS is custom CSS (e.g. brand colors and fonts) added in its own
+	// <style> element after the built-in styles, so it can override them.
+	// Any "</style" in it is escaped so it can't end the element early.
+	ExtraCSS string
+
+	// Responsive fits the terminal's columns to the browser window instead
+	// of the recorded width, and writes the recording again whenever the
+	// window is resized so long lines reflow to the new width.
+	Responsive bool
+
+	// ColorMode "16" rewrites 256-color and true-color sequences in the
+	// frames to the nearest of the 16 standard colors, for viewers and
+	// printers that render extended colors poorly. Empty (the default)
+	// preserves the original colors.
+	ColorMode string
+
+	// PageLinks are links to other pages of a recording split into several
+	// pages (e.g. previous, index and next), shown above and below the
+	// terminal. Links missing Text or URL are skipped.
+	PageLinks []FooterLink
+
+	// Minimap adds a strip along the right edge of the page with a tick at
+	// each TOC command's position, clickable to jump there. The current
+	// command's tick is highlighted as the page scrolls.
+	Minimap bool
+
+	// MaxRows caps the rendered output (0 = 100000 rows). Content with
+	// more lines keeps the first MaxRows-1 and ends in an "output truncated
+	// (N rows omitted)" notice, instead of the excess silently going
+	// missing. StreamingOptions.MaxRows caps streamed output the same way.
+	MaxRows uint32
+
+	// ErrorLines are lines of error output (0-indexed, see FindErrorLines)
+	// the viewer can step through with n / p, alongside command navigation.
+	// Not shown when Embedded.
+	ErrorLines []int
+
+	// TypedInput is what was typed during the recording (see
+	// ReconstructInput), shown in a collapsible "Typed input" panel below
+	// the terminal so keystrokes can be followed apart from the output,
+	// e.g. when teaching. Not shown when Embedded.
+	TypedInput string
+
+	// LineTimes is when each output line appeared (see LineTimes), embedded
+	// in the page as a JSON array in <script type="application/json"
+	// id="line-times">, element i being line i's time in seconds, so
+	// external tools can sync narration to the recording. Not rendered.
+	LineTimes []float64
+
+	// Collapsed starts the page with only the TOC's command list, for very
+	// long recordings: the terminal is written once a command is picked,
+	// and the page goes to it. Links to a command or line (#input-N,
+	// #line-N) load it straight away. Needs TOC; not shown when Embedded.
+	Collapsed bool
+
+	// OpenGraph adds Open Graph <meta> tags so links to the page unfurl as
+	// preview cards in chat apps: og:title (Title), og:description
+	// (OpenGraphDescription, or "Terminal recording of <Command>" if empty)
+	// and, if OpenGraphImage is set, og:image. Most apps only fetch an
+	// absolute image URL, e.g. a RenderSVG thumbnail published next to the
+	// page (some don't show SVG, so a PNG of it is safer).
+	OpenGraph            bool
+	OpenGraphDescription string
+	OpenGraphImage       string
+
+	// Timing is the recording's timing entries (see timing.Parse), embedded
+	// base64-encoded so the ▶ Play button replays the last frame's content
+	// in the browser at its recorded pace, each output entry's ByteCount
+	// bytes after its Delay, without building frames here (see
+	// BuildFrames). The byte counts are of the output as recorded, so the
+	// pace is approximate where StripMetadata's cleaning removed output;
+	// whatever is left over is written at the end. Replaces frame
+	// playback. Not shown when Embedded.
+	Timing []timing.Entry
+}
+
+// IndexPage is one page of a recording split into several pages, as listed
+// by RenderIndexHTML.
+type IndexPage struct {
+	URL string     // Page URL, relative to the index page (e.g. "page-1.html")
+	TOC []TOCEntry // Commands on the page, linked to from the index
+}
+
+// SVGOptions configures RenderSVG output.
+type SVGOptions struct {
+	Cols     int     // Terminal width in cells (0 = 80)
+	FontSize float64 // Font size in pixels (0 = 15, matching the HTML viewer)
+
+	// Overflow is how lines longer than Cols are drawn: "wrap" onto the
+	// next row as the terminal did (the default), "truncate" at Cols with
+	// an ellipsis, or "none" to keep them whole, widening the snapshot (to
+	// at most 500 cells; lines wider still are truncated there).
+	Overflow string
 }
 
 // StreamingOptions configures streaming HTML rendering behavior.
 // Use this for large terminal recordings where embedding data in HTML causes slow loading.
 // The generated HTML fetches session data from DataURL and streams it to xterm.js.
 type StreamingOptions struct {
-	Title      string     // Page title (defaults to "Terminal" if empty)
-	DataURL    string     // URL to fetch session data from (e.g., "./session.log", "/api/recording/123")
-	FooterLink FooterLink // Optional co-branding link in footer
-	Cols       uint16     // Terminal columns (0 = auto-detect, default 240)
-	MaxRows    uint32     // Maximum initial rows before auto-resize (0 = default 100000)
-	TOC        []TOCEntry // Optional table-of-contents entries for navigation
+	Title       string       // Page title (defaults to "Terminal" if empty)
+	Command     string       // Recorded command heading (see Options.Command)
+	DataURL     string       // URL to fetch session data from (e.g., "./session.log", "/api/recording/123")
+	FooterLink  FooterLink   // Optional co-branding link in footer
+	FooterLinks []FooterLink // Additional footer links (see Options.FooterLinks)
+	Cols        uint16       // Terminal columns (0 = auto-detect, default 240)
+	MaxRows     uint32       // Row cap and initial rows before auto-resize (0 = default 100000; see Options.MaxRows; not when tailing)
+	Scrollback  uint32       // xterm.js scrollback rows when tailing, capping memory (0 = default 10000; see WebSocketURL)
+	TOC         []TOCEntry   // Optional table-of-contents entries for navigation
+	StrictCSP   bool         // Emit a nonce-based CSP meta tag (see Options.StrictCSP)
+
+	HideAttribution bool   // Remove the "generated by record-tui" footer link (see Options.HideAttribution)
+	ExtraCSS        string // Custom CSS added after the built-in styles (see Options.ExtraCSS)
+
+	// Follow starts the page in follow mode, for watching a live recording:
+	// output is rendered as it arrives and the page stays scrolled to the
+	// newest line, like tail -f. Scrolling up pauses following and scrolling
+	// back to the bottom resumes it. The viewer can always toggle it.
+	Follow bool
+
+	// Minimap adds the command minimap strip (see Options.Minimap).
+	Minimap bool
+
+	// Collapsed starts the page with only the command list (see
+	// Options.Collapsed). DataURL isn't fetched until a command is picked.
+	Collapsed bool
+
+	// SameOriginDataURL rejects a DataURL that could be on another origin:
+	// only relative URLs ("./session.log", "/api/recording/123") are
+	// allowed, not absolute or protocol-relative ("//host/x") ones. Set it
+	// when DataURL comes from someone else, e.g. serving pages for several
+	// tenants. URLs with a scheme other than http or https are always
+	// rejected (see ErrUnsafeDataURL).
+	SameOriginDataURL bool
+
+	// WebSocketURL makes the page tail session data over a WebSocket instead
+	// of fetching DataURL once, for watching a recording live while it's
+	// made: output is written as it arrives, and the page reconnects if the
+	// connection drops. Serve it with TailHandler. Relative URLs resolve
+	// against the page (http becoming ws). With StrictCSP it must be on the
+	// page's own host. Leave empty to fetch DataURL (the default).
+	//
+	// A tailed recording isn't capped at MaxRows like a fetched one: its
+	// terminal is as tall as the browser window, and once it outgrows that,
+	// the oldest rows scroll into the Scrollback rows of xterm.js scrollback
+	// and are then dropped, so a long session can't use unbounded browser
+	// memory and its newest output stays in view.
+	WebSocketURL string
+}
+
+// TailOptions configures TailHandler.
+type TailOptions struct {
+	// AllowedOrigins are the hosts ("example.com", "example.com:8443"),
+	// besides the one serving TailHandler, whose pages may connect to it,
+	// e.g. where the page is served from elsewhere. A WebSocket opened by
+	// a page on any other host gets 403 Forbidden, so other sites can't
+	// read the log through a viewer's browser. "*" allows any.
+	AllowedOrigins []string
+}
+
+// DefaultChapterPrefix marks a typed line as a chapter bookmark (see TOCOptions).
+const DefaultChapterPrefix = timing.DefaultChapterPrefix
+
+// DefaultNoiseCommands is the commands usually left out of navigation (see
+// TOCOptions.Exclude): bare ls, cd and clear.
+var DefaultNoiseCommands = timing.DefaultNoiseCommands
+
+// TOCOptions configures optional BuildTOC behavior.
+type TOCOptions struct {
+	// ChapterPrefix marks a typed line as a named bookmark: typing
+	// "#chapter: Setup" (a shell comment, so nothing runs) adds a TOC entry
+	// labeled "Setup" at that point. Empty uses DefaultChapterPrefix.
+	ChapterPrefix string
+
+	// Exclude leaves commands typed exactly as one of these (e.g.
+	// DefaultNoiseCommands) out of the TOC. "ls" drops a bare ls but keeps
+	// "ls -la". Commands are matched as typed, so a chapter bookmark is
+	// never left out for its name.
+	Exclude []string
+
+	// ExcludePattern, if set, also leaves out commands it matches.
+	ExcludePattern *regexp.Regexp
+
+	// CollapseRepeats merges a command run several times in a row (e.g.
+	// "npm test" while debugging) into one entry at its first run, labeled
+	// with the count: "npm test (×10)". The same command with others
+	// between its runs keeps an entry per run.
+	CollapseRepeats bool
+
+	// PromptPattern matches the shell prompt BuildTOCFromPrompts looks for
+	// at the start of a line, e.g. `^➜ +\S+ ` for a custom PS1. It's matched
+	// against the line without colors, and what follows the match is the
+	// command. nil uses DefaultPromptPattern (bash, zsh and fish defaults).
+	PromptPattern *regexp.Regexp
 }
 
 // TOCEntry represents a navigation point in the terminal recording.